@@ -0,0 +1,47 @@
+package lcov
+
+import "sync"
+
+// Aggregator incrementally folds summaries and file records into a single Summary.
+// It is safe for concurrent use, so results parsed in parallel goroutines can be
+// added without any additional locking.
+type Aggregator struct {
+	mu      sync.Mutex
+	summary Summary
+}
+
+// NewAggregator creates an empty Aggregator
+func NewAggregator() *Aggregator {
+	return &Aggregator{}
+}
+
+// Add folds the totals of an already computed summary into the aggregate
+func (a *Aggregator) Add(s *Summary) {
+	if s == nil {
+		return
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.summary.addTotals(s)
+}
+
+// AddFile folds the counters of a single file record into the aggregate
+func (a *Aggregator) AddFile(f FileRecord) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.summary.addFile(&f)
+}
+
+// Result returns a snapshot of the aggregated summary with its coverage rates computed.
+// The Aggregator can keep being used after calling Result.
+func (a *Aggregator) Result() *Summary {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	result := a.summary
+	result.computeRates()
+	return &result
+}
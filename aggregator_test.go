@@ -0,0 +1,50 @@
+package lcov
+
+import (
+	"os"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAggregatorAdd(t *testing.T) {
+	aggregator := NewAggregator()
+	for _, path := range []string{"testdata/sample.lcov", "testdata/complex.lcov", "testdata/with_functions_and_branches.lcov"} {
+		file, err := os.Open(path)
+		require.NoError(t, err)
+		summary, err := Summarize(file)
+		file.Close()
+		require.NoError(t, err)
+		aggregator.Add(summary)
+	}
+
+	result := aggregator.Result()
+	assert.Equal(t, 7, result.TotalFiles)
+	assert.Equal(t, int64(34), result.TotalLines)           // 9 + 15 + 10
+	assert.Equal(t, int64(24), result.CoveredLines)         // 6 + 11 + 7
+	assert.InDelta(t, 70.59, result.LineCoverageRate, 0.01) // 24/34 * 100
+	assert.InDelta(t, 75.0, result.FunctionCoverageRate, 0.01)
+	assert.InDelta(t, 100.0, result.BranchCoverageRate, 0.01)
+}
+
+func TestAggregatorConcurrentAddFile(t *testing.T) {
+	aggregator := NewAggregator()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			aggregator.AddFile(FileRecord{Path: "file.go", LinesFound: 4, LinesHit: 1})
+		}()
+	}
+	wg.Wait()
+
+	result := aggregator.Result()
+	assert.Equal(t, 100, result.TotalFiles)
+	assert.Equal(t, int64(400), result.TotalLines)
+	assert.Equal(t, int64(100), result.CoveredLines)
+	assert.InDelta(t, 25.0, result.LineCoverageRate, 0.01)
+}
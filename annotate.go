@@ -0,0 +1,45 @@
+package lcov
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// WriteGcov writes a source file annotated with the line data of its detailed
+// record (see WithDetails), in the layout of gcov: every line is prefixed with its
+// execution count, '#####' when it was never executed, or '-' when it isn't
+// instrumented. The output can be read back with ParseGcov.
+func WriteGcov(w io.Writer, source io.Reader, f *FileRecord) error {
+	counts := make(map[int]int64, len(f.Lines))
+	for _, l := range f.Lines {
+		counts[l.Line] = addCount(counts[l.Line], l.Count)
+	}
+
+	ew := &errWriter{w: w}
+	ew.printf("%9s:%5d:Source:%s\n", "-", 0, f.Path)
+
+	scanner := bufio.NewScanner(source)
+	scanner.Buffer(make([]byte, 0, 64*1024), 10*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		ew.printf("%9s:%5d:%s\n", gcovCount(counts, line), line, scanner.Text())
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("error reading source: %w", err)
+	}
+	return ew.err
+}
+
+// gcovCount formats the execution count of a line as gcov does
+func gcovCount(counts map[int]int64, line int) string {
+	count, ok := counts[line]
+	switch {
+	case !ok:
+		return "-"
+	case count == 0:
+		return "#####"
+	default:
+		return strconv.FormatInt(count, 10)
+	}
+}
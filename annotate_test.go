@@ -0,0 +1,30 @@
+package lcov
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteGcov(t *testing.T) {
+	source := "package main\n\nfunc main() {\n\tprintln()\n}\n"
+	f := &FileRecord{Path: "main.go", Lines: []LineData{{Line: 3, Count: 12}, {Line: 4, Count: 0}}}
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteGcov(&buf, strings.NewReader(source), f))
+	assert.Equal(t, ""+
+		"        -:    0:Source:main.go\n"+
+		"        -:    1:package main\n"+
+		"        -:    2:\n"+
+		"       12:    3:func main() {\n"+
+		"    #####:    4:\tprintln()\n"+
+		"        -:    5:}\n", buf.String())
+
+	summary, err := ParseGcov(&buf)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), summary.TotalLines)
+	assert.Equal(t, int64(1), summary.CoveredLines)
+}
@@ -0,0 +1,203 @@
+package lcov
+
+import (
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// Attestation constants, following the in-toto statement and DSSE envelope layouts
+const (
+	AttestationPayloadType   = "application/vnd.in-toto+json"
+	AttestationStatementType = "https://in-toto.io/Statement/v1"
+	CoveragePredicateType    = "https://github.com/shastick/go-lcov-summary/coverage/v1"
+)
+
+// Signer signs attestation payloads. Implementations can wrap a KMS, sigstore or any
+// other key management; NewEd25519Signer provides a local key based one.
+type Signer interface {
+	// Sign returns the signature of the payload and the identifier of the key used
+	Sign(payload []byte) (signature []byte, keyID string, err error)
+}
+
+// Verifier checks attestation signatures
+type Verifier interface {
+	Verify(payload, signature []byte, keyID string) error
+}
+
+// Statement is the attested claim: the coverage of a set of subjects (e.g. release artifacts)
+type Statement struct {
+	Type          string            `json:"_type"`
+	Subject       []Subject         `json:"subject"`
+	PredicateType string            `json:"predicateType"`
+	Predicate     CoveragePredicate `json:"predicate"`
+}
+
+// Subject identifies an artifact by name and digests
+type Subject struct {
+	Name   string            `json:"name"`
+	Digest map[string]string `json:"digest"`
+}
+
+// CoveragePredicate holds the coverage summary and the fingerprint of the tracefile it was computed from
+type CoveragePredicate struct {
+	Tracefile Subject        `json:"tracefile"`
+	Files     int            `json:"files"`
+	Lines     CoverageMetric `json:"lines"`
+	Functions CoverageMetric `json:"functions"`
+	Branches  CoverageMetric `json:"branches"`
+	// Records is the coverage of every source file, when the summary was parsed WithDetails
+	Records []FileSummary `json:"records,omitempty"`
+}
+
+// CoverageMetric is the JSON representation of a single coverage metric
+type CoverageMetric struct {
+	Covered int64   `json:"covered"`
+	Total   int64   `json:"total"`
+	Rate    float64 `json:"rate"`
+}
+
+// Envelope is a DSSE envelope carrying a signed statement
+type Envelope struct {
+	PayloadType string              `json:"payloadType"`
+	Payload     string              `json:"payload"`
+	Signatures  []EnvelopeSignature `json:"signatures"`
+}
+
+// EnvelopeSignature is a single signature of an Envelope
+type EnvelopeSignature struct {
+	KeyID string `json:"keyid"`
+	Sig   string `json:"sig"`
+}
+
+// Fingerprint returns the hex encoded SHA-256 digest of a tracefile
+func Fingerprint(reader io.Reader) (string, error) {
+	h := sha256.New()
+	if _, err := io.Copy(h, reader); err != nil {
+		return "", fmt.Errorf("error reading tracefile: %w", err)
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// NewStatement creates a coverage statement about the given subjects. The
+// statement includes the per-file records of a summary parsed WithDetails.
+func NewStatement(s *Summary, tracefile Subject, subjects ...Subject) *Statement {
+	if subjects == nil {
+		subjects = []Subject{}
+	}
+	var records []FileSummary
+	if len(s.Files) > 0 {
+		records = s.FileSummaries()
+	}
+	return &Statement{
+		Type:          AttestationStatementType,
+		Subject:       subjects,
+		PredicateType: CoveragePredicateType,
+		Predicate: CoveragePredicate{
+			Tracefile: tracefile,
+			Files:     s.TotalFiles,
+			Lines:     CoverageMetric{Covered: s.CoveredLines, Total: s.TotalLines, Rate: s.LineCoverageRate},
+			Functions: CoverageMetric{Covered: s.CoveredFunctions, Total: s.TotalFunctions, Rate: s.FunctionCoverageRate},
+			Branches:  CoverageMetric{Covered: s.CoveredBranches, Total: s.TotalBranches, Rate: s.BranchCoverageRate},
+			Records:   records,
+		},
+	}
+}
+
+// WriteAttestation signs the statement and writes it as a DSSE envelope
+func WriteAttestation(w io.Writer, statement *Statement, signer Signer) error {
+	payload, err := json.Marshal(statement)
+	if err != nil {
+		return err
+	}
+	signature, keyID, err := signer.Sign(pae(AttestationPayloadType, payload))
+	if err != nil {
+		return fmt.Errorf("error signing attestation: %w", err)
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(Envelope{
+		PayloadType: AttestationPayloadType,
+		Payload:     base64.StdEncoding.EncodeToString(payload),
+		Signatures:  []EnvelopeSignature{{KeyID: keyID, Sig: base64.StdEncoding.EncodeToString(signature)}},
+	})
+}
+
+// ReadAttestation reads a DSSE envelope, verifies that at least one of its
+// signatures is valid and returns the attested statement.
+func ReadAttestation(reader io.Reader, verifier Verifier) (*Statement, error) {
+	var envelope Envelope
+	if err := json.NewDecoder(reader).Decode(&envelope); err != nil {
+		return nil, fmt.Errorf("invalid attestation envelope: %w", err)
+	}
+	if envelope.PayloadType != AttestationPayloadType {
+		return nil, fmt.Errorf("unsupported attestation payload type: %s", envelope.PayloadType)
+	}
+	payload, err := base64.StdEncoding.DecodeString(envelope.Payload)
+	if err != nil {
+		return nil, fmt.Errorf("invalid attestation payload: %w", err)
+	}
+
+	verified := false
+	for _, s := range envelope.Signatures {
+		sig, err := base64.StdEncoding.DecodeString(s.Sig)
+		if err == nil && verifier.Verify(pae(envelope.PayloadType, payload), sig, s.KeyID) == nil {
+			verified = true
+			break
+		}
+	}
+	if !verified {
+		return nil, errors.New("no valid attestation signature")
+	}
+
+	var statement Statement
+	if err := json.Unmarshal(payload, &statement); err != nil {
+		return nil, fmt.Errorf("invalid attestation statement: %w", err)
+	}
+	if statement.PredicateType != CoveragePredicateType {
+		return nil, fmt.Errorf("unsupported predicate type: %s", statement.PredicateType)
+	}
+	return &statement, nil
+}
+
+// pae is the DSSE pre-authentication encoding of a payload, which is what gets signed
+func pae(payloadType string, payload []byte) []byte {
+	return fmt.Appendf(nil, "DSSEv1 %d %s %d %s", len(payloadType), payloadType, len(payload), payload)
+}
+
+// Ed25519Signer signs and verifies attestations with an ed25519 key pair
+type Ed25519Signer struct {
+	KeyID      string
+	PrivateKey ed25519.PrivateKey
+	PublicKey  ed25519.PublicKey
+}
+
+// NewEd25519Signer creates a signer from a private key
+func NewEd25519Signer(keyID string, key ed25519.PrivateKey) *Ed25519Signer {
+	return &Ed25519Signer{KeyID: keyID, PrivateKey: key, PublicKey: key.Public().(ed25519.PublicKey)}
+}
+
+// Sign implements Signer
+func (s *Ed25519Signer) Sign(payload []byte) ([]byte, string, error) {
+	if s.PrivateKey == nil {
+		return nil, "", errors.New("no private key")
+	}
+	return ed25519.Sign(s.PrivateKey, payload), s.KeyID, nil
+}
+
+// Verify implements Verifier
+func (s *Ed25519Signer) Verify(payload, signature []byte, keyID string) error {
+	if keyID != s.KeyID {
+		return fmt.Errorf("unknown key: %s", keyID)
+	}
+	if !ed25519.Verify(s.PublicKey, payload, signature) {
+		return errors.New("invalid signature")
+	}
+	return nil
+}
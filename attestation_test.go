@@ -0,0 +1,83 @@
+package lcov
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAttestationRoundTrip(t *testing.T) {
+	data, err := os.ReadFile("testdata/with_functions_and_branches.lcov")
+	require.NoError(t, err)
+
+	fingerprint, err := Fingerprint(bytes.NewReader(data))
+	require.NoError(t, err)
+	assert.Len(t, fingerprint, 64)
+
+	summary, err := Summarize(bytes.NewReader(data))
+	require.NoError(t, err)
+
+	tracefile := Subject{Name: "coverage.info", Digest: map[string]string{"sha256": fingerprint}}
+	artifact := Subject{Name: "app-1.0.tar.gz", Digest: map[string]string{"sha256": "abc"}}
+	statement := NewStatement(summary, tracefile, artifact)
+
+	signer := NewEd25519Signer("ci-key", ed25519.NewKeyFromSeed(make([]byte, ed25519.SeedSize)))
+	var out bytes.Buffer
+	require.NoError(t, WriteAttestation(&out, statement, signer))
+	assert.Contains(t, out.String(), `"payloadType": "application/vnd.in-toto+json"`)
+
+	read, err := ReadAttestation(bytes.NewReader(out.Bytes()), signer)
+	require.NoError(t, err)
+	assert.Equal(t, statement, read)
+	assert.Equal(t, CoverageMetric{Covered: 7, Total: 10, Rate: 70}, read.Predicate.Lines)
+	assert.Equal(t, fingerprint, read.Predicate.Tracefile.Digest["sha256"])
+
+	other := NewEd25519Signer("ci-key", ed25519.NewKeyFromSeed(bytes.Repeat([]byte{1}, ed25519.SeedSize)))
+	_, err = ReadAttestation(bytes.NewReader(out.Bytes()), other)
+	assert.EqualError(t, err, "no valid attestation signature")
+}
+
+func TestReadAttestationErrors(t *testing.T) {
+	signer := NewEd25519Signer("ci-key", ed25519.NewKeyFromSeed(make([]byte, ed25519.SeedSize)))
+
+	tests := []struct {
+		name  string
+		input string
+		err   string
+	}{
+		{name: "not json", input: "nope", err: "invalid attestation envelope"},
+		{name: "payload type", input: `{"payloadType":"text/plain"}`, err: "unsupported attestation payload type: text/plain"},
+		{name: "payload encoding", input: `{"payloadType":"application/vnd.in-toto+json","payload":"!"}`, err: "invalid attestation payload"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ReadAttestation(strings.NewReader(tt.input), signer)
+			assert.ErrorContains(t, err, tt.err)
+		})
+	}
+}
+
+func TestAttestationRecords(t *testing.T) {
+	data, err := os.ReadFile("testdata/sample.lcov")
+	require.NoError(t, err)
+	summary, err := Summarize(bytes.NewReader(data), WithDetails())
+	require.NoError(t, err)
+
+	statement := NewStatement(summary, Subject{Name: "sample.lcov"})
+	require.Len(t, statement.Predicate.Records, 2)
+	assert.Equal(t, "/path/to/source/file1.go", statement.Predicate.Records[0].Path)
+	assert.Equal(t, []LineRange{{Start: 2, End: 2}, {Start: 4, End: 4}}, statement.Predicate.Records[0].UncoveredLines)
+
+	signer := NewEd25519Signer("ci-key", ed25519.NewKeyFromSeed(make([]byte, ed25519.SeedSize)))
+	var out bytes.Buffer
+	require.NoError(t, WriteAttestation(&out, statement, signer))
+	read, err := ReadAttestation(bytes.NewReader(out.Bytes()), signer)
+	require.NoError(t, err)
+	assert.Equal(t, statement, read)
+}
@@ -0,0 +1,78 @@
+package lcov
+
+import (
+	"fmt"
+	"html"
+	"io"
+)
+
+// Shields.io colors of the badge values
+const (
+	badgeRed     = "#e05d44"
+	badgeYellow  = "#dfb317"
+	badgeGreen   = "#4c1"
+	badgeUnknown = "#9f9f9f"
+)
+
+// badgeTemplate is a flat shields-style badge. Its parameters are the total width,
+// the label, the label width, the value width, the value color, the label text
+// center, the value, and the value text center; text is rendered at 10 times its
+// size and scaled down, like shields does.
+const badgeTemplate = `<svg xmlns="http://www.w3.org/2000/svg" width="%[1]d" height="20" role="img" aria-label="%[2]s: %[7]s">
+  <title>%[2]s: %[7]s</title>
+  <linearGradient id="s" x2="0" y2="100%%">
+    <stop offset="0" stop-color="#bbb" stop-opacity=".1"/>
+    <stop offset="1" stop-opacity=".1"/>
+  </linearGradient>
+  <clipPath id="r">
+    <rect width="%[1]d" height="20" rx="3" fill="#fff"/>
+  </clipPath>
+  <g clip-path="url(#r)">
+    <rect width="%[3]d" height="20" fill="#555"/>
+    <rect x="%[3]d" width="%[4]d" height="20" fill="%[5]s"/>
+    <rect width="%[1]d" height="20" fill="url(#s)"/>
+  </g>
+  <g fill="#fff" text-anchor="middle" font-family="Verdana,Geneva,DejaVu Sans,sans-serif" text-rendering="geometricPrecision" font-size="110">
+    <text x="%[6]d" y="150" fill="#010101" fill-opacity=".3" transform="scale(.1)">%[2]s</text>
+    <text x="%[6]d" y="140" transform="scale(.1)">%[2]s</text>
+    <text x="%[8]d" y="150" fill="#010101" fill-opacity=".3" transform="scale(.1)">%[7]s</text>
+    <text x="%[8]d" y="140" transform="scale(.1)">%[7]s</text>
+  </g>
+</svg>
+`
+
+// WriteBadge writes a shields-style SVG badge showing the line coverage rate of a
+// summary, colored red, yellow or green according to the thresholds. A summary
+// without line data shows 'unknown' in grey.
+func WriteBadge(w io.Writer, label string, s *Summary, thresholds ColorThresholds) error {
+	value, color := "unknown", badgeUnknown
+	if s.TotalLines > 0 {
+		value = fmt.Sprintf("%.1f%%", s.LineCoverageRate)
+		color = badgeColor(s.LineCoverageRate, thresholds)
+	}
+
+	labelWidth := badgeTextWidth(label)
+	valueWidth := badgeTextWidth(value)
+	_, err := fmt.Fprintf(w, badgeTemplate,
+		labelWidth+valueWidth, html.EscapeString(label), labelWidth, valueWidth, color,
+		labelWidth*10/2, html.EscapeString(value), labelWidth*10+valueWidth*10/2)
+	return err
+}
+
+// badgeColor returns the color of a coverage rate, in percent, according to the thresholds
+func badgeColor(rate float64, thresholds ColorThresholds) string {
+	switch {
+	case rate < thresholds.Medium:
+		return badgeRed
+	case rate < thresholds.High:
+		return badgeYellow
+	default:
+		return badgeGreen
+	}
+}
+
+// badgeTextWidth approximates the width in pixels of a badge section, with the
+// average width of 11px Verdana characters and 5px of padding on each side
+func badgeTextWidth(text string) int {
+	return len([]rune(text))*7 + 10
+}
@@ -0,0 +1,42 @@
+package lcov
+
+import (
+	"bytes"
+	"encoding/xml"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteBadge(t *testing.T) {
+	tests := []struct {
+		name    string
+		summary *Summary
+		value   string
+		color   string
+	}{
+		{name: "low", summary: &Summary{TotalLines: 10, CoveredLines: 5, LineCoverageRate: 50}, value: "50.0%", color: badgeRed},
+		{name: "medium", summary: &Summary{TotalLines: 10, CoveredLines: 8, LineCoverageRate: 80}, value: "80.0%", color: badgeYellow},
+		{name: "high", summary: &Summary{TotalLines: 10, CoveredLines: 9, LineCoverageRate: 90}, value: "90.0%", color: badgeGreen},
+		{name: "no data", summary: &Summary{}, value: "unknown", color: badgeUnknown},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			require.NoError(t, WriteBadge(&out, "coverage", tt.summary, DefaultColorThresholds))
+
+			// The badge must be well-formed XML
+			require.NoError(t, xml.Unmarshal(out.Bytes(), new(struct{})))
+			assert.Contains(t, out.String(), `aria-label="coverage: `+tt.value+`"`)
+			assert.Contains(t, out.String(), `fill="`+tt.color+`"`)
+		})
+	}
+}
+
+func TestWriteBadgeEscapesLabel(t *testing.T) {
+	var out bytes.Buffer
+	require.NoError(t, WriteBadge(&out, "a<b>&c", &Summary{}, DefaultColorThresholds))
+	assert.Contains(t, out.String(), "<title>a&lt;b&gt;&amp;c: unknown</title>")
+}
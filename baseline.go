@@ -0,0 +1,78 @@
+package lcov
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// ReadBaseline reads a baseline summary, as written by RenderJSON
+func ReadBaseline(r io.Reader) (JSONSummary, error) {
+	var baseline JSONSummary
+	if err := json.NewDecoder(r).Decode(&baseline); err != nil {
+		return JSONSummary{}, fmt.Errorf("invalid baseline: %w", err)
+	}
+	return baseline, nil
+}
+
+// Regression describes a metric of a summary that went down compared to a baseline
+type Regression struct {
+	Metric   string
+	Rate     float64
+	Baseline float64
+}
+
+// String formats the regression for display
+func (r Regression) String() string {
+	return fmt.Sprintf("%s coverage %.1f%% is below the baseline %.1f%%", r.Metric, r.Rate, r.Baseline)
+}
+
+// CheckBaseline returns the metrics of a summary more than tolerance percentage points
+// below the baseline. Metrics without data in the summary or the baseline are not checked.
+func CheckBaseline(s *Summary, baseline JSONSummary, tolerance float64) []Regression {
+	metrics := []struct {
+		name     string
+		hit      int64
+		found    int64
+		baseline CoverageMetric
+	}{
+		{"line", s.CoveredLines, s.TotalLines, baseline.Lines},
+		{"function", s.CoveredFunctions, s.TotalFunctions, baseline.Functions},
+		{"branch", s.CoveredBranches, s.TotalBranches, baseline.Branches},
+	}
+
+	var regressions []Regression
+	for _, m := range metrics {
+		reference, ok := rate(m.baseline.Covered, m.baseline.Total)
+		if !ok {
+			continue
+		}
+		if r, ok := rate(m.hit, m.found); ok && r < reference-tolerance {
+			regressions = append(regressions, Regression{Metric: m.name, Rate: r, Baseline: reference})
+		}
+	}
+	return regressions
+}
+
+// RatchetBaseline returns the baseline raised to the coverage of a summary: for
+// every metric, the one of the summary or the baseline with the highest rate.
+// Saving it after every passing run makes the required coverage follow the
+// achieved one without ever going down, even when a tolerance lets a run pass
+// slightly below the baseline.
+func RatchetBaseline(baseline JSONSummary, s *Summary) JSONSummary {
+	ratcheted := NewJSONSummary(s)
+	for _, m := range []struct{ ratcheted, baseline *CoverageMetric }{
+		{&ratcheted.Lines, &baseline.Lines},
+		{&ratcheted.Functions, &baseline.Functions},
+		{&ratcheted.Branches, &baseline.Branches},
+	} {
+		reference, ok := rate(m.baseline.Covered, m.baseline.Total)
+		if !ok {
+			continue
+		}
+		if r, ok := rate(m.ratcheted.Covered, m.ratcheted.Total); !ok || r < reference {
+			*m.ratcheted = *m.baseline
+		}
+	}
+	return ratcheted
+}
@@ -0,0 +1,64 @@
+package lcov
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckBaseline(t *testing.T) {
+	summary := &Summary{
+		TotalLines: 10, CoveredLines: 7,
+		TotalFunctions: 4, CoveredFunctions: 3,
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, RenderJSON(&buf, &Summary{
+		TotalLines: 100, CoveredLines: 72,
+		TotalFunctions: 4, CoveredFunctions: 2,
+		TotalBranches: 10, CoveredBranches: 5,
+	}))
+	baseline, err := ReadBaseline(&buf)
+	require.NoError(t, err)
+
+	// No branch data in the summary: the branch baseline doesn't apply
+	regressions := CheckBaseline(summary, baseline, 0)
+	assert.Equal(t, []Regression{{Metric: "line", Rate: 70, Baseline: 72}}, regressions)
+	assert.Equal(t, "line coverage 70.0% is below the baseline 72.0%", regressions[0].String())
+
+	assert.Empty(t, CheckBaseline(summary, baseline, 2))
+	assert.Empty(t, CheckBaseline(summary, JSONSummary{}, 0))
+}
+
+func TestReadBaselineInvalid(t *testing.T) {
+	_, err := ReadBaseline(strings.NewReader("lines: 80"))
+	assert.ErrorContains(t, err, "invalid baseline")
+}
+
+func TestRatchetBaseline(t *testing.T) {
+	baseline := NewJSONSummary(&Summary{
+		TotalFiles: 3,
+		TotalLines: 100, CoveredLines: 72, LineCoverageRate: 72,
+		TotalFunctions: 4, CoveredFunctions: 2, FunctionCoverageRate: 50,
+		TotalBranches: 10, CoveredBranches: 5, BranchCoverageRate: 50,
+	})
+	summary := &Summary{
+		TotalFiles: 4,
+		TotalLines: 100, CoveredLines: 71, LineCoverageRate: 71,
+		TotalFunctions: 4, CoveredFunctions: 3, FunctionCoverageRate: 75,
+	}
+
+	// Lines went down within the tolerance and branches have no data: their
+	// baseline is kept, while functions go up
+	assert.Equal(t, JSONSummary{
+		Files:     4,
+		Lines:     CoverageMetric{Covered: 72, Total: 100, Rate: 72},
+		Functions: CoverageMetric{Covered: 3, Total: 4, Rate: 75},
+		Branches:  CoverageMetric{Covered: 5, Total: 10, Rate: 50},
+	}, RatchetBaseline(baseline, summary))
+
+	assert.Equal(t, NewJSONSummary(summary), RatchetBaseline(JSONSummary{}, summary))
+}
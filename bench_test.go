@@ -0,0 +1,34 @@
+package lcov
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/shastick/go-lcov-summary/lcovtest"
+)
+
+func benchmarkSummarize(b *testing.B, opts ...Option) {
+	data := lcovtest.Tracefile(lcovtest.Config{
+		Files: 1000, Lines: 500, Functions: 50, Branches: 200, Coverage: 0.8, TestName: "synthetic",
+	})
+	b.SetBytes(int64(len(data)))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := Summarize(bytes.NewReader(data), opts...); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkSummarize(b *testing.B) {
+	benchmarkSummarize(b)
+}
+
+func BenchmarkSummarizeWithDetails(b *testing.B) {
+	benchmarkSummarize(b, WithDetails())
+}
+
+func BenchmarkSummarizeMergeDuplicates(b *testing.B) {
+	benchmarkSummarize(b, WithDuplicateStrategy(DuplicatesMerge))
+}
@@ -0,0 +1,257 @@
+// Package bitbucket publishes coverage summaries as Code Insights reports, with
+// annotations on the uncovered lines, to Bitbucket Cloud and Bitbucket Server
+// or Data Center.
+package bitbucket
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+
+	lcov "github.com/shastick/go-lcov-summary"
+)
+
+// DefaultAPIURL is the Bitbucket Cloud API used when none is configured
+const DefaultAPIURL = "https://api.bitbucket.org/2.0"
+
+// ReportKey identifies the report of this package, so that it is replaced in place
+const ReportKey = "go-lcov-summary"
+
+// MaxAnnotations is the number of annotations Bitbucket accepts per report
+const MaxAnnotations = 1000
+
+// annotationsPerRequest is the number of annotations Bitbucket Cloud accepts per request
+const annotationsPerRequest = 100
+
+// Commit identifies the commit to report on
+type Commit struct {
+	// APIURL is the Bitbucket Cloud API base URL, DefaultAPIURL if empty, or the
+	// base URL of the Bitbucket Server instance, e.g. https://bitbucket.example.com
+	APIURL string
+	// Server selects the API of Bitbucket Server and Data Center instead of Bitbucket Cloud
+	Server bool
+	Token  string
+	// Workspace is the workspace of the repository on Bitbucket Cloud, or its project key on Bitbucket Server
+	Workspace string
+	Repo      string
+	Commit    string
+}
+
+// CommitFromEnv fills the commit from BITBUCKET_TOKEN and the Bitbucket Pipelines environment
+func CommitFromEnv() Commit {
+	return Commit{
+		Token:     os.Getenv("BITBUCKET_TOKEN"),
+		Workspace: os.Getenv("BITBUCKET_WORKSPACE"),
+		Repo:      os.Getenv("BITBUCKET_REPO_SLUG"),
+		Commit:    os.Getenv("BITBUCKET_COMMIT"),
+	}
+}
+
+// Annotation is a comment of the report on a line of a source file
+type Annotation struct {
+	// Path is relative to the repository root
+	Path    string
+	Line    int
+	Message string
+}
+
+// Annotations returns the annotations of the uncovered lines: the uncovered
+// changed lines of a patch if not nil, otherwise the ranges of uncovered lines
+// of the detailed records of the summary, with their paths made relative to
+// root. At most MaxAnnotations are returned.
+func Annotations(summary *lcov.Summary, patch *lcov.PatchSummary, root string) []Annotation {
+	var annotations []Annotation
+	if patch != nil {
+		for _, f := range patch.Files {
+			for _, line := range f.Uncovered {
+				annotations = append(annotations, Annotation{Path: f.Path, Line: line, Message: "This changed line is not covered by tests"})
+			}
+		}
+	} else {
+		prefix := strings.TrimSuffix(root, "/") + "/"
+		for _, f := range summary.FileSummaries() {
+			path := strings.TrimPrefix(f.Path, prefix)
+			for _, r := range f.UncoveredLines {
+				message := fmt.Sprintf("Line %s is not covered by tests", r)
+				if r.Start != r.End {
+					message = fmt.Sprintf("Lines %s are not covered by tests", r)
+				}
+				annotations = append(annotations, Annotation{Path: path, Line: r.Start, Message: message})
+			}
+		}
+	}
+	if len(annotations) > MaxAnnotations {
+		annotations = annotations[:MaxAnnotations]
+	}
+	return annotations
+}
+
+// Report is the content of a Code Insights report
+type Report struct {
+	Summary *lcov.Summary
+	// Patch is the coverage of the changed lines, if known
+	Patch *lcov.PatchSummary
+	// Passed tells whether the coverage checks passed
+	Passed      bool
+	Annotations []Annotation
+}
+
+// Publish creates the Code Insights report of the commit with its annotations,
+// replacing the one previously published by this package
+func Publish(ctx context.Context, client *http.Client, commit Commit, report Report) error {
+	if commit.Workspace == "" || commit.Repo == "" || commit.Commit == "" {
+		return fmt.Errorf("missing workspace, repository or commit")
+	}
+
+	endpoint := reportEndpoint(commit)
+	// Deleting the report deletes its annotations, so that those of fixed lines don't linger
+	if err := call(ctx, client, commit, http.MethodDelete, endpoint, nil); err != nil && !isNotFound(err) {
+		return err
+	}
+	if err := call(ctx, client, commit, http.MethodPut, endpoint, newReport(commit, report)); err != nil {
+		return err
+	}
+
+	for start := 0; start < len(report.Annotations); start += annotationsPerRequest {
+		batch := report.Annotations[start:min(start+annotationsPerRequest, len(report.Annotations))]
+		if err := call(ctx, client, commit, http.MethodPost, endpoint+"/annotations", newAnnotations(commit, batch, start)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// reportEndpoint returns the URL of the report of the commit
+func reportEndpoint(commit Commit) string {
+	apiURL := strings.TrimSuffix(commit.APIURL, "/")
+	if commit.Server {
+		return fmt.Sprintf("%s/rest/insights/1.0/projects/%s/repos/%s/commits/%s/reports/%s",
+			apiURL, url.PathEscape(commit.Workspace), url.PathEscape(commit.Repo), url.PathEscape(commit.Commit), ReportKey)
+	}
+	if apiURL == "" {
+		apiURL = DefaultAPIURL
+	}
+	return fmt.Sprintf("%s/repositories/%s/%s/commit/%s/reports/%s",
+		apiURL, url.PathEscape(commit.Workspace), url.PathEscape(commit.Repo), url.PathEscape(commit.Commit), ReportKey)
+}
+
+// reportData is a metric of a report
+type reportData struct {
+	Title string  `json:"title"`
+	Type  string  `json:"type"`
+	Value float64 `json:"value"`
+}
+
+// newReport returns the body of the report in the layout of the API of the commit
+func newReport(commit Commit, report Report) map[string]any {
+	s := report.Summary
+	details := fmt.Sprintf("%.1f%% of %d lines covered", s.LineCoverageRate, s.TotalLines)
+	var data []reportData
+	for _, m := range lcov.Metrics {
+		if rate, ok := s.Rate(m); ok {
+			title := strings.ToUpper(string(m[:1])) + string(m[1:]) + " coverage"
+			data = append(data, reportData{Title: title, Type: "PERCENTAGE", Value: rate})
+		}
+	}
+	if p := report.Patch; p != nil {
+		if rate, ok := p.Rate(); ok {
+			details += fmt.Sprintf(", %.1f%% of %d changed lines", rate, p.ChangedLines)
+			data = append(data, reportData{Title: "Patch coverage", Type: "PERCENTAGE", Value: rate})
+		}
+	}
+
+	body := map[string]any{
+		"title":    "Coverage",
+		"details":  details,
+		"reporter": "go-lcov-summary",
+		"data":     data,
+	}
+	if commit.Server {
+		body["result"] = map[bool]string{true: "PASS", false: "FAIL"}[report.Passed]
+	} else {
+		body["report_type"] = "COVERAGE"
+		body["result"] = map[bool]string{true: "PASSED", false: "FAILED"}[report.Passed]
+	}
+	return body
+}
+
+// newAnnotations returns the body adding annotations in the layout of the API
+// of the commit, offset being the index of the first one in the report
+func newAnnotations(commit Commit, annotations []Annotation, offset int) any {
+	items := make([]map[string]any, len(annotations))
+	for i, a := range annotations {
+		id := fmt.Sprintf("%s-%d", ReportKey, offset+i+1)
+		if commit.Server {
+			items[i] = map[string]any{"externalId": id, "path": a.Path, "line": a.Line, "message": a.Message, "severity": "LOW", "type": "CODE_SMELL"}
+		} else {
+			items[i] = map[string]any{"external_id": id, "path": a.Path, "line": a.Line, "summary": a.Message, "severity": "LOW", "annotation_type": "CODE_SMELL"}
+		}
+	}
+	if commit.Server {
+		return map[string]any{"annotations": items}
+	}
+	return items
+}
+
+// apiError is the error of a request failing with an HTTP status
+type apiError struct {
+	status  int
+	message string
+}
+
+func (e *apiError) Error() string {
+	return e.message
+}
+
+// isNotFound reports whether err is the error of a request for a missing resource
+func isNotFound(err error) bool {
+	apiErr, ok := err.(*apiError)
+	return ok && apiErr.status == http.StatusNotFound
+}
+
+// call sends a Bitbucket API request with an optional JSON body, discarding the response
+func call(ctx context.Context, client *http.Client, commit Commit, method, endpoint string, body any) error {
+	var reader io.Reader
+	if body != nil {
+		payload, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(payload)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, endpoint, reader)
+	if err != nil {
+		return err
+	}
+	if commit.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+commit.Token)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("error calling the Bitbucket API: %w", err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 10<<20))
+	if err != nil {
+		return fmt.Errorf("error calling the Bitbucket API: %w", err)
+	}
+	if resp.StatusCode >= 300 {
+		return &apiError{
+			status:  resp.StatusCode,
+			message: fmt.Sprintf("Bitbucket API request failed with status %s: %s", resp.Status, bytes.TrimSpace(data)),
+		}
+	}
+	return nil
+}
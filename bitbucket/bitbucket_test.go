@@ -0,0 +1,140 @@
+package bitbucket
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	lcov "github.com/shastick/go-lcov-summary"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const tracefile = `SF:/repo/pkg/a.go
+DA:1,1
+DA:2,0
+DA:3,0
+DA:4,1
+DA:5,0
+LF:5
+LH:2
+end_of_record
+`
+
+func TestCommitFromEnv(t *testing.T) {
+	t.Setenv("BITBUCKET_TOKEN", "secret")
+	t.Setenv("BITBUCKET_WORKSPACE", "team")
+	t.Setenv("BITBUCKET_REPO_SLUG", "repo")
+	t.Setenv("BITBUCKET_COMMIT", "abc123")
+
+	assert.Equal(t, Commit{Token: "secret", Workspace: "team", Repo: "repo", Commit: "abc123"}, CommitFromEnv())
+}
+
+func TestAnnotations(t *testing.T) {
+	summary, err := lcov.Summarize(strings.NewReader(tracefile), lcov.WithDetails())
+	require.NoError(t, err)
+
+	assert.Equal(t, []Annotation{
+		{Path: "pkg/a.go", Line: 2, Message: "Lines 2-3 are not covered by tests"},
+		{Path: "pkg/a.go", Line: 5, Message: "Line 5 is not covered by tests"},
+	}, Annotations(summary, nil, "/repo/"))
+
+	patch := &lcov.PatchSummary{ChangedLines: 2, CoveredLines: 1, Files: []lcov.PatchFile{{Path: "pkg/a.go", Covered: []int{1}, Uncovered: []int{3}}}}
+	assert.Equal(t, []Annotation{
+		{Path: "pkg/a.go", Line: 3, Message: "This changed line is not covered by tests"},
+	}, Annotations(summary, patch, "/repo"))
+
+	patch.Files[0].Uncovered = make([]int, MaxAnnotations+1)
+	assert.Len(t, Annotations(summary, patch, "/repo"), MaxAnnotations)
+}
+
+func TestPublishCloud(t *testing.T) {
+	var report map[string]any
+	var annotations []map[string]any
+	deleted := false
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	endpoint := "/repositories/team/repo/commit/abc123/reports/" + ReportKey
+	mux.HandleFunc("DELETE "+endpoint, func(w http.ResponseWriter, r *http.Request) {
+		deleted = true
+		http.NotFound(w, r)
+	})
+	mux.HandleFunc("PUT "+endpoint, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer secret", r.Header.Get("Authorization"))
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&report))
+	})
+	mux.HandleFunc("POST "+endpoint+"/annotations", func(w http.ResponseWriter, r *http.Request) {
+		var batch []map[string]any
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&batch))
+		assert.LessOrEqual(t, len(batch), annotationsPerRequest)
+		annotations = append(annotations, batch...)
+	})
+
+	summary, err := lcov.Summarize(strings.NewReader(tracefile))
+	require.NoError(t, err)
+	var lines []Annotation
+	for i := 1; i <= 150; i++ {
+		lines = append(lines, Annotation{Path: "pkg/a.go", Line: i, Message: fmt.Sprintf("line %d", i)})
+	}
+
+	commit := Commit{APIURL: server.URL, Token: "secret", Workspace: "team", Repo: "repo", Commit: "abc123"}
+	require.NoError(t, Publish(context.Background(), server.Client(), commit, Report{Summary: summary, Passed: false, Annotations: lines}))
+	assert.True(t, deleted)
+	assert.Equal(t, "COVERAGE", report["report_type"])
+	assert.Equal(t, "FAILED", report["result"])
+	assert.Equal(t, "40.0% of 5 lines covered", report["details"])
+	assert.Equal(t, []any{map[string]any{"title": "Line coverage", "type": "PERCENTAGE", "value": 40.0}}, report["data"])
+	require.Len(t, annotations, 150)
+	assert.Equal(t, map[string]any{
+		"external_id": ReportKey + "-150", "path": "pkg/a.go", "line": 150.0, "summary": "line 150", "severity": "LOW", "annotation_type": "CODE_SMELL",
+	}, annotations[149])
+
+	assert.EqualError(t, Publish(context.Background(), server.Client(), Commit{}, Report{Summary: summary}), "missing workspace, repository or commit")
+}
+
+func TestPublishServer(t *testing.T) {
+	var report map[string]any
+	var annotations struct{ Annotations []map[string]any }
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	endpoint := "/rest/insights/1.0/projects/PROJ/repos/repo/commits/abc123/reports/" + ReportKey
+	mux.HandleFunc("DELETE "+endpoint, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+	mux.HandleFunc("PUT "+endpoint, func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&report))
+	})
+	mux.HandleFunc("POST "+endpoint+"/annotations", func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&annotations))
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	summary, err := lcov.Summarize(strings.NewReader(tracefile))
+	require.NoError(t, err)
+	patch := &lcov.PatchSummary{ChangedLines: 4, CoveredLines: 3}
+
+	commit := Commit{APIURL: server.URL + "/", Server: true, Workspace: "PROJ", Repo: "repo", Commit: "abc123"}
+	require.NoError(t, Publish(context.Background(), server.Client(), commit, Report{
+		Summary: summary, Patch: patch, Passed: true, Annotations: []Annotation{{Path: "pkg/a.go", Line: 2, Message: "uncovered"}},
+	}))
+	assert.Equal(t, "PASS", report["result"])
+	assert.NotContains(t, report, "report_type")
+	assert.Equal(t, "40.0% of 5 lines covered, 75.0% of 4 changed lines", report["details"])
+	assert.Equal(t, []map[string]any{
+		{"externalId": ReportKey + "-1", "path": "pkg/a.go", "line": 2.0, "message": "uncovered", "severity": "LOW", "type": "CODE_SMELL"},
+	}, annotations.Annotations)
+
+	mux.HandleFunc("/rest/insights/1.0/projects/PROJ/repos/other/commits/abc123/reports/"+ReportKey, func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "forbidden", http.StatusForbidden)
+	})
+	commit.Repo = "other"
+	assert.ErrorContains(t, Publish(context.Background(), server.Client(), commit, Report{Summary: summary}), "Bitbucket API request failed with status 403 Forbidden: forbidden")
+}
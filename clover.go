@@ -0,0 +1,97 @@
+package lcov
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+)
+
+// cloverCoverage mirrors the parts of the Clover XML report layout used for summaries
+type cloverCoverage struct {
+	XMLName xml.Name      `xml:"coverage"`
+	Project cloverProject `xml:"project"`
+}
+
+type cloverProject struct {
+	Packages []cloverPackage `xml:"package"`
+	Files    []cloverFile    `xml:"file"`
+}
+
+type cloverPackage struct {
+	Files []cloverFile `xml:"file"`
+}
+
+type cloverFile struct {
+	Name  string       `xml:"name,attr"`
+	Path  string       `xml:"path,attr"`
+	Lines []cloverLine `xml:"line"`
+}
+
+type cloverLine struct {
+	Number     int    `xml:"num,attr"`
+	Type       string `xml:"type,attr"`
+	Name       string `xml:"name,attr"`
+	Count      int64  `xml:"count,attr"`
+	TrueCount  int64  `xml:"truecount,attr"`
+	FalseCount int64  `xml:"falsecount,attr"`
+}
+
+// ParseClover summarizes an Atlassian Clover XML report, as emitted by PHPUnit and
+// some JavaScript toolchains. Statements and conditionals become line data,
+// conditionals also yield a true and a false branch, and methods become functions.
+// The parser options apply as for LCOV data.
+func ParseClover(reader io.Reader, opts ...Option) (*Summary, error) {
+	p := newParser(opts)
+	reader = p.track(reader)
+
+	var coverage cloverCoverage
+	if err := xml.NewDecoder(reader).Decode(&coverage); err != nil {
+		return nil, fmt.Errorf("invalid Clover report: %w", err)
+	}
+
+	files := newFileSet(p.duplicates)
+	cloverFiles := coverage.Project.Files
+	for _, pkg := range coverage.Project.Packages {
+		cloverFiles = append(cloverFiles, pkg.Files...)
+	}
+	for _, file := range cloverFiles {
+		files.add(cloverFileRecord(file))
+	}
+
+	return p.summarize(&Summary{}, files)
+}
+
+// cloverFileRecord converts a Clover file element to a detailed file record
+func cloverFileRecord(file cloverFile) *FileRecord {
+	f := &FileRecord{Path: file.Path}
+	if f.Path == "" {
+		f.Path = file.Name
+	}
+
+	for _, line := range file.Lines {
+		switch line.Type {
+		case "method":
+			f.Functions = append(f.Functions, FunctionData{Name: line.Name, Line: line.Number, Count: line.Count})
+			f.FunctionsFound++
+			if line.Count > 0 {
+				f.FunctionsHit++
+			}
+
+		case "stmt", "cond":
+			f.Lines = append(f.Lines, LineData{Line: line.Number, Count: line.Count})
+			f.LinesFound++
+			if line.Count > 0 {
+				f.LinesHit++
+			}
+			if line.Type == "cond" {
+				f.Branches = append(f.Branches,
+					BranchData{Line: line.Number, Branch: 0, Taken: line.TrueCount},
+					BranchData{Line: line.Number, Branch: 1, Taken: line.FalseCount})
+				f.BranchesFound += 2
+				f.BranchesHit += min(line.TrueCount, 1) + min(line.FalseCount, 1)
+			}
+		}
+	}
+
+	return f
+}
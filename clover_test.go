@@ -0,0 +1,38 @@
+package lcov
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseClover(t *testing.T) {
+	file, err := os.Open("testdata/clover.xml")
+	require.NoError(t, err)
+	defer file.Close()
+
+	summary, err := ParseClover(file, WithDetails())
+	require.NoError(t, err)
+
+	assert.Equal(t, 2, summary.TotalFiles)
+	assert.Equal(t, int64(4), summary.TotalLines)
+	assert.Equal(t, int64(2), summary.CoveredLines)
+	assert.Equal(t, int64(2), summary.TotalFunctions)
+	assert.Equal(t, int64(1), summary.CoveredFunctions)
+	assert.Equal(t, int64(2), summary.TotalBranches)
+	assert.Equal(t, int64(1), summary.CoveredBranches)
+
+	require.Len(t, summary.Files, 2)
+	assert.Equal(t, "/src/app/index.js", summary.Files[0].Path)
+	assert.Equal(t, []BranchData{{Line: 3, Branch: 0, Taken: 2}, {Line: 3, Branch: 1, Taken: 0}}, summary.Files[0].Branches)
+	assert.Equal(t, "/src/app/Util/Strings.php", summary.Files[1].Path)
+}
+
+func TestParseCloverInvalid(t *testing.T) {
+	summary, err := ParseClover(strings.NewReader("<report></report>"))
+	assert.ErrorContains(t, err, "invalid Clover report")
+	assert.Nil(t, summary)
+}
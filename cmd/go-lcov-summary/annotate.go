@@ -0,0 +1,134 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/shastick/go-lcov-summary"
+)
+
+// runAnnotate implements the 'annotate [flags] <coverage-file>...' subcommand
+func runAnnotate(args []string) error {
+	fs := flag.NewFlagSet("annotate", flag.ContinueOnError)
+	var src sourceDirsFlag
+	var outputDir, file string
+	fs.Var(&src, "src", "`directory` the source files are looked up in, or PREFIX=DIR to look the tracefile paths below PREFIX up below DIR; repeatable, tried in order (default the working directory)")
+	stringFlag(fs, &outputDir, "output-dir", "o", "coverage-annotated", "write the annotated copies of the source files, as <file>.gcov, to this `directory`")
+	fs.StringVar(&file, "file", "", "only print the annotated source `file` to stdout")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: go-lcov-summary annotate [flags] <coverage-file>...\n")
+		printFlags(fs)
+	}
+
+	inputs, err := parseInterleaved(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(inputs) == 0 {
+		return errors.New("no input given")
+	}
+	if inputs, err = expandInputs(inputs, ""); err != nil {
+		return err
+	}
+	summary, err := summarizeInputs(inputs, []lcov.Option{lcov.WithDetails()}, io.Discard, nil)
+	if err != nil {
+		return err
+	}
+	files := lcov.MergeFiles(summary.Files)
+
+	if file != "" {
+		for i := range files {
+			if sameFile(files[i].Path, file) {
+				source, _, ok := src.resolve(files[i].Path)
+				if !ok {
+					return fmt.Errorf("source of %s not found in %s", file, src.describe())
+				}
+				return annotateFile(os.Stdout, source, &files[i])
+			}
+		}
+		return fmt.Errorf("no coverage data for %s", file)
+	}
+
+	for i := range files {
+		source, relative, ok := src.resolve(files[i].Path)
+		if !ok {
+			fmt.Fprintf(os.Stderr, "Warning: source of %s not found in %s\n", files[i].Path, src.describe())
+			continue
+		}
+		if err := writeAnnotated(filepath.Join(outputDir, relative+".gcov"), source, &files[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeAnnotated writes the annotated copy of a source file to path
+func writeAnnotated(path, source string, f *lcov.FileRecord) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	out, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := annotateFile(out, source, f); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+// annotateFile writes the source file at path annotated with the coverage of f
+func annotateFile(w io.Writer, path string, f *lcov.FileRecord) error {
+	source, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer source.Close()
+	return lcov.WriteGcov(w, source, f)
+}
+
+// resolveSource looks up the source file of a tracefile path in the src directory.
+// As tracefile paths are often absolute paths of another machine, their leading
+// directories are dropped one by one until the file is found. It returns the path
+// of the source file and its path relative to src.
+func resolveSource(src, name string) (string, string, bool) {
+	if filepath.IsAbs(name) {
+		if _, err := os.Stat(name); err == nil {
+			if relative, err := filepath.Rel(src, name); err == nil && !strings.HasPrefix(relative, "..") {
+				return name, relative, true
+			}
+		}
+	}
+	parts := strings.Split(strings.Trim(filepath.ToSlash(name), "/"), "/")
+	for i := range parts {
+		relative := filepath.Join(parts[i:]...)
+		path := filepath.Join(src, relative)
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			return path, relative, true
+		}
+	}
+	return "", "", false
+}
+
+// sameFile reports whether a tracefile path designates the given relative path
+func sameFile(tracefilePath, relative string) bool {
+	relative = filepath.ToSlash(filepath.Clean(relative))
+	return tracefilePath == relative || strings.HasSuffix(tracefilePath, "/"+relative)
+}
+
+// selectFile returns the records of the source file at a relative path, see sameFile
+func selectFile(files []lcov.FileRecord, relative string) []lcov.FileRecord {
+	var selected []lcov.FileRecord
+	for i := range files {
+		if sameFile(files[i].Path, relative) {
+			selected = append(selected, files[i])
+		}
+	}
+	return selected
+}
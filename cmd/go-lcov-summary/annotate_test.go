@@ -0,0 +1,83 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunAnnotate(t *testing.T) {
+	src := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(src, "source"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(src, "source", "file1.go"), []byte("a\nb\nc\nd\ne\nf\n"), 0o644))
+
+	output := filepath.Join(t.TempDir(), "annotated")
+	require.NoError(t, runAnnotate([]string{"--src", src, "-o", output, "../../testdata/sample.lcov"}))
+
+	annotated, err := os.ReadFile(filepath.Join(output, "source", "file1.go.gcov"))
+	require.NoError(t, err)
+	assert.Equal(t, ""+
+		"        -:    0:Source:/path/to/source/file1.go\n"+
+		"        1:    1:a\n"+
+		"    #####:    2:b\n"+
+		"        1:    3:c\n"+
+		"    #####:    4:d\n"+
+		"        1:    5:e\n"+
+		"        -:    6:f\n", string(annotated))
+	// file2.go has no source: it is skipped
+	assert.NoFileExists(t, filepath.Join(output, "source", "file2.go.gcov"))
+
+	require.NoError(t, runAnnotate([]string{"--src", src, "--file", "source/file1.go", "../../testdata/sample.lcov"}))
+	assert.EqualError(t, runAnnotate([]string{"--src", src, "--file", "other.go", "../../testdata/sample.lcov"}), "no coverage data for other.go")
+}
+
+func TestResolveSource(t *testing.T) {
+	src := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(src, "pkg"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(src, "pkg", "a.go"), nil, 0o644))
+
+	path, relative, ok := resolveSource(src, "/home/ci/repo/pkg/a.go")
+	require.True(t, ok)
+	assert.Equal(t, filepath.Join(src, "pkg", "a.go"), path)
+	assert.Equal(t, filepath.Join("pkg", "a.go"), relative)
+
+	path, relative, ok = resolveSource(src, filepath.Join(src, "pkg", "a.go"))
+	require.True(t, ok)
+	assert.Equal(t, filepath.Join(src, "pkg", "a.go"), path)
+	assert.Equal(t, filepath.Join("pkg", "a.go"), relative)
+
+	_, _, ok = resolveSource(src, "/home/ci/repo/pkg/b.go")
+	assert.False(t, ok)
+}
+
+func TestSourceDirs(t *testing.T) {
+	src, vendor := t.TempDir(), t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(src, "pkg"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(src, "pkg", "a.go"), nil, 0o644))
+	require.NoError(t, os.MkdirAll(filepath.Join(vendor, "lib"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(vendor, "lib", "b.go"), nil, 0o644))
+
+	var dirs sourceDirsFlag
+	require.NoError(t, dirs.Set("/home/ci/deps/="+vendor))
+	require.NoError(t, dirs.Set(src))
+	assert.Equal(t, sourceDirsFlag{{prefix: "/home/ci/deps", dir: vendor}, {dir: src}}, dirs)
+	assert.Equal(t, "/home/ci/deps="+vendor+","+src, dirs.String())
+	assert.EqualError(t, dirs.Set("=dir"), `invalid source directory "=dir", expected DIR or PREFIX=DIR`)
+
+	// Paths below a prefix are only looked up below its directory
+	path, relative, ok := dirs.resolve("/home/ci/deps/lib/b.go")
+	require.True(t, ok)
+	assert.Equal(t, filepath.Join(vendor, "lib", "b.go"), path)
+	assert.Equal(t, filepath.Join("lib", "b.go"), relative)
+	_, _, ok = dirs.resolve("/home/ci/deps/pkg/c.go")
+	assert.False(t, ok)
+
+	path, _, ok = dirs.resolve("/home/ci/repo/pkg/a.go")
+	require.True(t, ok)
+	assert.Equal(t, filepath.Join(src, "pkg", "a.go"), path)
+	_, _, ok = dirs.resolve("/home/ci/repo/lib/b.go")
+	assert.False(t, ok)
+}
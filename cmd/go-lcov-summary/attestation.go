@@ -0,0 +1,144 @@
+package main
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/shastick/go-lcov-summary"
+)
+
+// runVerify implements the 'verify [flags] <attestation-file>' subcommand
+func runVerify(args []string) error {
+	fs := flag.NewFlagSet("verify", flag.ContinueOnError)
+	var key, tracefile string
+	fs.StringVar(&key, "key", "", "PEM encoded ed25519 public key `file` of the key the attestation was signed with, or that private key")
+	fs.StringVar(&tracefile, "tracefile", "", "also check that the attestation was computed from this tracefile `file`")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: go-lcov-summary verify --key <key-file> [flags] <attestation-file>\n")
+		fmt.Fprintf(fs.Output(), "\nVerifies the signature of a coverage attestation written by --attestation and prints the attested coverage.\n")
+		printFlags(fs)
+	}
+
+	inputs, err := parseInterleaved(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(inputs) != 1 {
+		return errors.New("expected a single attestation file")
+	}
+	if key == "" {
+		return errors.New("no --key given")
+	}
+	verifier, err := readKey(key)
+	if err != nil {
+		return err
+	}
+
+	file, err := os.Open(inputs[0])
+	if err != nil {
+		return fmt.Errorf("error opening attestation: %w", err)
+	}
+	defer file.Close()
+	statement, err := lcov.ReadAttestation(file, verifier)
+	if err != nil {
+		return err
+	}
+	if tracefile != "" {
+		subject, err := fileSubject(tracefile)
+		if err != nil {
+			return err
+		}
+		if digest := statement.Predicate.Tracefile.Digest["sha256"]; digest != subject.Digest["sha256"] {
+			return fmt.Errorf("attestation of a different tracefile: sha256 %s, not %s", digest, subject.Digest["sha256"])
+		}
+	}
+
+	predicate := statement.Predicate
+	fmt.Printf("Attested coverage of %s (sha256 %s)\n", predicate.Tracefile.Name, predicate.Tracefile.Digest["sha256"])
+	for _, subject := range statement.Subject {
+		fmt.Printf("  subject %s (sha256 %s)\n", subject.Name, subject.Digest["sha256"])
+	}
+	summary := lcov.JSONSummary{Files: predicate.Files, Lines: predicate.Lines, Functions: predicate.Functions, Branches: predicate.Branches}
+	return lcov.RenderText(os.Stdout, summary.Summary())
+}
+
+// readKey reads a PEM encoded ed25519 key: a PKCS #8 private key, as generated
+// by 'openssl genpkey -algorithm ed25519', or the PKIX public key extracted from
+// it by 'openssl pkey -pubout', which only verifies. The key is identified by the
+// SHA-256 digest of its public key.
+func readKey(path string) (*lcov.Ed25519Signer, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading attestation key: %w", err)
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("invalid attestation key %s: no PEM data", path)
+	}
+
+	var key any
+	if block.Type == "PUBLIC KEY" {
+		key, err = x509.ParsePKIXPublicKey(block.Bytes)
+	} else {
+		key, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid attestation key %s: %w", path, err)
+	}
+	signer := &lcov.Ed25519Signer{}
+	switch key := key.(type) {
+	case ed25519.PrivateKey:
+		signer.PrivateKey, signer.PublicKey = key, key.Public().(ed25519.PublicKey)
+	case ed25519.PublicKey:
+		signer.PublicKey = key
+	default:
+		return nil, fmt.Errorf("invalid attestation key %s: not an ed25519 key", path)
+	}
+	keyID := sha256.Sum256(signer.PublicKey)
+	signer.KeyID = hex.EncodeToString(keyID[:])
+	return signer, nil
+}
+
+// writeAttestation writes the signed coverage statement of a tracefile, about
+// the artifacts of the subjects files
+func writeAttestation(path string, signer lcov.Signer, summary *lcov.Summary, tracefile string, subjects []string) error {
+	traced, err := fileSubject(tracefile)
+	if err != nil {
+		return err
+	}
+	artifacts := make([]lcov.Subject, len(subjects))
+	for i, subject := range subjects {
+		if artifacts[i], err = fileSubject(subject); err != nil {
+			return err
+		}
+	}
+
+	var data bytes.Buffer
+	if err := lcov.WriteAttestation(&data, lcov.NewStatement(summary, traced, artifacts...), signer); err != nil {
+		return err
+	}
+	return writeFileAtomic(path, data.Bytes())
+}
+
+// fileSubject identifies a file by its name and SHA-256 digest
+func fileSubject(path string) (lcov.Subject, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return lcov.Subject{}, fmt.Errorf("error opening attestation subject: %w", err)
+	}
+	defer file.Close()
+	digest, err := lcov.Fingerprint(file)
+	if err != nil {
+		return lcov.Subject{}, err
+	}
+	return lcov.Subject{Name: filepath.Base(path), Digest: map[string]string{"sha256": digest}}, nil
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/shastick/go-lcov-summary"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeKeys writes the PEM encoded private and public keys of a test key pair
+func writeKeys(t *testing.T, dir string) (string, string) {
+	private := ed25519.NewKeyFromSeed(make([]byte, ed25519.SeedSize))
+	privateDER, err := x509.MarshalPKCS8PrivateKey(private)
+	require.NoError(t, err)
+	publicDER, err := x509.MarshalPKIXPublicKey(private.Public())
+	require.NoError(t, err)
+
+	privatePath, publicPath := filepath.Join(dir, "key.pem"), filepath.Join(dir, "key.pub")
+	require.NoError(t, os.WriteFile(privatePath, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: privateDER}), 0o600))
+	require.NoError(t, os.WriteFile(publicPath, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicDER}), 0o644))
+	return privatePath, publicPath
+}
+
+func TestReportAttestation(t *testing.T) {
+	dir := t.TempDir()
+	private, public := writeKeys(t, dir)
+	artifact := filepath.Join(dir, "app.tar.gz")
+	require.NoError(t, os.WriteFile(artifact, []byte("release"), 0o644))
+	inputs := []string{"../../testdata/sample.lcov"}
+
+	cfg := &config{format: defaultFormat, quiet: true, attestation: filepath.Join(dir, "coverage.intoto.json"), attestationKey: private, attestationSubjects: patternsFlag{artifact}}
+	require.NoError(t, report(cfg, inputs))
+
+	file, err := os.Open(cfg.attestation)
+	require.NoError(t, err)
+	defer file.Close()
+	verifier, err := readKey(public)
+	require.NoError(t, err)
+	statement, err := lcov.ReadAttestation(file, verifier)
+	require.NoError(t, err)
+	assert.Equal(t, "sample.lcov", statement.Predicate.Tracefile.Name)
+	assert.Equal(t, int64(6), statement.Predicate.Lines.Covered)
+	assert.Equal(t, int64(9), statement.Predicate.Lines.Total)
+	require.Len(t, statement.Predicate.Records, 2)
+	assert.Equal(t, "/path/to/source/file1.go", statement.Predicate.Records[0].Path)
+	require.Len(t, statement.Subject, 1)
+	assert.Equal(t, "app.tar.gz", statement.Subject[0].Name)
+
+	require.NoError(t, runVerify([]string{"--key", public, "--tracefile", inputs[0], cfg.attestation}))
+	require.NoError(t, runVerify([]string{"--key", private, cfg.attestation}))
+	assert.ErrorContains(t, runVerify([]string{"--key", public, "--tracefile", artifact, cfg.attestation}), "attestation of a different tracefile")
+
+	// Signing needs the private key
+	cfg.attestationKey = public
+	assert.EqualError(t, report(cfg, inputs), "invalid attestation key "+public+": not a private key")
+
+	cfg.attestationKey = private
+	assert.EqualError(t, report(cfg, append(inputs, "../../testdata/functions.lcov")), "--attestation requires a single tracefile, not stdin")
+}
+
+func TestReadKey(t *testing.T) {
+	dir := t.TempDir()
+	private, public := writeKeys(t, dir)
+
+	signer, err := readKey(private)
+	require.NoError(t, err)
+	verifier, err := readKey(public)
+	require.NoError(t, err)
+	assert.Len(t, signer.KeyID, 64)
+	assert.Equal(t, signer.KeyID, verifier.KeyID)
+	assert.Nil(t, verifier.PrivateKey)
+
+	invalid := filepath.Join(dir, "invalid.pem")
+	require.NoError(t, os.WriteFile(invalid, bytes.Repeat([]byte("x"), 10), 0o644))
+	_, err = readKey(invalid)
+	assert.EqualError(t, err, "invalid attestation key "+invalid+": no PEM data")
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/shastick/go-lcov-summary"
+)
+
+// runBadge implements the 'badge [flags] <coverage-file>...' subcommand
+func runBadge(args []string) error {
+	fs := flag.NewFlagSet("badge", flag.ContinueOnError)
+	var output, label string
+	stringFlag(fs, &output, "output", "o", "-", "write the SVG badge to this `file`, '-' for stdout")
+	fs.StringVar(&label, "label", "coverage", "badge `label`")
+	thresholds := lcov.DefaultColorThresholds
+	fs.Float64Var(&thresholds.Medium, "color-medium", thresholds.Medium, "coverage `percentage` from which the badge is yellow instead of red")
+	fs.Float64Var(&thresholds.High, "color-high", thresholds.High, "coverage `percentage` from which the badge is green instead of yellow")
+
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: go-lcov-summary badge [flags] <coverage-file>...\n")
+		printFlags(fs)
+	}
+
+	inputs, err := parseInterleaved(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(inputs) == 0 {
+		return errors.New("no input given")
+	}
+	if inputs, err = expandInputs(inputs, ""); err != nil {
+		return err
+	}
+	summary, err := summarizeInputs(inputs, nil, io.Discard, nil)
+	if err != nil {
+		return err
+	}
+
+	var badge bytes.Buffer
+	if err := lcov.WriteBadge(&badge, label, summary, thresholds); err != nil {
+		return err
+	}
+	if output == "-" {
+		_, err = os.Stdout.Write(badge.Bytes())
+		return err
+	}
+	return os.WriteFile(output, badge.Bytes(), 0o644)
+}
@@ -0,0 +1,21 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunBadge(t *testing.T) {
+	output := filepath.Join(t.TempDir(), "coverage.svg")
+	require.NoError(t, runBadge([]string{"../../testdata/sample.lcov", "-o", output, "--label", "lines"}))
+
+	badge, err := os.ReadFile(output)
+	require.NoError(t, err)
+	assert.Contains(t, string(badge), `aria-label="lines: 66.7%"`)
+
+	assert.EqualError(t, runBadge([]string{"-o", output}), "no input given")
+}
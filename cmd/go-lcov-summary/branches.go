@@ -0,0 +1,71 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+
+	"github.com/shastick/go-lcov-summary"
+)
+
+// runBranches implements the 'branches [flags] <coverage-file>...' subcommand
+func runBranches(args []string) error {
+	fs := flag.NewFlagSet("branches", flag.ContinueOnError)
+	var file string
+	var untaken bool
+	fs.StringVar(&file, "file", "", "only list the branches of this source `file`")
+	fs.BoolVar(&untaken, "untaken", false, "only list the branches that were never taken")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: go-lcov-summary branches [flags] <coverage-file>...\n")
+		printFlags(fs)
+	}
+
+	inputs, err := parseInterleaved(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(inputs) == 0 {
+		return errors.New("no input given")
+	}
+	if inputs, err = expandInputs(inputs, ""); err != nil {
+		return err
+	}
+	summary, err := summarizeInputs(inputs, []lcov.Option{lcov.WithDetails()}, io.Discard, nil)
+	if err != nil {
+		return err
+	}
+	files := lcov.MergeFiles(summary.Files)
+
+	if file != "" {
+		if files = selectFile(files, file); len(files) == 0 {
+			return fmt.Errorf("no coverage data for %s", file)
+		}
+	}
+	return printBranches(os.Stdout, files, untaken)
+}
+
+// printBranches writes a table of the branches of the files, by line, block
+// and branch number, flagging those never taken
+func printBranches(w io.Writer, files []lcov.FileRecord, untakenOnly bool) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "File\tLine\tBlock\tBranch\tTaken\n")
+	for _, f := range files {
+		for _, b := range f.Branches {
+			if untakenOnly && b.Taken > 0 {
+				continue
+			}
+			switch {
+			case b.Taken < 0:
+				fmt.Fprintf(tw, "%s\t%d\t%d\t%d\t-\tblock never executed\n", f.Path, b.Line, b.Block, b.Branch)
+			case b.Taken == 0:
+				fmt.Fprintf(tw, "%s\t%d\t%d\t%d\t0\tnever taken\n", f.Path, b.Line, b.Block, b.Branch)
+			default:
+				fmt.Fprintf(tw, "%s\t%d\t%d\t%d\t%d\n", f.Path, b.Line, b.Block, b.Branch, b.Taken)
+			}
+		}
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/shastick/go-lcov-summary"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunBranches(t *testing.T) {
+	require.NoError(t, runBranches([]string{"../../testdata/functions.lcov", "--file", "pkg/crypto/aes.go", "--untaken"}))
+	assert.EqualError(t, runBranches([]string{"../../testdata/functions.lcov", "--file", "missing.go"}), "no coverage data for missing.go")
+	assert.EqualError(t, runBranches(nil), "no input given")
+}
+
+func TestPrintBranches(t *testing.T) {
+	files := []lcov.FileRecord{{Path: "a.go", Branches: []lcov.BranchData{
+		{Line: 3, Block: 0, Branch: 0, Taken: 4},
+		{Line: 3, Block: 0, Branch: 1, Taken: 0},
+		{Line: 12, Block: 1, Branch: 0, Taken: -1},
+	}}}
+
+	var buf bytes.Buffer
+	require.NoError(t, printBranches(&buf, files, false))
+	assert.Equal(t, ""+
+		"File  Line  Block  Branch  Taken\n"+
+		"a.go  3     0      0       4\n"+
+		"a.go  3     0      1       0  never taken\n"+
+		"a.go  12    1      0       -  block never executed\n", buf.String())
+
+	buf.Reset()
+	require.NoError(t, printBranches(&buf, files, true))
+	assert.Equal(t, ""+
+		"File  Line  Block  Branch  Taken\n"+
+		"a.go  3     0      1       0  never taken\n"+
+		"a.go  12    1      0       -  block never executed\n", buf.String())
+}
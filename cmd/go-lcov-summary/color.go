@@ -0,0 +1,27 @@
+package main
+
+import "os"
+
+// Values of the --color flag
+const (
+	colorAuto   = "auto"
+	colorAlways = "always"
+	colorNever  = "never"
+)
+
+// useColor reports whether the summary written to output should be colored.
+// In auto mode colors are used on terminals only, unless NO_COLOR is set or
+// the terminal is dumb.
+func useColor(mode string, output *os.File) bool {
+	switch mode {
+	case colorAlways:
+		return true
+	case colorNever:
+		return false
+	}
+	if os.Getenv("NO_COLOR") != "" || os.Getenv("TERM") == "dumb" {
+		return false
+	}
+	info, err := output.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
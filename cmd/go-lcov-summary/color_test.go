@@ -0,0 +1,22 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUseColor(t *testing.T) {
+	file, err := os.Create(filepath.Join(t.TempDir(), "summary.txt"))
+	require.NoError(t, err)
+	defer file.Close()
+
+	t.Setenv("NO_COLOR", "")
+	assert.True(t, useColor(colorAlways, file))
+	assert.False(t, useColor(colorNever, file))
+	// Regular files aren't terminals
+	assert.False(t, useColor(colorAuto, file))
+}
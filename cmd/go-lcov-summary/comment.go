@@ -0,0 +1,92 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/signal"
+
+	"github.com/shastick/go-lcov-summary"
+	"github.com/shastick/go-lcov-summary/github"
+	"github.com/shastick/go-lcov-summary/gitlab"
+)
+
+// runComment implements the 'comment --github|--gitlab [flags] <coverage-file>...' subcommand
+func runComment(args []string) error {
+	pr := github.PullRequestFromEnv()
+	mr := gitlab.MergeRequestFromEnv()
+	fs := flag.NewFlagSet("comment", flag.ContinueOnError)
+	var useGitHub, useGitLab bool
+	var token, apiURL, baseline string
+	fs.BoolVar(&useGitHub, "github", false, "comment on a GitHub pull request")
+	fs.BoolVar(&useGitLab, "gitlab", false, "comment on a GitLab merge request")
+	fs.StringVar(&pr.Number, "pr", pr.Number, "GitHub pull request `number` (default from the GitHub Actions environment)")
+	fs.StringVar(&pr.Repo, "repo", pr.Repo, "GitHub repository, e.g. owner/repo (default $GITHUB_REPOSITORY)")
+	fs.StringVar(&mr.IID, "mr", mr.IID, "GitLab merge request `IID` (default $CI_MERGE_REQUEST_IID)")
+	fs.StringVar(&mr.Project, "project", mr.Project, "GitLab project ID or path (default $CI_PROJECT_ID)")
+	fs.StringVar(&token, "token", "", "API token (default $GITHUB_TOKEN or $GITLAB_TOKEN)")
+	fs.StringVar(&apiURL, "api-url", "", "API `URL` (default $GITHUB_API_URL or $CI_API_V4_URL, then the public instance)")
+	fs.StringVar(&baseline, "baseline", "", "coverage `file` of the base branch, to show the coverage delta")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: go-lcov-summary comment --github|--gitlab [flags] <coverage-file>...\n")
+		printFlags(fs)
+	}
+
+	inputs, err := parseInterleaved(fs, args)
+	if err != nil {
+		return err
+	}
+	if useGitHub == useGitLab {
+		return errors.New("exactly one of --github and --gitlab is required")
+	}
+	if len(inputs) == 0 {
+		return errors.New("no input given")
+	}
+	if inputs, err = expandInputs(inputs, ""); err != nil {
+		return err
+	}
+	summary, err := summarizeInputs(inputs, nil, io.Discard, nil)
+	if err != nil {
+		return err
+	}
+	var base *lcov.Summary
+	if baseline != "" {
+		if base, err = summarizeInput(nil, baseline, nil, io.Discard, nil); err != nil {
+			return err
+		}
+	}
+	body := github.CommentBody(summary, base)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	if useGitLab {
+		override(&mr.Token, token)
+		override(&mr.APIURL, apiURL)
+		if err := gitlab.UpsertNote(ctx, http.DefaultClient, mr, body); err != nil {
+			return err
+		}
+		fmt.Printf("Coverage note posted on merge request !%s\n", mr.IID)
+		return nil
+	}
+
+	override(&pr.Token, token)
+	override(&pr.APIURL, apiURL)
+	url, err := github.UpsertComment(ctx, http.DefaultClient, pr, body)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Coverage comment: %s\n", url)
+	return nil
+}
+
+// override replaces a value taken from the environment by the one given on the command line, if any
+func override(value *string, flagValue string) {
+	if flagValue != "" {
+		*value = flagValue
+	}
+}
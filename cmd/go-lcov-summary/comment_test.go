@@ -0,0 +1,55 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunComment(t *testing.T) {
+	var body string
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /repos/owner/repo/issues/5/comments", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("[]"))
+	})
+	mux.HandleFunc("POST /repos/owner/repo/issues/5/comments", func(w http.ResponseWriter, r *http.Request) {
+		var comment struct{ Body string }
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&comment))
+		body = comment.Body
+		w.Write([]byte(`{"id": 1, "html_url": "https://github.com/owner/repo/pull/5#issuecomment-1"}`))
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	t.Setenv("GITHUB_TOKEN", "")
+	require.NoError(t, runComment([]string{"--github", "--api-url", server.URL, "--repo", "owner/repo", "--pr", "5",
+		"--baseline", "../../testdata/sample.lcov", "../../testdata/sample.lcov"}))
+	assert.Contains(t, body, "| Lines | 66.7% | +0.0% | 6 | 9 |")
+
+	assert.EqualError(t, runComment([]string{"../../testdata/sample.lcov"}), "exactly one of --github and --gitlab is required")
+}
+
+func TestRunCommentGitLab(t *testing.T) {
+	var body string
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /projects/42/merge_requests/3/notes", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "secret", r.Header.Get("PRIVATE-TOKEN"))
+		w.Write([]byte("[]"))
+	})
+	mux.HandleFunc("POST /projects/42/merge_requests/3/notes", func(w http.ResponseWriter, r *http.Request) {
+		var note struct{ Body string }
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&note))
+		body = note.Body
+		w.Write([]byte(`{"id": 1}`))
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	t.Setenv("GITLAB_TOKEN", "secret")
+	require.NoError(t, runComment([]string{"--gitlab", "--api-url", server.URL, "--project", "42", "--mr", "3", "../../testdata/sample.lcov"}))
+	assert.Contains(t, body, "| Lines | 66.7% | 6 | 9 |")
+}
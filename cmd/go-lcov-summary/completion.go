@@ -0,0 +1,330 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/shastick/go-lcov-summary"
+)
+
+// The completion subcommand lists the other subcommands, so it can't be part of
+// the subcommands initializer
+func init() {
+	subcommands["completion"] = runCompletion
+}
+
+// completionShells are the shells completion scripts can be generated for
+var completionShells = []string{"bash", "zsh", "fish"}
+
+// runCompletion implements the 'completion bash|zsh|fish' subcommand
+func runCompletion(args []string) error {
+	fs := flag.NewFlagSet("completion", flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: go-lcov-summary completion bash|zsh|fish\n")
+		fmt.Fprintf(fs.Output(), "\nPrints the completion script of the shell, e.g. for bash:\n")
+		fmt.Fprintf(fs.Output(), "  source <(go-lcov-summary completion bash)\n")
+	}
+
+	positional, err := parseInterleaved(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 1 {
+		return errors.New("expected a single shell: bash, zsh or fish")
+	}
+
+	commands, err := completionCommands()
+	if err != nil {
+		return err
+	}
+	switch positional[0] {
+	case "bash":
+		return writeBashCompletion(os.Stdout, commands)
+	case "zsh":
+		return writeZshCompletion(os.Stdout, commands)
+	case "fish":
+		return writeFishCompletion(os.Stdout, commands)
+	default:
+		return fmt.Errorf("unsupported shell: %s", positional[0])
+	}
+}
+
+// completionCommand lists the flags of a command, the summary command having no name
+type completionCommand struct {
+	name  string
+	flags []completionFlag
+}
+
+// completionFlag describes a flag for completion. Flags taking a value complete
+// the given values, or file names when there are none.
+type completionFlag struct {
+	name       string
+	short      string
+	usage      string
+	takesValue bool
+	values     []string
+}
+
+// completionCommands returns the summary command followed by the subcommands, sorted by name
+func completionCommands() ([]completionCommand, error) {
+	commands := []completionCommand{{flags: completionFlags("", newFlagSet(&config{}, io.Discard))}}
+
+	names := make([]string, 0, len(subcommands))
+	for name := range subcommands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		command := completionCommand{name: name}
+		if name != "completion" {
+			var args []string
+			switch name {
+			case "upload":
+				args = []string{"codecov"}
+			case "report":
+				args = []string{"bitbucket"}
+			}
+			var fs *flag.FlagSet
+			inspectFlags = func(inspected *flag.FlagSet) { fs = inspected }
+			err := subcommands[name](args)
+			inspectFlags = nil
+			if !errors.Is(err, errInspected) {
+				return nil, fmt.Errorf("listing the flags of %s: %v", name, err)
+			}
+			command.flags = completionFlags(name, fs)
+		}
+		commands = append(commands, command)
+	}
+	return commands, nil
+}
+
+// completionFlags lists the flags of a flag set, short aliases being attached to their long flag
+func completionFlags(command string, fs *flag.FlagSet) []completionFlag {
+	var flags []completionFlag
+	fs.VisitAll(func(f *flag.Flag) {
+		if strings.HasPrefix(f.Usage, "shorthand for --") {
+			return
+		}
+		_, usage := flag.UnquoteUsage(f)
+		boolFlag, ok := f.Value.(interface{ IsBoolFlag() bool })
+		flags = append(flags, completionFlag{
+			name:       f.Name,
+			short:      shorthand(fs, f.Name),
+			usage:      usage,
+			takesValue: !ok || !boolFlag.IsBoolFlag(),
+			values:     flagValues(command, f.Name),
+		})
+	})
+	return flags
+}
+
+// flagValues returns the values a flag accepts, when they form a fixed set
+func flagValues(command, name string) []string {
+	switch {
+	case command == "" && name == "format":
+		return lcov.Renderers()
+	case command == "" && name == "unknown-records":
+		return []string{"ignore", "warn", "error"}
+	case command == "" && name == "duplicates":
+		return []string{"keep", "merge", "first"}
+	case command == "" && name == "log-format":
+		return []string{"text", "json"}
+	case command == "" && name == "color":
+		return []string{colorAuto, colorAlways, colorNever}
+	case command == "convert" && name == "from":
+		return []string{"lcov", "coverprofile", "cobertura", "jacoco", "clover", "gcov", "istanbul"}
+	case command == "lint" && name == "format":
+		return []string{"text", "json"}
+	case command == "convert" && name == "to":
+		return []string{"lcov", "coverprofile", "cobertura"}
+	}
+	return nil
+}
+
+// subcommandNames returns the names of the subcommands
+func subcommandNames(commands []completionCommand) []string {
+	var names []string
+	for _, command := range commands {
+		if command.name != "" {
+			names = append(names, command.name)
+		}
+	}
+	return names
+}
+
+// writeBashCompletion writes the bash completion script
+func writeBashCompletion(w io.Writer, commands []completionCommand) error {
+	ew := &errWriter{w: w}
+	names := strings.Join(subcommandNames(commands), " ")
+
+	ew.printf("# bash completion for go-lcov-summary\n")
+	ew.printf("_go_lcov_summary() {\n")
+	ew.printf("    local cur=\"${COMP_WORDS[COMP_CWORD]}\" prev=\"${COMP_WORDS[COMP_CWORD-1]}\" cmd=\"\"\n")
+	ew.printf("    if [[ ${COMP_CWORD} -gt 1 ]]; then\n")
+	ew.printf("        case \"${COMP_WORDS[1]}\" in\n")
+	ew.printf("            %s) cmd=\"${COMP_WORDS[1]}\" ;;\n", strings.ReplaceAll(names, " ", "|"))
+	ew.printf("        esac\n")
+	ew.printf("    fi\n\n")
+
+	ew.printf("    case \"${cmd}:${prev}\" in\n")
+	for _, command := range commands {
+		for _, f := range command.flags {
+			if !f.takesValue {
+				continue
+			}
+			action := "compgen -f -- \"${cur}\""
+			if len(f.values) > 0 {
+				action = fmt.Sprintf("compgen -W %q -- \"${cur}\"", strings.Join(f.values, " "))
+			}
+			ew.printf("        %s) COMPREPLY=($(%s)); return ;;\n", strings.Join(bashPatterns(command.name, f), "|"), action)
+		}
+	}
+	ew.printf("    esac\n\n")
+
+	ew.printf("    if [[ ${cur} == -* ]]; then\n")
+	ew.printf("        case \"${cmd}\" in\n")
+	for _, command := range commands {
+		var flags []string
+		for _, f := range command.flags {
+			flags = append(flags, "--"+f.name)
+			if f.short != "" {
+				flags = append(flags, "-"+f.short)
+			}
+		}
+		ew.printf("            %q) COMPREPLY=($(compgen -W %q -- \"${cur}\")) ;;\n", command.name, strings.Join(flags, " "))
+	}
+	ew.printf("        esac\n")
+	ew.printf("        return\n")
+	ew.printf("    fi\n\n")
+
+	ew.printf("    case \"${cmd}\" in\n")
+	ew.printf("        completion) COMPREPLY=($(compgen -W %q -- \"${cur}\")); return ;;\n", strings.Join(completionShells, " "))
+	ew.printf("        \"\") [[ ${COMP_CWORD} -eq 1 ]] && COMPREPLY=($(compgen -W %q -- \"${cur}\")) ;;\n", names)
+	ew.printf("    esac\n")
+	ew.printf("    COMPREPLY+=($(compgen -f -- \"${cur}\"))\n")
+	ew.printf("}\n")
+	ew.printf("complete -o filenames -F _go_lcov_summary go-lcov-summary\n")
+	return ew.err
+}
+
+// bashPatterns returns the 'command:flag' case patterns matching a flag being given its value
+func bashPatterns(command string, f completionFlag) []string {
+	patterns := []string{fmt.Sprintf("%s:--%s", command, f.name)}
+	if f.short != "" {
+		patterns = append(patterns, fmt.Sprintf("%s:-%s", command, f.short))
+	}
+	return patterns
+}
+
+// writeZshCompletion writes the zsh completion script
+func writeZshCompletion(w io.Writer, commands []completionCommand) error {
+	ew := &errWriter{w: w}
+
+	ew.printf("#compdef go-lcov-summary\n\n")
+	ew.printf("_go_lcov_summary() {\n")
+	ew.printf("    case $words[2] in\n")
+	for _, command := range commands[1:] {
+		ew.printf("    %s)\n", command.name)
+		ew.printf("        shift words\n")
+		ew.printf("        (( CURRENT-- ))\n")
+		ew.printf("        _arguments \\\n")
+		for _, f := range command.flags {
+			ew.printf("            %s \\\n", zshSpec(f))
+		}
+		if command.name == "completion" {
+			ew.printf("            '1:shell:(%s)'\n", strings.Join(completionShells, " "))
+		} else {
+			ew.printf("            '*:file:_files'\n")
+		}
+		ew.printf("        ;;\n")
+	}
+	ew.printf("    *)\n")
+	ew.printf("        if (( CURRENT == 2 )) && [[ $PREFIX != -* ]]; then\n")
+	ew.printf("            compadd -- %s\n", strings.Join(subcommandNames(commands), " "))
+	ew.printf("        fi\n")
+	ew.printf("        _arguments \\\n")
+	for _, f := range commands[0].flags {
+		ew.printf("            %s \\\n", zshSpec(f))
+	}
+	ew.printf("            '*:file:_files'\n")
+	ew.printf("        ;;\n")
+	ew.printf("    esac\n")
+	ew.printf("}\n\n")
+	ew.printf("_go_lcov_summary \"$@\"\n")
+	return ew.err
+}
+
+// zshSpec returns the _arguments specification of a flag
+func zshSpec(f completionFlag) string {
+	description := "[" + zshEscape(f.usage) + "]"
+	action := ""
+	if f.takesValue {
+		action = ":value:_files"
+		if len(f.values) > 0 {
+			action = ":value:(" + strings.Join(f.values, " ") + ")"
+		}
+	}
+
+	long, short := "--"+f.name, "-"+f.short
+	if f.takesValue {
+		long += "="
+	}
+	if f.short == "" {
+		return "'" + long + description + action + "'"
+	}
+	return fmt.Sprintf("'(-%s --%s)'{%s,%s}'%s%s'", f.short, f.name, short, long, description, action)
+}
+
+// zshEscape escapes a flag description for a single-quoted _arguments specification
+func zshEscape(s string) string {
+	return strings.NewReplacer(`'`, `'\''`, `[`, `\[`, `]`, `\]`, `:`, `\:`).Replace(s)
+}
+
+// writeFishCompletion writes the fish completion script
+func writeFishCompletion(w io.Writer, commands []completionCommand) error {
+	ew := &errWriter{w: w}
+	names := strings.Join(subcommandNames(commands), " ")
+
+	ew.printf("# fish completion for go-lcov-summary\n")
+	ew.printf("complete -c go-lcov-summary -n '__fish_use_subcommand' -a '%s'\n", names)
+	ew.printf("complete -c go-lcov-summary -n '__fish_seen_subcommand_from completion' -f -a '%s'\n", strings.Join(completionShells, " "))
+	for _, command := range commands {
+		condition := fmt.Sprintf("not __fish_seen_subcommand_from %s", names)
+		if command.name != "" {
+			condition = "__fish_seen_subcommand_from " + command.name
+		}
+		for _, f := range command.flags {
+			ew.printf("complete -c go-lcov-summary -n '%s' -l %s", condition, f.name)
+			if f.short != "" {
+				ew.printf(" -s %s", f.short)
+			}
+			if f.takesValue {
+				ew.printf(" -r")
+			}
+			if len(f.values) > 0 {
+				ew.printf(" -f -a '%s'", strings.Join(f.values, " "))
+			}
+			ew.printf(" -d '%s'\n", strings.ReplaceAll(f.usage, `'`, `\'`))
+		}
+	}
+	return ew.err
+}
+
+// errWriter remembers the first write error so the scripts can be written unconditionally
+type errWriter struct {
+	w   io.Writer
+	err error
+}
+
+func (ew *errWriter) printf(format string, args ...any) {
+	if ew.err != nil {
+		return
+	}
+	_, ew.err = fmt.Fprintf(ew.w, format, args...)
+}
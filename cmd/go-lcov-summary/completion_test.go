@@ -0,0 +1,67 @@
+package main
+
+import (
+	"bytes"
+	"os/exec"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompletionCommands(t *testing.T) {
+	commands, err := completionCommands()
+	require.NoError(t, err)
+	require.Equal(t, "", commands[0].name)
+	assert.Len(t, commands, len(subcommands)+1)
+
+	format := findCompletionFlag(commands, "", "format")
+	require.NotNil(t, format)
+	assert.Equal(t, "f", format.short)
+	assert.True(t, format.takesValue)
+	assert.Contains(t, format.values, "markdown")
+
+	quiet := findCompletionFlag(commands, "", "quiet")
+	require.NotNil(t, quiet)
+	assert.False(t, quiet.takesValue)
+
+	// The flags of upload are only declared after its service argument
+	assert.NotNil(t, findCompletionFlag(commands, "upload", "token"))
+}
+
+func findCompletionFlag(commands []completionCommand, command, name string) *completionFlag {
+	for _, c := range commands {
+		for i, f := range c.flags {
+			if c.name == command && f.name == name {
+				return &c.flags[i]
+			}
+		}
+	}
+	return nil
+}
+
+func TestCompletionScripts(t *testing.T) {
+	commands, err := completionCommands()
+	require.NoError(t, err)
+
+	var bash, zsh, fish bytes.Buffer
+	require.NoError(t, writeBashCompletion(&bash, commands))
+	require.NoError(t, writeZshCompletion(&zsh, commands))
+	require.NoError(t, writeFishCompletion(&fish, commands))
+
+	assert.Contains(t, bash.String(), `:--format|:-f) COMPREPLY=($(compgen -W "cobertura`)
+	assert.Contains(t, zsh.String(), `'(-f --format)'{-f,--format=}'[output format of the summary`)
+	assert.Contains(t, fish.String(), `-l format -s f -r -f -a 'cobertura`)
+
+	if _, err := exec.LookPath("bash"); err == nil {
+		cmd := exec.Command("bash", "-n")
+		cmd.Stdin = &bash
+		output, err := cmd.CombinedOutput()
+		assert.NoError(t, err, string(output))
+	}
+}
+
+func TestRunCompletion(t *testing.T) {
+	assert.EqualError(t, runCompletion(nil), "expected a single shell: bash, zsh or fish")
+	assert.EqualError(t, runCompletion([]string{"tcsh"}), "unsupported shell: tcsh")
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/shastick/go-lcov-summary"
+)
+
+// runConvert implements the 'convert [flags] <coverage-file>' subcommand
+func runConvert(args []string) error {
+	fs := flag.NewFlagSet("convert", flag.ContinueOnError)
+	var from, to, output string
+	fs.StringVar(&from, "from", "", "input `format`: lcov, coverprofile, cobertura, jacoco, clover, gcov or istanbul (default detected)")
+	fs.StringVar(&to, "to", string(lcov.FormatLCOV), "output `format`: lcov, coverprofile or cobertura")
+	stringFlag(fs, &output, "output", "o", "-", "write the converted data to this `file`, '-' for stdout")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: go-lcov-summary convert [flags] <coverage-file>|-\n")
+		printFlags(fs)
+	}
+
+	inputs, err := parseInterleaved(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(inputs) != 1 {
+		return errors.New("expected exactly one input")
+	}
+
+	summary, err := readFormat(inputs[0], lcov.Format(from))
+	if err != nil {
+		return err
+	}
+
+	// Converted in memory, so that no partial output is left on errors
+	var converted bytes.Buffer
+	if err := lcov.WriteFormat(lcov.Format(to), &converted, summary.Files); err != nil {
+		return err
+	}
+	if output == "-" {
+		_, err = os.Stdout.Write(converted.Bytes())
+		return err
+	}
+	return os.WriteFile(output, converted.Bytes(), 0o644)
+}
+
+// readFormat parses an input with its per-file details, '-' meaning stdin.
+// The format is detected when empty.
+func readFormat(input string, format lcov.Format) (*lcov.Summary, error) {
+	var reader io.Reader = os.Stdin
+	if input != "-" {
+		file, err := os.Open(input)
+		if err != nil {
+			return nil, fmt.Errorf("error opening file: %w", err)
+		}
+		defer file.Close()
+		reader = file
+	}
+
+	var summary *lcov.Summary
+	var err error
+	if format == "" {
+		summary, _, err = lcov.SummarizeAny(reader, lcov.WithDetails())
+	} else {
+		summary, err = lcov.ParseFormat(format, reader, lcov.WithDetails())
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error parsing coverage file %s: %w", input, err)
+	}
+	return summary, nil
+}
@@ -0,0 +1,33 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/shastick/go-lcov-summary"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunConvert(t *testing.T) {
+	output := filepath.Join(t.TempDir(), "out.info")
+	require.NoError(t, runConvert([]string{"--from", "coverprofile", "--to", "lcov", "../../testdata/coverage.out", "-o", output}))
+
+	file, err := os.Open(output)
+	require.NoError(t, err)
+	defer file.Close()
+	converted, err := lcov.Summarize(file)
+	require.NoError(t, err)
+	assert.Equal(t, 2, converted.TotalFiles)
+	assert.Equal(t, int64(10), converted.TotalLines)
+	assert.Equal(t, int64(6), converted.CoveredLines)
+}
+
+func TestRunConvertErrors(t *testing.T) {
+	output := filepath.Join(t.TempDir(), "out.xml")
+	assert.EqualError(t, runConvert([]string{"--to", "jacoco", "../../testdata/sample.lcov", "-o", output}), "unsupported output format: jacoco")
+	assert.NoFileExists(t, output)
+	assert.ErrorContains(t, runConvert([]string{"--from", "xlsx", "../../testdata/sample.lcov"}), "unsupported input format: xlsx")
+	assert.EqualError(t, runConvert([]string{"a.info", "b.info"}), "expected exactly one input")
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/shastick/go-lcov-summary"
+)
+
+// reportEstimate prints the coverage estimated from every nth source file block
+// of the LCOV inputs, read one after the other, only the line rate when quiet
+func reportEstimate(w io.Writer, inputs []string, every int, quiet bool) error {
+	readers := make([]io.Reader, 0, len(inputs))
+	for _, input := range inputs {
+		if input == "-" {
+			readers = append(readers, os.Stdin)
+			continue
+		}
+		file, err := os.Open(input)
+		if err != nil {
+			return fmt.Errorf("error opening file: %w", err)
+		}
+		defer file.Close()
+		readers = append(readers, file)
+	}
+
+	estimate, err := lcov.EstimateSummary(io.MultiReader(readers...), every)
+	if err != nil {
+		return fmt.Errorf("error estimating coverage: %w", err)
+	}
+	if quiet {
+		_, err := fmt.Fprintf(w, "%.1f\n", estimate.LineCoverageRate)
+		return err
+	}
+
+	ew := &errWriter{w: w}
+	ew.printf("Estimated coverage rate, from %d of %d source files:\n", estimate.SampledFiles, estimate.TotalFiles)
+	ew.printf("  source files: %d\n", estimate.TotalFiles)
+	metrics := []struct {
+		label, unit    string
+		rate           float64
+		interval       lcov.Interval
+		covered, total int64
+	}{
+		{"lines.......", "lines", estimate.LineCoverageRate, estimate.Lines, estimate.CoveredLines, estimate.TotalLines},
+		{"functions...", "functions", estimate.FunctionCoverageRate, estimate.Functions, estimate.CoveredFunctions, estimate.TotalFunctions},
+		{"branches....", "branches", estimate.BranchCoverageRate, estimate.Branches, estimate.CoveredBranches, estimate.TotalBranches},
+	}
+	for _, m := range metrics {
+		if m.total == 0 {
+			ew.printf("  %s: no data found\n", m.label)
+			continue
+		}
+		ew.printf("  %s: %.1f%% (95%% confidence %.1f%% to %.1f%%, about %d of %d %s)\n",
+			m.label, m.rate, m.interval.Low, m.interval.High, m.covered, m.total, m.unit)
+	}
+	return ew.err
+}
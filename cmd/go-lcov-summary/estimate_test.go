@@ -0,0 +1,24 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReportEstimate(t *testing.T) {
+	var out strings.Builder
+	require.NoError(t, reportEstimate(&out, []string{"../../testdata/sample.lcov"}, 1, false))
+	assert.Equal(t, `Estimated coverage rate, from 2 of 2 source files:
+  source files: 2
+  lines.......: 66.7% (95% confidence 66.7% to 66.7%, about 6 of 9 lines)
+  functions...: no data found
+  branches....: no data found
+`, out.String())
+
+	out.Reset()
+	require.NoError(t, reportEstimate(&out, []string{"../../testdata/sample.lcov"}, 2, true))
+	assert.Equal(t, "60.0\n", out.String())
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/shastick/go-lcov-summary"
+)
+
+// runFilter implements the 'filter [flags] <coverage-file>...' subcommand
+func runFilter(args []string) error {
+	fs := flag.NewFlagSet("filter", flag.ContinueOnError)
+	var output string
+	var extract, remove patternsFlag
+	stringFlag(fs, &output, "output", "o", "-", "write the filtered LCOV data to this `file`, '-' for stdout")
+	fs.Var(&extract, "extract", "only keep the files matching this glob `pattern`, like 'lcov --extract' (repeatable)")
+	fs.Var(&remove, "remove", "drop the files matching this glob `pattern`, like 'lcov --remove' (repeatable)")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: go-lcov-summary filter [flags] <coverage-file>...\n")
+		printFlags(fs)
+	}
+
+	inputs, err := parseInterleaved(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(inputs) == 0 {
+		return errors.New("no input given")
+	}
+	if inputs, err = expandInputs(inputs, ""); err != nil {
+		return err
+	}
+	summary, err := summarizeInputs(inputs, []lcov.Option{lcov.WithDetails()}, io.Discard, nil)
+	if err != nil {
+		return err
+	}
+
+	return writeLCOV(output, lcov.FilterFiles(summary.Files, extract, remove))
+}
+
+// patternsFlag is a repeatable flag collecting glob patterns
+type patternsFlag []string
+
+func (p *patternsFlag) String() string {
+	return strings.Join(*p, ",")
+}
+
+func (p *patternsFlag) Set(value string) error {
+	*p = append(*p, value)
+	return nil
+}
@@ -0,0 +1,27 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/shastick/go-lcov-summary"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunFilter(t *testing.T) {
+	output := filepath.Join(t.TempDir(), "filtered.info")
+	require.NoError(t, runFilter([]string{"../../testdata/sample.lcov", "--remove", "*2.go", "--remove", "vendor/*", "-o", output}))
+
+	file, err := os.Open(output)
+	require.NoError(t, err)
+	defer file.Close()
+	filtered, err := lcov.Summarize(file, lcov.WithDetails())
+	require.NoError(t, err)
+
+	require.Len(t, filtered.Files, 1)
+	assert.Equal(t, "/path/to/source/file1.go", filtered.Files[0].Path)
+
+	assert.EqualError(t, runFilter([]string{"--extract", "*.go"}), "no input given")
+}
@@ -0,0 +1,416 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/shastick/go-lcov-summary"
+	"github.com/shastick/go-lcov-summary/gitlab"
+	"github.com/shastick/go-lcov-summary/otlp"
+)
+
+// config holds the parsed command line of the summary command
+type config struct {
+	// teeLCOV is the path the parsed LCOV data is copied to, '-' for stdout
+	teeLCOV string
+	// format is the name of the renderer of the summary, output the file it is written to instead of stdout
+	format string
+	output string
+	// list requests the per-file table instead of the summary, fileTable adds it to the summary
+	list      bool
+	fileTable bool
+	// color is the --color mode, and colors the thresholds of the rate colors
+	color  string
+	colors lcov.ColorThresholds
+	// quiet prints the line coverage only, verbose adds parse statistics and progress the parsing progress
+	quiet    bool
+	verbose  bool
+	progress bool
+	// logFormat enables structured logs of the parsing on stderr, in the text or json format of slog
+	logFormat string
+	// watch reprints the summary whenever an input changes, after clearing the screen if clear is set
+	watch bool
+	clear bool
+	// incremental keeps the summaries of the inputs from one run of watch mode to the next
+	incremental *incrementalInputs
+	// github reports to the GitHub Actions job summary, outputs and annotations
+	github bool
+	// gitlab prints the coverage line for GitLab, and writes a Cobertura report to gitlabCobertura if set
+	gitlab          bool
+	gitlabCobertura string
+	// jenkinsCobertura and jenkinsJaCoCo are the reports written for the Jenkins Coverage Plugin, if set
+	jenkinsCobertura string
+	jenkinsJaCoCo    string
+	// htmlDir is the directory the HTML report is written to, if set
+	htmlDir string
+	// otlp exports the metrics to the OTLP endpoint of the environment, with the
+	// resource attributes of otlpAttributes, parsed into otlpResource, on top of its own
+	otlp           bool
+	otlpAttributes string
+	otlpResource   map[string]string
+	// glob selects the tracefiles of directory inputs, or of the working directory
+	glob string
+	// sourceDirs are the directories the source files are looked up in, whose
+	// exclusion markers are honored when set
+	sourceDirs sourceDirsFlag
+	// excludeGenerated drops the generated files, matching generatedPatterns or
+	// with the generated code header
+	excludeGenerated  bool
+	generatedPatterns patternsFlag
+	// failUnder is the minimum coverage of every metric, overridden per metric by thresholds
+	failUnder  float64
+	thresholds lcov.Thresholds
+	// failUnderFile is the minimum line coverage of every source file
+	failUnderFile float64
+	// thresholdsFile holds the 'pattern: percentage' thresholds of the files matching each pattern
+	thresholdsFile string
+	// functionThresholds are the line and branch coverage required of the functions matching each pattern
+	functionThresholds functionThresholdsFlag
+	// failOnEmpty rejects inputs without any file record
+	failOnEmpty bool
+	// strict rejects file records claiming more hits than found items, clampHits lowers their hits
+	strict    bool
+	clampHits bool
+	// deriveTotals computes the line and branch counters from the DA and BRDA records
+	deriveTotals bool
+	// checkCounts warns about every counter record not matching the detail records
+	checkCounts bool
+	// unknownRecords is the name of the policy for records of unknown type, parsed into unknownPolicy
+	unknownRecords string
+	unknownPolicy  lcov.UnknownRecordPolicy
+	// duplicates is the name of the strategy for repeated SF blocks of a source file, parsed into duplicateStrategy
+	duplicates        string
+	duplicateStrategy lcov.DuplicateStrategy
+	// diffBase is the git revision the patch coverage is computed against, or
+	// diffFile the unified diff it is computed from, failUnderPatch its threshold
+	// and uncoveredOut the file the uncovered added lines are written to
+	diffBase       string
+	diffFile       string
+	failUnderPatch float64
+	uncoveredOut   string
+	// baseline is the summary file compared against, saveBaseline the one the summary is saved to
+	baseline          string
+	saveBaseline      string
+	baselineTolerance float64
+	// ratchet is the baseline file compared against, if it exists, and raised to the summary
+	ratchet string
+	// history is the coverage history the rates are compared against, with the
+	// moving average of its averageOf last entries less averageTolerance
+	history          string
+	averageOf        int
+	averageTolerance float64
+	// attestation is the file the signed coverage statement is written to, with the
+	// key of attestationKey, about the attestationSubjects files
+	attestation         string
+	attestationKey      string
+	attestationSubjects patternsFlag
+	// sample estimates the summary from every sample-th source file block, when not zero
+	sample int
+	// warnOnly reports the violations of the coverage checks as warnings, without failing
+	warnOnly bool
+	// summaryOut is the file the structured result of the run is written to
+	summaryOut string
+	// version prints the version instead of summarizing, and schema the JSON Schema of the json format
+	version bool
+	schema  bool
+	// inputs lists the positional arguments, '-' meaning stdin
+	inputs []string
+}
+
+// newFlagSet declares the flags of the summary command on a new flag set.
+// Flags with a short alias are registered under both names, so that '-t' and
+// '--tee-lcov' are equivalent.
+func newFlagSet(cfg *config, output io.Writer) *flag.FlagSet {
+	fs := flag.NewFlagSet("go-lcov-summary", flag.ContinueOnError)
+	fs.SetOutput(output)
+
+	stringFlag(fs, &cfg.teeLCOV, "tee-lcov", "t", "", "also write the parsed LCOV data to this `file`, '-' for stdout (the summary then goes to stderr)")
+
+	stringFlag(fs, &cfg.format, "format", "f", defaultFormat, "output `format` of the summary: "+strings.Join(lcov.Renderers(), ", "))
+	stringFlag(fs, &cfg.output, "output", "o", "", "write the summary to this `file` instead of stdout, replacing it atomically, e.g. for the node_exporter textfile collector with --format openmetrics")
+	boolFlag(fs, &cfg.list, "list", "l", false, "print a table of every source file with its coverage, like 'lcov --list' (same as --format list)")
+	fs.BoolVar(&cfg.fileTable, "file-table", false, "add the per-file table to the summary, in formats supporting it (markdown)")
+	fs.StringVar(&cfg.color, "color", colorAuto, "color the coverage rates: auto (on terminals), always or never")
+	fs.Float64Var(&cfg.colors.Medium, "color-medium", lcov.DefaultColorThresholds.Medium, "coverage `percentage` from which rates are yellow instead of red")
+	fs.Float64Var(&cfg.colors.High, "color-high", lcov.DefaultColorThresholds.High, "coverage `percentage` from which rates are green instead of yellow")
+	boolFlag(fs, &cfg.quiet, "quiet", "q", false, "only print the line coverage percentage, without warnings")
+	boolFlag(fs, &cfg.verbose, "verbose", "v", false, "also print parse statistics of every input")
+	fs.StringVar(&cfg.logFormat, "log-format", "", "write structured logs of the parsing and its warnings to stderr, in this `format`: text or json (with debug events in --verbose mode)")
+	fs.BoolVar(&cfg.progress, "progress", false, "print the parsing progress of every input to stderr, for large tracefiles")
+	boolFlag(fs, &cfg.watch, "watch", "w", false, "keep running and print the summary again whenever an input changes")
+	fs.BoolVar(&cfg.clear, "clear", false, "clear the screen before printing the summary again in watch mode")
+	fs.BoolVar(&cfg.github, "github", false, "in GitHub Actions, add the summary to the job summary, set the coverage-* step outputs and annotate the files below the line coverage threshold")
+	fs.BoolVar(&cfg.gitlab, "gitlab", false, "also print the line coverage for the GitLab job coverage regex "+gitlab.CoverageRegex)
+	fs.StringVar(&cfg.gitlabCobertura, "gitlab-cobertura", "", "also write a Cobertura report to this `file`, for the GitLab coverage_report artifact")
+	fs.StringVar(&cfg.jenkinsCobertura, "jenkins-cobertura", "", "also write a Cobertura report to this `file`, in the layout of the Jenkins Coverage Plugin")
+	fs.StringVar(&cfg.jenkinsJaCoCo, "jenkins-jacoco", "", "also write a JaCoCo report to this `file`, in the layout of the Jenkins Coverage Plugin")
+	fs.StringVar(&cfg.htmlDir, "html", "", "also write an HTML report, with the highlighted source of every file annotated with its coverage like genhtml, to this `directory`, reading the sources from --source-dir")
+	fs.BoolVar(&cfg.otlp, "otlp", false, "also export the coverage metrics to the OpenTelemetry collector of $OTEL_EXPORTER_OTLP_ENDPOINT (default http://localhost:4318), with OTLP/HTTP")
+	fs.StringVar(&cfg.otlpAttributes, "otlp-attributes", "", "comma separated key=value resource `attributes` of the exported metrics, e.g. vcs.ref.head.name=main, added to $OTEL_RESOURCE_ATTRIBUTES and those of the CI job")
+	stringFlag(fs, &cfg.glob, "glob", "g", "", "select the files of directory inputs, or of the working directory when none is given, matching this `pattern`; '**' matches any number of directories (default '*.lcov' and '*.info')")
+
+	fs.Var(&cfg.sourceDirs, "source-dir", "`directory` the source files are looked up in, for --exclude-generated, and to honor their LCOV_EXCL_* exclusion markers, or PREFIX=DIR to look the tracefile paths below PREFIX up below DIR; repeatable, tried in order (default the working directory, without exclusion markers)")
+	fs.BoolVar(&cfg.excludeGenerated, "exclude-generated", false, "leave out the generated files: those matching --generated-pattern, and those whose source has the '// Code generated ... DO NOT EDIT.' header")
+	fs.Var(&cfg.generatedPatterns, "generated-pattern", "glob `pattern` of the generated files for --exclude-generated, replacing the defaults "+strings.Join(lcov.DefaultGeneratedPatterns, ", ")+" (repeatable)")
+
+	fs.Float64Var(&cfg.failUnder, "fail-under", 0, "exit with an error when any coverage rate is below this `percentage`")
+	fs.BoolVar(&cfg.failOnEmpty, "fail-on-empty", false, "exit with an error when an input holds no coverage data, which usually means a broken pipeline")
+	fs.BoolVar(&cfg.strict, "strict", false, "exit with an error when a source file claims more hits than lines, functions or branches (e.g. LH > LF), or its SF block lacks end_of_record, instead of warning")
+	fs.StringVar(&cfg.unknownRecords, "unknown-records", lcov.UnknownRecordsIgnore.String(), "handling of the records of unknown type, e.g. added by newer lcov versions: ignore, warn or error")
+	fs.StringVar(&cfg.duplicates, "duplicates", lcov.DuplicatesKeep.String(), "handling of the SF blocks repeating a source file within a tracefile, e.g. concatenated with 'cat': keep (count every block), merge (union their data) or first (drop the others)")
+	fs.BoolVar(&cfg.clampHits, "clamp-hits", false, "lower the hits of source files claiming more hits than lines, functions or branches to their found counts")
+	fs.BoolVar(&cfg.deriveTotals, "derive-totals", false, "ignore the LF, LH, BRF and BRH records and count the lines and branches from the DA and BRDA records")
+	fs.BoolVar(&cfg.checkCounts, "check-counts", false, "warn about every LF, LH, BRF and BRH record not matching the DA and BRDA records")
+	fs.Float64Var(&cfg.thresholds.Lines, "fail-under-lines", 0, "exit with an error when the line coverage is below this `percentage`")
+	fs.Float64Var(&cfg.thresholds.Functions, "fail-under-functions", 0, "exit with an error when the function coverage is below this `percentage`")
+	fs.Float64Var(&cfg.thresholds.Branches, "fail-under-branches", 0, "exit with an error when the branch coverage is below this `percentage`")
+	fs.Float64Var(&cfg.failUnderFile, "fail-under-file", 0, "exit with an error when the line coverage of any source file is below this `percentage`, listing the offending files")
+	fs.Var(&cfg.functionThresholds, "fail-under-function", "exit with an error when the line or branch coverage of any function matching a pattern is below its percentage, given as `pattern=lines[,branches]`, the pattern being a file glob optionally followed by ':' and a function name glob, e.g. 'pkg/crypto/**:Encrypt*=90,80' (repeatable)")
+	fs.StringVar(&cfg.thresholdsFile, "thresholds-file", "", "exit with an error when any coverage rate of the files matching a pattern of this `file` is below its percentage, given as 'pattern: percentage' lines, e.g. 'pkg/payments/**: 90'")
+
+	fs.StringVar(&cfg.diffBase, "diff-base", "", "also report the coverage of the lines changed since the merge base with this git `revision`, e.g. origin/main")
+	fs.StringVar(&cfg.diffFile, "diff-file", "", "also report the coverage of the lines added by the unified diff of this `file`, '-' for stdin, e.g. the output of git diff")
+	fs.Float64Var(&cfg.failUnderPatch, "fail-under-patch", 0, "exit with an error when the coverage of the changed lines is below this `percentage` (requires --diff-base or --diff-file)")
+	fs.StringVar(&cfg.uncoveredOut, "uncovered-out", "", "write every added line of the diff that was never executed, with its hunk context, as JSON to this `file` (requires --diff-base or --diff-file)")
+	fs.StringVar(&cfg.baseline, "baseline", "", "exit with an error when any coverage rate is below the one of this baseline `file`, written by --save-baseline (the markdown format also shows the delta)")
+	fs.Float64Var(&cfg.baselineTolerance, "baseline-tolerance", 0, "percentage `points` a coverage rate may drop below the baseline")
+	fs.StringVar(&cfg.saveBaseline, "save-baseline", "", "write the summary to this baseline `file` when all coverage checks pass")
+	fs.StringVar(&cfg.ratchet, "ratchet", "", "exit with an error when any coverage rate is below the one of this baseline `file`, and raise the rates of the file to the achieved ones when all coverage checks pass, so coverage may never decrease; the file is created by the first run")
+	fs.StringVar(&cfg.history, "history", "", "exit with an error when any coverage rate is below the moving average of the last entries of this JSON-lines history `file`, as written by the history record subcommand")
+	fs.IntVar(&cfg.averageOf, "average-of", 5, "number of history entries, `n`, the moving average of --history is computed over")
+	fs.Float64Var(&cfg.averageTolerance, "average-tolerance", 0, "percentage `points` a coverage rate may drop below the moving average of --history")
+	fs.StringVar(&cfg.attestation, "attestation", "", "write the coverage of the tracefile, with its per-file records, as an in-toto statement signed in a DSSE envelope to this `file` (requires --attestation-key)")
+	fs.StringVar(&cfg.attestationKey, "attestation-key", "", "PEM encoded ed25519 private key `file` --attestation is signed with, e.g. generated by openssl genpkey -algorithm ed25519")
+	fs.Var(&cfg.attestationSubjects, "attestation-subject", "artifact `file`, e.g. a release archive, whose digest --attestation is about; repeatable")
+	fs.IntVar(&cfg.sample, "sample", 0, "only parse every `n`th source file block of the LCOV inputs, and print the estimated coverage rates with their 95% confidence bounds, a quick preview of huge tracefiles; other outputs are skipped, and the coverage checks can't be combined with it")
+	fs.BoolVar(&cfg.warnOnly, "warn-only", false, "report the violations of the coverage checks as warnings, and annotations with --github, and exit successfully, to observe new checks before enforcing them; the baseline files are left unchanged")
+
+	fs.StringVar(&cfg.summaryOut, "summary-out", "", "also write the result of the run as JSON to this `file`, whatever the format: the summary, the coverage checks, their violations and the warnings")
+
+	fs.BoolVar(&cfg.version, "version", false, "print the version of go-lcov-summary and exit")
+	fs.BoolVar(&cfg.schema, "schema", false, "print the JSON Schema of the json format and exit")
+
+	fs.Usage = func() { printUsage(fs) }
+	return fs
+}
+
+// stringFlag registers a string flag under its long name and, if not empty, its short alias
+func stringFlag(fs *flag.FlagSet, p *string, long, short, value, usage string) {
+	fs.StringVar(p, long, value, usage)
+	if short != "" {
+		fs.StringVar(p, short, value, "shorthand for --"+long)
+	}
+}
+
+// boolFlag registers a boolean flag under its long name and, if not empty, its short alias
+func boolFlag(fs *flag.FlagSet, p *bool, long, short string, value bool, usage string) {
+	fs.BoolVar(p, long, value, usage)
+	if short != "" {
+		fs.BoolVar(p, short, value, "shorthand for --"+long)
+	}
+}
+
+// parseFlags parses the command line arguments of the summary command.
+// Flags and positional arguments may be interleaved, '--' ends flag parsing.
+// Errors are reported to output along with the usage, and flag.ErrHelp is
+// returned when --help was requested.
+func parseFlags(args []string, output io.Writer) (*config, error) {
+	cfg := &config{}
+	fs := newFlagSet(cfg, output)
+
+	inputs, err := parseInterleaved(fs, args)
+	if err != nil {
+		return nil, err
+	}
+	cfg.inputs = inputs
+	if cfg.version || cfg.schema {
+		return cfg, nil
+	}
+
+	// The combined threshold applies to the metrics without a threshold of their own
+	for _, threshold := range []*float64{&cfg.thresholds.Lines, &cfg.thresholds.Functions, &cfg.thresholds.Branches} {
+		if *threshold == 0 {
+			*threshold = cfg.failUnder
+		}
+	}
+
+	if cfg.list {
+		cfg.format = "list"
+	}
+	if _, ok := lcov.LookupRenderer(cfg.format); !ok {
+		return nil, usageError(fs, fmt.Errorf("unknown output format: %s", cfg.format))
+	}
+	if cfg.quiet && cfg.verbose {
+		return nil, usageError(fs, errors.New("--quiet and --verbose are mutually exclusive"))
+	}
+	switch cfg.color {
+	case colorAuto, colorAlways, colorNever:
+	default:
+		return nil, usageError(fs, fmt.Errorf("invalid color mode: %s", cfg.color))
+	}
+	switch cfg.logFormat {
+	case "", "text", "json":
+	default:
+		return nil, usageError(fs, fmt.Errorf("invalid log format: %s", cfg.logFormat))
+	}
+	if cfg.unknownPolicy, err = lcov.ParseUnknownRecordPolicy(cfg.unknownRecords); err != nil {
+		return nil, usageError(fs, err)
+	}
+	if cfg.duplicateStrategy, err = lcov.ParseDuplicateStrategy(cfg.duplicates); err != nil {
+		return nil, usageError(fs, err)
+	}
+	if cfg.otlpResource, err = otlp.ParseAttributes(cfg.otlpAttributes); err != nil {
+		return nil, usageError(fs, fmt.Errorf("invalid --otlp-attributes: %w", err))
+	}
+	if len(cfg.generatedPatterns) == 0 {
+		cfg.generatedPatterns = lcov.DefaultGeneratedPatterns
+	}
+	if cfg.ratchet != "" && (cfg.baseline != "" || cfg.saveBaseline != "") {
+		return nil, usageError(fs, errors.New("--ratchet can't be combined with --baseline or --save-baseline"))
+	}
+	if cfg.diffBase != "" && cfg.diffFile != "" {
+		return nil, usageError(fs, errors.New("--diff-base and --diff-file are mutually exclusive"))
+	}
+	if cfg.failUnderPatch > 0 && cfg.diffBase == "" && cfg.diffFile == "" {
+		return nil, usageError(fs, errors.New("--fail-under-patch requires --diff-base or --diff-file"))
+	}
+	if cfg.uncoveredOut != "" && cfg.diffBase == "" && cfg.diffFile == "" {
+		return nil, usageError(fs, errors.New("--uncovered-out requires --diff-base or --diff-file"))
+	}
+	if cfg.attestation != "" && cfg.attestationKey == "" {
+		return nil, usageError(fs, errors.New("--attestation requires --attestation-key"))
+	}
+	if cfg.attestation == "" && (cfg.attestationKey != "" || len(cfg.attestationSubjects) > 0) {
+		return nil, usageError(fs, errors.New("--attestation-key and --attestation-subject require --attestation"))
+	}
+	if cfg.averageOf <= 0 {
+		return nil, usageError(fs, fmt.Errorf("invalid --average-of window: %d", cfg.averageOf))
+	}
+	if cfg.sample < 0 {
+		return nil, usageError(fs, fmt.Errorf("invalid --sample interval: %d", cfg.sample))
+	}
+	if cfg.sample > 0 && cfg.watch {
+		return nil, usageError(fs, errors.New("--sample can't be combined with --watch"))
+	}
+	// An estimate isn't checked, so that gates would silently pass
+	gated := cfg.thresholds != (lcov.Thresholds{}) || cfg.failUnderFile > 0 || cfg.thresholdsFile != "" || len(cfg.functionThresholds) > 0 ||
+		cfg.diffBase != "" || cfg.diffFile != "" || cfg.baseline != "" || cfg.saveBaseline != "" || cfg.ratchet != "" || cfg.history != "" ||
+		cfg.summaryOut != "" || cfg.attestation != ""
+	if cfg.sample > 0 && gated {
+		return nil, usageError(fs, errors.New("--sample can't be combined with the coverage checks, --diff-base, --diff-file, --baseline, --save-baseline, --ratchet, --history, --summary-out or --attestation"))
+	}
+	if len(cfg.inputs) == 0 && cfg.glob == "" {
+		return nil, usageError(fs, errors.New("no input given"))
+	}
+	return cfg, nil
+}
+
+// usageError reports an invalid command line like the flag package does for invalid flags
+func usageError(fs *flag.FlagSet, err error) error {
+	fmt.Fprintln(fs.Output(), err)
+	fs.Usage()
+	return err
+}
+
+// inspectFlags, when set, is given the flag set of a command instead of parsing
+// it, which lets the completion subcommand list the flags of every command
+var inspectFlags func(fs *flag.FlagSet)
+
+// errInspected stops a command once its flag set was given to inspectFlags
+var errInspected = errors.New("flags inspected")
+
+// parseInterleaved parses flags that may be interleaved with positional arguments,
+// and returns the latter. '--' ends flag parsing.
+func parseInterleaved(fs *flag.FlagSet, args []string) ([]string, error) {
+	if inspectFlags != nil {
+		inspectFlags(fs)
+		return nil, errInspected
+	}
+
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		rest := fs.Args()
+		if len(rest) == 0 {
+			return positional, nil
+		}
+		// Parse stops at the first positional argument: collect it and resume
+		// after it, unless flag parsing was explicitly ended with '--'
+		if i := len(args) - len(rest); i > 0 && args[i-1] == "--" {
+			return append(positional, rest...), nil
+		}
+		positional = append(positional, rest[0])
+		args = rest[1:]
+	}
+}
+
+// printUsage writes the help text of the summary command
+func printUsage(fs *flag.FlagSet) {
+	w := fs.Output()
+	fmt.Fprintf(w, "Usage: go-lcov-summary [flags] <coverage-file|directory>...\n")
+	fmt.Fprintf(w, "       go-lcov-summary [flags] - (read coverage data from stdin)\n")
+	fmt.Fprintf(w, "       go-lcov-summary upload codecov|coveralls [flags] <lcov-file>...\n")
+	fmt.Fprintf(w, "       go-lcov-summary badge [flags] <coverage-file>...\n")
+	fmt.Fprintf(w, "       go-lcov-summary merge [flags] <coverage-file>...\n")
+	fmt.Fprintf(w, "       go-lcov-summary filter [flags] <coverage-file>...\n")
+	fmt.Fprintf(w, "       go-lcov-summary convert [flags] <coverage-file>\n")
+	fmt.Fprintf(w, "       go-lcov-summary serve [flags] <coverage-file>...\n")
+	fmt.Fprintf(w, "       go-lcov-summary publish --s3|--gcs <bucket/prefix> [flags] <coverage-file>...\n")
+	fmt.Fprintf(w, "       go-lcov-summary comment --github|--gitlab [flags] <coverage-file>...\n")
+	fmt.Fprintf(w, "       go-lcov-summary report bitbucket [flags] <coverage-file>...\n")
+	fmt.Fprintf(w, "       go-lcov-summary annotate [flags] <coverage-file>...\n")
+	fmt.Fprintf(w, "       go-lcov-summary owners [flags] <coverage-file>...\n")
+	fmt.Fprintf(w, "       go-lcov-summary packages [flags] <coverage-file>...\n")
+	fmt.Fprintf(w, "       go-lcov-summary hotspots [flags] <coverage-file>...\n")
+	fmt.Fprintf(w, "       go-lcov-summary functions [flags] <coverage-file>...\n")
+	fmt.Fprintf(w, "       go-lcov-summary tests [flags] <coverage-file>...\n")
+	fmt.Fprintf(w, "       go-lcov-summary branches [flags] <coverage-file>...\n")
+	fmt.Fprintf(w, "       go-lcov-summary record [flags] <coverage-file>...\n")
+	fmt.Fprintf(w, "       go-lcov-summary trend [flags] [<coverage-file>...]\n")
+	fmt.Fprintf(w, "       go-lcov-summary lint [flags] <lcov-file>...\n")
+	fmt.Fprintf(w, "       go-lcov-summary size [flags] <lcov-file>...\n")
+	fmt.Fprintf(w, "       go-lcov-summary verify --key <key-file> [flags] <attestation-file>\n")
+	fmt.Fprintf(w, "       go-lcov-summary completion bash|zsh|fish\n")
+	printFlags(fs)
+}
+
+// printFlags writes the flags of a flag set, along with their short alias
+func printFlags(fs *flag.FlagSet) {
+	w := fs.Output()
+	fmt.Fprintf(w, "\nFlags:\n")
+	fs.VisitAll(func(f *flag.Flag) {
+		if strings.HasPrefix(f.Usage, "shorthand for --") {
+			return
+		}
+		name, usage := flag.UnquoteUsage(f)
+		names := "--" + f.Name
+		if short := shorthand(fs, f.Name); short != "" {
+			names = "-" + short + ", " + names
+		}
+		if name != "" {
+			names += " " + name
+		}
+		switch f.DefValue {
+		case "", "0", "false":
+		default:
+			usage += fmt.Sprintf(" (default %s)", f.DefValue)
+		}
+		fmt.Fprintf(w, "  %s\n    \t%s\n", names, usage)
+	})
+}
+
+// shorthand returns the short alias registered for a long flag name, if any
+func shorthand(fs *flag.FlagSet, long string) string {
+	var short string
+	fs.VisitAll(func(f *flag.Flag) {
+		if f.Usage == "shorthand for --"+long {
+			short = f.Name
+		}
+	})
+	return short
+}
@@ -0,0 +1,158 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"testing"
+
+	"github.com/shastick/go-lcov-summary"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseFlags(t *testing.T) {
+	tests := []struct {
+		name   string
+		args   []string
+		tee    string
+		inputs []string
+	}{
+		{name: "positional only", args: []string{"coverage.info"}, inputs: []string{"coverage.info"}},
+		{name: "stdin", args: []string{"-"}, inputs: []string{"-"}},
+		{name: "long flag", args: []string{"--tee-lcov", "out.info", "coverage.info"}, tee: "out.info", inputs: []string{"coverage.info"}},
+		{name: "short flag", args: []string{"-t=-", "coverage.info"}, tee: "-", inputs: []string{"coverage.info"}},
+		{name: "flag after input", args: []string{"coverage.info", "--tee-lcov", "out.info"}, tee: "out.info", inputs: []string{"coverage.info"}},
+		{name: "several inputs", args: []string{"a.info", "-t", "-", "b.info"}, tee: "-", inputs: []string{"a.info", "b.info"}},
+		{name: "terminator", args: []string{"--", "--tee-lcov"}, inputs: []string{"--tee-lcov"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var output bytes.Buffer
+			cfg, err := parseFlags(tt.args, &output)
+			require.NoError(t, err)
+			assert.Equal(t, tt.tee, cfg.teeLCOV)
+			assert.Equal(t, tt.inputs, cfg.inputs)
+			assert.Empty(t, output.String())
+		})
+	}
+}
+
+func TestParseFlagsFormat(t *testing.T) {
+	var output bytes.Buffer
+	cfg, err := parseFlags([]string{"coverage.info"}, &output)
+	require.NoError(t, err)
+	assert.Equal(t, "text", cfg.format)
+
+	cfg, err = parseFlags([]string{"-f", "json", "coverage.info"}, &output)
+	require.NoError(t, err)
+	assert.Equal(t, "json", cfg.format)
+
+	cfg, err = parseFlags([]string{"coverage.info", "--list"}, &output)
+	require.NoError(t, err)
+	assert.Equal(t, "list", cfg.format)
+
+	cfg, err = parseFlags([]string{"-f", "openmetrics", "-o", "coverage.prom", "coverage.info"}, &output)
+	require.NoError(t, err)
+	assert.Equal(t, "openmetrics", cfg.format)
+	assert.Equal(t, "coverage.prom", cfg.output)
+}
+
+func TestParseFlagsVersion(t *testing.T) {
+	var output bytes.Buffer
+	cfg, err := parseFlags([]string{"--version"}, &output)
+	require.NoError(t, err)
+	assert.True(t, cfg.version)
+
+	cfg, err = parseFlags([]string{"--schema"}, &output)
+	require.NoError(t, err)
+	assert.True(t, cfg.schema)
+}
+
+func TestParseFlagsThresholds(t *testing.T) {
+	var output bytes.Buffer
+	cfg, err := parseFlags([]string{"--fail-under", "80", "--fail-under-branches=50", "coverage.info"}, &output)
+	require.NoError(t, err)
+	assert.Equal(t, lcov.Thresholds{Lines: 80, Functions: 80, Branches: 50}, cfg.thresholds)
+
+	cfg, err = parseFlags([]string{"--fail-under-lines", "90.5", "coverage.info"}, &output)
+	require.NoError(t, err)
+	assert.Equal(t, lcov.Thresholds{Lines: 90.5}, cfg.thresholds)
+	assert.False(t, cfg.warnOnly)
+
+	cfg, err = parseFlags([]string{"--fail-under", "80", "--warn-only", "coverage.info"}, &output)
+	require.NoError(t, err)
+	assert.True(t, cfg.warnOnly)
+
+	cfg, err = parseFlags([]string{"--fail-under-file", "60", "coverage.info"}, &output)
+	require.NoError(t, err)
+	assert.Equal(t, lcov.Thresholds{}, cfg.thresholds)
+	assert.Equal(t, 60.0, cfg.failUnderFile)
+}
+
+func TestParseFlagsErrors(t *testing.T) {
+	var output bytes.Buffer
+	_, err := parseFlags([]string{"--help"}, &output)
+	assert.ErrorIs(t, err, flag.ErrHelp)
+	assert.Contains(t, output.String(), "-t, --tee-lcov file")
+	assert.NotContains(t, output.String(), "shorthand")
+
+	output.Reset()
+	_, err = parseFlags([]string{"--tee-lcov", "out.info"}, &output)
+	assert.EqualError(t, err, "no input given")
+	assert.Contains(t, output.String(), "Usage:")
+
+	output.Reset()
+	_, err = parseFlags([]string{"--format", "docx", "a.info"}, &output)
+	assert.EqualError(t, err, "unknown output format: docx")
+
+	output.Reset()
+	_, err = parseFlags([]string{"--color=sometimes", "a.info"}, &output)
+	assert.EqualError(t, err, "invalid color mode: sometimes")
+
+	output.Reset()
+	_, err = parseFlags([]string{"--unknown-records=fail", "a.info"}, &output)
+	assert.EqualError(t, err, "unknown record policy: fail")
+
+	output.Reset()
+	_, err = parseFlags([]string{"--duplicates=sum", "a.info"}, &output)
+	assert.EqualError(t, err, "unknown duplicate strategy: sum")
+
+	output.Reset()
+	_, err = parseFlags([]string{"--otlp-attributes=team", "a.info"}, &output)
+	assert.EqualError(t, err, "invalid --otlp-attributes: expected key=value: team")
+
+	output.Reset()
+	_, err = parseFlags([]string{"--log-format=xml", "a.info"}, &output)
+	assert.EqualError(t, err, "invalid log format: xml")
+
+	output.Reset()
+	_, err = parseFlags([]string{"-q", "-v", "a.info"}, &output)
+	assert.EqualError(t, err, "--quiet and --verbose are mutually exclusive")
+
+	output.Reset()
+	_, err = parseFlags([]string{"--fail-under-patch", "80", "a.info"}, &output)
+	assert.EqualError(t, err, "--fail-under-patch requires --diff-base or --diff-file")
+	_, err = parseFlags([]string{"--uncovered-out", "uncovered.json", "a.info"}, &output)
+	assert.EqualError(t, err, "--uncovered-out requires --diff-base or --diff-file")
+
+	_, err = parseFlags([]string{"--diff-base", "main", "--diff-file", "changes.diff", "a.info"}, &output)
+	assert.EqualError(t, err, "--diff-base and --diff-file are mutually exclusive")
+	_, err = parseFlags([]string{"--ratchet", "ratchet.json", "--save-baseline", "baseline.json", "a.info"}, &output)
+	assert.EqualError(t, err, "--ratchet can't be combined with --baseline or --save-baseline")
+	for _, gate := range [][]string{{"--fail-under", "80"}, {"--fail-under-branches", "50"}, {"--ratchet", "ratchet.json"}, {"--summary-out", "result.json"}, {"--history", "history.jsonl"}} {
+		_, err = parseFlags(append([]string{"--sample", "10", "a.info"}, gate...), &output)
+		assert.ErrorContains(t, err, "--sample can't be combined with the coverage checks", gate[0])
+	}
+	_, err = parseFlags([]string{"--history", "history.jsonl", "--average-of", "0", "a.info"}, &output)
+	assert.EqualError(t, err, "invalid --average-of window: 0")
+	_, err = parseFlags([]string{"--attestation", "coverage.intoto.json", "a.info"}, &output)
+	assert.EqualError(t, err, "--attestation requires --attestation-key")
+	_, err = parseFlags([]string{"--attestation-key", "key.pem", "a.info"}, &output)
+	assert.EqualError(t, err, "--attestation-key and --attestation-subject require --attestation")
+
+	output.Reset()
+	_, err = parseFlags([]string{"--nope", "a.info"}, &output)
+	assert.Error(t, err)
+	assert.Contains(t, output.String(), "flag provided but not defined: -nope")
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/shastick/go-lcov-summary"
+)
+
+// runFunctions implements the 'functions [flags] <coverage-file>...' subcommand
+func runFunctions(args []string) error {
+	fs := flag.NewFlagSet("functions", flag.ContinueOnError)
+	var file string
+	var uncovered bool
+	fs.StringVar(&file, "file", "", "only list the functions of this source `file`")
+	fs.BoolVar(&uncovered, "uncovered", false, "only list the functions that were never executed")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: go-lcov-summary functions [flags] <coverage-file>...\n")
+		printFlags(fs)
+	}
+
+	inputs, err := parseInterleaved(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(inputs) == 0 {
+		return errors.New("no input given")
+	}
+	if inputs, err = expandInputs(inputs, ""); err != nil {
+		return err
+	}
+	summary, err := summarizeInputs(inputs, []lcov.Option{lcov.WithDetails()}, io.Discard, nil)
+	if err != nil {
+		return err
+	}
+	files := lcov.MergeFiles(summary.Files)
+
+	if file != "" {
+		if files = selectFile(files, file); len(files) == 0 {
+			return fmt.Errorf("no coverage data for %s", file)
+		}
+	}
+	return printFunctions(os.Stdout, files, uncovered)
+}
+
+// functionThresholdsFlag collects the 'pattern=lines[,branches]' function thresholds of a repeatable flag
+type functionThresholdsFlag []lcov.FunctionThreshold
+
+func (f *functionThresholdsFlag) String() string {
+	var values []string
+	for _, t := range *f {
+		values = append(values, fmt.Sprintf("%s=%g,%g", t.Pattern, t.Lines, t.Branches))
+	}
+	return strings.Join(values, " ")
+}
+
+func (f *functionThresholdsFlag) Set(value string) error {
+	threshold, err := lcov.ParseFunctionThreshold(value)
+	if err != nil {
+		return err
+	}
+	*f = append(*f, threshold)
+	return nil
+}
+
+// printFunctions writes a table of the functions of the files with their
+// execution counts, flagging those never executed
+func printFunctions(w io.Writer, files []lcov.FileRecord, uncoveredOnly bool) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "File\tLine\tCount\tFunction\n")
+	for _, f := range files {
+		for _, fn := range f.Functions {
+			if uncoveredOnly && fn.Count > 0 {
+				continue
+			}
+			// FNDA records without an FN record don't tell the line
+			line := "-"
+			if fn.Line > 0 {
+				line = strconv.Itoa(fn.Line)
+			}
+			fmt.Fprintf(tw, "%s\t%s\t%d\t%s", f.Path, line, fn.Count, fn.Name)
+			if fn.Count == 0 {
+				fmt.Fprintf(tw, "\tnever executed")
+			}
+			fmt.Fprintln(tw)
+		}
+	}
+	return tw.Flush()
+}
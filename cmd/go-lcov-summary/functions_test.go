@@ -0,0 +1,38 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/shastick/go-lcov-summary"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunFunctions(t *testing.T) {
+	require.NoError(t, runFunctions([]string{"../../testdata/functions.lcov", "--file", "pkg/crypto/aes.go"}))
+	assert.EqualError(t, runFunctions([]string{"../../testdata/functions.lcov", "--file", "missing.go"}), "no coverage data for missing.go")
+	assert.EqualError(t, runFunctions(nil), "no input given")
+}
+
+func TestPrintFunctions(t *testing.T) {
+	files := []lcov.FileRecord{{Path: "a.go", Functions: []lcov.FunctionData{
+		{Name: "Encrypt", Line: 1, Count: 4},
+		{Name: "Decrypt", Line: 10, Count: 0},
+		{Name: "init", Count: 1},
+	}}}
+
+	var buf bytes.Buffer
+	require.NoError(t, printFunctions(&buf, files, false))
+	assert.Equal(t, ""+
+		"File  Line  Count  Function\n"+
+		"a.go  1     4      Encrypt\n"+
+		"a.go  10    0      Decrypt  never executed\n"+
+		"a.go  -     1      init\n", buf.String())
+
+	buf.Reset()
+	require.NoError(t, printFunctions(&buf, files, true))
+	assert.Equal(t, ""+
+		"File  Line  Count  Function\n"+
+		"a.go  10    0      Decrypt  never executed\n", buf.String())
+}
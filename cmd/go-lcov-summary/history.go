@@ -0,0 +1,140 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"time"
+
+	"github.com/shastick/go-lcov-summary"
+	"github.com/shastick/go-lcov-summary/history"
+)
+
+// defaultHistoryFile is the history file used when --history isn't given
+const defaultHistoryFile = "coverage-history.jsonl"
+
+// runRecord implements the 'record [flags] <coverage-file>...' subcommand
+func runRecord(args []string) error {
+	fs := flag.NewFlagSet("record", flag.ContinueOnError)
+	var path, commit string
+	fs.StringVar(&path, "history", defaultHistoryFile, "JSON-lines history `file` to add the coverage to")
+	fs.StringVar(&commit, "commit", firstEnv("GITHUB_SHA", "CI_COMMIT_SHA"), "`commit` the coverage was measured on (default $GITHUB_SHA or $CI_COMMIT_SHA)")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: go-lcov-summary record [flags] <coverage-file>...\n")
+		printFlags(fs)
+	}
+
+	inputs, err := parseInterleaved(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(inputs) == 0 {
+		return errors.New("no input given")
+	}
+	if commit == "" {
+		return errors.New("no commit given")
+	}
+	if inputs, err = expandInputs(inputs, ""); err != nil {
+		return err
+	}
+	summary, err := summarizeInputs(inputs, nil, io.Discard, nil)
+	if err != nil {
+		return err
+	}
+	return history.Record(path, history.NewEntry(commit, time.Now().UTC(), summary))
+}
+
+// runTrend implements the 'trend [flags] [<coverage-file>...]' subcommand
+func runTrend(args []string) error {
+	fs := flag.NewFlagSet("trend", flag.ContinueOnError)
+	var path, svg string
+	var last int
+	thresholds := lcov.DefaultColorThresholds
+	fs.StringVar(&path, "history", defaultHistoryFile, "JSON-lines history `file` written by the record subcommand, unless tracefiles are given")
+	fs.IntVar(&last, "last", 20, "show the last `n` commits only, 0 for all of them")
+	fs.StringVar(&svg, "svg", "", "write an SVG chart of the coverage over time to this `file` instead of printing the table")
+	fs.Float64Var(&thresholds.Medium, "color-medium", thresholds.Medium, "coverage `percentage` from which the chart band is yellow instead of red")
+	fs.Float64Var(&thresholds.High, "color-high", thresholds.High, "coverage `percentage` from which the chart band is green instead of yellow")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: go-lcov-summary trend [flags] [<coverage-file>...]\n")
+		printFlags(fs)
+	}
+
+	inputs, err := parseInterleaved(fs, args)
+	if err != nil {
+		return err
+	}
+	var entries []history.Entry
+	if len(inputs) > 0 {
+		if entries, err = datedEntries(inputs); err != nil {
+			return err
+		}
+	} else {
+		if entries, err = history.Load(path); err != nil {
+			return err
+		}
+		if len(entries) == 0 {
+			return fmt.Errorf("no coverage recorded in %s", path)
+		}
+	}
+
+	if svg == "" {
+		return history.WriteTrend(os.Stdout, entries, last)
+	}
+	if last > 0 && len(entries) > last {
+		entries = entries[len(entries)-last:]
+	}
+	out, err := os.Create(svg)
+	if err != nil {
+		return err
+	}
+	if err := history.WriteTrendSVG(out, entries, thresholds); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+// tracefileDate matches the date in the name of a tracefile, e.g. coverage-2024-05-01.info
+var tracefileDate = regexp.MustCompile(`\d{4}-\d{2}-\d{2}`)
+
+// datedEntries returns the history entries of tracefiles, in chronological
+// order, dated by the date in their name or else by their modification time,
+// and named after them
+func datedEntries(inputs []string) ([]history.Entry, error) {
+	var entries []history.Entry
+	for _, input := range inputs {
+		info, err := os.Stat(input)
+		if err != nil {
+			return nil, err
+		}
+		date := info.ModTime().UTC()
+		if match := tracefileDate.FindString(filepath.Base(input)); match != "" {
+			if parsed, err := time.Parse(time.DateOnly, match); err == nil {
+				date = parsed
+			}
+		}
+		summary, err := summarizeInputs([]string{input}, nil, io.Discard, nil)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, history.NewEntry(filepath.Base(input), date, summary))
+	}
+	sort.SliceStable(entries, func(i, j int) bool { return entries[i].Time.Before(entries[j].Time) })
+	return entries, nil
+}
+
+// firstEnv returns the value of the first environment variable set among names
+func firstEnv(names ...string) string {
+	for _, name := range names {
+		if value := os.Getenv(name); value != "" {
+			return value
+		}
+	}
+	return ""
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/shastick/go-lcov-summary/history"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunRecordAndTrend(t *testing.T) {
+	t.Setenv("GITHUB_SHA", "")
+	t.Setenv("CI_COMMIT_SHA", "")
+	path := filepath.Join(t.TempDir(), "history.jsonl")
+
+	assert.EqualError(t, runTrend([]string{"--history", path}), "no coverage recorded in "+path)
+	assert.EqualError(t, runRecord([]string{"--history", path, "../../testdata/sample.lcov"}), "no commit given")
+
+	require.NoError(t, runRecord([]string{"--history", path, "--commit", "abc123", "../../testdata/sample.lcov"}))
+	require.NoError(t, runRecord([]string{"../../testdata/functions.lcov", "--history", path, "--commit", "def456"}))
+
+	entries, err := history.Load(path)
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	assert.Equal(t, "abc123", entries[0].Commit)
+	assert.Equal(t, int64(6), entries[0].CoveredLines)
+	assert.Equal(t, "def456", entries[1].Commit)
+
+	require.NoError(t, runTrend([]string{"--history", path, "--last", "1"}))
+}
+
+func TestRunTrendSVG(t *testing.T) {
+	t.Setenv("GITHUB_SHA", "")
+	t.Setenv("CI_COMMIT_SHA", "")
+	dir := t.TempDir()
+	path, svg := filepath.Join(dir, "history.jsonl"), filepath.Join(dir, "trend.svg")
+	require.NoError(t, runRecord([]string{"--history", path, "--commit", "abc123", "../../testdata/sample.lcov"}))
+	require.NoError(t, runTrend([]string{"--history", path, "--svg", svg}))
+	data, err := os.ReadFile(svg)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "<title>abc123 ")
+
+	// Dated tracefiles are charted in chronological order
+	data, err = os.ReadFile("../../testdata/sample.lcov")
+	require.NoError(t, err)
+	older, newer := filepath.Join(dir, "coverage-2024-05-01.info"), filepath.Join(dir, "latest.info")
+	require.NoError(t, os.WriteFile(older, data, 0o644))
+	require.NoError(t, os.WriteFile(newer, data, 0o644))
+	require.NoError(t, os.Chtimes(newer, time.Now(), time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)))
+
+	entries, err := datedEntries([]string{newer, older})
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	assert.Equal(t, "coverage-2024-05-01.info", entries[0].Commit)
+	assert.Equal(t, time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC), entries[0].Time)
+	assert.Equal(t, "latest.info", entries[1].Commit)
+	assert.Equal(t, int64(6), entries[1].CoveredLines)
+
+	require.NoError(t, runTrend([]string{"--svg", svg, newer, older}))
+	data, err = os.ReadFile(svg)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), ">2024-05-01</text>")
+	assert.Contains(t, string(data), ">2024-06-01</text>")
+}
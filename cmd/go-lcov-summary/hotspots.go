@@ -0,0 +1,56 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+
+	"github.com/shastick/go-lcov-summary"
+)
+
+// runHotspots implements the 'hotspots [flags] <coverage-file>...' subcommand
+func runHotspots(args []string) error {
+	fs := flag.NewFlagSet("hotspots", flag.ContinueOnError)
+	var top int
+	var minHeat int64
+	fs.IntVar(&top, "top", 20, "print at most this `number` of ranges, 0 for all")
+	fs.Int64Var(&minHeat, "min-heat", 1, "only print the uncovered ranges next to a line executed at least this `count` of times")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: go-lcov-summary hotspots [flags] <coverage-file>...\n")
+		printFlags(fs)
+	}
+
+	inputs, err := parseInterleaved(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(inputs) == 0 {
+		return errors.New("no input given")
+	}
+	if inputs, err = expandInputs(inputs, ""); err != nil {
+		return err
+	}
+	summary, err := summarizeInputs(inputs, []lcov.Option{lcov.WithDetails()}, io.Discard, nil)
+	if err != nil {
+		return err
+	}
+
+	hotspots := lcov.Hotspots(summary.Files, minHeat)
+	if top > 0 && len(hotspots) > top {
+		hotspots = hotspots[:top]
+	}
+	return printHotspots(os.Stdout, hotspots)
+}
+
+// printHotspots writes a table of the uncovered ranges with their heat
+func printHotspots(w io.Writer, hotspots []lcov.Hotspot) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 0, ' ', tabwriter.AlignRight)
+	fmt.Fprintf(tw, "Heat\t  Uncovered lines\n")
+	for _, h := range hotspots {
+		fmt.Fprintf(tw, "%d\t  %s:%s\n", h.Heat, h.File, h.Lines)
+	}
+	return tw.Flush()
+}
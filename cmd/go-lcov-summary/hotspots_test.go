@@ -0,0 +1,27 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/shastick/go-lcov-summary"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunHotspots(t *testing.T) {
+	require.NoError(t, runHotspots([]string{"--top", "1", "../../testdata/sample.lcov"}))
+	assert.EqualError(t, runHotspots(nil), "no input given")
+}
+
+func TestPrintHotspots(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, printHotspots(&buf, []lcov.Hotspot{
+		{File: "a.go", Lines: lcov.LineRange{Start: 2, End: 3}, Heat: 1000},
+		{File: "a.go", Lines: lcov.LineRange{Start: 6, End: 6}, Heat: 5},
+	}))
+	assert.Equal(t, ""+
+		"Heat  Uncovered lines\n"+
+		"1000  a.go:2-3\n"+
+		"   5  a.go:6\n", buf.String())
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"io"
+	"os"
+	"sync"
+
+	"github.com/shastick/go-lcov-summary"
+)
+
+// incrementalInputs keeps the summaries of the LCOV inputs of a long-running
+// command up to date between its runs, parsing only the blocks appended to them
+// since the previous run, as tracefiles being written by a test suite are
+type incrementalInputs struct {
+	mu          sync.Mutex
+	summarizers map[string]*lcov.Incremental
+}
+
+func newIncrementalInputs() *incrementalInputs {
+	return &incrementalInputs{summarizers: make(map[string]*lcov.Incremental)}
+}
+
+// summarize returns the up to date summary of an input, with its file records,
+// and false when it isn't an LCOV tracefile, which is to be parsed from scratch
+func (c *incrementalInputs) summarize(input string, opts []lcov.Option) (*lcov.Summary, bool, error) {
+	if input == "-" || !isLCOVFile(input) {
+		return nil, false, nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	summarizer, ok := c.summarizers[input]
+	if !ok {
+		// The records are kept whatever the number of inputs, to be merged with the others
+		opts = append(opts[:len(opts):len(opts)], lcov.WithDetails())
+		summarizer = lcov.NewIncremental(input, opts...)
+		c.summarizers[input] = summarizer
+	}
+	summary, err := summarizer.Update()
+	return summary, true, err
+}
+
+// isLCOVFile reports whether the content of the file at path is LCOV data
+func isLCOVFile(path string) bool {
+	file, err := os.Open(path)
+	if err != nil {
+		// Reported by the parser
+		return false
+	}
+	defer file.Close()
+	head := make([]byte, 8192)
+	n, err := io.ReadFull(file, head)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return false
+	}
+	return lcov.DetectFormat(head[:n]) == lcov.FormatLCOV
+}
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIncrementalInputs(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "coverage.info")
+	require.NoError(t, os.WriteFile(path, []byte("SF:/src/a.go\nDA:1,1\nDA:2,0\nLF:2\nLH:1\nend_of_record\n"), 0o644))
+	incremental := newIncrementalInputs()
+
+	summary, ok, err := incremental.summarize(path, nil)
+	require.NoError(t, err)
+	require.True(t, ok)
+	assert.Equal(t, int64(2), summary.TotalLines)
+
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0o644)
+	require.NoError(t, err)
+	_, err = file.WriteString("SF:/src/b.go\nDA:1,1\nLF:1\nLH:1\nend_of_record\n")
+	require.NoError(t, err)
+	require.NoError(t, file.Close())
+
+	summary, err = summarizeInputsWith(incremental, []string{path}, nil, io.Discard, nil)
+	require.NoError(t, err)
+	assert.Equal(t, 2, summary.TotalFiles)
+	assert.Equal(t, int64(3), summary.TotalLines)
+	assert.Len(t, summary.Files, 2)
+
+	// Other formats are parsed from scratch
+	_, ok, err = incremental.summarize("../../testdata/sample.gcov", nil)
+	require.NoError(t, err)
+	assert.False(t, ok)
+}
@@ -0,0 +1,169 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/shastick/go-lcov-summary"
+)
+
+// summarizeInputs parses every input, '-' meaning stdin, and returns
+// their combined summary. Several inputs are merged by source file, so the
+// tracefiles of sharded test jobs are summarized as a single run would be.
+// Parse statistics are written to verbose, and the parsing progress to progress if not nil.
+func summarizeInputs(inputs []string, opts []lcov.Option, verbose, progress io.Writer) (*lcov.Summary, error) {
+	return summarizeInputsWith(nil, inputs, opts, verbose, progress)
+}
+
+// summarizeInputsWith is summarizeInputs, the summaries of the LCOV inputs being
+// kept up to date by incremental if not nil
+func summarizeInputsWith(incremental *incrementalInputs, inputs []string, opts []lcov.Option, verbose, progress io.Writer) (*lcov.Summary, error) {
+	if len(inputs) == 1 {
+		return summarizeInput(incremental, inputs[0], opts, verbose, progress)
+	}
+
+	// Merging needs the per-file records of every input
+	opts = append(opts, lcov.WithDetails())
+
+	var files []lcov.FileRecord
+	var warnings []lcov.Warning
+	unknown := make(map[string]bool)
+	for _, input := range inputs {
+		summary, err := summarizeInput(incremental, input, opts, verbose, progress)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, summary.Files...)
+		warnings = append(warnings, summary.Warnings...)
+		for _, recordType := range summary.UnknownRecords {
+			unknown[recordType] = true
+		}
+	}
+
+	summary := lcov.SummarizeFiles(lcov.MergeFiles(files))
+	summary.Warnings = warnings
+	for recordType := range unknown {
+		summary.UnknownRecords = append(summary.UnknownRecords, recordType)
+	}
+	sort.Strings(summary.UnknownRecords)
+	fmt.Fprintf(verbose, "Merged %d inputs: %d source files\n", len(inputs), summary.TotalFiles)
+	return summary, nil
+}
+
+// summarizeInput parses a single input, '-' meaning stdin, whatever its coverage format
+func summarizeInput(incremental *incrementalInputs, input string, opts []lcov.Option, verbose, progress io.Writer) (*lcov.Summary, error) {
+	start := time.Now()
+	if incremental != nil {
+		summary, ok, err := incremental.summarize(input, opts)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing coverage file %s: %w", input, err)
+		}
+		if ok {
+			var size int64
+			if info, err := os.Stat(input); err == nil {
+				size = info.Size()
+			}
+			logParsed(verbose, input, lcov.FormatLCOV, size, summary, start)
+			return summary, nil
+		}
+	}
+
+	name, source, size := "stdin", io.Reader(os.Stdin), int64(0)
+	if input != "-" {
+		file, err := os.Open(input)
+		if err != nil {
+			return nil, fmt.Errorf("error opening file: %w", err)
+		}
+		defer file.Close()
+		name, source = input, file
+		if info, err := file.Stat(); err == nil {
+			size = info.Size()
+		}
+	}
+
+	if progress != nil {
+		printer := newProgressPrinter(progress, name, size)
+		defer printer.finish()
+		opts = append(opts[:len(opts):len(opts)], lcov.WithProgress(printer.update))
+	}
+
+	reader := &countingReader{r: source}
+	summary, format, err := lcov.SummarizeAny(reader, opts...)
+	if err != nil {
+		if input == "-" {
+			return nil, fmt.Errorf("error parsing coverage data from stdin: %w", err)
+		}
+		return nil, fmt.Errorf("error parsing coverage file %s: %w", input, err)
+	}
+	logParsed(verbose, name, format, reader.n, summary, start)
+	return summary, nil
+}
+
+// logParsed writes the parse statistics of an input
+func logParsed(w io.Writer, input string, format lcov.Format, size int64, s *lcov.Summary, start time.Time) {
+	fmt.Fprintf(w, "Parsed %s as %s (%d bytes) in %s: %d source files, %d lines, %d functions, %d branches, %d warnings\n",
+		input, format, size, time.Since(start).Round(time.Microsecond), s.TotalFiles, s.TotalLines, s.TotalFunctions, s.TotalBranches, len(s.Warnings))
+}
+
+// countingReader counts the bytes read through it
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
+
+// expandInputs replaces directory inputs with the tracefiles found below them,
+// matching the glob pattern if any. Without inputs, the pattern selects files
+// below the working directory.
+func expandInputs(inputs []string, glob string) ([]string, error) {
+	var expanded []string
+	for _, input := range inputs {
+		info, err := os.Stat(input)
+		if input == "-" || err != nil || !info.IsDir() {
+			// Errors are reported when opening the input
+			expanded = append(expanded, input)
+			continue
+		}
+		found, err := findTracefiles(input, glob)
+		if err != nil {
+			return nil, err
+		}
+		if len(found) == 0 {
+			return nil, fmt.Errorf("no tracefile found in %s", input)
+		}
+		expanded = append(expanded, found...)
+	}
+
+	if len(inputs) == 0 && glob != "" {
+		found, err := findTracefiles(".", glob)
+		if err != nil {
+			return nil, err
+		}
+		if len(found) == 0 {
+			return nil, fmt.Errorf("no file matches %s", glob)
+		}
+		return found, nil
+	}
+	return expanded, nil
+}
+
+// findTracefiles returns the paths of the tracefiles found below a directory
+func findTracefiles(dir, glob string) ([]string, error) {
+	found, err := lcov.FindTracefiles(os.DirFS(dir), glob)
+	if err != nil {
+		return nil, fmt.Errorf("error searching %s: %w", dir, err)
+	}
+	for i := range found {
+		found[i] = filepath.Join(dir, filepath.FromSlash(found[i]))
+	}
+	return found, nil
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSummarizeInputs(t *testing.T) {
+	single, err := summarizeInputs([]string{"../../testdata/sample.lcov"}, nil, io.Discard, nil)
+	require.NoError(t, err)
+
+	// The same tracefile twice covers the same lines, and the same files
+	merged, err := summarizeInputs([]string{"../../testdata/sample.lcov", "../../testdata/sample.lcov"}, nil, io.Discard, nil)
+	require.NoError(t, err)
+	assert.Equal(t, single.TotalFiles, merged.TotalFiles)
+	assert.Equal(t, single.TotalLines, merged.TotalLines)
+	assert.Equal(t, single.CoveredLines, merged.CoveredLines)
+
+	// Formats can be mixed
+	mixed, err := summarizeInputs([]string{"../../testdata/sample.lcov", "../../testdata/coverage.out"}, nil, io.Discard, nil)
+	require.NoError(t, err)
+	assert.Equal(t, 4, mixed.TotalFiles)
+	assert.Equal(t, int64(19), mixed.TotalLines)
+}
+
+func TestSummarizeInputsVerbose(t *testing.T) {
+	var verbose bytes.Buffer
+	_, err := summarizeInputs([]string{"../../testdata/sample.lcov", "../../testdata/coverage.out"}, nil, &verbose, nil)
+	require.NoError(t, err)
+
+	lines := strings.Split(strings.TrimSpace(verbose.String()), "\n")
+	require.Len(t, lines, 3)
+	assert.Regexp(t, `^Parsed \.\./\.\./testdata/sample\.lcov as lcov \(176 bytes\) in .+: 2 source files, 9 lines, 0 functions, 0 branches, 0 warnings$`, lines[0])
+	assert.Contains(t, lines[1], "as coverprofile")
+	assert.Equal(t, "Merged 2 inputs: 4 source files", lines[2])
+}
+
+func TestSummarizeInputsStdin(t *testing.T) {
+	stdin, err := os.Open("../../testdata/coverage.out")
+	require.NoError(t, err)
+	defer stdin.Close()
+	defer func(original *os.File) { os.Stdin = original }(os.Stdin)
+	os.Stdin = stdin
+
+	var verbose bytes.Buffer
+	summary, err := summarizeInputs([]string{"-"}, nil, &verbose, nil)
+	require.NoError(t, err)
+	assert.Equal(t, 2, summary.TotalFiles)
+	assert.Contains(t, verbose.String(), "Parsed stdin as coverprofile")
+}
+
+func TestSummarizeInputsErrors(t *testing.T) {
+	_, err := summarizeInputs([]string{"../../testdata/sample.lcov", "missing.info"}, nil, io.Discard, nil)
+	assert.ErrorContains(t, err, "error opening file")
+}
+
+func TestExpandInputs(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"a/lcov.info", "b/c/coverage.lcov", "b/c/coverage.out"} {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(t, os.WriteFile(path, nil, 0o644))
+	}
+
+	inputs, err := expandInputs([]string{"-", dir, "missing.info"}, "")
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"-",
+		filepath.Join(dir, "a", "lcov.info"),
+		filepath.Join(dir, "b", "c", "coverage.lcov"),
+		"missing.info",
+	}, inputs)
+
+	inputs, err = expandInputs([]string{dir}, "**/*.out")
+	require.NoError(t, err)
+	assert.Equal(t, []string{filepath.Join(dir, "b", "c", "coverage.out")}, inputs)
+
+	_, err = expandInputs([]string{filepath.Join(dir, "a")}, "*.xml")
+	assert.ErrorContains(t, err, "no tracefile found")
+}
+
+func TestExpandInputsWorkingDirectory(t *testing.T) {
+	_, err := expandInputs(nil, "testdata/*.lcov")
+	assert.EqualError(t, err, "no file matches testdata/*.lcov")
+
+	inputs, err := expandInputs(nil, "*_test.go")
+	require.NoError(t, err)
+	assert.Contains(t, inputs, "inputs_test.go")
+}
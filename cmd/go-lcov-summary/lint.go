@@ -0,0 +1,95 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/shastick/go-lcov-summary"
+)
+
+// runLint implements the 'lint [flags] <lcov-file>...' subcommand
+func runLint(args []string) error {
+	fs := flag.NewFlagSet("lint", flag.ContinueOnError)
+	var format string
+	stringFlag(fs, &format, "format", "f", "text", "output `format` of the findings: text ('file:line: message [rule]') or json")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: go-lcov-summary lint [flags] <lcov-file>...|-\n")
+		fmt.Fprintf(fs.Output(), "\nChecks the structure of LCOV tracefiles, and exits with an error when any issue is found.\n")
+		printFlags(fs)
+	}
+
+	inputs, err := parseInterleaved(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(inputs) == 0 {
+		return errors.New("no input given")
+	}
+	if format != "text" && format != "json" {
+		return fmt.Errorf("unsupported format: %s", format)
+	}
+	if inputs, err = expandInputs(inputs, ""); err != nil {
+		return err
+	}
+	return lintInputs(os.Stdout, inputs, format)
+}
+
+// inputFinding is a lint finding of an input, in the JSON output
+type inputFinding struct {
+	Input string `json:"input"`
+	lcov.Finding
+}
+
+// lintInputs writes the findings of every input, '-' meaning stdin, and fails when there are any
+func lintInputs(w io.Writer, inputs []string, format string) error {
+	findings := []inputFinding{}
+	for _, input := range inputs {
+		found, err := lintInput(input)
+		if err != nil {
+			return err
+		}
+		for _, f := range found {
+			findings = append(findings, inputFinding{Input: input, Finding: f})
+		}
+	}
+
+	if format == "json" {
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(findings); err != nil {
+			return err
+		}
+	} else {
+		for _, f := range findings {
+			if _, err := fmt.Fprintf(w, "%s:%s\n", f.Input, f.Finding); err != nil {
+				return err
+			}
+		}
+	}
+
+	if len(findings) > 0 {
+		return fmt.Errorf("%d issues found", len(findings))
+	}
+	return nil
+}
+
+// lintInput returns the findings of an input, '-' meaning stdin
+func lintInput(input string) ([]lcov.Finding, error) {
+	if input == "-" {
+		return lcov.Lint(os.Stdin)
+	}
+	file, err := os.Open(input)
+	if err != nil {
+		return nil, fmt.Errorf("error opening file: %w", err)
+	}
+	defer file.Close()
+	findings, err := lcov.Lint(file)
+	if err != nil {
+		return nil, fmt.Errorf("error reading %s: %w", input, err)
+	}
+	return findings, nil
+}
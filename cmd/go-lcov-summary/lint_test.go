@@ -0,0 +1,39 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLintInputs(t *testing.T) {
+	var out bytes.Buffer
+	require.NoError(t, lintInputs(&out, []string{"../../testdata/sample.lcov"}, "text"))
+	assert.Empty(t, out.String())
+
+	input := "../../testdata/with_functions_and_branches.lcov"
+	assert.EqualError(t, lintInputs(&out, []string{input}, "text"), "2 issues found")
+	assert.Equal(t, input+":34: /path/to/source/utils.go: BRF is 2 but the block has 4 BRDA records [count-mismatch]\n"+
+		input+":34: /path/to/source/utils.go: BRH is 2 but the block has 3 BRDA records taken [count-mismatch]\n", out.String())
+
+	out.Reset()
+	assert.Error(t, lintInputs(&out, []string{input}, "json"))
+	var findings []map[string]any
+	require.NoError(t, json.Unmarshal(out.Bytes(), &findings))
+	require.Len(t, findings, 2)
+	assert.Equal(t, map[string]any{
+		"input":   input,
+		"line":    34.0,
+		"file":    "/path/to/source/utils.go",
+		"rule":    "count-mismatch",
+		"message": "BRF is 2 but the block has 4 BRDA records",
+	}, findings[0])
+}
+
+func TestRunLintErrors(t *testing.T) {
+	assert.EqualError(t, runLint(nil), "no input given")
+	assert.EqualError(t, runLint([]string{"--format", "xml", "../../testdata/sample.lcov"}), "unsupported format: xml")
+}
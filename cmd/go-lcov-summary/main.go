@@ -1,62 +1,618 @@
 package main
 
 import (
+	"bytes"
+	"context"
+	"errors"
+	"flag"
 	"fmt"
 	"github.com/shastick/go-lcov-summary"
+	"github.com/shastick/go-lcov-summary/github"
+	"github.com/shastick/go-lcov-summary/gitlab"
+	"github.com/shastick/go-lcov-summary/history"
+	"github.com/shastick/go-lcov-summary/otlp"
 	"io"
+	"log/slog"
 	"os"
+	"os/signal"
+	"path/filepath"
 )
 
+// defaultFormat is the output format used when none is requested
+const defaultFormat = "text"
+
+// summaryFormats are the output formats rendering the summary totals only.
+// The others may need the per-file details of the inputs.
+var summaryFormats = map[string]bool{"text": true, "json": true, "csv": true, "openmetrics": true}
+
+// thresholdFormats are the formats always colored according to the color
+// thresholds: markdown with emojis, xlsx with conditional formatting and the
+// treemap with fills, rather than escape codes
+var thresholdFormats = map[string]bool{"markdown": true, "xlsx": true, "treemap": true}
+
+// subcommands maps the subcommand names to their implementation, which is given
+// the arguments following the name
+var subcommands = map[string]func(args []string) error{
+	"upload":    runUpload,
+	"annotate":  runAnnotate,
+	"badge":     runBadge,
+	"merge":     runMerge,
+	"filter":    runFilter,
+	"convert":   runConvert,
+	"serve":     runServe,
+	"comment":   runComment,
+	"owners":    runOwners,
+	"packages":  runPackages,
+	"hotspots":  runHotspots,
+	"functions": runFunctions,
+	"tests":     runTests,
+	"branches":  runBranches,
+	"record":    runRecord,
+	"trend":     runTrend,
+	"lint":      runLint,
+	"size":      runSize,
+	"publish":   runPublish,
+	"report":    runReport,
+	"verify":    runVerify,
+}
+
 func main() {
-	if len(os.Args) != 2 {
-		fmt.Fprintf(os.Stderr, "Usage: %s <lcov-file>\n", os.Args[0])
-		fmt.Fprintf(os.Stderr, "       %s - (read from stdin)\n", os.Args[0])
+	if len(os.Args) > 1 {
+		if run, ok := subcommands[os.Args[1]]; ok {
+			err := run(os.Args[2:])
+			if errors.Is(err, flag.ErrHelp) {
+				return
+			}
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+			return
+		}
+	}
+
+	cfg, err := parseFlags(os.Args[1:], os.Stderr)
+	if errors.Is(err, flag.ErrHelp) {
+		return
+	}
+	if err != nil {
+		// Already reported along with the usage
 		os.Exit(1)
 	}
+	if cfg.version {
+		fmt.Printf("go-lcov-summary %s\n", lcov.Version())
+		return
+	}
+	if cfg.schema {
+		os.Stdout.Write(lcov.JSONSchema())
+		return
+	}
+
+	if cfg.watch {
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+		defer stop()
+		cfg.incremental = newIncrementalInputs()
+		expand := func() ([]string, error) { return expandInputs(cfg.inputs, cfg.glob) }
+		watchInputs(ctx, expand, watchInterval, func(inputs []string, err error) {
+			if cfg.clear {
+				fmt.Fprint(os.Stdout, clearScreen)
+			}
+			if err == nil {
+				err = report(cfg, inputs)
+			}
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			}
+		})
+		return
+	}
+
+	inputs, err := expandInputs(cfg.inputs, cfg.glob)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	if err := report(cfg, inputs); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// newLogger returns the logger of the parsing events, in the text or json
+// format of slog, including the debug events in verbose mode
+func newLogger(w io.Writer, format string, verbose bool) *slog.Logger {
+	options := &slog.HandlerOptions{Level: slog.LevelInfo}
+	if verbose {
+		options.Level = slog.LevelDebug
+	}
+	if format == "json" {
+		return slog.New(slog.NewJSONHandler(w, options))
+	}
+	return slog.New(slog.NewTextHandler(w, options))
+}
+
+// report summarizes the inputs and displays the summary as configured. It returns
+// an error when the inputs can't be summarized or the coverage is below a threshold.
+func report(cfg *config, inputs []string) error {
+	if cfg.sample > 0 {
+		return reportEstimate(os.Stdout, inputs, cfg.sample, cfg.quiet)
+	}
+	// The attestation is about the digest of the tracefile the summary is computed from
+	if cfg.attestation != "" && (len(inputs) != 1 || inputs[0] == "-") {
+		return errors.New("--attestation requires a single tracefile, not stdin")
+	}
+
+	var opts []lcov.Option
+	if cfg.teeLCOV != "" || cfg.github || cfg.gitlabCobertura != "" || cfg.jenkinsCobertura != "" || cfg.jenkinsJaCoCo != "" || cfg.htmlDir != "" || cfg.attestation != "" || cfg.diffBase != "" || cfg.diffFile != "" || cfg.fileTable || len(cfg.sourceDirs) > 0 || cfg.excludeGenerated || cfg.failUnderFile > 0 || cfg.thresholdsFile != "" || len(cfg.functionThresholds) > 0 || !summaryFormats[cfg.format] {
+		opts = append(opts, lcov.WithDetails())
+	}
+	if cfg.failOnEmpty {
+		opts = append(opts, lcov.WithFailOnEmpty())
+	}
+	if cfg.strict {
+		opts = append(opts, lcov.WithStrict())
+	}
+	if cfg.clampHits {
+		opts = append(opts, lcov.WithClampHits())
+	}
+	if cfg.deriveTotals {
+		opts = append(opts, lcov.WithDeriveTotals(true))
+	}
+	if cfg.checkCounts {
+		opts = append(opts, lcov.WithCheckCounts())
+	}
+	if cfg.unknownPolicy != lcov.UnknownRecordsIgnore {
+		opts = append(opts, lcov.WithUnknownRecords(cfg.unknownPolicy))
+	}
+	if cfg.duplicateStrategy != lcov.DuplicatesKeep {
+		opts = append(opts, lcov.WithDuplicateStrategy(cfg.duplicateStrategy))
+	}
+
+	// Structured logs carry the warnings, which are otherwise printed once parsed
+	logged := cfg.logFormat != ""
+	if logged {
+		opts = append(opts, lcov.WithLogger(newLogger(os.Stderr, cfg.logFormat, cfg.verbose)))
+	}
+	var verbose io.Writer = io.Discard
+	if cfg.verbose {
+		verbose = os.Stderr
+		// Warnings are printed as they occur, along with the parse statistics of their input
+		if !logged {
+			opts = append(opts, lcov.WithWarningHandler(func(warning lcov.Warning) {
+				fmt.Fprintf(os.Stderr, "Warning: %s\n", warning)
+			}))
+		}
+	}
+	var progress io.Writer
+	if cfg.progress {
+		progress = os.Stderr
+	}
+	summary, err := summarizeInputsWith(cfg.incremental, inputs, opts, verbose, progress)
+	if err != nil {
+		return err
+	}
+	if len(cfg.sourceDirs) > 0 {
+		if summary, err = excludeMarked(summary, cfg.sourceDirs, verbose); err != nil {
+			return err
+		}
+	}
+	if cfg.excludeGenerated {
+		summary = excludeGenerated(summary, cfg.generatedPatterns, cfg.sourceDirs, verbose)
+	}
+	var pathThresholds []lcov.PathThreshold
+	if cfg.thresholdsFile != "" {
+		if pathThresholds, err = readPathThresholds(cfg.thresholdsFile); err != nil {
+			return err
+		}
+	}
+	var baseline *lcov.JSONSummary
+	if cfg.baseline != "" {
+		if baseline, err = readBaseline(cfg.baseline); err != nil {
+			return err
+		}
+	}
+	if cfg.ratchet != "" {
+		// The first run has no achieved coverage to compare against
+		if baseline, err = readBaseline(cfg.ratchet); errors.Is(err, os.ErrNotExist) {
+			baseline, err = nil, nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+	var signer *lcov.Ed25519Signer
+	if cfg.attestationKey != "" {
+		if signer, err = readKey(cfg.attestationKey); err != nil {
+			return err
+		}
+		if signer.PrivateKey == nil {
+			return fmt.Errorf("invalid attestation key %s: not a private key", cfg.attestationKey)
+		}
+	}
+	var entries []history.Entry
+	if cfg.history != "" {
+		// A missing history has no average to compare against yet
+		if entries, err = history.Load(cfg.history); err != nil {
+			return err
+		}
+	}
 
-	var reader io.Reader
+	// In pipe mode the LCOV data goes to stdout for the next stage, and the summary to stderr
+	output := os.Stdout
+	if cfg.teeLCOV != "" {
+		if err := writeLCOV(cfg.teeLCOV, summary.Files); err != nil {
+			return fmt.Errorf("error writing LCOV data: %w", err)
+		}
+		if cfg.teeLCOV == "-" {
+			output = os.Stderr
+		}
+	}
+
+	if !cfg.quiet && !cfg.verbose && !logged {
+		for _, warning := range summary.Warnings {
+			fmt.Fprintf(os.Stderr, "Warning: %s\n", warning)
+		}
+	}
+
+	var patch *lcov.PatchSummary
+	if cfg.diffBase != "" || cfg.diffFile != "" {
+		diff, err := readDiff(cfg.diffBase, cfg.diffFile)
+		if err != nil {
+			return err
+		}
+		patch = lcov.NewPatchSummary(summary.Files, diff)
+		if cfg.uncoveredOut != "" {
+			if err := writeUncovered(cfg.uncoveredOut, patch.UncoveredArtifact(diff)); err != nil {
+				return err
+			}
+		}
+	}
+	if cfg.attestation != "" {
+		if err := writeAttestation(cfg.attestation, signer, summary, inputs[0], cfg.attestationSubjects); err != nil {
+			return err
+		}
+	}
 
-	if os.Args[1] == "-" {
-		// Read from stdin
-		reader = os.Stdin
+	// Display summary, or only the line coverage for scripts
+	var summaryOutput io.Writer = output
+	var rendered bytes.Buffer
+	if cfg.output != "" {
+		summaryOutput = &rendered
+	}
+	if cfg.quiet {
+		fmt.Fprintf(summaryOutput, "%.1f\n", summary.LineCoverageRate)
 	} else {
-		// Read from file
-		file, err := os.Open(os.Args[1])
+		var renderOpts []lcov.RenderOption
+		if (cfg.output == "" && useColor(cfg.color, output)) || thresholdFormats[cfg.format] {
+			renderOpts = append(renderOpts, lcov.WithColor(cfg.colors))
+		}
+		if baseline != nil {
+			renderOpts = append(renderOpts, lcov.WithBaseline(baseline.Summary()))
+		}
+		if cfg.fileTable {
+			renderOpts = append(renderOpts, lcov.WithFileTable())
+		}
+		if patch != nil {
+			// The text format shows it in its summary block
+			renderOpts = append(renderOpts, lcov.WithPatch(patch))
+		}
+		if err := lcov.RenderFormat(cfg.format, summaryOutput, summary, renderOpts...); err != nil {
+			return fmt.Errorf("error writing summary: %w", err)
+		}
+	}
+	if cfg.output != "" {
+		if err := writeFileAtomic(cfg.output, rendered.Bytes()); err != nil {
+			return fmt.Errorf("error writing summary: %w", err)
+		}
+	}
+
+	if patch != nil && !cfg.quiet && cfg.format != "text" {
+		writePatchCoverage(sideOutput(cfg.format, output), patch)
+	}
+
+	if cfg.github {
+		if err := reportGitHub(sideOutput(cfg.format, output), summary, cfg.thresholds.Lines); err != nil {
+			return err
+		}
+	}
+
+	if cfg.gitlab {
+		// The coverage regex is matched against the whole job log
+		fmt.Fprintln(sideOutput(cfg.format, output), gitlab.CoverageLine(summary))
+	}
+	if cfg.gitlabCobertura != "" {
+		if err := writeCobertura(cfg.gitlabCobertura, summary); err != nil {
+			return fmt.Errorf("error writing Cobertura report: %w", err)
+		}
+	}
+	if cfg.jenkinsCobertura != "" || cfg.jenkinsJaCoCo != "" {
+		if err := writeJenkinsReports(cfg, summary); err != nil {
+			return err
+		}
+	}
+	if cfg.htmlDir != "" {
+		if err := lcov.WriteHTMLReport(cfg.htmlDir, summary, sourceOpener(cfg.sourceDirs, verbose)); err != nil {
+			return fmt.Errorf("error writing HTML report: %w", err)
+		}
+	}
+	if cfg.otlp {
+		if err := exportMetrics(summary, cfg.otlpResource); err != nil {
+			return err
+		}
+	}
+
+	var violations []fmt.Stringer
+	for _, violation := range lcov.CheckThresholds(summary, cfg.thresholds) {
+		violations = append(violations, violation)
+	}
+	for _, violation := range lcov.CheckFileThresholds(summary.Files, cfg.failUnderFile) {
+		violations = append(violations, violation)
+	}
+	for _, violation := range lcov.CheckPathThresholds(summary.Files, pathThresholds) {
+		violations = append(violations, violation)
+	}
+	if len(cfg.functionThresholds) > 0 {
+		for _, violation := range lcov.CheckFunctionThresholds(lcov.MergeFiles(summary.Files), cfg.functionThresholds) {
+			violations = append(violations, violation)
+		}
+	}
+	if rate, ok := patchRate(patch); ok && cfg.failUnderPatch > 0 && rate < cfg.failUnderPatch {
+		violations = append(violations, lcov.ThresholdViolation{Metric: "patch", Rate: rate, Required: cfg.failUnderPatch})
+	}
+	if baseline != nil {
+		for _, regression := range lcov.CheckBaseline(summary, *baseline, cfg.baselineTolerance) {
+			violations = append(violations, regression)
+		}
+	}
+	if cfg.history != "" {
+		drops, err := history.GateMovingAverage(summary, entries, cfg.averageOf, cfg.averageTolerance)
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "Error opening file: %v\n", err)
-			os.Exit(1)
+			return err
+		}
+		for _, drop := range drops {
+			violations = append(violations, drop)
+		}
+	}
+	// The result is written whether the checks pass or not
+	if cfg.summaryOut != "" {
+		if err := writeResult(cfg.summaryOut, newResult(cfg, summary, patch, pathThresholds, violations)); err != nil {
+			return fmt.Errorf("error writing result: %w", err)
+		}
+	}
+	if cfg.warnOnly && len(violations) > 0 {
+		for _, violation := range violations {
+			fmt.Fprintf(os.Stderr, "Warning: %s\n", violation)
+		}
+		if cfg.github {
+			if _, ok := github.FromEnv(); ok {
+				for _, violation := range violations {
+					if err := github.WriteWarning(sideOutput(cfg.format, output), "Coverage check", violation.String()); err != nil {
+						return err
+					}
+				}
+			}
+		}
+		// The baselines are only updated by passing runs
+		return nil
+	}
+	for i, violation := range violations {
+		// The last violation is returned, the others are reported right away
+		if i == len(violations)-1 {
+			return errors.New(violation.String())
 		}
-		defer file.Close()
-		reader = file
+		fmt.Fprintf(os.Stderr, "Error: %s\n", violation)
 	}
 
-	summary, err := lcov.Summarize(reader)
+	// Only passing runs update the baseline, so that it never goes down
+	if cfg.saveBaseline != "" {
+		if err := writeBaseline(cfg.saveBaseline, summary); err != nil {
+			return fmt.Errorf("error writing baseline: %w", err)
+		}
+	}
+	if cfg.ratchet != "" {
+		var previous lcov.JSONSummary
+		if baseline != nil {
+			previous = *baseline
+		}
+		if err := writeBaseline(cfg.ratchet, lcov.RatchetBaseline(previous, summary).Summary()); err != nil {
+			return fmt.Errorf("error writing ratchet: %w", err)
+		}
+	}
+	return nil
+}
+
+// readBaseline reads the baseline file at path
+func readBaseline(path string) (*lcov.JSONSummary, error) {
+	file, err := os.Open(path)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error parsing LCOV file: %v\n", err)
-		os.Exit(1)
+		return nil, err
+	}
+	defer file.Close()
+	baseline, err := lcov.ReadBaseline(file)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
 	}
+	return &baseline, nil
+}
 
-	// Display summary
-	displaySummary(summary)
+// readPathThresholds reads the thresholds file at path
+func readPathThresholds(path string) ([]lcov.PathThreshold, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	thresholds, err := lcov.ParsePathThresholds(file)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return thresholds, nil
 }
 
-func displaySummary(summary *lcov.Summary) {
-	fmt.Println("Summary coverage rate:")
-	fmt.Printf("  source files: %d\n", summary.TotalFiles)
-	fmt.Printf("  lines.......: %.1f%% (%d of %d lines)\n",
-		summary.LineCoverageRate, summary.CoveredLines, summary.TotalLines)
+// writeBaseline writes the summary totals to the baseline file at path
+func writeBaseline(path string, summary *lcov.Summary) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := lcov.RenderJSON(file, summary); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
 
-	if summary.TotalFunctions > 0 {
-		fmt.Printf("  functions...: %.1f%% (%d of %d functions)\n",
-			summary.FunctionCoverageRate, summary.CoveredFunctions, summary.TotalFunctions)
-	} else {
-		fmt.Println("  functions...: no data found")
+// writeUncovered writes the uncovered added lines of a change as JSON
+func writeUncovered(path string, artifact *lcov.UncoveredArtifact) error {
+	var data bytes.Buffer
+	if err := artifact.WriteJSON(&data); err != nil {
+		return err
 	}
+	return writeFileAtomic(path, data.Bytes())
+}
 
-	if summary.TotalBranches > 0 {
-		fmt.Printf("  branches....: %.1f%% (%d of %d branches)\n",
-			summary.BranchCoverageRate, summary.CoveredBranches, summary.TotalBranches)
-	} else {
-		fmt.Println("  branches....: no data found")
+// writeFileAtomic writes data to a temporary file next to path and renames it to
+// path, so that readers such as the node_exporter textfile collector never see a
+// partially written file
+func writeFileAtomic(path string, data []byte) error {
+	file, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	_, err = file.Write(data)
+	if err == nil {
+		err = file.Chmod(0o644)
+	}
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(file.Name(), path)
+	}
+	if err != nil {
+		os.Remove(file.Name())
+	}
+	return err
+}
+
+// exportMetrics exports the metrics of the summary to the OTLP endpoint of the
+// environment, with additional resource attributes
+func exportMetrics(summary *lcov.Summary, attributes map[string]string) error {
+	exporter, err := otlp.FromEnv()
+	if err != nil {
+		return err
+	}
+	for key, value := range attributes {
+		exporter.Resource[key] = value
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	return exporter.Export(ctx, summary)
+}
+
+// sideOutput returns where the lines accompanying the summary are printed, such
+// as the patch coverage and the CI annotations: along with the summary for the
+// formats read by people, and to stderr to keep the output of machine-readable
+// formats intact, CI runners reading both
+func sideOutput(format string, output io.Writer) io.Writer {
+	switch format {
+	case "text", "list", "markdown":
+		return output
+	}
+	return os.Stderr
+}
+
+// reportGitHub reports the summary to GitHub Actions, and writes to w the
+// annotations of the files below the line coverage threshold if any. Outside of
+// GitHub Actions it does nothing.
+func reportGitHub(w io.Writer, summary *lcov.Summary, threshold float64) error {
+	actions, ok := github.FromEnv()
+	if !ok {
+		return nil
+	}
+	if err := actions.Report(summary); err != nil {
+		return err
+	}
+	if threshold > 0 {
+		return actions.WriteAnnotations(w, lcov.MergeFiles(summary.Files), threshold)
+	}
+	return nil
+}
+
+// writeCobertura writes a summary as a Cobertura report to the given path
+func writeCobertura(path string, summary *lcov.Summary) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := lcov.WriteCobertura(file, summary); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// writeJenkinsReports writes the reports for the Jenkins Coverage Plugin, with
+// the paths relative to the first source directory, its prefix if it has one,
+// or else to the repository root
+func writeJenkinsReports(cfg *config, summary *lcov.Summary) error {
+	var root string
+	if len(cfg.sourceDirs) > 0 {
+		root = cfg.sourceDirs[0].prefix
+		if root == "" {
+			root = cfg.sourceDirs[0].dir
+		}
+	}
+	if root == "" {
+		var err error
+		if root, err = repositoryRoot(); err != nil {
+			return err
+		}
+	}
+	if abs, err := filepath.Abs(root); err == nil {
+		root = filepath.ToSlash(abs)
+	}
+
+	reports := []struct {
+		path, name string
+		write      func(w io.Writer, s *lcov.Summary, root string) error
+	}{
+		{cfg.jenkinsCobertura, "Cobertura", lcov.WriteJenkinsCobertura},
+		{cfg.jenkinsJaCoCo, "JaCoCo", lcov.WriteJenkinsJaCoCo},
+	}
+	for _, report := range reports {
+		if report.path == "" {
+			continue
+		}
+		file, err := os.Create(report.path)
+		if err != nil {
+			return fmt.Errorf("error writing Jenkins %s report: %w", report.name, err)
+		}
+		err = report.write(file, summary, root)
+		if closeErr := file.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			return fmt.Errorf("error writing Jenkins %s report: %w", report.name, err)
+		}
+	}
+	return nil
+}
+
+// writeLCOV writes file records as LCOV to the given path, '-' meaning stdout
+func writeLCOV(path string, files []lcov.FileRecord) error {
+	if path == "-" {
+		return lcov.WriteLCOV(os.Stdout, files)
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := lcov.WriteLCOV(file, files); err != nil {
+		file.Close()
+		return err
 	}
+	return file.Close()
 }
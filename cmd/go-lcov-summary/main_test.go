@@ -0,0 +1,366 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/shastick/go-lcov-summary"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReportBaseline(t *testing.T) {
+	baseline := filepath.Join(t.TempDir(), "baseline.json")
+	inputs := []string{"../../testdata/sample.lcov"}
+
+	// Nothing to compare against yet: the baseline is saved
+	cfg := &config{format: defaultFormat, quiet: true, saveBaseline: baseline}
+	require.NoError(t, report(cfg, inputs))
+	data, err := os.ReadFile(baseline)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"covered": 6`)
+
+	cfg.baseline = baseline
+	require.NoError(t, report(cfg, inputs))
+
+	require.NoError(t, os.WriteFile(baseline, []byte(`{"lines": {"covered": 8, "total": 9}}`), 0o644))
+	assert.EqualError(t, report(cfg, inputs), "line coverage 66.7% is below the baseline 88.9%")
+	// A failing run leaves the baseline untouched
+	data, err = os.ReadFile(baseline)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"covered": 8`)
+
+	cfg.baselineTolerance = 25
+	require.NoError(t, report(cfg, inputs))
+}
+
+func TestReportRatchet(t *testing.T) {
+	ratchet := filepath.Join(t.TempDir(), "ratchet.json")
+	inputs := []string{"../../testdata/sample.lcov"}
+
+	// The first run creates the file
+	cfg := &config{format: defaultFormat, quiet: true, ratchet: ratchet}
+	require.NoError(t, report(cfg, inputs))
+	data, err := os.ReadFile(ratchet)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"covered": 6`)
+
+	require.NoError(t, os.WriteFile(ratchet, []byte(`{"lines": {"covered": 7, "total": 10}}`), 0o644))
+	assert.EqualError(t, report(cfg, inputs), "line coverage 66.7% is below the baseline 70.0%")
+
+	// Passing within the tolerance keeps the achieved coverage of the file
+	cfg.baselineTolerance = 5
+	require.NoError(t, report(cfg, inputs))
+	data, err = os.ReadFile(ratchet)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"covered": 7`)
+}
+
+func TestReportHistory(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.jsonl")
+	inputs := []string{"../../testdata/sample.lcov"}
+
+	// A missing history has no average yet
+	cfg := &config{format: defaultFormat, quiet: true, history: path, averageOf: 2}
+	require.NoError(t, report(cfg, inputs))
+
+	history := `{"commit": "a", "total_lines": 10, "covered_lines": 9}
+{"commit": "b", "total_lines": 10, "covered_lines": 7}
+{"commit": "c", "total_lines": 10, "covered_lines": 8}
+`
+	require.NoError(t, os.WriteFile(path, []byte(history), 0o644))
+	assert.EqualError(t, report(cfg, inputs), "line coverage 66.7% is below the required 75.0%")
+
+	cfg.averageTolerance = 10
+	require.NoError(t, report(cfg, inputs))
+}
+
+func TestReportDuplicates(t *testing.T) {
+	baseline := filepath.Join(t.TempDir(), "baseline.json")
+	inputs := []string{"../../testdata/concatenated.lcov"}
+
+	cfg := &config{format: defaultFormat, quiet: true, saveBaseline: baseline}
+	require.NoError(t, report(cfg, inputs))
+	data, err := os.ReadFile(baseline)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"files": 3`)
+
+	// The blocks of main.go are merged into a single file
+	cfg.duplicateStrategy = lcov.DuplicatesMerge
+	require.NoError(t, report(cfg, inputs))
+	data, err = os.ReadFile(baseline)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"files": 2`)
+	assert.Contains(t, string(data), `"total": 6`)
+}
+
+func TestReportOutput(t *testing.T) {
+	dir := t.TempDir()
+	output := filepath.Join(dir, "coverage.prom")
+	require.NoError(t, os.WriteFile(output, []byte("stale"), 0o644))
+
+	cfg := &config{format: "openmetrics", output: output}
+	require.NoError(t, report(cfg, []string{"../../testdata/sample.lcov"}))
+	data, err := os.ReadFile(output)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "coverage_lines_covered 6\n")
+	assert.True(t, strings.HasSuffix(string(data), "# EOF\n"))
+
+	// The temporary file is renamed over the output
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Len(t, entries, 1)
+}
+
+func TestReportOTLP(t *testing.T) {
+	var body string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		body = string(data)
+	}))
+	defer server.Close()
+	t.Setenv("OTEL_EXPORTER_OTLP_METRICS_ENDPOINT", server.URL)
+	t.Setenv("OTEL_RESOURCE_ATTRIBUTES", "")
+	t.Setenv("GITHUB_ACTIONS", "")
+	t.Setenv("GITLAB_CI", "")
+
+	cfg := &config{format: defaultFormat, quiet: true, otlp: true, otlpResource: map[string]string{"vcs.ref.head.name": "main"}}
+	require.NoError(t, report(cfg, []string{"../../testdata/sample.lcov"}))
+	assert.Contains(t, body, `{"key":"vcs.ref.head.name","value":{"stringValue":"main"}}`)
+	assert.Contains(t, body, `"name":"coverage.covered"`)
+}
+
+func TestReportFailOnEmpty(t *testing.T) {
+	empty := filepath.Join(t.TempDir(), "empty.info")
+	require.NoError(t, os.WriteFile(empty, nil, 0o644))
+
+	cfg := &config{format: defaultFormat, quiet: true}
+	require.NoError(t, report(cfg, []string{empty}))
+
+	cfg.failOnEmpty = true
+	assert.EqualError(t, report(cfg, []string{empty}), "error parsing coverage file "+empty+": no coverage data found")
+	require.NoError(t, report(cfg, []string{"../../testdata/sample.lcov"}))
+}
+
+func TestReportStrict(t *testing.T) {
+	inconsistent := filepath.Join(t.TempDir(), "inconsistent.info")
+	require.NoError(t, os.WriteFile(inconsistent, []byte("SF:main.go\nDA:1,1\nLF:1\nLH:2\nend_of_record\n"), 0o644))
+
+	cfg := &config{format: defaultFormat, quiet: true}
+	require.NoError(t, report(cfg, []string{inconsistent}))
+
+	cfg.strict = true
+	assert.EqualError(t, report(cfg, []string{inconsistent}),
+		"error parsing coverage file "+inconsistent+": inconsistent coverage data: main.go: 2 lines hit (LH) but only 1 lines found (LF)")
+	require.NoError(t, report(cfg, []string{"../../testdata/sample.lcov"}))
+}
+
+func TestReportFailUnderFile(t *testing.T) {
+	cfg := &config{format: defaultFormat, quiet: true, failUnderFile: 60}
+	require.NoError(t, report(cfg, []string{"../../testdata/sample.lcov"}))
+
+	// The last offending file is returned, the others are printed
+	cfg.failUnderFile = 70
+	assert.EqualError(t, report(cfg, []string{"../../testdata/sample.lcov"}),
+		"/path/to/source/file1.go: line coverage 60.0% is below the required 70.0%")
+}
+
+func TestReportGitHubAnnotations(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("GITHUB_ACTIONS", "true")
+	t.Setenv("GITHUB_STEP_SUMMARY", filepath.Join(dir, "summary.md"))
+	t.Setenv("GITHUB_OUTPUT", filepath.Join(dir, "output"))
+	t.Setenv("GITHUB_WORKSPACE", "")
+
+	var out strings.Builder
+	summary := &lcov.Summary{Files: []lcov.FileRecord{{Path: "a.go", LinesFound: 4, LinesHit: 1}}}
+	require.NoError(t, reportGitHub(&out, summary, 80))
+	assert.Equal(t, "::warning file=a.go,line=1,title=Low coverage::Line coverage 25.0%25 (1 of 4 lines) is below 80.0%25\n", out.String())
+
+	// Machine-readable formats keep stdout to themselves
+	assert.Equal(t, io.Writer(os.Stdout), sideOutput("markdown", os.Stdout))
+	assert.Equal(t, io.Writer(os.Stderr), sideOutput("json", os.Stdout))
+}
+
+func TestReportFailUnderFunction(t *testing.T) {
+	inputs := []string{"../../testdata/functions.lcov"}
+	cfg := &config{format: defaultFormat, quiet: true}
+	require.NoError(t, cfg.functionThresholds.Set("**:Dec*=100,100"))
+	require.NoError(t, report(cfg, inputs))
+
+	require.NoError(t, cfg.functionThresholds.Set("pkg/crypto/*=,100"))
+	assert.EqualError(t, report(cfg, inputs), "/src/repo/pkg/crypto/aes.go:1: function Encrypt has 50.0% branch coverage, 100.0% required")
+}
+
+func TestReportThresholdsFile(t *testing.T) {
+	thresholds := filepath.Join(t.TempDir(), "thresholds")
+	require.NoError(t, os.WriteFile(thresholds, []byte("source/file2.go: 70\nsource/*.go: 80\n"), 0o644))
+
+	cfg := &config{format: defaultFormat, quiet: true, thresholdsFile: thresholds}
+	assert.EqualError(t, report(cfg, []string{"../../testdata/sample.lcov"}),
+		"source/*.go: line coverage 66.7% is below the required 80.0%")
+
+	require.NoError(t, os.WriteFile(thresholds, []byte("source/*.go 80\n"), 0o644))
+	assert.EqualError(t, report(cfg, []string{"../../testdata/sample.lcov"}), thresholds+": line 1: expected 'pattern: percentage'")
+}
+
+func TestReportExcludeGenerated(t *testing.T) {
+	src := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(src, "enum_string.go"), []byte("// Code generated by stringer; DO NOT EDIT.\n\npackage api\n"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(src, "api.go"), []byte("package api\n"), 0o644))
+	tracefile := filepath.Join(t.TempDir(), "coverage.info")
+	require.NoError(t, os.WriteFile(tracefile, []byte(""+
+		"SF:/build/api/api.go\nDA:1,1\nLF:1\nLH:1\nend_of_record\n"+
+		"SF:/build/api/enum_string.go\nDA:1,0\nLF:1\nLH:0\nend_of_record\n"+
+		"SF:/build/api/api.pb.go\nDA:1,0\nLF:1\nLH:0\nend_of_record\n"), 0o644))
+
+	cfg := &config{format: defaultFormat, quiet: true, thresholds: lcov.Thresholds{Lines: 100}}
+	assert.EqualError(t, report(cfg, []string{tracefile}), "line coverage 33.3% is below the required 100.0%")
+
+	cfg.excludeGenerated, cfg.generatedPatterns, cfg.sourceDirs = true, lcov.DefaultGeneratedPatterns, sourceDirsFlag{{dir: src}}
+	require.NoError(t, report(cfg, []string{tracefile}))
+}
+
+func TestReportExclusionMarkers(t *testing.T) {
+	src := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(src, "main.go"), []byte("package main\n\nfunc main() {\n\tpanic(1) // LCOV_EXCL_LINE\n}\n"), 0o644))
+	tracefile := filepath.Join(t.TempDir(), "coverage.info")
+	require.NoError(t, os.WriteFile(tracefile, []byte("SF:/build/main.go\nDA:3,1\nDA:4,0\nLF:2\nLH:1\nend_of_record\n"), 0o644))
+
+	cfg := &config{format: defaultFormat, quiet: true, thresholds: lcov.Thresholds{Lines: 100}}
+	assert.EqualError(t, report(cfg, []string{tracefile}), "line coverage 50.0% is below the required 100.0%")
+
+	cfg.sourceDirs = sourceDirsFlag{{dir: src}}
+	require.NoError(t, report(cfg, []string{tracefile}))
+
+	// The tracefile directory of another machine is substituted
+	cfg.sourceDirs = sourceDirsFlag{{prefix: "/elsewhere", dir: src}}
+	assert.Error(t, report(cfg, []string{tracefile}))
+	cfg.sourceDirs = sourceDirsFlag{{prefix: "/elsewhere", dir: t.TempDir()}, {prefix: "/build", dir: src}}
+	require.NoError(t, report(cfg, []string{tracefile}))
+}
+
+func TestReportSummaryOut(t *testing.T) {
+	out := filepath.Join(t.TempDir(), "result.json")
+	cfg := &config{format: defaultFormat, quiet: true, summaryOut: out, thresholds: lcov.Thresholds{Lines: 70}}
+	assert.EqualError(t, report(cfg, []string{"../../testdata/sample.lcov"}), "line coverage 66.7% is below the required 70.0%")
+
+	// The result is written by failing runs as well
+	data, err := os.ReadFile(out)
+	require.NoError(t, err)
+	var r result
+	require.NoError(t, json.Unmarshal(data, &r))
+	assert.False(t, r.Passed)
+	assert.Equal(t, int64(6), r.Summary.Lines.Covered)
+	assert.Equal(t, int64(9), r.Summary.Lines.Total)
+	assert.Equal(t, resultThresholds{Lines: 70}, r.Thresholds)
+	assert.Equal(t, []string{"line coverage 66.7% is below the required 70.0%"}, r.Violations)
+	assert.Empty(t, r.Warnings)
+	assert.Contains(t, string(data), `"warnings": []`)
+
+	cfg.thresholds.Lines = 60
+	require.NoError(t, report(cfg, []string{"../../testdata/sample.lcov"}))
+	data, err = os.ReadFile(out)
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal(data, &r))
+	assert.True(t, r.Passed)
+	assert.Empty(t, r.Violations)
+}
+
+func TestReportJenkins(t *testing.T) {
+	dir := t.TempDir()
+	cfg := &config{format: defaultFormat, quiet: true, sourceDirs: sourceDirsFlag{{dir: "/path/to"}},
+		jenkinsCobertura: filepath.Join(dir, "cobertura.xml"), jenkinsJaCoCo: filepath.Join(dir, "jacoco.xml")}
+	require.NoError(t, report(cfg, []string{"../../testdata/sample.lcov"}))
+
+	cobertura, err := os.ReadFile(cfg.jenkinsCobertura)
+	require.NoError(t, err)
+	assert.Contains(t, string(cobertura), "<source>/path/to</source>")
+	assert.Contains(t, string(cobertura), `filename="source/file1.go"`)
+
+	jacoco, err := os.ReadFile(cfg.jenkinsJaCoCo)
+	require.NoError(t, err)
+	assert.Contains(t, string(jacoco), `<sourcefile name="file1.go">`)
+	assert.Contains(t, string(jacoco), `<counter type="LINE" missed="3" covered="6"></counter>`)
+}
+
+func TestReportHTML(t *testing.T) {
+	src := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(src, "source"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(src, "source", "file1.go"), []byte("package source\n"), 0o644))
+	dir := filepath.Join(t.TempDir(), "html")
+	cfg := &config{format: defaultFormat, quiet: true, htmlDir: dir, sourceDirs: sourceDirsFlag{{dir: src}}}
+	require.NoError(t, report(cfg, []string{"../../testdata/sample.lcov"}))
+
+	assert.FileExists(t, filepath.Join(dir, "index.html"))
+	page, err := os.ReadFile(filepath.Join(dir, "file1.go.html"))
+	require.NoError(t, err)
+	assert.Contains(t, string(page), `<span class="kw">package</span> source`)
+	page, err = os.ReadFile(filepath.Join(dir, "file2.go.html"))
+	require.NoError(t, err)
+	assert.Contains(t, string(page), "Source not found")
+}
+
+func TestReportDiffFile(t *testing.T) {
+	diff := filepath.Join(t.TempDir(), "changes.diff")
+	require.NoError(t, os.WriteFile(diff, []byte("+++ b/source/file1.go\n@@ -1,0 +1,3 @@\n+a\n+b\n+c\n"), 0o644))
+
+	cfg := &config{format: defaultFormat, quiet: true, diffFile: diff, failUnderPatch: 70}
+	assert.EqualError(t, report(cfg, []string{"../../testdata/sample.lcov"}), "patch coverage 66.7% is below the required 70.0%")
+
+	cfg.failUnderPatch = 60
+	require.NoError(t, report(cfg, []string{"../../testdata/sample.lcov"}))
+
+	// The patch coverage is a row of the summary block of the text format
+	cfg.quiet, cfg.output = false, filepath.Join(t.TempDir(), "summary.txt")
+	require.NoError(t, report(cfg, []string{"../../testdata/sample.lcov"}))
+	data, err := os.ReadFile(cfg.output)
+	require.NoError(t, err)
+	assert.True(t, strings.HasSuffix(string(data), "  branches....: no data found\n  patch.......: 66.7% (2 of 3 changed lines)\n"))
+	cfg.output = ""
+
+	// Line 2 is the uncovered added line
+	cfg.quiet, cfg.uncoveredOut = true, filepath.Join(t.TempDir(), "uncovered.json")
+	require.NoError(t, report(cfg, []string{"../../testdata/sample.lcov"}))
+	var artifact lcov.UncoveredArtifact
+	data, err = os.ReadFile(cfg.uncoveredOut)
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal(data, &artifact))
+	assert.Equal(t, 3, artifact.ChangedLines)
+	assert.Equal(t, 2, artifact.CoveredLines)
+	assert.Equal(t, []lcov.UncoveredChange{{File: "source/file1.go", Line: 2, Hunk: "@@ -1,0 +1,3 @@", Text: "b"}}, artifact.Uncovered)
+	cfg.uncoveredOut = ""
+
+	cfg.diffFile = filepath.Join(t.TempDir(), "missing.diff")
+	assert.ErrorContains(t, report(cfg, []string{"../../testdata/sample.lcov"}), "error opening diff")
+}
+
+func TestReportDeriveTotals(t *testing.T) {
+	tracefile := filepath.Join(t.TempDir(), "coverage.info")
+	require.NoError(t, os.WriteFile(tracefile, []byte("SF:/src/a.go\nDA:1,1\nDA:2,0\nLF:2\nLH:2\nend_of_record\n"), 0o644))
+
+	cfg := &config{format: defaultFormat, quiet: true, thresholds: lcov.Thresholds{Lines: 60}}
+	require.NoError(t, report(cfg, []string{tracefile}))
+
+	cfg.deriveTotals = true
+	assert.EqualError(t, report(cfg, []string{tracefile}), "line coverage 50.0% is below the required 60.0%")
+}
+
+func TestReportWarnOnly(t *testing.T) {
+	ratchet := filepath.Join(t.TempDir(), "ratchet.json")
+	require.NoError(t, os.WriteFile(ratchet, []byte(`{"lines": {"covered": 7, "total": 10}}`), 0o644))
+
+	cfg := &config{format: defaultFormat, quiet: true, failUnderFile: 70, ratchet: ratchet, warnOnly: true}
+	cfg.thresholds.Lines = 90
+	require.NoError(t, report(cfg, []string{"../../testdata/sample.lcov"}))
+	// The violations don't raise nor lower the ratchet
+	data, err := os.ReadFile(ratchet)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"covered": 7`)
+}
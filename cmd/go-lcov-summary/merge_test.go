@@ -0,0 +1,35 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/shastick/go-lcov-summary"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunMerge(t *testing.T) {
+	dir := t.TempDir()
+	shard := filepath.Join(dir, "shard.info")
+	require.NoError(t, os.WriteFile(shard, []byte("TN:\nSF:/path/to/source/file1.go\nFN:1,main\nFNDA:2,main\nDA:1,3\nDA:2,1\nLF:2\nLH:2\nend_of_record\n"), 0o644))
+
+	output := filepath.Join(dir, "merged.info")
+	require.NoError(t, runMerge([]string{"../../testdata/sample.lcov", shard, "-o", output}))
+
+	file, err := os.Open(output)
+	require.NoError(t, err)
+	defer file.Close()
+	merged, err := lcov.Summarize(file, lcov.WithDetails())
+	require.NoError(t, err)
+
+	assert.Equal(t, 2, merged.TotalFiles)
+	assert.Equal(t, int64(9), merged.TotalLines)
+	assert.Equal(t, int64(7), merged.CoveredLines)
+	assert.Equal(t, int64(1), merged.TotalFunctions)
+	require.Equal(t, "/path/to/source/file1.go", merged.Files[0].Path)
+	assert.Equal(t, lcov.LineData{Line: 1, Count: 4}, merged.Files[0].Lines[0])
+
+	assert.EqualError(t, runMerge(nil), "no input given")
+}
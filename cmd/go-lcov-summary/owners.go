@@ -0,0 +1,140 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/shastick/go-lcov-summary"
+)
+
+// codeownersLocations are the CODEOWNERS files looked up in the repository root, in order
+var codeownersLocations = []string{".github/CODEOWNERS", "CODEOWNERS", "docs/CODEOWNERS"}
+
+// runOwners implements the 'owners [flags] <coverage-file>...' subcommand
+func runOwners(args []string) error {
+	fs := flag.NewFlagSet("owners", flag.ContinueOnError)
+	var codeownersPath, root string
+	var failUnder float64
+	ownerThresholds := ownerThresholdsFlag{}
+	fs.StringVar(&codeownersPath, "codeowners", "", "CODEOWNERS `file` (default .github/CODEOWNERS, CODEOWNERS or docs/CODEOWNERS in the repository root)")
+	fs.StringVar(&root, "root", "", "repository root `directory` the tracefile paths are made relative to (default the git top-level directory)")
+	fs.Float64Var(&failUnder, "fail-under", 0, "exit with an error when any coverage rate of any owner is below this `percentage`")
+	fs.Var(ownerThresholds, "fail-under-owner", "exit with an error when any coverage rate of an owner is below a percentage, given as `owner=percentage`, overriding --fail-under (repeatable)")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: go-lcov-summary owners [flags] <coverage-file>...\n")
+		printFlags(fs)
+	}
+
+	inputs, err := parseInterleaved(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(inputs) == 0 {
+		return errors.New("no input given")
+	}
+	if root == "" {
+		if root, err = repositoryRoot(); err != nil {
+			return err
+		}
+	}
+	if codeownersPath == "" {
+		if codeownersPath, err = findCodeowners(root); err != nil {
+			return err
+		}
+	}
+	codeowners, err := readCodeowners(codeownersPath)
+	if err != nil {
+		return err
+	}
+
+	if inputs, err = expandInputs(inputs, ""); err != nil {
+		return err
+	}
+	summary, err := summarizeInputs(inputs, []lcov.Option{lcov.WithDetails()}, io.Discard, nil)
+	if err != nil {
+		return err
+	}
+	owners := lcov.SummarizeByOwner(summary.Files, codeowners, root)
+	if err := lcov.RenderOwners(os.Stdout, owners); err != nil {
+		return err
+	}
+
+	var violations []string
+	for _, o := range owners {
+		threshold, ok := ownerThresholds[o.Owner]
+		if !ok {
+			threshold = failUnder
+		}
+		for _, violation := range lcov.CheckThresholds(o.Summary, lcov.Thresholds{Lines: threshold, Functions: threshold, Branches: threshold}) {
+			violations = append(violations, fmt.Sprintf("%s: %s", o.Owner, violation))
+		}
+	}
+	for i, violation := range violations {
+		// The last violation is returned, the others are reported right away
+		if i == len(violations)-1 {
+			return errors.New(violation)
+		}
+		fmt.Fprintf(os.Stderr, "Error: %s\n", violation)
+	}
+	return nil
+}
+
+// repositoryRoot returns the top-level directory of the git repository, or the
+// working directory outside of a repository
+func repositoryRoot() (string, error) {
+	if root, err := git("", "rev-parse", "--show-toplevel"); err == nil {
+		return strings.TrimSpace(root), nil
+	}
+	return os.Getwd()
+}
+
+// findCodeowners returns the path of the CODEOWNERS file of the repository
+func findCodeowners(root string) (string, error) {
+	for _, location := range codeownersLocations {
+		path := filepath.Join(root, location)
+		if _, err := os.Stat(path); err == nil {
+			return path, nil
+		}
+	}
+	return "", fmt.Errorf("no CODEOWNERS file found in %s", root)
+}
+
+// readCodeowners parses the CODEOWNERS file at path
+func readCodeowners(path string) (*lcov.Codeowners, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return lcov.ParseCodeowners(file)
+}
+
+// ownerThresholdsFlag is a repeatable flag collecting 'owner=percentage' thresholds
+type ownerThresholdsFlag map[string]float64
+
+func (o ownerThresholdsFlag) String() string {
+	var values []string
+	for owner, threshold := range o {
+		values = append(values, owner+"="+strconv.FormatFloat(threshold, 'f', -1, 64))
+	}
+	return strings.Join(values, ",")
+}
+
+func (o ownerThresholdsFlag) Set(value string) error {
+	owner, threshold, ok := strings.Cut(value, "=")
+	if !ok || owner == "" {
+		return errors.New("expected owner=percentage")
+	}
+	percentage, err := strconv.ParseFloat(threshold, 64)
+	if err != nil {
+		return fmt.Errorf("invalid percentage: %s", threshold)
+	}
+	o[owner] = percentage
+	return nil
+}
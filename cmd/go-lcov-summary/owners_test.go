@@ -0,0 +1,33 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunOwners(t *testing.T) {
+	root := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(root, ".github"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(root, ".github", "CODEOWNERS"), []byte("* @org/core\n"), 0o644))
+
+	require.NoError(t, runOwners([]string{"--root", root, "../../testdata/sample.lcov"}))
+	require.NoError(t, runOwners([]string{"--root", root, "--fail-under", "90", "--fail-under-owner", "@org/core=60", "../../testdata/sample.lcov"}))
+	assert.EqualError(t, runOwners([]string{"--root", root, "--fail-under", "90", "../../testdata/sample.lcov"}),
+		"@org/core: line coverage 66.7% is below the required 90.0%")
+
+	empty := t.TempDir()
+	assert.EqualError(t, runOwners([]string{"--root", empty, "../../testdata/sample.lcov"}), "no CODEOWNERS file found in "+empty)
+}
+
+func TestOwnerThresholdsFlag(t *testing.T) {
+	thresholds := ownerThresholdsFlag{}
+	require.NoError(t, thresholds.Set("@org/api=80"))
+	assert.Equal(t, ownerThresholdsFlag{"@org/api": 80}, thresholds)
+	assert.EqualError(t, thresholds.Set("@org/api"), "expected owner=percentage")
+	assert.EqualError(t, thresholds.Set("@org/api=high"), "invalid percentage: high")
+	assert.Equal(t, "@org/api=80", thresholds.String())
+}
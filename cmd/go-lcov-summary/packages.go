@@ -0,0 +1,113 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/shastick/go-lcov-summary"
+)
+
+// runPackages implements the 'packages [flags] <coverage-file>...' subcommand
+func runPackages(args []string) error {
+	flags := flag.NewFlagSet("packages", flag.ContinueOnError)
+	var root string
+	var failUnder float64
+	flags.StringVar(&root, "root", "", "repository root `directory` searched for go.mod files, which the tracefile paths are made relative to (default the git top-level directory)")
+	flags.Float64Var(&failUnder, "fail-under", 0, "exit with an error when any coverage rate of any package is below this `percentage`")
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: go-lcov-summary packages [flags] <coverage-file>...\n")
+		printFlags(flags)
+	}
+
+	inputs, err := parseInterleaved(flags, args)
+	if err != nil {
+		return err
+	}
+	if len(inputs) == 0 {
+		return errors.New("no input given")
+	}
+	if root == "" {
+		if root, err = repositoryRoot(); err != nil {
+			return err
+		}
+	}
+	modules, err := findGoModules(root)
+	if err != nil {
+		return err
+	}
+	if len(modules) == 0 {
+		return fmt.Errorf("no go.mod file found in %s", root)
+	}
+
+	if inputs, err = expandInputs(inputs, ""); err != nil {
+		return err
+	}
+	summary, err := summarizeInputs(inputs, []lcov.Option{lcov.WithDetails()}, io.Discard, nil)
+	if err != nil {
+		return err
+	}
+	packages := lcov.SummarizeByPackage(summary.Files, modules, root)
+	if err := lcov.RenderPackages(os.Stdout, packages); err != nil {
+		return err
+	}
+
+	var violations []string
+	for _, p := range packages {
+		for _, violation := range lcov.CheckThresholds(p.Summary, lcov.Thresholds{Lines: failUnder, Functions: failUnder, Branches: failUnder}) {
+			violations = append(violations, fmt.Sprintf("%s: %s", p.ImportPath, violation))
+		}
+	}
+	for i, violation := range violations {
+		// The last violation is returned, the others are reported right away
+		if i == len(violations)-1 {
+			return errors.New(violation)
+		}
+		fmt.Fprintf(os.Stderr, "Error: %s\n", violation)
+	}
+	return nil
+}
+
+// findGoModules returns the modules of the go.mod files below root, skipping
+// the vendor and testdata directories and hidden ones, which the go command
+// ignores as well
+func findGoModules(root string) ([]lcov.GoModule, error) {
+	var modules []lcov.GoModule
+	err := filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() {
+			name := entry.Name()
+			if path != root && (name == "vendor" || name == "testdata" || strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_")) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if entry.Name() != "go.mod" {
+			return nil
+		}
+
+		file, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		modulePath, err := lcov.ParseGoModPath(file)
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		dir, err := filepath.Rel(root, filepath.Dir(path))
+		if err != nil {
+			return err
+		}
+		modules = append(modules, lcov.GoModule{Path: modulePath, Dir: filepath.ToSlash(dir)})
+		return nil
+	})
+	return modules, err
+}
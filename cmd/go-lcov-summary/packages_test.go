@@ -0,0 +1,30 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunPackages(t *testing.T) {
+	root := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(root, "go.mod"), []byte("module example.com/repo\n\ngo 1.23\n"), 0o644))
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "vendor", "example.com", "dep"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "vendor", "example.com", "dep", "go.mod"), []byte("module example.com/dep\n"), 0o644))
+	tracefile := filepath.Join(t.TempDir(), "coverage.lcov")
+	require.NoError(t, os.WriteFile(tracefile, []byte("SF:"+filepath.Join(root, "pkg", "api.go")+"\nDA:1,1\nDA:2,0\nLF:2\nLH:1\nend_of_record\n"), 0o644))
+
+	require.NoError(t, runPackages([]string{"--root", root, tracefile}))
+	assert.EqualError(t, runPackages([]string{"--root", root, "--fail-under", "90", tracefile}),
+		"example.com/repo/pkg: line coverage 50.0% is below the required 90.0%")
+
+	modules, err := findGoModules(root)
+	require.NoError(t, err)
+	assert.Len(t, modules, 1)
+
+	empty := t.TempDir()
+	assert.EqualError(t, runPackages([]string{"--root", empty, tracefile}), "no go.mod file found in "+empty)
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/shastick/go-lcov-summary"
+)
+
+// readDiff returns the changes since the merge base with the base revision, or
+// else those of the unified diff of a file, '-' meaning stdin
+func readDiff(base, path string) ([]lcov.DiffFile, error) {
+	if base != "" {
+		return gitDiff("", base)
+	}
+	if path == "-" {
+		return lcov.ParseUnifiedDiff(os.Stdin)
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("error opening diff: %w", err)
+	}
+	defer file.Close()
+	return lcov.ParseUnifiedDiff(file)
+}
+
+// gitDiff returns the changes of the working tree in dir since its merge base with
+// the base revision, i.e. the changes a pull request against base would bring
+func gitDiff(dir, base string) ([]lcov.DiffFile, error) {
+	mergeBase, err := git(dir, "merge-base", base, "HEAD")
+	if err != nil {
+		return nil, err
+	}
+	diff, err := git(dir, "diff", "--no-color", "--no-ext-diff", "--unified=0", strings.TrimSpace(mergeBase))
+	if err != nil {
+		return nil, err
+	}
+	return lcov.ParseUnifiedDiff(strings.NewReader(diff))
+}
+
+// git runs a git command in dir and returns its output
+func git(dir string, args ...string) (string, error) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("git %s: %w: %s", args[0], err, strings.TrimSpace(stderr.String()))
+	}
+	return string(out), nil
+}
+
+// writePatchCoverage writes the coverage of the changed lines
+func writePatchCoverage(w io.Writer, patch *lcov.PatchSummary) {
+	rate, ok := patch.Rate()
+	if !ok {
+		fmt.Fprintf(w, "Patch coverage: no changed lines with coverage data\n")
+		return
+	}
+	fmt.Fprintf(w, "Patch coverage: %.1f%% (%d of %d changed lines)\n",
+		rate, patch.CoveredLines, patch.ChangedLines)
+}
+
+// patchRate returns the percentage of the changed lines that are covered, false
+// without patch or changed lines with coverage data
+func patchRate(patch *lcov.PatchSummary) (float64, bool) {
+	if patch == nil {
+		return 0, false
+	}
+	return patch.Rate()
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/shastick/go-lcov-summary"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGitDiff(t *testing.T) {
+	dir := t.TempDir()
+	run := func(args ...string) {
+		_, err := git(dir, append([]string{"-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)...)
+		require.NoError(t, err)
+	}
+	write := func(content string) {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "main.go"), []byte(content), 0o644))
+	}
+
+	run("init", "-q", "-b", "main")
+	write("a\nb\nc\n")
+	run("add", ".")
+	run("commit", "-q", "-m", "base")
+	run("checkout", "-q", "-b", "feature")
+	write("a\nB\nc\nd\n")
+
+	diff, err := gitDiff(dir, "main")
+	require.NoError(t, err)
+	require.Len(t, diff, 1)
+	assert.Equal(t, "main.go", diff[0].Path)
+	var added []int
+	for _, hunk := range diff[0].Hunks {
+		for _, line := range hunk.Added {
+			added = append(added, line.Line)
+		}
+	}
+	assert.Equal(t, []int{2, 4}, added)
+
+	_, err = gitDiff(dir, "unknown")
+	assert.ErrorContains(t, err, "git merge-base")
+}
+
+func TestWritePatchCoverage(t *testing.T) {
+	files := []lcov.FileRecord{{Path: "/src/main.go", Lines: []lcov.LineData{{Line: 2, Count: 1}, {Line: 3, Count: 0}, {Line: 4, Count: 0}}}}
+	diff, err := lcov.ParseUnifiedDiff(strings.NewReader("+++ b/main.go\n@@ -1,0 +2,3 @@\n+b\n+c\n+d\n"))
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	writePatchCoverage(&buf, lcov.NewPatchSummary(files, diff))
+	assert.Equal(t, "Patch coverage: 33.3% (1 of 3 changed lines)\n", buf.String())
+
+	buf.Reset()
+	writePatchCoverage(&buf, lcov.NewPatchSummary(files, nil))
+	assert.Equal(t, "Patch coverage: no changed lines with coverage data\n", buf.String())
+}
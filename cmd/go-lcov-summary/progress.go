@@ -0,0 +1,66 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/shastick/go-lcov-summary"
+)
+
+// progressInterval is the minimum delay between two progress updates
+const progressInterval = 100 * time.Millisecond
+
+// progressPrinter keeps a single status line about the parsing of an input up to date
+type progressPrinter struct {
+	w     io.Writer
+	name  string
+	total int64 // size of the input, 0 when unknown
+	now   func() time.Time
+	last  time.Time
+	state lcov.Progress
+}
+
+// newProgressPrinter creates a printer for an input of the given size, 0 if unknown
+func newProgressPrinter(w io.Writer, name string, total int64) *progressPrinter {
+	return &progressPrinter{w: w, name: name, total: total, now: time.Now}
+}
+
+// update records the progress of the parser, and prints it unless it was printed recently
+func (p *progressPrinter) update(progress lcov.Progress) {
+	p.state = progress
+	if now := p.now(); now.Sub(p.last) >= progressInterval {
+		p.last = now
+		p.print()
+	}
+}
+
+// finish prints the final progress and ends the status line
+func (p *progressPrinter) finish() {
+	p.print()
+	fmt.Fprintln(p.w)
+}
+
+// print overwrites the status line with the current progress
+func (p *progressPrinter) print() {
+	status := formatBytes(p.state.Bytes)
+	if p.total > 0 {
+		status = fmt.Sprintf("%s / %s (%d%%)", status, formatBytes(p.total), min(p.state.Bytes*100/p.total, 100))
+	}
+	// Trailing spaces erase the end of a longer previous line
+	fmt.Fprintf(p.w, "\rParsing %s: %s, %d files    ", p.name, status, p.state.Files)
+}
+
+// formatBytes formats a size in bytes with a binary unit
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"bytes"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/shastick/go-lcov-summary"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProgressPrinter(t *testing.T) {
+	var buf bytes.Buffer
+	printer := newProgressPrinter(&buf, "big.info", 4<<20)
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	printer.now = func() time.Time { return now }
+
+	printer.update(lcov.Progress{Bytes: 1 << 20, Files: 10})
+	// Too soon: only recorded
+	now = now.Add(progressInterval / 2)
+	printer.update(lcov.Progress{Bytes: 2 << 20, Files: 20})
+	assert.Equal(t, "\rParsing big.info: 1.0 MiB / 4.0 MiB (25%), 10 files    ", buf.String())
+
+	buf.Reset()
+	printer.finish()
+	assert.Equal(t, "\rParsing big.info: 2.0 MiB / 4.0 MiB (50%), 20 files    \n", buf.String())
+}
+
+func TestSummarizeInputsProgress(t *testing.T) {
+	var progress bytes.Buffer
+	_, err := summarizeInputs([]string{"../../testdata/sample.lcov"}, nil, io.Discard, &progress)
+	require.NoError(t, err)
+	assert.Contains(t, progress.String(), "\rParsing ../../testdata/sample.lcov: 176 B / 176 B (100%), 2 files    \n")
+}
+
+func TestFormatBytes(t *testing.T) {
+	assert.Equal(t, "512 B", formatBytes(512))
+	assert.Equal(t, "1.5 KiB", formatBytes(1536))
+	assert.Equal(t, "300.0 MiB", formatBytes(300<<20))
+	assert.Equal(t, "2.0 GiB", formatBytes(2<<30))
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+
+	"github.com/shastick/go-lcov-summary"
+	"github.com/shastick/go-lcov-summary/publish"
+)
+
+// runPublish implements the 'publish --s3|--gcs bucket/prefix [flags] <coverage-file>...' subcommand
+func runPublish(args []string) error {
+	fs := flag.NewFlagSet("publish", flag.ContinueOnError)
+	var s3Location, gcsLocation, region, label string
+	fs.StringVar(&s3Location, "s3", "", "upload the report to this Amazon S3 `bucket/prefix`, with the credentials of $AWS_ACCESS_KEY_ID and $AWS_SECRET_ACCESS_KEY")
+	fs.StringVar(&gcsLocation, "gcs", "", "upload the report to this Google Cloud Storage `bucket/prefix`, with the token of $GOOGLE_OAUTH_ACCESS_TOKEN or of the instance service account")
+	fs.StringVar(&region, "region", "", "`region` of the S3 bucket (default $AWS_REGION, or us-east-1)")
+	fs.StringVar(&label, "label", "coverage", "badge `label`")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: go-lcov-summary publish --s3|--gcs <bucket/prefix> [flags] <coverage-file>...\n")
+		printFlags(fs)
+	}
+
+	inputs, err := parseInterleaved(fs, args)
+	if err != nil {
+		return err
+	}
+	if (s3Location == "") == (gcsLocation == "") {
+		return errors.New("exactly one of --s3 and --gcs is required")
+	}
+	if len(inputs) == 0 {
+		return errors.New("no input given")
+	}
+
+	var bucket publish.Bucket
+	var name, prefix string
+	if s3Location != "" {
+		if name, prefix, err = publish.ParseLocation(s3Location); err != nil {
+			return err
+		}
+		s3 := publish.S3FromEnv(name)
+		if region != "" {
+			s3.Region = region
+		}
+		bucket = s3
+	} else {
+		if name, prefix, err = publish.ParseLocation(gcsLocation); err != nil {
+			return err
+		}
+		bucket = publish.GCSFromEnv(name)
+	}
+
+	if inputs, err = expandInputs(inputs, ""); err != nil {
+		return err
+	}
+	summary, err := summarizeInputs(inputs, nil, io.Discard, nil)
+	if err != nil {
+		return err
+	}
+	objects, err := reportObjects(summary, label)
+	if err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	urls, err := publish.Publish(ctx, bucket, prefix, objects)
+	for _, url := range urls {
+		fmt.Printf("Published %s\n", url)
+	}
+	return err
+}
+
+// reportObjects renders the files of a published report: the summary page,
+// which links to the others, the JSON summary and the badge
+func reportObjects(summary *lcov.Summary, label string) ([]publish.Object, error) {
+	var page, jsonSummary, badge bytes.Buffer
+	if err := summaryPage.Execute(&page, summaryPageData{JSONSummary: lcov.NewJSONSummary(summary), JSONPath: "summary.json"}); err != nil {
+		return nil, err
+	}
+	if err := lcov.RenderJSON(&jsonSummary, summary); err != nil {
+		return nil, err
+	}
+	if err := lcov.WriteBadge(&badge, label, summary, lcov.DefaultColorThresholds); err != nil {
+		return nil, err
+	}
+	return []publish.Object{
+		{Name: "index.html", ContentType: "text/html; charset=utf-8", Data: page.Bytes()},
+		{Name: "summary.json", ContentType: "application/json", Data: jsonSummary.Bytes()},
+		{Name: "badge.svg", ContentType: "image/svg+xml", Data: badge.Bytes()},
+	}, nil
+}
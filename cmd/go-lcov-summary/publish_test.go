@@ -0,0 +1,57 @@
+package main
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunPublish(t *testing.T) {
+	var mu sync.Mutex
+	contentTypes := make(map[string]string)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPut, r.Method)
+		assert.True(t, strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=AKID/"), r.Header.Get("Authorization"))
+		mu.Lock()
+		defer mu.Unlock()
+		contentTypes[r.URL.Path] = r.Header.Get("Content-Type")
+	}))
+	defer server.Close()
+
+	t.Setenv("AWS_ACCESS_KEY_ID", "AKID")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "secret")
+	t.Setenv("AWS_SESSION_TOKEN", "")
+	t.Setenv("AWS_ENDPOINT_URL_S3", server.URL)
+
+	require.NoError(t, runPublish([]string{"--s3", "reports/coverage/main", "../../testdata/sample.lcov"}))
+	assert.Equal(t, map[string]string{
+		"/reports/coverage/main/index.html":   "text/html; charset=utf-8",
+		"/reports/coverage/main/summary.json": "application/json",
+		"/reports/coverage/main/badge.svg":    "image/svg+xml",
+	}, contentTypes)
+}
+
+func TestRunPublishErrors(t *testing.T) {
+	assert.EqualError(t, runPublish([]string{"../../testdata/sample.lcov"}), "exactly one of --s3 and --gcs is required")
+	assert.EqualError(t, runPublish([]string{"--s3", "a", "--gcs", "b", "../../testdata/sample.lcov"}), "exactly one of --s3 and --gcs is required")
+	assert.EqualError(t, runPublish([]string{"--gcs", "reports"}), "no input given")
+	assert.EqualError(t, runPublish([]string{"--s3", "/coverage", "../../testdata/sample.lcov"}), `invalid location "/coverage", expected bucket/prefix`)
+}
+
+func TestReportObjects(t *testing.T) {
+	summary, err := summarizeInputs([]string{"../../testdata/sample.lcov"}, nil, io.Discard, nil)
+	require.NoError(t, err)
+	objects, err := reportObjects(summary, "coverage")
+	require.NoError(t, err)
+	require.Len(t, objects, 3)
+	assert.Contains(t, string(objects[0].Data), `<a href="summary.json">JSON</a>`)
+	assert.Contains(t, string(objects[0].Data), `<img src="badge.svg"`)
+	assert.Contains(t, string(objects[1].Data), `"files": 2`)
+	assert.Contains(t, string(objects[2].Data), "66.7%")
+}
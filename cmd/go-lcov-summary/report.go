@@ -0,0 +1,93 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/signal"
+
+	"github.com/shastick/go-lcov-summary"
+	"github.com/shastick/go-lcov-summary/bitbucket"
+)
+
+// runReport implements the 'report <service> [flags] <coverage-file>...' subcommand
+func runReport(args []string) error {
+	if len(args) == 0 || args[0] != "bitbucket" {
+		return fmt.Errorf("usage: %s report bitbucket [flags] <coverage-file>...", os.Args[0])
+	}
+
+	commit := bitbucket.CommitFromEnv()
+	fs := flag.NewFlagSet("report bitbucket", flag.ContinueOnError)
+	var diffBase, root string
+	var failUnder float64
+	fs.StringVar(&commit.APIURL, "api-url", commit.APIURL, "API `URL`, the instance URL for Bitbucket Server (default "+bitbucket.DefaultAPIURL+")")
+	fs.BoolVar(&commit.Server, "server", false, "use the API of Bitbucket Server and Data Center instead of Bitbucket Cloud")
+	fs.StringVar(&commit.Token, "token", commit.Token, "API token (default $BITBUCKET_TOKEN)")
+	fs.StringVar(&commit.Workspace, "workspace", commit.Workspace, "workspace of the repository, its project key on Bitbucket Server (default $BITBUCKET_WORKSPACE)")
+	fs.StringVar(&commit.Repo, "repo", commit.Repo, "repository slug (default $BITBUCKET_REPO_SLUG)")
+	fs.StringVar(&commit.Commit, "commit", commit.Commit, "commit SHA (default $BITBUCKET_COMMIT)")
+	fs.StringVar(&diffBase, "diff-base", "", "git `revision` whose changes since its merge base are annotated and reported as patch coverage (default annotate all uncovered lines)")
+	fs.StringVar(&root, "root", "", "repository root `directory` the tracefile paths are made relative to (default the git top-level directory)")
+	fs.Float64Var(&failUnder, "fail-under", 0, "report a failure, and exit with an error, when any coverage rate is below this `percentage`")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: go-lcov-summary report bitbucket [flags] <coverage-file>...\n")
+		printFlags(fs)
+	}
+
+	inputs, err := parseInterleaved(fs, args[1:])
+	if err != nil {
+		return err
+	}
+	if len(inputs) == 0 {
+		return errors.New("no input given")
+	}
+	if root == "" {
+		if root, err = repositoryRoot(); err != nil {
+			return err
+		}
+	}
+	if inputs, err = expandInputs(inputs, ""); err != nil {
+		return err
+	}
+	summary, err := summarizeInputs(inputs, []lcov.Option{lcov.WithDetails()}, io.Discard, nil)
+	if err != nil {
+		return err
+	}
+	var patch *lcov.PatchSummary
+	if diffBase != "" {
+		diff, err := gitDiff(root, diffBase)
+		if err != nil {
+			return err
+		}
+		patch = lcov.NewPatchSummary(summary.Files, diff)
+	}
+
+	violations := lcov.CheckThresholds(summary, lcov.Thresholds{Lines: failUnder, Functions: failUnder, Branches: failUnder})
+	report := bitbucket.Report{
+		Summary:     summary,
+		Patch:       patch,
+		Passed:      len(violations) == 0,
+		Annotations: bitbucket.Annotations(summary, patch, root),
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	if err := bitbucket.Publish(ctx, http.DefaultClient, commit, report); err != nil {
+		return err
+	}
+	fmt.Printf("Coverage report published on commit %s with %d annotations\n", commit.Commit, len(report.Annotations))
+
+	for i, violation := range violations {
+		// The last violation is returned, the others are reported right away
+		if i == len(violations)-1 {
+			return errors.New(violation.String())
+		}
+		fmt.Fprintf(os.Stderr, "Error: %s\n", violation)
+	}
+	return nil
+}
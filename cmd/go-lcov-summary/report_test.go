@@ -0,0 +1,38 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunReportBitbucket(t *testing.T) {
+	var report struct{ Result string }
+	var annotations []struct{ Path string }
+	endpoint := "/repositories/team/repo/commit/abc123/reports/go-lcov-summary"
+	mux := http.NewServeMux()
+	mux.HandleFunc("DELETE "+endpoint, http.NotFound)
+	mux.HandleFunc("PUT "+endpoint, func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&report))
+	})
+	mux.HandleFunc("POST "+endpoint+"/annotations", func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&annotations))
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	t.Setenv("BITBUCKET_TOKEN", "")
+	err := runReport([]string{"bitbucket", "--api-url", server.URL, "--workspace", "team", "--repo", "repo", "--commit", "abc123",
+		"--root", "/path/to", "--fail-under", "70", "../../testdata/sample.lcov"})
+	assert.EqualError(t, err, "line coverage 66.7% is below the required 70.0%")
+	assert.Equal(t, "FAILED", report.Result)
+	require.Len(t, annotations, 3)
+	assert.Equal(t, "source/file1.go", annotations[0].Path)
+
+	assert.EqualError(t, runReport([]string{"github"}), "usage: "+os.Args[0]+" report bitbucket [flags] <coverage-file>...")
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/shastick/go-lcov-summary"
+)
+
+// result is the structured outcome of a run written by --summary-out, whatever
+// the format of the summary
+type result struct {
+	// Passed tells whether every coverage check passed
+	Passed     bool                 `json:"passed"`
+	Summary    lcov.JSONSummary     `json:"summary"`
+	Patch      *lcov.CoverageMetric `json:"patch,omitempty"`
+	Thresholds resultThresholds     `json:"thresholds"`
+	Violations []string             `json:"violations"`
+	Warnings   []resultWarning      `json:"warnings"`
+}
+
+// resultThresholds are the coverage checks evaluated by the run, omitted when not set
+type resultThresholds struct {
+	Lines     float64               `json:"lines,omitempty"`
+	Functions float64               `json:"functions,omitempty"`
+	Branches  float64               `json:"branches,omitempty"`
+	File      float64               `json:"file,omitempty"`
+	Patch     float64               `json:"patch,omitempty"`
+	Paths     []resultPathThreshold `json:"paths,omitempty"`
+	// FunctionPatterns are the thresholds of --fail-under-function
+	FunctionPatterns []resultFunctionThreshold `json:"function_patterns,omitempty"`
+	Baseline         string                    `json:"baseline,omitempty"`
+}
+
+type resultPathThreshold struct {
+	Pattern string  `json:"pattern"`
+	Rate    float64 `json:"rate"`
+}
+
+type resultFunctionThreshold struct {
+	Pattern  string  `json:"pattern"`
+	Lines    float64 `json:"lines,omitempty"`
+	Branches float64 `json:"branches,omitempty"`
+}
+
+type resultWarning struct {
+	File    string `json:"file,omitempty"`
+	Message string `json:"message"`
+}
+
+// newResult builds the result of a run from its summary, checks and violations
+func newResult(cfg *config, summary *lcov.Summary, patch *lcov.PatchSummary, pathThresholds []lcov.PathThreshold, violations []fmt.Stringer) result {
+	r := result{
+		Passed:  len(violations) == 0,
+		Summary: lcov.NewJSONSummary(summary),
+		Thresholds: resultThresholds{
+			Lines:     cfg.thresholds.Lines,
+			Functions: cfg.thresholds.Functions,
+			Branches:  cfg.thresholds.Branches,
+			File:      cfg.failUnderFile,
+			Patch:     cfg.failUnderPatch,
+			Baseline:  cfg.baseline,
+		},
+		Violations: []string{},
+		Warnings:   []resultWarning{},
+	}
+	if cfg.ratchet != "" {
+		r.Thresholds.Baseline = cfg.ratchet
+	}
+	if patch != nil {
+		rate, _ := patch.Rate()
+		r.Patch = &lcov.CoverageMetric{Covered: int64(patch.CoveredLines), Total: int64(patch.ChangedLines), Rate: rate}
+	}
+	for _, t := range pathThresholds {
+		r.Thresholds.Paths = append(r.Thresholds.Paths, resultPathThreshold{Pattern: t.Pattern, Rate: t.Rate})
+	}
+	for _, t := range cfg.functionThresholds {
+		r.Thresholds.FunctionPatterns = append(r.Thresholds.FunctionPatterns, resultFunctionThreshold{Pattern: t.Pattern, Lines: t.Lines, Branches: t.Branches})
+	}
+	for _, violation := range violations {
+		r.Violations = append(r.Violations, violation.String())
+	}
+	for _, warning := range summary.Warnings {
+		r.Warnings = append(r.Warnings, resultWarning{File: warning.File, Message: warning.Message})
+	}
+	return r
+}
+
+// writeResult writes the result as indented JSON to path, atomically
+func writeResult(path string, r result) error {
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(path, append(data, '\n'))
+}
@@ -0,0 +1,159 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"html/template"
+	"io"
+	"net/http"
+	"os"
+	"os/signal"
+	"sync"
+	"time"
+
+	"github.com/shastick/go-lcov-summary"
+)
+
+// runServe implements the 'serve [flags] <coverage-file>...' subcommand
+func runServe(args []string) error {
+	fs := flag.NewFlagSet("serve", flag.ContinueOnError)
+	var addr string
+	fs.StringVar(&addr, "addr", ":8080", "`address` to listen on")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: go-lcov-summary serve [flags] <coverage-file>...\n")
+		printFlags(fs)
+	}
+
+	inputs, err := parseInterleaved(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(inputs) == 0 {
+		return errors.New("no input given")
+	}
+	if inputs, err = expandInputs(inputs, ""); err != nil {
+		return err
+	}
+	for _, input := range inputs {
+		if input == "-" {
+			return errors.New("serve needs input files, stdin can't be read again")
+		}
+	}
+
+	server := &http.Server{
+		Addr:              addr,
+		Handler:           newServeHandler(newSummaryCache(inputs)),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	go func() {
+		<-ctx.Done()
+		shutdown, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		server.Shutdown(shutdown)
+	}()
+
+	fmt.Fprintf(os.Stderr, "Serving the coverage summary on %s\n", addr)
+	if err := server.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// summaryCache keeps the summary of the inputs, summarizing them again when they change
+type summaryCache struct {
+	inputs      []string
+	incremental *incrementalInputs
+
+	mu      sync.Mutex
+	states  []inputState
+	summary *lcov.Summary
+}
+
+func newSummaryCache(inputs []string) *summaryCache {
+	return &summaryCache{inputs: inputs, incremental: newIncrementalInputs()}
+}
+
+// get returns the summary of the current version of the inputs
+func (c *summaryCache) get() (*lcov.Summary, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	states := statInputs(c.inputs)
+	if c.summary != nil && equalStates(states, c.states) {
+		return c.summary, nil
+	}
+	summary, err := summarizeInputsWith(c.incremental, c.inputs, nil, io.Discard, nil)
+	if err != nil {
+		return nil, err
+	}
+	c.summary, c.states = summary, states
+	return summary, nil
+}
+
+// summaryPage is the HTML page served at the root, and published along with the
+// JSON summary at JSONPath
+var summaryPage = template.Must(template.New("summary").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>Coverage summary</title>
+<style>
+body { font-family: sans-serif; margin: 2em; }
+table { border-collapse: collapse; }
+th, td { padding: .3em 1em; text-align: right; border-bottom: 1px solid #ddd; }
+th:first-child, td:first-child { text-align: left; }
+</style>
+</head>
+<body>
+<h1>Coverage summary</h1>
+<p><img src="badge.svg" alt="coverage badge"> {{.Files}} source files</p>
+<table>
+<tr><th>Metric</th><th>Rate</th><th>Covered</th><th>Total</th></tr>
+<tr><td>Lines</td><td>{{printf "%.1f" .Lines.Rate}}%</td><td>{{.Lines.Covered}}</td><td>{{.Lines.Total}}</td></tr>
+<tr><td>Functions</td><td>{{printf "%.1f" .Functions.Rate}}%</td><td>{{.Functions.Covered}}</td><td>{{.Functions.Total}}</td></tr>
+<tr><td>Branches</td><td>{{printf "%.1f" .Branches.Rate}}%</td><td>{{.Branches.Covered}}</td><td>{{.Branches.Total}}</td></tr>
+</table>
+<p><a href="{{.JSONPath}}">JSON</a></p>
+</body>
+</html>
+`))
+
+// summaryPageData are the values of summaryPage
+type summaryPageData struct {
+	lcov.JSONSummary
+	JSONPath string
+}
+
+// newServeHandler serves the summary as JSON at /summary, as a badge at
+// /badge.svg, as Prometheus metrics at /metrics and as a small HTML page at the root
+func newServeHandler(cache *summaryCache) http.Handler {
+	mux := http.NewServeMux()
+
+	serve := func(contentType string, render func(w io.Writer, s *lcov.Summary) error) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			summary, err := cache.get()
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+			w.Header().Set("Content-Type", contentType)
+			w.Header().Set("Cache-Control", "no-cache")
+			render(w, summary)
+		}
+	}
+
+	mux.Handle("GET /summary", serve("application/json", lcov.RenderJSON))
+	mux.Handle("GET /badge.svg", serve("image/svg+xml", func(w io.Writer, s *lcov.Summary) error {
+		return lcov.WriteBadge(w, "coverage", s, lcov.DefaultColorThresholds)
+	}))
+	mux.Handle("GET /metrics", serve("text/plain; version=0.0.4; charset=utf-8", lcov.WritePrometheus))
+	mux.Handle("GET /{$}", serve("text/html; charset=utf-8", func(w io.Writer, s *lcov.Summary) error {
+		return summaryPage.Execute(w, summaryPageData{JSONSummary: lcov.NewJSONSummary(s), JSONPath: "summary"})
+	}))
+	return mux
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/shastick/go-lcov-summary"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServeHandler(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "coverage.info")
+	require.NoError(t, os.WriteFile(path, []byte("SF:a.go\nDA:1,1\nDA:2,0\nLF:2\nLH:1\nend_of_record\n"), 0o644))
+
+	server := httptest.NewServer(newServeHandler(newSummaryCache([]string{path})))
+	defer server.Close()
+
+	get := func(path string) (*http.Response, string) {
+		resp, err := http.Get(server.URL + path)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		return resp, string(body)
+	}
+
+	resp, body := get("/summary")
+	assert.Equal(t, "application/json", resp.Header.Get("Content-Type"))
+	var summary lcov.JSONSummary
+	require.NoError(t, json.Unmarshal([]byte(body), &summary))
+	assert.Equal(t, lcov.CoverageMetric{Covered: 1, Total: 2, Rate: 50}, summary.Lines)
+
+	resp, body = get("/badge.svg")
+	assert.Equal(t, "image/svg+xml", resp.Header.Get("Content-Type"))
+	assert.Contains(t, body, "coverage: 50.0%")
+
+	resp, body = get("/metrics")
+	assert.Contains(t, resp.Header.Get("Content-Type"), "text/plain; version=0.0.4")
+	assert.Contains(t, body, "coverage_lines_covered 1\n")
+	assert.Contains(t, body, "coverage_ratio{metric=\"line\"} 0.5\n")
+
+	_, body = get("/")
+	assert.Contains(t, body, "<td>Lines</td><td>50.0%</td><td>1</td><td>2</td>")
+
+	resp, _ = get("/unknown")
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+	// The summary follows the changes of the tracefile
+	require.NoError(t, os.WriteFile(path, []byte("SF:a.go\nDA:1,1\nDA:2,1\nDA:3,1\nLF:3\nLH:3\nend_of_record\n"), 0o644))
+	_, body = get("/summary")
+	require.NoError(t, json.Unmarshal([]byte(body), &summary))
+	assert.Equal(t, 100.0, summary.Lines.Rate)
+
+	require.NoError(t, os.Remove(path))
+	resp, _ = get("/summary")
+	assert.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+}
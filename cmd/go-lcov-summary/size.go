@@ -0,0 +1,64 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/shastick/go-lcov-summary"
+)
+
+// runSize implements the 'size [flags] <lcov-file>...' subcommand
+func runSize(args []string) error {
+	fs := flag.NewFlagSet("size", flag.ContinueOnError)
+	var top int
+	fs.IntVar(&top, "top", 10, "list this `number` of the largest source files")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: go-lcov-summary size [flags] <lcov-file>...|-\n")
+		fmt.Fprintf(fs.Output(), "\nBreaks down the size of LCOV tracefiles by record type and source file, with suggestions to reduce it.\n")
+		printFlags(fs)
+	}
+
+	inputs, err := parseInterleaved(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(inputs) == 0 {
+		return errors.New("no input given")
+	}
+	if inputs, err = expandInputs(inputs, ""); err != nil {
+		return err
+	}
+	for i, input := range inputs {
+		if len(inputs) > 1 {
+			if i > 0 {
+				fmt.Println()
+			}
+			fmt.Printf("%s:\n", input)
+		}
+		if err := writeSize(os.Stdout, input, top); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeSize writes the size breakdown of an input, '-' meaning stdin
+func writeSize(w io.Writer, input string, top int) error {
+	reader := io.Reader(os.Stdin)
+	if input != "-" {
+		file, err := os.Open(input)
+		if err != nil {
+			return fmt.Errorf("error opening file: %w", err)
+		}
+		defer file.Close()
+		reader = file
+	}
+	report, err := lcov.AnalyzeSize(reader)
+	if err != nil {
+		return fmt.Errorf("error reading %s: %w", input, err)
+	}
+	return report.WriteText(w, top)
+}
@@ -0,0 +1,18 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteSize(t *testing.T) {
+	var out strings.Builder
+	require.NoError(t, writeSize(&out, "../../testdata/concatenated.lcov", 1))
+	assert.Contains(t, out.String(), "Total size: 361 bytes\n")
+	assert.Contains(t, out.String(), "go-lcov-summary merge writes a single block per file")
+
+	assert.ErrorContains(t, writeSize(&out, "missing.info", 1), "error opening file")
+}
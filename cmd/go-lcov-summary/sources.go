@@ -0,0 +1,127 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/shastick/go-lcov-summary"
+)
+
+// sourceRoot is a directory the source files are looked up in
+type sourceRoot struct {
+	// prefix is the directory of the tracefile paths that dir stands for, if set,
+	// typically the checkout directory of the machine that produced the tracefile
+	prefix string
+	dir    string
+}
+
+// sourceDirsFlag is a repeatable flag of source directories, each given as DIR,
+// or as PREFIX=DIR to look the tracefile paths below PREFIX up below DIR
+type sourceDirsFlag []sourceRoot
+
+func (s *sourceDirsFlag) String() string {
+	roots := make([]string, len(*s))
+	for i, root := range *s {
+		roots[i] = root.dir
+		if root.prefix != "" {
+			roots[i] = root.prefix + "=" + root.dir
+		}
+	}
+	return strings.Join(roots, ",")
+}
+
+func (s *sourceDirsFlag) Set(value string) error {
+	prefix, dir, found := strings.Cut(value, "=")
+	if !found {
+		prefix, dir = "", value
+	}
+	if dir == "" || (found && prefix == "") {
+		return fmt.Errorf("invalid source directory %q, expected DIR or PREFIX=DIR", value)
+	}
+	*s = append(*s, sourceRoot{prefix: strings.TrimSuffix(filepath.ToSlash(prefix), "/"), dir: dir})
+	return nil
+}
+
+// resolve looks up the source file of a tracefile path in the source
+// directories, in order, or in the working directory if there are none. The
+// paths below the prefix of a directory are looked up below it, the prefix
+// substituted, other directories are searched as described by resolveSource.
+// It returns the path of the source file and its path relative to its directory.
+func (s sourceDirsFlag) resolve(name string) (string, string, bool) {
+	roots := s
+	if len(roots) == 0 {
+		roots = sourceDirsFlag{{}}
+	}
+	for _, root := range roots {
+		if root.prefix == "" {
+			if path, relative, ok := resolveSource(root.dir, name); ok {
+				return path, relative, true
+			}
+			continue
+		}
+		rest, ok := strings.CutPrefix(filepath.ToSlash(name), root.prefix+"/")
+		if !ok {
+			continue
+		}
+		relative := filepath.FromSlash(rest)
+		path := filepath.Join(root.dir, relative)
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			return path, relative, true
+		}
+	}
+	return "", "", false
+}
+
+// sourceOpener returns a function opening the source file of a tracefile path,
+// looked up in the source directories, reporting those not found to missing
+func sourceOpener(dirs sourceDirsFlag, missing io.Writer) func(path string) (io.ReadCloser, bool) {
+	return func(path string) (io.ReadCloser, bool) {
+		source, _, ok := dirs.resolve(path)
+		if !ok {
+			fmt.Fprintf(missing, "Source of %s not found in %s\n", path, dirs.describe())
+			return nil, false
+		}
+		file, err := os.Open(source)
+		if err != nil {
+			return nil, false
+		}
+		return file, true
+	}
+}
+
+// describe returns the source directories as shown in messages
+func (s sourceDirsFlag) describe() string {
+	if len(s) == 0 {
+		return "the working directory"
+	}
+	return s.String()
+}
+
+// excludeMarked returns the summary of the files of a detailed summary without
+// the data excluded by the LCOV_EXCL_* markers of their sources in the source
+// directories, reporting the files whose source isn't found to verbose
+func excludeMarked(summary *lcov.Summary, dirs sourceDirsFlag, verbose io.Writer) (*lcov.Summary, error) {
+	files, err := lcov.ExcludeMarked(summary.Files, sourceOpener(dirs, verbose))
+	if err != nil {
+		return nil, err
+	}
+	kept := lcov.SummarizeFiles(files)
+	kept.Warnings, kept.UnknownRecords = summary.Warnings, summary.UnknownRecords
+	return kept, nil
+}
+
+// excludeGenerated returns the summary of the files of a detailed summary that
+// aren't generated, matching the patterns or with the generated code header in
+// their source in the source directories, reporting the excluded ones to verbose
+func excludeGenerated(summary *lcov.Summary, patterns []string, dirs sourceDirsFlag, verbose io.Writer) *lcov.Summary {
+	files, excluded := lcov.ExcludeGenerated(summary.Files, patterns, sourceOpener(dirs, io.Discard))
+	for _, path := range excluded {
+		fmt.Fprintf(verbose, "Excluded generated file %s\n", path)
+	}
+	kept := lcov.SummarizeFiles(files)
+	kept.Warnings, kept.UnknownRecords = summary.Warnings, summary.UnknownRecords
+	return kept
+}
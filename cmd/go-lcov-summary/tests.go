@@ -0,0 +1,86 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+
+	"github.com/shastick/go-lcov-summary"
+)
+
+// runTests implements the 'tests [flags] <coverage-file>...' subcommand
+func runTests(args []string) error {
+	fs := flag.NewFlagSet("tests", flag.ContinueOnError)
+	var test string
+	fs.StringVar(&test, "test", "", "list the files covered by the test of this `name` instead of the tests")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: go-lcov-summary tests [flags] <coverage-file>...\n")
+		printFlags(fs)
+	}
+
+	inputs, err := parseInterleaved(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(inputs) == 0 {
+		return errors.New("no input given")
+	}
+	if inputs, err = expandInputs(inputs, ""); err != nil {
+		return err
+	}
+	summary, err := summarizeInputs(inputs, []lcov.Option{lcov.WithDetails()}, io.Discard, nil)
+	if err != nil {
+		return err
+	}
+	tests := lcov.CoverageByTest(summary.Files)
+
+	if test != "" {
+		for _, t := range tests {
+			if t.Name == test {
+				return printTestFiles(os.Stdout, t)
+			}
+		}
+		return fmt.Errorf("no coverage data for test %s", test)
+	}
+	// Lines of the same file are counted once, whatever the tests executing them
+	total := lcov.SummarizeFiles(lcov.MergeFiles(summary.Files)).TotalLines
+	return printTests(os.Stdout, tests, total)
+}
+
+// printTests writes a table of the tests with the lines they cover, their share
+// of the total lines, and the lines only they cover, flagging the tests
+// without such lines
+func printTests(w io.Writer, tests []lcov.TestCoverage, total int64) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "Test\tFiles\tLines\tShare\tUnique\n")
+	for _, t := range tests {
+		name := t.Name
+		if name == "" {
+			name = "(unnamed)"
+		}
+		share := "-"
+		if total > 0 {
+			share = fmt.Sprintf("%.1f%%", float64(t.CoveredLines)/float64(total)*100)
+		}
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%s\t%d", name, len(t.Files), t.CoveredLines, share, t.UniqueLines)
+		if t.UniqueLines == 0 && len(tests) > 1 {
+			fmt.Fprintf(tw, "\tno unique lines")
+		}
+		fmt.Fprintln(tw)
+	}
+	return tw.Flush()
+}
+
+// printTestFiles writes a table of the files covered by a test, with the lines
+// it covers and those only it covers
+func printTestFiles(w io.Writer, t lcov.TestCoverage) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "File\tLines\tUnique\n")
+	for _, f := range t.Files {
+		fmt.Fprintf(tw, "%s\t%d\t%d\n", f.Path, f.CoveredLines, f.UniqueLines)
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/shastick/go-lcov-summary"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunTests(t *testing.T) {
+	tracefile := filepath.Join(t.TempDir(), "coverage.info")
+	require.NoError(t, os.WriteFile(tracefile, []byte("TN:unit\nSF:/src/a.go\nDA:1,1\nend_of_record\nTN:e2e\nSF:/src/a.go\nDA:1,1\nDA:2,1\nend_of_record\n"), 0o644))
+
+	require.NoError(t, runTests([]string{tracefile}))
+	require.NoError(t, runTests([]string{tracefile, "--test", "e2e"}))
+	assert.EqualError(t, runTests([]string{"--test", "other", tracefile}), "no coverage data for test other")
+	assert.EqualError(t, runTests(nil), "no input given")
+}
+
+func TestPrintTests(t *testing.T) {
+	tests := []lcov.TestCoverage{
+		{Name: "", CoveredLines: 1, Files: []lcov.TestFileCoverage{{Path: "a.go", CoveredLines: 1}}},
+		{Name: "unit", CoveredLines: 3, UniqueLines: 2, Files: []lcov.TestFileCoverage{
+			{Path: "a.go", CoveredLines: 2, UniqueLines: 1},
+			{Path: "b.go", CoveredLines: 1, UniqueLines: 1},
+		}},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, printTests(&buf, tests, 4))
+	assert.Equal(t, ""+
+		"Test       Files  Lines  Share  Unique\n"+
+		"(unnamed)  1      1      25.0%  0  no unique lines\n"+
+		"unit       2      3      75.0%  2\n", buf.String())
+
+	buf.Reset()
+	require.NoError(t, printTestFiles(&buf, tests[1]))
+	assert.Equal(t, ""+
+		"File  Lines  Unique\n"+
+		"a.go  2      1\n"+
+		"b.go  1      1\n", buf.String())
+}
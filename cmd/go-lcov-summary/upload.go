@@ -0,0 +1,121 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/signal"
+
+	"github.com/shastick/go-lcov-summary"
+	"github.com/shastick/go-lcov-summary/codecov"
+	"github.com/shastick/go-lcov-summary/coveralls"
+)
+
+// runUpload implements the 'upload <service> [flags] <lcov-file>...' subcommand
+func runUpload(args []string) error {
+	if len(args) > 0 {
+		switch args[0] {
+		case "codecov":
+			return uploadCodecov(args[1:])
+		case "coveralls":
+			return uploadCoveralls(args[1:])
+		}
+	}
+	return fmt.Errorf("usage: %s upload codecov|coveralls [flags] <lcov-file>...", os.Args[0])
+}
+
+// uploadCodecov uploads the tracefiles to Codecov
+func uploadCodecov(args []string) error {
+	u := codecov.FromEnv()
+	fs := flag.NewFlagSet("upload codecov", flag.ContinueOnError)
+	baseURL := fs.String("url", codecov.DefaultURL, "Codecov instance URL")
+	fs.StringVar(&u.Token, "token", u.Token, "upload token (default $CODECOV_TOKEN)")
+	fs.StringVar(&u.Commit, "commit", u.Commit, "commit SHA (default from the CI environment)")
+	fs.StringVar(&u.Branch, "branch", u.Branch, "branch name")
+	fs.StringVar(&u.PR, "pr", u.PR, "pull request number")
+	fs.StringVar(&u.Slug, "slug", u.Slug, "repository slug, e.g. owner/repo")
+	fs.StringVar(&u.Flags, "flags", u.Flags, "comma separated Codecov flags")
+	inputs, err := parseInterleaved(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(inputs) == 0 {
+		return fmt.Errorf("no tracefile given")
+	}
+
+	reports := make(map[string][]byte)
+	for _, path := range inputs {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("error opening file: %w", err)
+		}
+		// Don't upload garbage: the tracefile must at least parse
+		if _, err := lcov.Summarize(bytes.NewReader(data)); err != nil {
+			return fmt.Errorf("error parsing LCOV file %s: %w", path, err)
+		}
+		reports[path] = data
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	result, err := codecov.Send(ctx, http.DefaultClient, *baseURL, u, codecov.WrapReport(nil, reports, inputs))
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Uploaded to Codecov: %s\n", result)
+	return nil
+}
+
+// uploadCoveralls uploads the coverage of the tracefiles to Coveralls, with the
+// digests of the sources found
+func uploadCoveralls(args []string) error {
+	job := coveralls.JobFromEnv()
+	fs := flag.NewFlagSet("upload coveralls", flag.ContinueOnError)
+	endpoint := fs.String("url", coveralls.DefaultEndpoint, "Coveralls jobs API URL")
+	root := fs.String("root", "", "`prefix` removed from the tracefile paths to make them relative to the repository (default the repository root)")
+	var sourceDirs sourceDirsFlag
+	fs.Var(&sourceDirs, "src", "`directory` the source files are looked up in, or PREFIX=DIR; repeatable (default the working directory)")
+	fs.StringVar(&job.RepoToken, "token", job.RepoToken, "repository token (default $COVERALLS_REPO_TOKEN)")
+	fs.StringVar(&job.CommitSHA, "commit", job.CommitSHA, "commit SHA (default from the CI environment)")
+	fs.StringVar(&job.Branch, "branch", job.Branch, "branch name")
+	fs.StringVar(&job.PullRequest, "pr", job.PullRequest, "pull request number")
+	fs.StringVar(&job.ServiceName, "service-name", job.ServiceName, "CI service name (default from the CI environment or $COVERALLS_SERVICE_NAME)")
+	fs.BoolVar(&job.Parallel, "parallel", job.Parallel, "mark the job as one of parallel jobs, completed by the parallel build webhook (default $COVERALLS_PARALLEL)")
+	inputs, err := parseInterleaved(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(inputs) == 0 {
+		return fmt.Errorf("no tracefile given")
+	}
+	if inputs, err = expandInputs(inputs, ""); err != nil {
+		return err
+	}
+	if *root == "" {
+		if *root, err = repositoryRoot(); err != nil {
+			return err
+		}
+	}
+
+	summary, err := summarizeInputs(inputs, []lcov.Option{lcov.WithDetails()}, io.Discard, nil)
+	if err != nil {
+		return err
+	}
+	payload, err := coveralls.NewPayload(summary.Files, *root, job, sourceOpener(sourceDirs, os.Stderr))
+	if err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	if err := coveralls.Upload(ctx, http.DefaultClient, *endpoint, payload); err != nil {
+		return err
+	}
+	fmt.Printf("Uploaded %d source files to Coveralls\n", len(payload.SourceFiles))
+	return nil
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/shastick/go-lcov-summary/coveralls"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUploadCoveralls(t *testing.T) {
+	var received coveralls.Payload
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		file, _, err := r.FormFile("json_file")
+		require.NoError(t, err)
+		require.NoError(t, json.NewDecoder(file).Decode(&received))
+	}))
+	defer server.Close()
+
+	src := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(src, "file1.go"), []byte("a\nb\nc\nd\ne\nf\n"), 0o644))
+
+	t.Setenv("COVERALLS_REPO_TOKEN", "secret")
+	require.NoError(t, runUpload([]string{"coveralls", "--url", server.URL, "--root", "/path/to", "--src", "/path/to/source=" + src, "../../testdata/sample.lcov"}))
+
+	assert.Equal(t, "secret", received.RepoToken)
+	require.Len(t, received.SourceFiles, 2)
+	file1 := received.SourceFiles[0]
+	assert.Equal(t, "source/file1.go", file1.Name)
+	assert.Equal(t, "e078642afbf6dede5ec83b6307ffc646", file1.SourceDigest)
+	assert.Len(t, file1.Coverage, 6)
+	// The source of file2.go isn't found
+	assert.Empty(t, received.SourceFiles[1].SourceDigest)
+
+	assert.EqualError(t, runUpload([]string{"bitbucket"}), "usage: "+os.Args[0]+" upload codecov|coveralls [flags] <lcov-file>...")
+}
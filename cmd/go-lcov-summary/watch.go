@@ -0,0 +1,102 @@
+package main
+
+import (
+	"context"
+	"os"
+	"slices"
+	"time"
+)
+
+// watchInterval is the delay between two checks of the watched inputs
+const watchInterval = 500 * time.Millisecond
+
+// clearScreen is the ANSI sequence clearing the terminal and moving the cursor home
+const clearScreen = "\x1b[H\x1b[2J"
+
+// inputState identifies a version of an input file
+type inputState struct {
+	size    int64
+	modTime time.Time
+	exists  bool
+}
+
+// statInputs returns the current state of every input file. Stdin never changes.
+func statInputs(inputs []string) []inputState {
+	states := make([]inputState, len(inputs))
+	for i, input := range inputs {
+		if input == "-" {
+			continue
+		}
+		if info, err := os.Stat(input); err == nil {
+			states[i] = inputState{size: info.Size(), modTime: info.ModTime(), exists: true}
+		}
+	}
+	return states
+}
+
+// watchSnapshot is the state of the watched inputs at one check
+type watchSnapshot struct {
+	inputs []string
+	states []inputState
+	err    error
+}
+
+// takeSnapshot expands the inputs again, so that tracefiles added to or removed
+// from a watched directory are picked up, and records the state of every file
+func takeSnapshot(expand func() ([]string, error)) watchSnapshot {
+	inputs, err := expand()
+	return watchSnapshot{inputs: inputs, states: statInputs(inputs), err: err}
+}
+
+// equal reports whether two snapshots of the inputs are the same
+func (s watchSnapshot) equal(other watchSnapshot) bool {
+	if (s.err == nil) != (other.err == nil) || s.err != nil && s.err.Error() != other.err.Error() {
+		return false
+	}
+	return slices.Equal(s.inputs, other.inputs) && equalStates(s.states, other.states)
+}
+
+// watchInputs calls changed once, then every time the inputs change, until the
+// context is done. The inputs are expanded again at every check and changed
+// receives the current list, or the error expanding it. Inputs are polled, which
+// works the same on every platform and file system, and a change is only reported
+// once the files stopped changing for an interval, so that a tracefile being
+// written is not summarized half way.
+func watchInputs(ctx context.Context, expand func() ([]string, error), interval time.Duration, changed func(inputs []string, err error)) {
+	// Changes made while reporting are caught by the next check
+	reported := takeSnapshot(expand)
+	previous := reported
+	changed(reported.inputs, reported.err)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		current := takeSnapshot(expand)
+		stable := current.equal(previous)
+		previous = current
+		if stable && !current.equal(reported) {
+			reported = current
+			changed(current.inputs, current.err)
+		}
+	}
+}
+
+// equalStates reports whether two snapshots of the inputs are the same
+func equalStates(a, b []inputState) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i].size != b[i].size || a[i].exists != b[i].exists || !a[i].modTime.Equal(b[i].modTime) {
+			return false
+		}
+	}
+	return true
+}
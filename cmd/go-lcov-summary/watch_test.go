@@ -0,0 +1,76 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWatchInputs(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "coverage.info")
+	require.NoError(t, os.WriteFile(path, []byte("TN:\n"), 0o644))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	changes := make(chan struct{}, 10)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		expand := func() ([]string, error) { return []string{path, "-"}, nil }
+		watchInputs(ctx, expand, 5*time.Millisecond, func([]string, error) { changes <- struct{}{} })
+	}()
+
+	// Initial report
+	<-changes
+
+	require.NoError(t, os.WriteFile(path, []byte("TN:\nSF:main.go\nend_of_record\n"), 0o644))
+	select {
+	case <-changes:
+	case <-time.After(5 * time.Second):
+		t.Fatal("change not reported")
+	}
+
+	cancel()
+	<-done
+	assert.Empty(t, changes)
+}
+
+func TestWatchInputsExpandsAgain(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "a.info"), []byte("TN:\n"), 0o644))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	changes := make(chan []string, 10)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		expand := func() ([]string, error) { return expandInputs([]string{dir}, "") }
+		watchInputs(ctx, expand, 5*time.Millisecond, func(inputs []string, err error) {
+			assert.NoError(t, err)
+			changes <- inputs
+		})
+	}()
+
+	assert.Equal(t, []string{filepath.Join(dir, "a.info")}, <-changes)
+
+	// A tracefile added to the watched directory is picked up
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "b.info"), []byte("TN:\n"), 0o644))
+	select {
+	case inputs := <-changes:
+		assert.Equal(t, []string{filepath.Join(dir, "a.info"), filepath.Join(dir, "b.info")}, inputs)
+	case <-time.After(5 * time.Second):
+		t.Fatal("new tracefile not reported")
+	}
+
+	cancel()
+	<-done
+	assert.Empty(t, changes)
+}
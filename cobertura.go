@@ -0,0 +1,256 @@
+package lcov
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strings"
+)
+
+func init() {
+	RegisterRenderer("cobertura", RendererFunc(func(w io.Writer, s *Summary) error {
+		return WriteCobertura(w, s)
+	}))
+}
+
+// coberturaCoverage mirrors the parts of the Cobertura XML layout used for summaries.
+// The rates and counters are only used when writing reports.
+type coberturaCoverage struct {
+	XMLName         xml.Name           `xml:"coverage"`
+	LineRate        float64            `xml:"line-rate,attr"`
+	BranchRate      float64            `xml:"branch-rate,attr"`
+	LinesCovered    int64              `xml:"lines-covered,attr"`
+	LinesValid      int64              `xml:"lines-valid,attr"`
+	BranchesCovered int64              `xml:"branches-covered,attr"`
+	BranchesValid   int64              `xml:"branches-valid,attr"`
+	Complexity      int                `xml:"complexity,attr"`
+	Version         string             `xml:"version,attr"`
+	Timestamp       int64              `xml:"timestamp,attr"`
+	Sources         *coberturaSources  `xml:"sources,omitempty"`
+	Packages        []coberturaPackage `xml:"packages>package"`
+}
+
+type coberturaSources struct {
+	Source []string `xml:"source"`
+}
+
+type coberturaPackage struct {
+	Name       string           `xml:"name,attr"`
+	LineRate   float64          `xml:"line-rate,attr"`
+	BranchRate float64          `xml:"branch-rate,attr"`
+	Complexity int              `xml:"complexity,attr"`
+	Classes    []coberturaClass `xml:"classes>class"`
+}
+
+type coberturaClass struct {
+	Name       string            `xml:"name,attr"`
+	Filename   string            `xml:"filename,attr"`
+	LineRate   float64           `xml:"line-rate,attr"`
+	BranchRate float64           `xml:"branch-rate,attr"`
+	Complexity int               `xml:"complexity,attr"`
+	Methods    []coberturaMethod `xml:"methods>method"`
+	Lines      []coberturaLine   `xml:"lines>line"`
+}
+
+type coberturaMethod struct {
+	Name       string          `xml:"name,attr"`
+	Signature  string          `xml:"signature,attr"`
+	LineRate   float64         `xml:"line-rate,attr"`
+	BranchRate float64         `xml:"branch-rate,attr"`
+	Complexity int             `xml:"complexity,attr"`
+	Lines      []coberturaLine `xml:"lines>line"`
+}
+
+type coberturaLine struct {
+	Number            int    `xml:"number,attr"`
+	Hits              int64  `xml:"hits,attr"`
+	Branch            bool   `xml:"branch,attr"`
+	ConditionCoverage string `xml:"condition-coverage,attr,omitempty"`
+}
+
+// ParseCobertura summarizes a Cobertura XML report. Classes of the same file are
+// merged, methods become functions (executed when their first line was hit) and
+// the 'condition-coverage' of branch lines becomes branch data. The parser
+// options apply as for LCOV data.
+func ParseCobertura(reader io.Reader, opts ...Option) (*Summary, error) {
+	p := newParser(opts)
+	reader = p.track(reader)
+
+	var coverage coberturaCoverage
+	if err := xml.NewDecoder(reader).Decode(&coverage); err != nil {
+		return nil, fmt.Errorf("invalid Cobertura report: %w", err)
+	}
+
+	// Several classes (e.g. inner classes) may share a file, they are not duplicates
+	classes := newFileSet(DuplicatesMerge)
+	for _, pkg := range coverage.Packages {
+		for _, class := range pkg.Classes {
+			f, err := coberturaFileRecord(class)
+			if err != nil {
+				return nil, err
+			}
+			classes.add(f)
+		}
+	}
+
+	files := newFileSet(p.duplicates)
+	for i := range classes.files {
+		files.add(&classes.files[i])
+	}
+	return p.summarize(&Summary{}, files)
+}
+
+// coberturaFileRecord converts a Cobertura class to a detailed file record
+func coberturaFileRecord(class coberturaClass) (*FileRecord, error) {
+	f := &FileRecord{Path: class.Filename}
+
+	for _, line := range class.Lines {
+		f.Lines = append(f.Lines, LineData{Line: line.Number, Count: line.Hits})
+		if !line.Branch || line.ConditionCoverage == "" {
+			continue
+		}
+		// condition-coverage="50% (1/2)"
+		var percent, covered, total int
+		if _, err := fmt.Sscanf(line.ConditionCoverage, "%d%% (%d/%d)", &percent, &covered, &total); err != nil {
+			return nil, fmt.Errorf("invalid condition coverage in %s line %d: %s", class.Filename, line.Number, line.ConditionCoverage)
+		}
+		for i := 0; i < total; i++ {
+			taken := int64(0)
+			if i < covered {
+				taken = 1
+			}
+			f.Branches = append(f.Branches, BranchData{Line: line.Number, Branch: i, Taken: taken})
+		}
+	}
+
+	for _, method := range class.Methods {
+		fn := FunctionData{Name: strings.TrimSpace(method.Name)}
+		if len(method.Lines) > 0 {
+			fn.Line = method.Lines[0].Number
+			fn.Count = method.Lines[0].Hits
+		}
+		f.Functions = append(f.Functions, fn)
+	}
+
+	f.recount()
+	return f, nil
+}
+
+// WriteCobertura writes a summary as a Cobertura XML report, the coverage format
+// most CI systems display natively. Each source file becomes a class, grouped in
+// packages by directory, and functions become methods. The summary must have been
+// parsed WithDetails to report anything but the totals.
+func WriteCobertura(w io.Writer, s *Summary) error {
+	return encodeCobertura(w, xml.Header, newCoberturaCoverage(s, MergeFiles(s.Files)))
+}
+
+// newCoberturaCoverage returns the Cobertura report of the totals of a summary
+// and of its merged, detailed file records
+func newCoberturaCoverage(s *Summary, files []FileRecord) coberturaCoverage {
+	coverage := coberturaCoverage{
+		LineRate:        coberturaRate(s.CoveredLines, s.TotalLines),
+		BranchRate:      coberturaRate(s.CoveredBranches, s.TotalBranches),
+		LinesCovered:    s.CoveredLines,
+		LinesValid:      s.TotalLines,
+		BranchesCovered: s.CoveredBranches,
+		BranchesValid:   s.TotalBranches,
+		Version:         "go-lcov-summary",
+	}
+
+	// Source files are grouped in packages by directory
+	packages := make(map[string][]FileRecord)
+	var names []string
+	for _, f := range files {
+		dir := path.Dir(f.Path)
+		if _, ok := packages[dir]; !ok {
+			names = append(names, dir)
+		}
+		packages[dir] = append(packages[dir], f)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		pkg := coberturaPackage{Name: name}
+		var total FileRecord
+		for i := range packages[name] {
+			f := &packages[name][i]
+			total.LinesHit += f.LinesHit
+			total.LinesFound += f.LinesFound
+			total.BranchesHit += f.BranchesHit
+			total.BranchesFound += f.BranchesFound
+			pkg.Classes = append(pkg.Classes, coberturaClassOf(f))
+		}
+		pkg.LineRate = coberturaRate(total.LinesHit, total.LinesFound)
+		pkg.BranchRate = coberturaRate(total.BranchesHit, total.BranchesFound)
+		coverage.Packages = append(coverage.Packages, pkg)
+	}
+	return coverage
+}
+
+// encodeCobertura writes a Cobertura report after the given XML header
+func encodeCobertura(w io.Writer, header string, coverage coberturaCoverage) error {
+	if _, err := io.WriteString(w, header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(coverage); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// coberturaClassOf converts a merged, detailed file record to a Cobertura class
+func coberturaClassOf(f *FileRecord) coberturaClass {
+	class := coberturaClass{
+		Name:       strings.TrimSuffix(path.Base(f.Path), path.Ext(f.Path)),
+		Filename:   f.Path,
+		LineRate:   coberturaRate(f.LinesHit, f.LinesFound),
+		BranchRate: coberturaRate(f.BranchesHit, f.BranchesFound),
+	}
+
+	// Branches are reported per line as 'covered/total' conditions
+	type conditions struct{ covered, total int }
+	branches := make(map[int]*conditions)
+	for _, b := range f.Branches {
+		c, ok := branches[b.Line]
+		if !ok {
+			c = &conditions{}
+			branches[b.Line] = c
+		}
+		c.total++
+		if b.Taken > 0 {
+			c.covered++
+		}
+	}
+
+	for _, l := range f.Lines {
+		line := coberturaLine{Number: l.Line, Hits: l.Count}
+		if c, ok := branches[l.Line]; ok {
+			line.Branch = true
+			line.ConditionCoverage = fmt.Sprintf("%d%% (%d/%d)", c.covered*100/c.total, c.covered, c.total)
+		}
+		class.Lines = append(class.Lines, line)
+	}
+
+	for _, fn := range f.Functions {
+		method := coberturaMethod{Name: fn.Name, LineRate: coberturaRate(min(fn.Count, 1), 1), BranchRate: 1}
+		if fn.Line > 0 {
+			method.Lines = []coberturaLine{{Number: fn.Line, Hits: fn.Count}}
+		}
+		class.Methods = append(class.Methods, method)
+	}
+
+	return class
+}
+
+// coberturaRate returns a Cobertura rate, a fraction between 0 and 1, counting no data as fully covered
+func coberturaRate(hit, found int64) float64 {
+	if found == 0 {
+		return 1
+	}
+	return float64(hit) / float64(found)
+}
@@ -0,0 +1,66 @@
+package lcov
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseCobertura(t *testing.T) {
+	file, err := os.Open("testdata/cobertura.xml")
+	require.NoError(t, err)
+	defer file.Close()
+
+	summary, err := ParseCobertura(file, WithDetails())
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, summary.TotalFiles)
+	assert.Equal(t, int64(5), summary.TotalLines)
+	assert.Equal(t, int64(3), summary.CoveredLines)
+	assert.Equal(t, int64(1), summary.TotalFunctions)
+	assert.Equal(t, int64(1), summary.CoveredFunctions)
+	assert.Equal(t, int64(2), summary.TotalBranches)
+	assert.Equal(t, int64(1), summary.CoveredBranches)
+
+	require.Len(t, summary.Files, 1)
+	assert.Equal(t, "app/Main.java", summary.Files[0].Path)
+	assert.Equal(t, FunctionData{Name: "main", Line: 3, Count: 1}, summary.Files[0].Functions[0])
+}
+
+func TestParseCoberturaErrors(t *testing.T) {
+	_, err := ParseCobertura(strings.NewReader("<report/>"))
+	assert.ErrorContains(t, err, "invalid Cobertura report")
+
+	input := `<coverage><packages><package><classes><class filename="a.go"><lines>
+<line number="1" hits="1" branch="true" condition-coverage="half"/></lines></class></classes></package></packages></coverage>`
+	_, err = ParseCobertura(strings.NewReader(input))
+	assert.EqualError(t, err, "invalid condition coverage in a.go line 1: half")
+}
+
+func TestWriteCoberturaRoundTrip(t *testing.T) {
+	file, err := os.Open("testdata/functions.lcov")
+	require.NoError(t, err)
+	defer file.Close()
+
+	summary, err := Summarize(file, WithDetails())
+	require.NoError(t, err)
+
+	var out bytes.Buffer
+	require.NoError(t, WriteCobertura(&out, summary))
+	assert.True(t, strings.HasPrefix(out.String(), "<?xml"))
+	assert.Equal(t, FormatCobertura, DetectFormat(out.Bytes()))
+
+	converted, err := ParseCobertura(&out)
+	require.NoError(t, err)
+	assert.Equal(t, summary.TotalFiles, converted.TotalFiles)
+	assert.Equal(t, summary.TotalLines, converted.TotalLines)
+	assert.Equal(t, summary.CoveredLines, converted.CoveredLines)
+	assert.Equal(t, summary.TotalFunctions, converted.TotalFunctions)
+	assert.Equal(t, summary.CoveredFunctions, converted.CoveredFunctions)
+	assert.Equal(t, summary.TotalBranches, converted.TotalBranches)
+	assert.Equal(t, summary.CoveredBranches, converted.CoveredBranches)
+}
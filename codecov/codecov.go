@@ -0,0 +1,150 @@
+// Package codecov uploads LCOV tracefiles to Codecov through its v4 upload API.
+package codecov
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+)
+
+// DefaultURL is the Codecov instance used when none is configured
+const DefaultURL = "https://codecov.io"
+
+// Upload holds the metadata sent along with a report
+type Upload struct {
+	Token    string
+	Commit   string
+	Branch   string
+	PR       string
+	Build    string
+	BuildURL string
+	Service  string
+	Slug     string
+	Flags    string
+}
+
+// FromEnv fills the upload metadata from CODECOV_TOKEN and the environment of common CI services
+func FromEnv() Upload {
+	u := Upload{Token: os.Getenv("CODECOV_TOKEN"), Flags: os.Getenv("CODECOV_FLAGS")}
+
+	switch {
+	case os.Getenv("GITHUB_ACTIONS") == "true":
+		u.Service = "github-actions"
+		u.Commit = os.Getenv("GITHUB_SHA")
+		u.Branch = os.Getenv("GITHUB_HEAD_REF")
+		if u.Branch == "" {
+			u.Branch = os.Getenv("GITHUB_REF_NAME")
+		}
+		if ref := os.Getenv("GITHUB_REF"); strings.HasPrefix(ref, "refs/pull/") {
+			u.PR = strings.Split(strings.TrimPrefix(ref, "refs/pull/"), "/")[0]
+		}
+		u.Build = os.Getenv("GITHUB_RUN_ID")
+		u.Slug = os.Getenv("GITHUB_REPOSITORY")
+		if server := os.Getenv("GITHUB_SERVER_URL"); server != "" && u.Slug != "" && u.Build != "" {
+			u.BuildURL = fmt.Sprintf("%s/%s/actions/runs/%s", server, u.Slug, u.Build)
+		}
+	case os.Getenv("GITLAB_CI") == "true":
+		u.Service = "gitlab"
+		u.Commit = os.Getenv("CI_COMMIT_SHA")
+		u.Branch = os.Getenv("CI_COMMIT_REF_NAME")
+		u.PR = os.Getenv("CI_MERGE_REQUEST_IID")
+		u.Build = os.Getenv("CI_JOB_ID")
+		u.BuildURL = os.Getenv("CI_JOB_URL")
+		u.Slug = os.Getenv("CI_PROJECT_PATH")
+	}
+
+	return u
+}
+
+// WrapReport wraps tracefiles in the envelope expected by the Codecov upload API:
+// an optional list of network files followed by every report, each introduced by its path.
+func WrapReport(network []string, reports map[string][]byte, order []string) []byte {
+	var b bytes.Buffer
+	for _, path := range network {
+		b.WriteString(path + "\n")
+	}
+	b.WriteString("<<<<<< network\n")
+	for _, name := range order {
+		fmt.Fprintf(&b, "# path=%s\n", name)
+		b.Write(reports[name])
+		if n := len(reports[name]); n > 0 && reports[name][n-1] != '\n' {
+			b.WriteByte('\n')
+		}
+		b.WriteString("<<<<<< EOF\n")
+	}
+	return b.Bytes()
+}
+
+// Send uploads a wrapped report. It first requests an upload location from the
+// Codecov instance at baseURL (DefaultURL if empty), then stores the report there,
+// and returns the URL where the processed report will be visible.
+func Send(ctx context.Context, client *http.Client, baseURL string, u Upload, report []byte) (string, error) {
+	if baseURL == "" {
+		baseURL = DefaultURL
+	}
+	if u.Commit == "" {
+		return "", fmt.Errorf("missing commit SHA")
+	}
+
+	query := url.Values{"package": {"go-lcov-summary"}}
+	for key, value := range map[string]string{
+		"token": u.Token, "commit": u.Commit, "branch": u.Branch, "pr": u.PR, "build": u.Build,
+		"build_url": u.BuildURL, "service": u.Service, "slug": u.Slug, "flags": u.Flags,
+	} {
+		if value != "" {
+			query.Set(key, value)
+		}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost,
+		strings.TrimSuffix(baseURL, "/")+"/upload/v4?"+query.Encode(), nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Accept", "text/plain")
+	body, err := do(client, req)
+	if err != nil {
+		return "", err
+	}
+
+	// The response holds the result URL and the storage URL on two lines
+	lines := strings.Split(strings.TrimSpace(string(body)), "\n")
+	if len(lines) != 2 {
+		return "", fmt.Errorf("unexpected codecov response: %s", body)
+	}
+	resultURL, storageURL := strings.TrimSpace(lines[0]), strings.TrimSpace(lines[1])
+
+	req, err = http.NewRequestWithContext(ctx, http.MethodPut, storageURL, bytes.NewReader(report))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "text/plain")
+	if _, err := do(client, req); err != nil {
+		return "", err
+	}
+
+	return resultURL, nil
+}
+
+// do sends a request and returns the response body, failing on non 2xx statuses
+func do(client *http.Client, req *http.Request) ([]byte, error) {
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error uploading to Codecov: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, fmt.Errorf("error uploading to Codecov: %w", err)
+	}
+	if resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("codecov upload failed with status %s: %s", resp.Status, bytes.TrimSpace(body))
+	}
+	return body, nil
+}
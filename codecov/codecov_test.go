@@ -0,0 +1,72 @@
+package codecov
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWrapReport(t *testing.T) {
+	report := WrapReport([]string{"main.go"}, map[string][]byte{"coverage.info": []byte("SF:main.go\nend_of_record")}, []string{"coverage.info"})
+	assert.Equal(t, "main.go\n<<<<<< network\n# path=coverage.info\nSF:main.go\nend_of_record\n<<<<<< EOF\n", string(report))
+}
+
+func TestFromEnv(t *testing.T) {
+	t.Setenv("GITHUB_ACTIONS", "")
+	t.Setenv("GITLAB_CI", "true")
+	t.Setenv("CI_COMMIT_SHA", "abc123")
+	t.Setenv("CI_COMMIT_REF_NAME", "feature")
+	t.Setenv("CI_MERGE_REQUEST_IID", "12")
+	t.Setenv("CI_JOB_ID", "99")
+	t.Setenv("CI_JOB_URL", "https://gitlab.example.com/job/99")
+	t.Setenv("CI_PROJECT_PATH", "group/project")
+	t.Setenv("CODECOV_TOKEN", "secret")
+	t.Setenv("CODECOV_FLAGS", "")
+
+	assert.Equal(t, Upload{
+		Token: "secret", Commit: "abc123", Branch: "feature", PR: "12", Build: "99",
+		BuildURL: "https://gitlab.example.com/job/99", Service: "gitlab", Slug: "group/project",
+	}, FromEnv())
+}
+
+func TestSend(t *testing.T) {
+	var stored string
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	mux.HandleFunc("/upload/v4", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "abc123", r.URL.Query().Get("commit"))
+		assert.Equal(t, "secret", r.URL.Query().Get("token"))
+		io.WriteString(w, "https://codecov.io/result\n"+server.URL+"/storage\n")
+	})
+	mux.HandleFunc("/storage", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPut, r.Method)
+		body, _ := io.ReadAll(r.Body)
+		stored = string(body)
+	})
+
+	result, err := Send(context.Background(), server.Client(), server.URL, Upload{Token: "secret", Commit: "abc123"}, []byte("report"))
+	require.NoError(t, err)
+	assert.Equal(t, "https://codecov.io/result", result)
+	assert.Equal(t, "report", stored)
+}
+
+func TestSendErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "bad token", http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	_, err := Send(context.Background(), server.Client(), server.URL, Upload{}, nil)
+	assert.EqualError(t, err, "missing commit SHA")
+
+	_, err = Send(context.Background(), server.Client(), server.URL, Upload{Commit: "abc"}, nil)
+	assert.EqualError(t, err, "codecov upload failed with status 400 Bad Request: bad token")
+}
@@ -0,0 +1,164 @@
+package lcov
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Unowned is the owner name under which files without any code owner are summarized
+const Unowned = "(unowned)"
+
+// CodeownersRule is a line of a CODEOWNERS file
+type CodeownersRule struct {
+	Pattern string
+	// Owners are the users, teams or emails owning the matching files. A rule
+	// without owners makes the matching files unowned.
+	Owners []string
+}
+
+// Codeowners holds the rules of a CODEOWNERS file, in file order
+type Codeowners struct {
+	Rules []CodeownersRule
+}
+
+// ParseCodeowners parses a CODEOWNERS file, as used by GitHub and GitLab. GitLab
+// sections ('[Section]' lines) are ignored, their rules apply like any other.
+func ParseCodeowners(r io.Reader) (*Codeowners, error) {
+	codeowners := &Codeowners{}
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line, _, _ := strings.Cut(scanner.Text(), "#")
+		fields := strings.Fields(line)
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "[") || strings.HasPrefix(fields[0], "^[") {
+			continue
+		}
+		codeowners.Rules = append(codeowners.Rules, CodeownersRule{Pattern: fields[0], Owners: fields[1:]})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error reading CODEOWNERS: %w", err)
+	}
+	return codeowners, nil
+}
+
+// Owners returns the owners of a repository-relative path: those of the last
+// matching rule, as in GitHub. It returns nil when the path has no owner.
+func (c *Codeowners) Owners(name string) []string {
+	name = strings.TrimPrefix(name, "./")
+	for i := len(c.Rules) - 1; i >= 0; i-- {
+		if c.Rules[i].matches(name) {
+			if len(c.Rules[i].Owners) == 0 {
+				return nil
+			}
+			return c.Rules[i].Owners
+		}
+	}
+	return nil
+}
+
+// matches reports whether the rule applies to a repository-relative path, following
+// the gitignore rules CODEOWNERS patterns are based on: patterns containing a '/'
+// other than a trailing one are relative to the repository root, others match at
+// any depth, and a pattern matching a directory applies to everything below it
+// except for 'dir/*' which only covers the files directly in dir.
+func (r CodeownersRule) matches(name string) bool {
+	pattern := strings.TrimSuffix(r.Pattern, "/")
+	if pattern == "" || pattern == "*" {
+		return true
+	}
+	if strings.Contains(pattern, "/") {
+		pattern = "/" + strings.TrimPrefix(pattern, "/")
+	}
+	if matchGlob(pattern, name) {
+		return true
+	}
+	return !strings.HasSuffix(pattern, "/*") && matchGlob(pattern+"/**", name)
+}
+
+// OwnerSummary is the coverage of the files owned by an owner
+type OwnerSummary struct {
+	Owner string
+	*Summary
+}
+
+// SummarizeByOwner computes the coverage summary of every code owner. File paths
+// are made relative to root, the repository root, before matching the rules. Files
+// with several owners count for each of them, and files without any owner are
+// summarized under Unowned. Owners are sorted by name, Unowned last.
+func SummarizeByOwner(files []FileRecord, codeowners *Codeowners, root string) []OwnerSummary {
+	byOwner := make(map[string][]FileRecord)
+	for _, f := range MergeFiles(files) {
+		relative := strings.TrimPrefix(f.Path, strings.TrimSuffix(root, "/")+"/")
+		owners := codeowners.Owners(relative)
+		if len(owners) == 0 {
+			owners = []string{Unowned}
+		}
+		for _, owner := range owners {
+			byOwner[owner] = append(byOwner[owner], f)
+		}
+	}
+
+	summaries := make([]OwnerSummary, 0, len(byOwner))
+	for owner, owned := range byOwner {
+		summaries = append(summaries, OwnerSummary{Owner: owner, Summary: SummarizeFiles(owned)})
+	}
+	sort.Slice(summaries, func(i, j int) bool {
+		if (summaries[i].Owner == Unowned) != (summaries[j].Owner == Unowned) {
+			return summaries[j].Owner == Unowned
+		}
+		return summaries[i].Owner < summaries[j].Owner
+	})
+	return summaries
+}
+
+// RenderOwners writes an aligned table of the coverage of every owner, in the
+// layout of RenderList
+func RenderOwners(w io.Writer, owners []OwnerSummary, opts ...RenderOption) error {
+	rows := make([]groupRow, len(owners))
+	for i, o := range owners {
+		rows[i] = groupRow{name: o.Owner, summary: o.Summary}
+	}
+	return renderGroups(w, "Owner", rows, opts)
+}
+
+// groupRow is a row of a table of groups of files, such as owners or packages
+type groupRow struct {
+	name    string
+	summary *Summary
+}
+
+// renderGroups writes an aligned table of the number of files and coverage of
+// every group, in the layout of RenderList, under a column named title
+func renderGroups(w io.Writer, title string, rows []groupRow, opts []RenderOption) error {
+	cfg := newRenderConfig(opts)
+	ew := &errWriter{w: w}
+
+	largest := int64(0)
+	width := len(title)
+	for _, r := range rows {
+		largest = max(largest, r.summary.TotalLines, r.summary.TotalFunctions, r.summary.TotalBranches)
+		width = max(width, len(r.name))
+	}
+	table := listTable{
+		rateWidth: len(listRate(cfg, 1, 1)),
+		numWidth:  max(len("Num"), len(strconv.FormatInt(largest, 10))),
+	}
+	cell := table.rateWidth + 1 + table.numWidth
+	filesWidth := max(len("Files"), len(strconv.FormatInt(largest, 10)))
+
+	ew.printf("%-*s|%*s|%-*s|%-*s|%s\n", width, "", filesWidth, "", cell, "Lines", cell, "Functions", "Branches")
+	header := fmt.Sprintf("%-*s %*s", table.rateWidth, "Rate", table.numWidth, "Num")
+	ew.printf("%-*s|%*s|%s|%s|%s\n", width, title, filesWidth, "Files", header, header, header)
+	ew.printf("%s\n", strings.Repeat("=", width+filesWidth+1+3*(cell+1)))
+	for _, r := range rows {
+		s := r.summary
+		ew.printf("%-*s|%*d|%s|%s|%s\n", width, r.name, filesWidth, s.TotalFiles,
+			table.cell(cfg, s.CoveredLines, s.TotalLines),
+			table.cell(cfg, s.CoveredFunctions, s.TotalFunctions),
+			table.cell(cfg, s.CoveredBranches, s.TotalBranches))
+	}
+	return ew.err
+}
@@ -0,0 +1,74 @@
+package lcov
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testCodeowners = `# Default owners
+*                @org/core
+
+/docs/           @org/docs
+*.js             @org/frontend
+pkg/api/         @org/api @alice
+internal/*       @org/platform
+[Generated]
+/gen/
+`
+
+func TestCodeownersOwners(t *testing.T) {
+	codeowners, err := ParseCodeowners(strings.NewReader(testCodeowners))
+	require.NoError(t, err)
+	require.Len(t, codeowners.Rules, 6)
+
+	tests := []struct {
+		path   string
+		owners []string
+	}{
+		{"main.go", []string{"@org/core"}},
+		{"docs/index.md", []string{"@org/docs"}},
+		{"web/docs/index.md", []string{"@org/core"}},
+		{"web/app.js", []string{"@org/frontend"}},
+		{"pkg/api/handler.go", []string{"@org/api", "@alice"}},
+		{"./pkg/api/v1/handler.go", []string{"@org/api", "@alice"}},
+		{"internal/util.go", []string{"@org/platform"}},
+		{"internal/db/conn.go", []string{"@org/core"}},
+		{"gen/types.go", nil},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.owners, codeowners.Owners(tt.path), tt.path)
+	}
+}
+
+func TestSummarizeByOwner(t *testing.T) {
+	codeowners, err := ParseCodeowners(strings.NewReader("pkg/api/ @org/api @alice\n/cmd/ @org/cli\n"))
+	require.NoError(t, err)
+
+	files := []FileRecord{
+		{Path: "/src/repo/pkg/api/api.go", LinesFound: 10, LinesHit: 8},
+		{Path: "/src/repo/cmd/main.go", LinesFound: 4, LinesHit: 1},
+		{Path: "/src/repo/main.go", LinesFound: 2, LinesHit: 2},
+	}
+	owners := SummarizeByOwner(files, codeowners, "/src/repo/")
+	require.Len(t, owners, 4)
+	assert.Equal(t, []string{"@alice", "@org/api", "@org/cli", Unowned},
+		[]string{owners[0].Owner, owners[1].Owner, owners[2].Owner, owners[3].Owner})
+	assert.Equal(t, 80.0, owners[1].LineCoverageRate)
+	assert.Equal(t, 25.0, owners[2].LineCoverageRate)
+	assert.Equal(t, 1, owners[3].TotalFiles)
+
+	var buf bytes.Buffer
+	require.NoError(t, RenderOwners(&buf, owners))
+	assert.Equal(t, ""+
+		"         |     |Lines     |Functions |Branches\n"+
+		"Owner    |Files|Rate   Num|Rate   Num|Rate   Num\n"+
+		"================================================\n"+
+		"@alice   |    1| 80.0%  10|     -   0|     -   0\n"+
+		"@org/api |    1| 80.0%  10|     -   0|     -   0\n"+
+		"@org/cli |    1| 25.0%   4|     -   0|     -   0\n"+
+		"(unowned)|    1|100.0%   2|     -   0|     -   0\n", buf.String())
+}
@@ -0,0 +1,214 @@
+// Package coveralls converts detailed LCOV data to the Coveralls API payload and uploads it.
+package coveralls
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"strings"
+
+	lcov "github.com/shastick/go-lcov-summary"
+)
+
+// DefaultEndpoint is the Coveralls jobs API
+const DefaultEndpoint = "https://coveralls.io/api/v1/jobs"
+
+// maxLines bounds the coverage array of a file whose source isn't read, so that
+// a corrupted DA record, e.g. DA:2000000000, doesn't exhaust the memory
+const maxLines = 1 << 20
+
+// Job identifies the CI job the coverage belongs to
+type Job struct {
+	RepoToken     string
+	ServiceName   string
+	ServiceJobID  string
+	ServiceNumber string
+	PullRequest   string
+	CommitSHA     string
+	Branch        string
+	Parallel      bool
+}
+
+// Payload is the JSON document accepted by the Coveralls jobs API
+type Payload struct {
+	RepoToken          string       `json:"repo_token,omitempty"`
+	ServiceName        string       `json:"service_name,omitempty"`
+	ServiceJobID       string       `json:"service_job_id,omitempty"`
+	ServiceNumber      string       `json:"service_number,omitempty"`
+	ServicePullRequest string       `json:"service_pull_request,omitempty"`
+	Parallel           bool         `json:"parallel,omitempty"`
+	Git                *Git         `json:"git,omitempty"`
+	SourceFiles        []SourceFile `json:"source_files"`
+}
+
+// Git holds the commit information of the payload
+type Git struct {
+	Head   Head   `json:"head"`
+	Branch string `json:"branch,omitempty"`
+}
+
+// Head identifies the commit
+type Head struct {
+	ID string `json:"id"`
+}
+
+// SourceFile is the coverage of a single file
+type SourceFile struct {
+	Name string `json:"name"`
+	// SourceDigest is the MD5 digest of the source, which Coveralls checks
+	// against the file of the repository, empty when the source isn't read
+	SourceDigest string `json:"source_digest,omitempty"`
+	// Coverage holds the hit count of every line, indexed from line 1, nil for lines without code
+	Coverage []*int64 `json:"coverage"`
+	// Branches is a flat list of line, block, branch and hit count quadruplets
+	Branches []int64 `json:"branches,omitempty"`
+}
+
+// JobFromEnv fills a job from the COVERALLS_* variables and the environment of common CI services
+func JobFromEnv() Job {
+	job := Job{RepoToken: os.Getenv("COVERALLS_REPO_TOKEN"), Parallel: os.Getenv("COVERALLS_PARALLEL") == "true"}
+
+	switch {
+	case os.Getenv("GITHUB_ACTIONS") == "true":
+		job.ServiceName = "github"
+		job.ServiceJobID = os.Getenv("GITHUB_RUN_ID")
+		job.ServiceNumber = os.Getenv("GITHUB_RUN_NUMBER")
+		job.CommitSHA = os.Getenv("GITHUB_SHA")
+		job.Branch = os.Getenv("GITHUB_HEAD_REF")
+		if job.Branch == "" {
+			job.Branch = os.Getenv("GITHUB_REF_NAME")
+		}
+		if ref := os.Getenv("GITHUB_REF"); strings.HasPrefix(ref, "refs/pull/") {
+			job.PullRequest = strings.Split(strings.TrimPrefix(ref, "refs/pull/"), "/")[0]
+		}
+	case os.Getenv("GITLAB_CI") == "true":
+		job.ServiceName = "gitlab-ci"
+		job.ServiceJobID = os.Getenv("CI_JOB_ID")
+		job.ServiceNumber = os.Getenv("CI_PIPELINE_IID")
+		job.CommitSHA = os.Getenv("CI_COMMIT_SHA")
+		job.Branch = os.Getenv("CI_COMMIT_REF_NAME")
+		job.PullRequest = os.Getenv("CI_MERGE_REQUEST_IID")
+	}
+
+	if name := os.Getenv("COVERALLS_SERVICE_NAME"); name != "" {
+		job.ServiceName = name
+	}
+	if id := os.Getenv("COVERALLS_SERVICE_JOB_ID"); id != "" {
+		job.ServiceJobID = id
+	}
+	return job
+}
+
+// NewPayload builds the Coveralls payload of detailed file records (see lcov.WithDetails).
+// The root prefix is removed from SF paths, as Coveralls expects repository-relative names.
+// The sources are read with open, if not nil, to fill their digest and size the
+// coverage arrays to their lines; the DA records beyond the end of a source, or
+// beyond a million lines when it isn't found, are left out.
+func NewPayload(files []lcov.FileRecord, root string, job Job, open func(path string) (io.ReadCloser, bool)) (*Payload, error) {
+	payload := &Payload{
+		RepoToken:          job.RepoToken,
+		ServiceName:        job.ServiceName,
+		ServiceJobID:       job.ServiceJobID,
+		ServiceNumber:      job.ServiceNumber,
+		ServicePullRequest: job.PullRequest,
+		Parallel:           job.Parallel,
+		SourceFiles:        []SourceFile{},
+	}
+	if job.CommitSHA != "" {
+		payload.Git = &Git{Head: Head{ID: job.CommitSHA}, Branch: job.Branch}
+	}
+
+	root = strings.TrimSuffix(root, "/")
+	for _, f := range lcov.MergeFiles(files) {
+		name := f.Path
+		if root != "" {
+			name = strings.TrimPrefix(strings.TrimPrefix(name, root), "/")
+		}
+		source := SourceFile{Name: name, Coverage: []*int64{}}
+		lines := maxLines
+		if open != nil {
+			if reader, ok := open(f.Path); ok {
+				data, err := io.ReadAll(reader)
+				reader.Close()
+				if err != nil {
+					return nil, fmt.Errorf("error reading source of %s: %w", f.Path, err)
+				}
+				source.SourceDigest = fmt.Sprintf("%x", md5.Sum(data))
+				lines = countLines(data)
+				source.Coverage = make([]*int64, lines)
+			}
+		}
+
+		for _, l := range f.Lines {
+			if l.Line <= 0 || l.Line > lines {
+				continue
+			}
+			for len(source.Coverage) < l.Line {
+				source.Coverage = append(source.Coverage, nil)
+			}
+			count := l.Count
+			source.Coverage[l.Line-1] = &count
+		}
+		for _, b := range f.Branches {
+			source.Branches = append(source.Branches, int64(b.Line), int64(b.Block), int64(b.Branch), max(b.Taken, 0))
+		}
+
+		payload.SourceFiles = append(payload.SourceFiles, source)
+	}
+
+	return payload, nil
+}
+
+// countLines returns the number of lines of a source, the last one possibly
+// lacking its newline
+func countLines(data []byte) int {
+	lines := bytes.Count(data, []byte("\n"))
+	if len(data) > 0 && data[len(data)-1] != '\n' {
+		lines++
+	}
+	return lines
+}
+
+// Upload posts the payload to the Coveralls jobs API at the given endpoint (DefaultEndpoint if empty)
+func Upload(ctx context.Context, client *http.Client, endpoint string, payload *Payload) error {
+	if endpoint == "" {
+		endpoint = DefaultEndpoint
+	}
+
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	part, err := form.CreateFormFile("json_file", "coveralls.json")
+	if err != nil {
+		return err
+	}
+	if err := json.NewEncoder(part).Encode(payload); err != nil {
+		return err
+	}
+	if err := form.Close(); err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, &body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", form.FormDataContentType())
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("error uploading to Coveralls: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return fmt.Errorf("coveralls upload failed with status %s: %s", resp.Status, bytes.TrimSpace(message))
+	}
+	return nil
+}
@@ -0,0 +1,120 @@
+package coveralls
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	lcov "github.com/shastick/go-lcov-summary"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func summarize(t *testing.T, path string) *lcov.Summary {
+	t.Helper()
+	file, err := os.Open(path)
+	require.NoError(t, err)
+	defer file.Close()
+
+	summary, err := lcov.Summarize(file, lcov.WithDetails())
+	require.NoError(t, err)
+	return summary
+}
+
+func countPtr(i int64) *int64 {
+	return &i
+}
+
+func TestNewPayload(t *testing.T) {
+	summary := summarize(t, "../testdata/concatenated.lcov")
+
+	payload, err := NewPayload(summary.Files, "/path/to/", Job{RepoToken: "secret", ServiceName: "github", CommitSHA: "abc123", Branch: "main"}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "secret", payload.RepoToken)
+	assert.Equal(t, &Git{Head: Head{ID: "abc123"}, Branch: "main"}, payload.Git)
+
+	require.Len(t, payload.SourceFiles, 2)
+	main := payload.SourceFiles[0]
+	assert.Equal(t, "source/main.go", main.Name)
+	assert.Equal(t, []*int64{countPtr(3), countPtr(1), countPtr(0), countPtr(0)}, main.Coverage)
+	assert.Equal(t, []int64{2, 0, 0, 1, 2, 0, 1, 0}, main.Branches)
+}
+
+func TestNewPayloadSources(t *testing.T) {
+	files := []lcov.FileRecord{
+		{Path: "/repo/a.go", Lines: []lcov.LineData{{Line: 1, Count: 2}, {Line: 4, Count: 1}}},
+		{Path: "/repo/b.go", Lines: []lcov.LineData{{Line: 1, Count: 1}, {Line: 2000000000, Count: 1}}},
+	}
+	open := func(path string) (io.ReadCloser, bool) {
+		if path != "/repo/a.go" {
+			return nil, false
+		}
+		return io.NopCloser(strings.NewReader("package a\n\nfunc A() {}")), true
+	}
+
+	payload, err := NewPayload(files, "/repo", Job{}, open)
+	require.NoError(t, err)
+	require.Len(t, payload.SourceFiles, 2)
+
+	// The coverage array spans the source, without the record beyond its end
+	a := payload.SourceFiles[0]
+	assert.Equal(t, fmt.Sprintf("%x", md5.Sum([]byte("package a\n\nfunc A() {}"))), a.SourceDigest)
+	assert.Equal(t, []*int64{countPtr(2), nil, nil}, a.Coverage)
+
+	// Without its source, a file is bounded all the same
+	b := payload.SourceFiles[1]
+	assert.Empty(t, b.SourceDigest)
+	assert.Equal(t, []*int64{countPtr(1)}, b.Coverage)
+}
+
+func TestJobFromEnv(t *testing.T) {
+	t.Setenv("GITLAB_CI", "")
+	t.Setenv("GITHUB_ACTIONS", "true")
+	t.Setenv("GITHUB_RUN_ID", "42")
+	t.Setenv("GITHUB_SHA", "abc123")
+	t.Setenv("GITHUB_HEAD_REF", "feature")
+	t.Setenv("GITHUB_REF", "refs/pull/7/merge")
+	t.Setenv("COVERALLS_REPO_TOKEN", "secret")
+	t.Setenv("COVERALLS_SERVICE_NAME", "")
+	t.Setenv("COVERALLS_SERVICE_JOB_ID", "")
+
+	job := JobFromEnv()
+	assert.Equal(t, "github", job.ServiceName)
+	assert.Equal(t, "42", job.ServiceJobID)
+	assert.Equal(t, "abc123", job.CommitSHA)
+	assert.Equal(t, "feature", job.Branch)
+	assert.Equal(t, "7", job.PullRequest)
+	assert.Equal(t, "secret", job.RepoToken)
+}
+
+func TestUpload(t *testing.T) {
+	var received Payload
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		file, _, err := r.FormFile("json_file")
+		require.NoError(t, err)
+		require.NoError(t, json.NewDecoder(file).Decode(&received))
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	payload := &Payload{RepoToken: "secret", SourceFiles: []SourceFile{{Name: "main.go", Coverage: []*int64{nil, countPtr(1)}}}}
+	require.NoError(t, Upload(context.Background(), server.Client(), server.URL, payload))
+	assert.Equal(t, *payload, received)
+}
+
+func TestUploadError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "invalid token", http.StatusUnprocessableEntity)
+	}))
+	defer server.Close()
+
+	err := Upload(context.Background(), server.Client(), server.URL, &Payload{})
+	assert.EqualError(t, err, "coveralls upload failed with status 422 Unprocessable Entity: invalid token")
+}
@@ -0,0 +1,164 @@
+package lcov
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Go coverprofile modes
+const (
+	CoverModeSet   = "set"
+	CoverModeCount = "count"
+)
+
+// CoverprofileOption configures how a coverprofile is written
+type CoverprofileOption func(*coverprofileConfig)
+
+type coverprofileConfig struct {
+	mode        string
+	prefix      string
+	replacement string
+}
+
+// WithCoverMode sets the coverprofile mode, CoverModeSet (default) or CoverModeCount
+func WithCoverMode(mode string) CoverprofileOption {
+	return func(c *coverprofileConfig) {
+		c.mode = mode
+	}
+}
+
+// WithPathMapping replaces the given prefix of SF paths, typically the local
+// module root, with a replacement such as the module's import path.
+func WithPathMapping(prefix, replacement string) CoverprofileOption {
+	return func(c *coverprofileConfig) {
+		c.prefix = prefix
+		c.replacement = replacement
+	}
+}
+
+// WriteCoverprofile converts detailed file records (see WithDetails) to the Go
+// coverprofile format, so they can be fed to tools that only understand it.
+// Every DA record becomes a single-statement block spanning its line.
+func WriteCoverprofile(w io.Writer, files []FileRecord, opts ...CoverprofileOption) error {
+	cfg := &coverprofileConfig{mode: CoverModeSet}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	if cfg.mode != CoverModeSet && cfg.mode != CoverModeCount {
+		return fmt.Errorf("unsupported cover mode: %s", cfg.mode)
+	}
+
+	for i := range files {
+		if files[i].LinesFound > 0 && len(files[i].Lines) == 0 {
+			return fmt.Errorf("no line data for %s: the tracefile must be parsed WithDetails", files[i].Path)
+		}
+	}
+
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "mode: %s\n", cfg.mode)
+
+	// The same file may appear in several records; profile blocks must be unique
+	for _, f := range MergeFiles(files) {
+		path := f.Path
+		if cfg.prefix != "" && strings.HasPrefix(path, cfg.prefix) {
+			path = cfg.replacement + strings.TrimPrefix(path, cfg.prefix)
+		}
+
+		for _, l := range f.Lines {
+			count := l.Count
+			if cfg.mode == CoverModeSet && count > 0 {
+				count = 1
+			}
+			fmt.Fprintf(bw, "%s:%d.1,%d.0 1 %d\n", path, l.Line, l.Line+1, count)
+		}
+	}
+
+	return bw.Flush()
+}
+
+// ParseCoverprofile summarizes a Go coverprofile ('go test -coverprofile').
+// Every line spanned by a block gets the highest execution count of the blocks
+// covering it, and the profile mode line is ignored. Paths are kept as found in
+// the profile, i.e. import paths. The parser options apply as for LCOV data.
+// The status lines of 'go test' are skipped, so that the output of
+// 'go test -coverprofile=/dev/stdout' can be parsed directly.
+func ParseCoverprofile(reader io.Reader, opts ...Option) (*Summary, error) {
+	p := newParser(opts)
+	reader = p.track(reader)
+
+	counts := make(map[string]map[int]int64)
+	var order []string
+
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 0, 64*1024), 10*1024*1024)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "mode:") || isGoTestOutput(line) {
+			continue
+		}
+
+		// path/to/file.go:startLine.startCol,endLine.endCol numStmts count
+		var path string
+		var startLine, startCol, endLine, endCol, statements int
+		var count int64
+		i := strings.LastIndexByte(line, ':')
+		if i < 0 {
+			return nil, fmt.Errorf("invalid coverprofile line %d: %s", lineNumber, line)
+		}
+		path = line[:i]
+		if _, err := fmt.Sscanf(line[i+1:], "%d.%d,%d.%d %d %d",
+			&startLine, &startCol, &endLine, &endCol, &statements, &count); err != nil || endLine < startLine {
+			return nil, fmt.Errorf("invalid coverprofile line %d: %s", lineNumber, line)
+		}
+		if statements == 0 {
+			continue
+		}
+
+		lines, ok := counts[path]
+		if !ok {
+			lines = make(map[int]int64)
+			counts[path] = lines
+			order = append(order, path)
+		}
+		// A block ending at column 1 doesn't cover its last line
+		if endCol <= 1 && endLine > startLine {
+			endLine--
+		}
+		for l := startLine; l <= endLine; l++ {
+			if previous, ok := lines[l]; !ok || count > previous {
+				lines[l] = count
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error reading coverprofile: %w", err)
+	}
+
+	files := newFileSet(p.duplicates)
+	for _, path := range order {
+		f := &FileRecord{Path: path}
+		for line, count := range counts[path] {
+			f.Lines = append(f.Lines, LineData{Line: line, Count: count})
+		}
+		f.sortDetails()
+		f.recount()
+		files.add(f)
+	}
+
+	return p.summarize(&Summary{}, files)
+}
+
+// goTestOutputPrefixes start the status lines 'go test' prints along with the profile
+var goTestOutputPrefixes = []string{"ok ", "ok\t", "?", "PASS", "FAIL", "coverage:", "=== ", "--- "}
+
+// isGoTestOutput reports whether a line is a status line of 'go test' rather than a profile block
+func isGoTestOutput(line string) bool {
+	for _, prefix := range goTestOutputPrefixes {
+		if strings.HasPrefix(line, prefix) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,127 @@
+package lcov
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteCoverprofile(t *testing.T) {
+	file, err := os.Open("testdata/concatenated.lcov")
+	require.NoError(t, err)
+	defer file.Close()
+
+	summary, err := Summarize(file, WithDetails())
+	require.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		opts     []CoverprofileOption
+		expected string
+	}{
+		{
+			name: "set",
+			expected: `mode: set
+/path/to/source/main.go:1.1,2.0 1 1
+/path/to/source/main.go:2.1,3.0 1 1
+/path/to/source/main.go:3.1,4.0 1 0
+/path/to/source/main.go:4.1,5.0 1 0
+/path/to/source/utils.go:1.1,2.0 1 1
+/path/to/source/utils.go:2.1,3.0 1 1
+`,
+		},
+		{
+			name: "count with path mapping",
+			opts: []CoverprofileOption{WithCoverMode(CoverModeCount), WithPathMapping("/path/to", "example.com/mod")},
+			expected: `mode: count
+example.com/mod/source/main.go:1.1,2.0 1 3
+example.com/mod/source/main.go:2.1,3.0 1 1
+example.com/mod/source/main.go:3.1,4.0 1 0
+example.com/mod/source/main.go:4.1,5.0 1 0
+example.com/mod/source/utils.go:1.1,2.0 1 1
+example.com/mod/source/utils.go:2.1,3.0 1 1
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			require.NoError(t, WriteCoverprofile(&out, summary.Files, tt.opts...))
+			assert.Equal(t, tt.expected, out.String())
+		})
+	}
+}
+
+func TestWriteCoverprofileErrors(t *testing.T) {
+	var out bytes.Buffer
+	err := WriteCoverprofile(&out, nil, WithCoverMode("atomic!"))
+	assert.EqualError(t, err, "unsupported cover mode: atomic!")
+
+	err = WriteCoverprofile(&out, []FileRecord{{Path: "main.go", LinesFound: 3}})
+	assert.EqualError(t, err, "no line data for main.go: the tracefile must be parsed WithDetails")
+}
+
+func TestParseCoverprofile(t *testing.T) {
+	file, err := os.Open("testdata/coverage.out")
+	require.NoError(t, err)
+	defer file.Close()
+
+	summary, err := ParseCoverprofile(file, WithDetails())
+	require.NoError(t, err)
+
+	assert.Equal(t, 2, summary.TotalFiles)
+	assert.Equal(t, int64(10), summary.TotalLines)
+	assert.Equal(t, int64(6), summary.CoveredLines)
+	assert.Equal(t, "example.com/mod/main.go", summary.Files[0].Path)
+	// Line 8 is both the end of an uncovered block and the start of a covered one
+	assert.Equal(t, []LineData{
+		{Line: 3, Count: 4}, {Line: 4, Count: 4}, {Line: 5, Count: 4},
+		{Line: 7, Count: 0}, {Line: 8, Count: 2}, {Line: 9, Count: 2}, {Line: 10, Count: 2},
+	}, summary.Files[0].Lines)
+}
+
+func TestCoverprofileRoundTrip(t *testing.T) {
+	file, err := os.Open("testdata/with_functions_and_branches.lcov")
+	require.NoError(t, err)
+	defer file.Close()
+
+	original, err := Summarize(file)
+	require.NoError(t, err)
+	_, err = file.Seek(0, 0)
+	require.NoError(t, err)
+	detailed, err := Summarize(file, WithDetails())
+	require.NoError(t, err)
+
+	var out bytes.Buffer
+	require.NoError(t, WriteCoverprofile(&out, detailed.Files))
+	converted, err := ParseCoverprofile(&out)
+	require.NoError(t, err)
+	assert.Equal(t, original.TotalLines, converted.TotalLines)
+	assert.Equal(t, original.CoveredLines, converted.CoveredLines)
+}
+
+func TestParseCoverprofileErrors(t *testing.T) {
+	for _, input := range []string{"mode: set\nno colon", "mode: set\nmain.go:1.1,x 1 1", "mode: set\nmain.go:5.1,2.1 1 1"} {
+		_, err := ParseCoverprofile(strings.NewReader(input))
+		assert.ErrorContains(t, err, "invalid coverprofile line 2")
+	}
+}
+
+func TestParseCoverprofileGoTestOutput(t *testing.T) {
+	output := "ok  \texample.com/a\t0.010s\tcoverage: 50.0% of statements\n" +
+		"mode: set\n" +
+		"example.com/a/a.go:3.10,5.2 2 1\n" +
+		"ok  \texample.com/b\t0.012s\tcoverage: 0.0% of statements\n" +
+		"?   \texample.com/c\t[no test files]\n"
+
+	assert.Equal(t, FormatCoverprofile, DetectFormat([]byte(output)))
+	summary, err := ParseCoverprofile(strings.NewReader(output))
+	require.NoError(t, err)
+	assert.Equal(t, 1, summary.TotalFiles)
+	assert.Equal(t, int64(3), summary.TotalLines)
+}
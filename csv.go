@@ -0,0 +1,35 @@
+package lcov
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+)
+
+func init() {
+	RegisterRenderer("csv", RendererFunc(RenderCSV))
+}
+
+// RenderCSV writes the summary as CSV, with a header and one row per metric:
+// metric,covered,total,rate. The rate is left empty for metrics without data.
+func RenderCSV(w io.Writer, s *Summary) error {
+	cw := csv.NewWriter(w)
+
+	rows := [][]string{{"metric", "covered", "total", "rate"}}
+	for _, m := range []struct {
+		name       string
+		hit, found int64
+	}{
+		{"lines", s.CoveredLines, s.TotalLines},
+		{"functions", s.CoveredFunctions, s.TotalFunctions},
+		{"branches", s.CoveredBranches, s.TotalBranches},
+	} {
+		var rateValue string
+		if r, ok := rate(m.hit, m.found); ok {
+			rateValue = strconv.FormatFloat(r, 'f', -1, 64)
+		}
+		rows = append(rows, []string{m.name, strconv.FormatInt(m.hit, 10), strconv.FormatInt(m.found, 10), rateValue})
+	}
+
+	return cw.WriteAll(rows)
+}
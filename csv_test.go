@@ -0,0 +1,21 @@
+package lcov
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRenderCSV(t *testing.T) {
+	summary := &Summary{TotalLines: 10, CoveredLines: 7, TotalFunctions: 3, CoveredFunctions: 1}
+
+	var out bytes.Buffer
+	require.NoError(t, RenderCSV(&out, summary))
+	assert.Equal(t, `metric,covered,total,rate
+lines,7,10,70
+functions,1,3,33.33333333333333
+branches,0,0,
+`, out.String())
+}
@@ -0,0 +1,114 @@
+package lcov
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+)
+
+// Decoder reads the records of LCOV data one at a time, with their values
+// parsed, for programs building their own aggregations. Unlike Parser, it
+// doesn't group records by source file nor check their consistency.
+type Decoder struct {
+	scanner *bufio.Scanner
+	// parser validates the record values
+	parser *Parser
+	// line is the number of the last line read
+	line int
+	err  error
+}
+
+// NewDecoder returns a decoder reading from r
+func NewDecoder(r io.Reader) *Decoder {
+	return &Decoder{scanner: bufio.NewScanner(r), parser: newParser(nil)}
+}
+
+// Line returns the number of the line of the last record returned by Next,
+// starting at 1
+func (d *Decoder) Line() int {
+	return d.line
+}
+
+// Next returns the next record, setting its Data to the parsed value:
+//
+//   - a string for TN and SF
+//   - LineData for DA
+//   - FunctionData for FN, and for FNDA without its line
+//   - BranchData for BRDA
+//   - int64 for the LF, LH, FNF, FNH, BRF and BRH counters
+//   - nil for end_of_record and unknown record types
+//
+// Blank lines are skipped. An invalid line returns a *DecodeError with its line
+// number, and the following call continues with the next line. At the end
+// of the input, Next returns io.EOF, or the error that ended reading.
+func (d *Decoder) Next() (Record, error) {
+	if d.err != nil {
+		return Record{}, d.err
+	}
+	for d.scanner.Scan() {
+		d.line++
+		line := bytes.TrimSpace(d.scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		record, err := d.decode(line)
+		if err != nil {
+			return Record{}, &DecodeError{Line: d.line, Err: err}
+		}
+		return record, nil
+	}
+	d.err = io.EOF
+	if err := d.scanner.Err(); err != nil {
+		d.err = fmt.Errorf("error reading LCOV data: %w", err)
+	}
+	return Record{}, d.err
+}
+
+// decode parses a non-blank line
+func (d *Decoder) decode(line []byte) (Record, error) {
+	recordType, value, err := d.parser.parseRecord(line)
+	if err != nil {
+		return Record{}, err
+	}
+	record := Record{Type: recordType, Value: string(value)}
+
+	switch recordType {
+	case RecordTestName, RecordSourceFile:
+		record.Data = record.Value
+
+	case RecordLineData:
+		if !d.parser.isValidLineData(value) {
+			return Record{}, fmt.Errorf("%w: %s", ErrInvalidLineData, value)
+		}
+		record.Data = parseLineData(value)
+
+	case RecordFunctionName:
+		if !d.parser.isValidFunctionName(value) {
+			return Record{}, fmt.Errorf("%w: %s", ErrInvalidFunctionName, value)
+		}
+		record.Data = parseFunctionName(value)
+
+	case RecordFunctionData:
+		count, name, found := bytes.Cut(value, []byte{','})
+		n, ok := parseCount(count)
+		if !found || !ok || len(name) == 0 || bytes.IndexByte(name, ',') >= 0 {
+			return Record{}, fmt.Errorf("%w: %s", ErrInvalidFunctionData, value)
+		}
+		record.Data = FunctionData{Name: string(name), Count: n}
+
+	case RecordBranchData:
+		if !d.parser.isValidBranchData(value) {
+			return Record{}, fmt.Errorf("%w: %s", ErrInvalidBranchData, value)
+		}
+		record.Data = parseBranchData(value)
+
+	case RecordLinesFound, RecordLinesHit, RecordFunctionsFound, RecordFunctionsHit, RecordBranchFound, RecordBranchHit:
+		n, ok := atoi64(value)
+		if !ok {
+			return Record{}, errorOf(ErrInvalidCounter, "invalid %s value: %s", recordType, value)
+		}
+		record.Data = n
+	}
+	return record, nil
+}
@@ -0,0 +1,83 @@
+package lcov
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecoder(t *testing.T) {
+	input := `TN:unit
+SF:src/a.c
+
+FN:3,main
+FNDA:1,main
+DA:3,4294967296
+BRDA:4,0,1,-
+LF:1
+LH:1
+VER:2
+end_of_record
+`
+	d := NewDecoder(strings.NewReader(input))
+	var records []Record
+	var lines []int
+	for {
+		record, err := d.Next()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		records = append(records, record)
+		lines = append(lines, d.Line())
+	}
+	assert.Equal(t, []Record{
+		{Type: RecordTestName, Value: "unit", Data: "unit"},
+		{Type: RecordSourceFile, Value: "src/a.c", Data: "src/a.c"},
+		{Type: RecordFunctionName, Value: "3,main", Data: FunctionData{Name: "main", Line: 3}},
+		{Type: RecordFunctionData, Value: "1,main", Data: FunctionData{Name: "main", Count: 1}},
+		{Type: RecordLineData, Value: "3,4294967296", Data: LineData{Line: 3, Count: 4294967296}},
+		{Type: RecordBranchData, Value: "4,0,1,-", Data: BranchData{Line: 4, Block: 0, Branch: 1, Taken: -1}},
+		{Type: RecordLinesFound, Value: "1", Data: int64(1)},
+		{Type: RecordLinesHit, Value: "1", Data: int64(1)},
+		{Type: "VER", Value: "2"},
+		{Type: RecordEndOfRecord},
+	}, records)
+	assert.Equal(t, []int{1, 2, 4, 5, 6, 7, 8, 9, 10, 11}, lines)
+
+	// The end of the input is sticky
+	_, err := d.Next()
+	assert.Equal(t, io.EOF, err)
+}
+
+func TestDecoderErrors(t *testing.T) {
+	d := NewDecoder(strings.NewReader("SF:a.c\nDA:x,1\nFNDA:1\nBRF:many\ngarbage\nDA:1,1\n"))
+	_, err := d.Next()
+	require.NoError(t, err)
+
+	// Decoding continues after invalid lines
+	for _, expected := range []string{
+		"line 2: invalid line data format: x,1",
+		"line 3: invalid function data format: 1",
+		"line 4: invalid BRF value: many",
+		"line 5: invalid record format: garbage",
+	} {
+		_, err = d.Next()
+		assert.EqualError(t, err, expected)
+	}
+	record, err := d.Next()
+	require.NoError(t, err)
+	assert.Equal(t, LineData{Line: 1, Count: 1}, record.Data)
+
+	readErr := errors.New("connection reset")
+	d = NewDecoder(iotest.ErrReader(readErr))
+	_, err = d.Next()
+	assert.ErrorIs(t, err, readErr)
+	_, err = d.Next()
+	assert.ErrorIs(t, err, readErr)
+}
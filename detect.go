@@ -0,0 +1,139 @@
+package lcov
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"regexp"
+)
+
+// Format identifies a supported coverage input format
+type Format string
+
+// Supported input formats
+const (
+	FormatLCOV         Format = "lcov"
+	FormatCoverprofile Format = "coverprofile"
+	FormatCobertura    Format = "cobertura"
+	FormatJaCoCo       Format = "jacoco"
+	FormatClover       Format = "clover"
+	FormatGcov         Format = "gcov"
+	FormatIstanbul     Format = "istanbul"
+)
+
+// sniffSize is the number of leading bytes inspected to detect the input format
+const sniffSize = 8192
+
+var (
+	gcovHeader   = regexp.MustCompile(`^\s*-:\s*0:(Source|Graph|Data|Runs):`)
+	xmlRootStart = regexp.MustCompile(`<([A-Za-z][\w.-]*)[\s>/]`)
+)
+
+// DetectFormat guesses the format of coverage data from its first bytes.
+// Data that isn't recognized as any other format is assumed to be LCOV.
+func DetectFormat(head []byte) Format {
+	head = bytes.TrimPrefix(head, []byte("\xef\xbb\xbf"))
+	trimmed := bytes.TrimSpace(head)
+
+	switch {
+	// 'go test' may print status lines before the profile
+	case bytes.HasPrefix(trimmed, []byte("mode:")), bytes.Contains(head, []byte("\nmode: ")):
+		return FormatCoverprofile
+
+	case bytes.HasPrefix(trimmed, []byte("{")):
+		return FormatIstanbul
+
+	case bytes.HasPrefix(trimmed, []byte("<")):
+		return detectXMLFormat(trimmed)
+
+	case gcovHeader.Match(head):
+		return FormatGcov
+	}
+	return FormatLCOV
+}
+
+// detectXMLFormat distinguishes the XML formats by their root element and doctype
+func detectXMLFormat(head []byte) Format {
+	if bytes.Contains(head, []byte("JACOCO")) {
+		return FormatJaCoCo
+	}
+
+	// Skip the prolog: declaration, comments and doctype
+	body := head
+	for {
+		body = bytes.TrimSpace(body)
+		if !bytes.HasPrefix(body, []byte("<?")) && !bytes.HasPrefix(body, []byte("<!")) {
+			break
+		}
+		end := bytes.IndexByte(body, '>')
+		if end < 0 {
+			break
+		}
+		body = body[end+1:]
+	}
+
+	root := xmlRootStart.FindSubmatch(body)
+	if root == nil {
+		return FormatLCOV
+	}
+	switch string(root[1]) {
+	case "report":
+		return FormatJaCoCo
+	case "coverage":
+		// Clover and Cobertura share their root element name
+		if bytes.Contains(body, []byte("<project")) {
+			return FormatClover
+		}
+		return FormatCobertura
+	}
+	return FormatLCOV
+}
+
+// ParseFormat summarizes coverage data of the given format
+func ParseFormat(format Format, reader io.Reader, opts ...Option) (*Summary, error) {
+	switch format {
+	case FormatLCOV:
+		return Summarize(reader, opts...)
+	case FormatCoverprofile:
+		return ParseCoverprofile(reader, opts...)
+	case FormatCobertura:
+		return ParseCobertura(reader, opts...)
+	case FormatJaCoCo:
+		return ParseJaCoCo(reader, opts...)
+	case FormatClover:
+		return ParseClover(reader, opts...)
+	case FormatGcov:
+		return ParseGcov(reader, opts...)
+	case FormatIstanbul:
+		return ParseIstanbul(reader, opts...)
+	}
+	return nil, fmt.Errorf("unsupported input format: %s", format)
+}
+
+// WriteFormat writes detailed file records in the given format. LCOV, Go
+// coverprofile and Cobertura data can be written.
+func WriteFormat(format Format, w io.Writer, files []FileRecord) error {
+	switch format {
+	case FormatLCOV:
+		return WriteLCOV(w, files)
+	case FormatCoverprofile:
+		return WriteCoverprofile(w, files, WithCoverMode(CoverModeCount))
+	case FormatCobertura:
+		return WriteCobertura(w, SummarizeFiles(files))
+	}
+	return fmt.Errorf("unsupported output format: %s", format)
+}
+
+// SummarizeAny detects the format of the coverage data and summarizes it with the matching parser
+func SummarizeAny(reader io.Reader, opts ...Option) (*Summary, Format, error) {
+	buffered := bufio.NewReaderSize(reader, sniffSize)
+	head, err := buffered.Peek(sniffSize)
+	if err != nil && err != io.EOF && err != bufio.ErrBufferFull {
+		return nil, "", fmt.Errorf("error reading coverage data: %w", err)
+	}
+
+	format := DetectFormat(head)
+	summary, err := ParseFormat(format, buffered, opts...)
+	return summary, format, err
+}
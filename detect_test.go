@@ -0,0 +1,80 @@
+package lcov
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSummarizeAny(t *testing.T) {
+	tests := []struct {
+		path   string
+		format Format
+		files  int
+		lines  int64
+	}{
+		{path: "testdata/sample.lcov", format: FormatLCOV, files: 2, lines: 9},
+		{path: "testdata/coverage.out", format: FormatCoverprofile, files: 2, lines: 10},
+		{path: "testdata/cobertura.xml", format: FormatCobertura, files: 1, lines: 5},
+		{path: "testdata/jacoco.xml", format: FormatJaCoCo, files: 2, lines: 6},
+		{path: "testdata/clover.xml", format: FormatClover, files: 2, lines: 4},
+		{path: "testdata/sample.gcov", format: FormatGcov, files: 1, lines: 5},
+		{path: "testdata/istanbul.json", format: FormatIstanbul, files: 1, lines: 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.format), func(t *testing.T) {
+			file, err := os.Open(tt.path)
+			require.NoError(t, err)
+			defer file.Close()
+
+			summary, format, err := SummarizeAny(file)
+			require.NoError(t, err)
+			assert.Equal(t, tt.format, format)
+			assert.Equal(t, tt.files, summary.TotalFiles)
+			assert.Equal(t, tt.lines, summary.TotalLines)
+		})
+	}
+}
+
+func TestDetectFormat(t *testing.T) {
+	assert.Equal(t, FormatLCOV, DetectFormat([]byte("TN:\nSF:main.go\n")))
+	assert.Equal(t, FormatLCOV, DetectFormat(nil))
+	assert.Equal(t, FormatCoverprofile, DetectFormat([]byte("\xef\xbb\xbfmode: set\n")))
+	assert.Equal(t, FormatJaCoCo, DetectFormat([]byte("<?xml version=\"1.0\"?><report name=\"x\">")))
+	assert.Equal(t, FormatLCOV, DetectFormat([]byte("<html>")))
+}
+
+func TestParseFormatUnsupported(t *testing.T) {
+	_, err := ParseFormat("xlsx", strings.NewReader(""))
+	assert.EqualError(t, err, "unsupported input format: xlsx")
+}
+
+func TestWriteFormat(t *testing.T) {
+	file, err := os.Open("testdata/functions.lcov")
+	require.NoError(t, err)
+	defer file.Close()
+
+	original, err := Summarize(file, WithDetails())
+	require.NoError(t, err)
+
+	for _, format := range []Format{FormatLCOV, FormatCoverprofile, FormatCobertura} {
+		t.Run(string(format), func(t *testing.T) {
+			var out bytes.Buffer
+			require.NoError(t, WriteFormat(format, &out, original.Files))
+
+			converted, detected, err := SummarizeAny(&out)
+			require.NoError(t, err)
+			assert.Equal(t, format, detected)
+			assert.Equal(t, original.TotalLines, converted.TotalLines)
+			assert.Equal(t, original.CoveredLines, converted.CoveredLines)
+		})
+	}
+
+	assert.EqualError(t, WriteFormat(FormatJaCoCo, io.Discard, nil), "unsupported output format: jacoco")
+}
@@ -0,0 +1,38 @@
+package lcov
+
+import "io"
+
+func init() {
+	RegisterRenderer("flycheck", RendererFunc(RenderDiagnostics))
+}
+
+// RenderDiagnostics writes one 'file:line:col: warning: message' diagnostic per
+// uncovered line and never executed function, the format understood by Emacs'
+// flycheck and compilation-mode, vim's quickfix and generic LSP diagnostic converters.
+// The summary must have been parsed WithDetails, otherwise nothing is written.
+func RenderDiagnostics(w io.Writer, s *Summary) error {
+	ew := &errWriter{w: w}
+
+	for _, f := range MergeFiles(s.Files) {
+		functions := f.Functions
+		for _, l := range f.Lines {
+			// Report functions at their position among the uncovered lines
+			for len(functions) > 0 && functions[0].Line <= l.Line {
+				if functions[0].Count == 0 && functions[0].Line > 0 {
+					ew.printf("%s:%d:1: warning: function %s never executed\n", f.Path, functions[0].Line, functions[0].Name)
+				}
+				functions = functions[1:]
+			}
+			if l.Count == 0 {
+				ew.printf("%s:%d:1: warning: uncovered line\n", f.Path, l.Line)
+			}
+		}
+		for _, fn := range functions {
+			if fn.Count == 0 && fn.Line > 0 {
+				ew.printf("%s:%d:1: warning: function %s never executed\n", f.Path, fn.Line, fn.Name)
+			}
+		}
+	}
+
+	return ew.err
+}
@@ -0,0 +1,33 @@
+package lcov
+
+import (
+	"bytes"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRenderDiagnostics(t *testing.T) {
+	file, err := os.Open("testdata/with_functions_and_branches.lcov")
+	require.NoError(t, err)
+	defer file.Close()
+
+	summary, err := Summarize(file, WithDetails())
+	require.NoError(t, err)
+
+	var out bytes.Buffer
+	require.NoError(t, RenderDiagnostics(&out, summary))
+	assert.Equal(t, `/path/to/source/main.go:3:1: warning: uncovered line
+/path/to/source/main.go:5:1: warning: function helper never executed
+/path/to/source/main.go:5:1: warning: uncovered line
+/path/to/source/utils.go:3:1: warning: uncovered line
+`, out.String())
+
+	renderer, ok := LookupRenderer("flycheck")
+	require.True(t, ok)
+	out.Reset()
+	require.NoError(t, renderer.Render(&out, &Summary{TotalLines: 3}))
+	assert.Empty(t, out.String())
+}
@@ -0,0 +1,126 @@
+package lcov
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// DiffFile holds the hunks of a single file in a unified diff
+type DiffFile struct {
+	// Path is the path of the file after the change, with any 'b/' prefix removed
+	Path  string
+	Hunks []DiffHunk
+}
+
+// DiffHunk represents a single hunk of a unified diff
+type DiffHunk struct {
+	// Header is the full '@@ -a,b +c,d @@ context' line
+	Header   string
+	NewStart int
+	NewLines int
+	Added    []DiffLine
+}
+
+// DiffLine is an added line, numbered in the new version of the file
+type DiffLine struct {
+	Line int
+	Text string
+}
+
+// ParseUnifiedDiff parses the output of 'git diff' (or any unified diff) and returns
+// the added lines of every modified or created file. Deleted files are skipped.
+func ParseUnifiedDiff(reader io.Reader) ([]DiffFile, error) {
+	var files []DiffFile
+	var current *DiffFile
+	var hunk *DiffHunk
+	var newLine int
+
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 0, 64*1024), 10*1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+
+		switch {
+		case strings.HasPrefix(line, "diff "):
+			current, hunk = nil, nil
+
+		case strings.HasPrefix(line, "+++ ") && (hunk == nil || hunk.done(newLine)):
+			current, hunk = nil, nil
+			path := strings.TrimPrefix(line, "+++ ")
+			if i := strings.IndexByte(path, '\t'); i >= 0 {
+				path = path[:i]
+			}
+			if path == "/dev/null" {
+				continue
+			}
+			files = append(files, DiffFile{Path: strings.TrimPrefix(path, "b/")})
+			current = &files[len(files)-1]
+
+		case strings.HasPrefix(line, "@@ "):
+			if current == nil {
+				continue
+			}
+			start, count, err := parseHunkHeader(line)
+			if err != nil {
+				return nil, err
+			}
+			current.Hunks = append(current.Hunks, DiffHunk{Header: line, NewStart: start, NewLines: count})
+			hunk = &current.Hunks[len(current.Hunks)-1]
+			newLine = start
+
+		case hunk != nil && strings.HasPrefix(line, "+"):
+			hunk.Added = append(hunk.Added, DiffLine{Line: newLine, Text: line[1:]})
+			newLine++
+
+		case hunk != nil && (strings.HasPrefix(line, " ") || line == ""):
+			newLine++
+
+			// Removed lines ('-') and '\ No newline at end of file' don't advance the new file
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error reading diff: %w", err)
+	}
+	return files, nil
+}
+
+// done reports whether all the lines announced by the hunk header have been read
+func (h *DiffHunk) done(nextLine int) bool {
+	return nextLine >= h.NewStart+h.NewLines
+}
+
+// parseHunkHeader extracts the new file range from a '@@ -a,b +c,d @@' header
+func parseHunkHeader(header string) (int, int, error) {
+	fields := strings.Fields(header)
+	if len(fields) < 3 || !strings.HasPrefix(fields[2], "+") {
+		return 0, 0, fmt.Errorf("invalid hunk header: %s", header)
+	}
+
+	rng := strings.TrimPrefix(fields[2], "+")
+	count := 1
+	if i := strings.IndexByte(rng, ','); i >= 0 {
+		var err error
+		if count, err = strconv.Atoi(rng[i+1:]); err != nil {
+			return 0, 0, fmt.Errorf("invalid hunk header: %s", header)
+		}
+		rng = rng[:i]
+	}
+	start, err := strconv.Atoi(rng)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid hunk header: %s", header)
+	}
+	return start, count, nil
+}
+
+// pathMatches reports whether a tracefile SF path designates the same file as a
+// repository-relative path, as SF paths are frequently absolute.
+func pathMatches(sourceFile, relative string) bool {
+	if sourceFile == relative {
+		return true
+	}
+	return strings.HasSuffix(sourceFile, "/"+strings.TrimPrefix(relative, "./"))
+}
@@ -0,0 +1,55 @@
+package lcov
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const sampleDiff = `diff --git a/source/main.go b/source/main.go
+index 1111111..2222222 100644
+--- a/source/main.go
++++ b/source/main.go
+@@ -1,3 +1,5 @@ package main
+ line one
++added two
++added three
+ line four
+-removed
++++ added five looks like a header
+diff --git a/old.go b/old.go
+deleted file mode 100644
+--- a/old.go
++++ /dev/null
+@@ -1 +0,0 @@
+-gone
+`
+
+func TestParseUnifiedDiff(t *testing.T) {
+	files, err := ParseUnifiedDiff(strings.NewReader(sampleDiff))
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+
+	assert.Equal(t, "source/main.go", files[0].Path)
+	require.Len(t, files[0].Hunks, 1)
+	hunk := files[0].Hunks[0]
+	assert.Equal(t, "@@ -1,3 +1,5 @@ package main", hunk.Header)
+	assert.Equal(t, []DiffLine{
+		{Line: 2, Text: "added two"},
+		{Line: 3, Text: "added three"},
+		{Line: 5, Text: "++ added five looks like a header"},
+	}, hunk.Added)
+}
+
+func TestParseHunkHeaderErrors(t *testing.T) {
+	tests := []string{"@@ -1 @@", "@@ -1 +a,2 @@", "@@ -1 +1,b @@"}
+	for _, header := range tests {
+		t.Run(header, func(t *testing.T) {
+			_, _, err := parseHunkHeader(header)
+			assert.Error(t, err)
+			assert.Contains(t, err.Error(), "invalid hunk header")
+		})
+	}
+}
@@ -0,0 +1,44 @@
+package lcov
+
+import (
+	"io/fs"
+	"path"
+	"strings"
+)
+
+// FindTracefiles walks fsys and returns the paths of the files matching a glob
+// pattern, in lexical order. '**' matches any number of directories, and relative
+// patterns may match at any depth. An empty pattern selects the usual tracefile
+// names, '*.lcov' and '*.info' such as 'lcov.info'. Hidden directories are skipped.
+func FindTracefiles(fsys fs.FS, pattern string) ([]string, error) {
+	var found []string
+	err := fs.WalkDir(fsys, ".", func(name string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			// Hidden directories such as .git never hold the tracefiles of interest
+			if name != "." && strings.HasPrefix(d.Name(), ".") {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if isTracefile(name, pattern) {
+			found = append(found, name)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return found, nil
+}
+
+// isTracefile reports whether a file path matches the tracefile pattern, or a usual tracefile name
+func isTracefile(name, pattern string) bool {
+	if pattern != "" {
+		return matchGlob(pattern, name)
+	}
+	ext := path.Ext(name)
+	return ext == ".lcov" || ext == ".info"
+}
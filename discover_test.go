@@ -0,0 +1,32 @@
+package lcov
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFindTracefiles(t *testing.T) {
+	fsys := fstest.MapFS{
+		"lcov.info":                 {},
+		"pkg/a/coverage.lcov":       {},
+		"pkg/a/main.go":             {},
+		"pkg/b/coverage/lcov.info":  {},
+		"pkg/b/coverage/report.xml": {},
+		".git/objects/lcov.info":    {},
+	}
+
+	found, err := FindTracefiles(fsys, "")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"lcov.info", "pkg/a/coverage.lcov", "pkg/b/coverage/lcov.info"}, found)
+
+	found, err = FindTracefiles(fsys, "pkg/**/*.info")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"pkg/b/coverage/lcov.info"}, found)
+
+	found, err = FindTracefiles(fsys, "*.xml")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"pkg/b/coverage/report.xml"}, found)
+}
@@ -0,0 +1,62 @@
+package lcov
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Errors of invalid LCOV data, wrapped by the errors of Parser and Decoder so
+// that callers can tell them apart with errors.Is. See also ErrNoData,
+// ErrInconsistent and ErrUnknownRecord.
+var (
+	// ErrInvalidRecord is the error of a line that isn't a TYPE:value record
+	ErrInvalidRecord = errors.New("invalid record format")
+	// ErrOrphanRecord is the error of a record of a source file outside of an SF block
+	ErrOrphanRecord = errors.New("record without source file")
+	// ErrInvalidLineData is the error of a malformed DA record
+	ErrInvalidLineData = errors.New("invalid line data format")
+	// ErrInvalidFunctionName is the error of a malformed FN record
+	ErrInvalidFunctionName = errors.New("invalid function name format")
+	// ErrInvalidFunctionData is the error of a malformed FNDA record
+	ErrInvalidFunctionData = errors.New("invalid function data format")
+	// ErrInvalidBranchData is the error of a malformed BRDA record
+	ErrInvalidBranchData = errors.New("invalid branch data format")
+	// ErrInvalidCounter is the error of an LF, LH, FNF, FNH, BRF or BRH record
+	// whose value isn't an integer
+	ErrInvalidCounter = errors.New("invalid counter value")
+)
+
+// DecodeError is the error of an invalid line read by a Decoder
+type DecodeError struct {
+	// Line is the number of the line, starting at 1
+	Line int
+	Err  error
+}
+
+func (e *DecodeError) Error() string {
+	return fmt.Sprintf("line %d: %v", e.Line, e.Err)
+}
+
+func (e *DecodeError) Unwrap() error {
+	return e.Err
+}
+
+// kindError is an error with its own message that matches the sentinel error
+// of its kind with errors.Is
+type kindError struct {
+	kind    error
+	message string
+}
+
+func (e *kindError) Error() string {
+	return e.message
+}
+
+func (e *kindError) Unwrap() error {
+	return e.kind
+}
+
+// errorOf formats an error of a kind, e.g. ErrOrphanRecord, with a message of its own
+func errorOf(kind error, format string, args ...any) error {
+	return &kindError{kind: kind, message: fmt.Sprintf(format, args...)}
+}
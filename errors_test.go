@@ -0,0 +1,53 @@
+package lcov
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSummarizeErrorKinds(t *testing.T) {
+	tests := []struct {
+		input string
+		kind  error
+	}{
+		{"garbage", ErrInvalidRecord},
+		{"DA:1,1", ErrOrphanRecord},
+		{"BRH:1", ErrOrphanRecord},
+		{"SF:a.c\nDA:1", ErrInvalidLineData},
+		{"SF:a.c\nFN:main", ErrInvalidFunctionName},
+		{"SF:a.c\nBRDA:1,0,0", ErrInvalidBranchData},
+		{"SF:a.c\nLF:many", ErrInvalidCounter},
+		{"SF:a.c\nVER:2", ErrUnknownRecord},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			opts := []Option{}
+			if tt.kind == ErrUnknownRecord {
+				opts = append(opts, WithUnknownRecords(UnknownRecordsError))
+			}
+			_, err := Summarize(strings.NewReader(tt.input), opts...)
+			require.Error(t, err)
+			assert.ErrorIs(t, err, tt.kind)
+		})
+	}
+
+	_, err := Summarize(strings.NewReader(""), WithFailOnEmpty())
+	assert.ErrorIs(t, err, ErrNoData)
+}
+
+func TestDecodeError(t *testing.T) {
+	d := NewDecoder(strings.NewReader("SF:a.c\n\nFNDA:x,main\n"))
+	_, err := d.Next()
+	require.NoError(t, err)
+	_, err = d.Next()
+
+	var decodeErr *DecodeError
+	require.ErrorAs(t, err, &decodeErr)
+	assert.Equal(t, 3, decodeErr.Line)
+	assert.True(t, errors.Is(err, ErrInvalidFunctionData))
+	assert.EqualError(t, err, "line 3: invalid function data format: x,main")
+}
@@ -0,0 +1,137 @@
+package lcov
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"math"
+)
+
+// Estimate is an approximate summary computed from a sample of the file blocks of a tracefile
+type Estimate struct {
+	// Summary holds the totals extrapolated from the sample. TotalFiles is exact.
+	Summary
+	SampledFiles int
+	// Lines, Functions and Branches are the 95% confidence intervals of the coverage rates
+	Lines     Interval
+	Functions Interval
+	Branches  Interval
+}
+
+// Interval is a confidence interval of a coverage percentage
+type Interval struct {
+	Low  float64
+	High float64
+}
+
+// EstimateSummary quickly estimates the summary of a huge tracefile by only parsing
+// every Nth SF block, the other blocks being skipped without validation. Coverage
+// rates come with 95% confidence bounds, treating files as sampling clusters.
+func EstimateSummary(reader io.Reader, every int) (*Estimate, error) {
+	if every < 1 {
+		return nil, fmt.Errorf("invalid sampling interval: %d", every)
+	}
+
+	var sample bytes.Buffer
+	var blocks int
+	sampling := false
+
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 0, 64*1024), 10*1024*1024)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		trimmed := bytes.TrimSpace(line)
+		if bytes.HasPrefix(trimmed, []byte("SF:")) {
+			sampling = blocks%every == 0
+			blocks++
+		}
+		if sampling {
+			sample.Write(line)
+			sample.WriteByte('\n')
+		}
+		if string(trimmed) == string(RecordEndOfRecord) {
+			sampling = false
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error reading LCOV data: %w", err)
+	}
+
+	summary, err := Summarize(&sample, WithDetails())
+	if err != nil {
+		return nil, err
+	}
+
+	estimate := &Estimate{SampledFiles: summary.TotalFiles}
+	estimate.TotalFiles = blocks
+	scale := 1.0
+	if summary.TotalFiles > 0 {
+		scale = float64(blocks) / float64(summary.TotalFiles)
+	}
+
+	type counts struct{ hit, found int64 }
+	lines := make([]counts, len(summary.Files))
+	functions := make([]counts, len(summary.Files))
+	branches := make([]counts, len(summary.Files))
+	for i, f := range summary.Files {
+		lines[i] = counts{f.LinesHit, f.LinesFound}
+		functions[i] = counts{f.FunctionsHit, f.FunctionsFound}
+		branches[i] = counts{f.BranchesHit, f.BranchesFound}
+	}
+
+	interval := func(samples []counts, found int64) Interval {
+		if found == 0 {
+			return Interval{}
+		}
+		xs := make([]float64, len(samples))
+		ys := make([]float64, len(samples))
+		for i, s := range samples {
+			xs[i], ys[i] = float64(s.hit), float64(s.found)
+		}
+		return ratioInterval(xs, ys, float64(len(samples))/float64(blocks))
+	}
+
+	estimate.TotalLines = int64(math.Round(float64(summary.TotalLines) * scale))
+	estimate.CoveredLines = int64(math.Round(float64(summary.CoveredLines) * scale))
+	estimate.TotalFunctions = int64(math.Round(float64(summary.TotalFunctions) * scale))
+	estimate.CoveredFunctions = int64(math.Round(float64(summary.CoveredFunctions) * scale))
+	estimate.TotalBranches = int64(math.Round(float64(summary.TotalBranches) * scale))
+	estimate.CoveredBranches = int64(math.Round(float64(summary.CoveredBranches) * scale))
+	estimate.LineCoverageRate = summary.LineCoverageRate
+	estimate.FunctionCoverageRate = summary.FunctionCoverageRate
+	estimate.BranchCoverageRate = summary.BranchCoverageRate
+	estimate.Warnings = summary.Warnings
+
+	estimate.Lines = interval(lines, summary.TotalLines)
+	estimate.Functions = interval(functions, summary.TotalFunctions)
+	estimate.Branches = interval(branches, summary.TotalBranches)
+
+	return estimate, nil
+}
+
+// ratioInterval returns the 95% confidence interval, in percent, of the ratio
+// estimator sum(x)/sum(y) over a cluster sample drawn with the given sampling fraction.
+func ratioInterval(x, y []float64, fraction float64) Interval {
+	n := float64(len(x))
+	var sumX, sumY float64
+	for i := range x {
+		sumX += x[i]
+		sumY += y[i]
+	}
+	ratio := sumX / sumY
+	if n < 2 || fraction >= 1 {
+		return Interval{Low: ratio * 100, High: ratio * 100}
+	}
+
+	var residuals float64
+	for i := range x {
+		d := x[i] - ratio*y[i]
+		residuals += d * d
+	}
+	meanY := sumY / n
+	variance := (1 - fraction) / (n * meanY * meanY) * residuals / (n - 1)
+	margin := 1.96 * math.Sqrt(variance)
+
+	return Interval{Low: math.Max(ratio-margin, 0) * 100, High: math.Min(ratio+margin, 1) * 100}
+}
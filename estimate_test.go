@@ -0,0 +1,56 @@
+package lcov
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEstimateSummary(t *testing.T) {
+	// 100 files of 10 lines, the even ones fully covered and the odd ones at 20%
+	var input strings.Builder
+	for i := 0; i < 100; i++ {
+		hit := 2
+		if i%2 == 0 {
+			hit = 10
+		}
+		fmt.Fprintf(&input, "SF:/src/file%d.go\nLF:10\nLH:%d\nend_of_record\n", i, hit)
+	}
+
+	estimate, err := EstimateSummary(strings.NewReader(input.String()), 3)
+	require.NoError(t, err)
+
+	assert.Equal(t, 100, estimate.TotalFiles)
+	assert.Equal(t, 34, estimate.SampledFiles)
+	assert.Equal(t, int64(1000), estimate.TotalLines)
+	// True rate is 60%
+	assert.InDelta(t, 60.0, estimate.LineCoverageRate, 5)
+	assert.Less(t, estimate.Lines.Low, 60.0)
+	assert.Greater(t, estimate.Lines.High, 60.0)
+	assert.Equal(t, Interval{}, estimate.Functions)
+}
+
+func TestEstimateSummaryExhaustive(t *testing.T) {
+	file, err := os.Open("testdata/complex.lcov")
+	require.NoError(t, err)
+	defer file.Close()
+
+	estimate, err := EstimateSummary(file, 1)
+	require.NoError(t, err)
+	assert.Equal(t, 3, estimate.SampledFiles)
+	assert.Equal(t, int64(15), estimate.TotalLines)
+	assert.InDelta(t, 73.33, estimate.Lines.Low, 0.01)
+	assert.InDelta(t, 73.33, estimate.Lines.High, 0.01)
+}
+
+func TestEstimateSummaryErrors(t *testing.T) {
+	_, err := EstimateSummary(strings.NewReader(""), 0)
+	assert.EqualError(t, err, "invalid sampling interval: 0")
+
+	_, err = EstimateSummary(strings.NewReader("SF:a.go\nDA:x\nend_of_record\n"), 1)
+	assert.ErrorContains(t, err, "invalid line data format")
+}
@@ -0,0 +1,134 @@
+package lcov
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+)
+
+// Exclusion markers of lcov, written in comments of the source files
+var (
+	markerExcludeLine        = []byte("LCOV_EXCL_LINE")
+	markerExcludeStart       = []byte("LCOV_EXCL_START")
+	markerExcludeStop        = []byte("LCOV_EXCL_STOP")
+	markerExcludeBranchLine  = []byte("LCOV_EXCL_BR_LINE")
+	markerExcludeBranchStart = []byte("LCOV_EXCL_BR_START")
+	markerExcludeBranchStop  = []byte("LCOV_EXCL_BR_STOP")
+)
+
+// Exclusions are the lines of a source file excluded from coverage by lcov
+// exclusion markers, as returned by ParseExclusions
+type Exclusions struct {
+	// lines are excluded with all their data, branches only for their branch data
+	lines    map[int]bool
+	branches map[int]bool
+}
+
+// ParseExclusions reads the exclusion markers of a source file, as geninfo does:
+//
+//   - LCOV_EXCL_LINE excludes its line
+//   - LCOV_EXCL_START and LCOV_EXCL_STOP exclude the lines between them, their own included
+//   - LCOV_EXCL_BR_LINE, LCOV_EXCL_BR_START and LCOV_EXCL_BR_STOP do the same for branches only
+//
+// A region without LCOV_EXCL_STOP extends to the end of the file.
+func ParseExclusions(source io.Reader) (*Exclusions, error) {
+	e := &Exclusions{lines: make(map[int]bool), branches: make(map[int]bool)}
+	scanner := bufio.NewScanner(source)
+	scanner.Buffer(make([]byte, 0, 64*1024), 10*1024*1024)
+	var excluding, excludingBranches bool
+	for line := 1; scanner.Scan(); line++ {
+		text := scanner.Bytes()
+		// Every marker starts with LCOV_EXCL_, most lines don't have any
+		if bytes.Contains(text, []byte("LCOV_EXCL_")) {
+			excluding = excluding || bytes.Contains(text, markerExcludeStart)
+			excludingBranches = excludingBranches || bytes.Contains(text, markerExcludeBranchStart)
+			if excluding || bytes.Contains(text, markerExcludeLine) || bytes.Contains(text, markerExcludeStop) {
+				e.lines[line] = true
+			}
+			if excludingBranches || bytes.Contains(text, markerExcludeBranchLine) || bytes.Contains(text, markerExcludeBranchStop) {
+				e.branches[line] = true
+			}
+			excluding = excluding && !bytes.Contains(text, markerExcludeStop)
+			excludingBranches = excludingBranches && !bytes.Contains(text, markerExcludeBranchStop)
+			continue
+		}
+		if excluding {
+			e.lines[line] = true
+		}
+		if excludingBranches {
+			e.branches[line] = true
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error reading source: %w", err)
+	}
+	return e, nil
+}
+
+// Empty reports whether the source has no exclusion marker
+func (e *Exclusions) Empty() bool {
+	return len(e.lines) == 0 && len(e.branches) == 0
+}
+
+// Apply drops the line, function and branch data of a detailed record (see
+// WithDetails) on excluded lines, functions being excluded by the line they
+// start at, and recomputes the counters of the metrics that had data.
+func (e *Exclusions) Apply(f *FileRecord) {
+	if e.Empty() {
+		return
+	}
+
+	if len(f.Lines) > 0 {
+		var lines []LineData
+		for _, l := range f.Lines {
+			if !e.lines[l.Line] {
+				lines = append(lines, l)
+			}
+		}
+		f.Lines = lines
+		f.LinesFound, f.LinesHit = countLines(f.Lines)
+	}
+	if len(f.Functions) > 0 {
+		var functions []FunctionData
+		for _, fn := range f.Functions {
+			if !e.lines[fn.Line] {
+				functions = append(functions, fn)
+			}
+		}
+		f.Functions = functions
+		f.FunctionsFound, f.FunctionsHit = countFunctions(f.Functions)
+	}
+	if len(f.Branches) > 0 {
+		var branches []BranchData
+		for _, b := range f.Branches {
+			if !e.lines[b.Line] && !e.branches[b.Line] {
+				branches = append(branches, b)
+			}
+		}
+		f.Branches = branches
+		f.BranchesFound, f.BranchesHit = countBranches(f.Branches)
+	}
+}
+
+// ExcludeMarked returns copies of detailed records without the data excluded by
+// the lcov exclusion markers of their sources, see ParseExclusions. Sources are
+// read with open, which returns false for the files whose source isn't
+// available; their records are kept as is.
+func ExcludeMarked(files []FileRecord, open func(path string) (io.ReadCloser, bool)) ([]FileRecord, error) {
+	kept := make([]FileRecord, len(files))
+	for i := range files {
+		kept[i] = *files[i].clone()
+		source, ok := open(files[i].Path)
+		if !ok {
+			continue
+		}
+		exclusions, err := ParseExclusions(source)
+		source.Close()
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", files[i].Path, err)
+		}
+		exclusions.Apply(&kept[i])
+	}
+	return kept, nil
+}
@@ -0,0 +1,77 @@
+package lcov
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const excludedSource = `package main
+
+func main() {
+	debug() // LCOV_EXCL_LINE
+	// LCOV_EXCL_START
+	if unreachable {
+		panic("unreachable")
+	}
+	// LCOV_EXCL_STOP
+	if err != nil { // LCOV_EXCL_BR_LINE
+		return
+	}
+}
+`
+
+func TestParseExclusions(t *testing.T) {
+	e, err := ParseExclusions(strings.NewReader(excludedSource))
+	require.NoError(t, err)
+	assert.Equal(t, map[int]bool{4: true, 5: true, 6: true, 7: true, 8: true, 9: true}, e.lines)
+	assert.Equal(t, map[int]bool{10: true}, e.branches)
+
+	// An unterminated region extends to the end of the file
+	e, err = ParseExclusions(strings.NewReader("a\n// LCOV_EXCL_BR_START\nb\nc\n"))
+	require.NoError(t, err)
+	assert.Empty(t, e.lines)
+	assert.Equal(t, map[int]bool{2: true, 3: true, 4: true}, e.branches)
+
+	e, err = ParseExclusions(strings.NewReader("package main\n"))
+	require.NoError(t, err)
+	assert.True(t, e.Empty())
+}
+
+func TestExcludeMarked(t *testing.T) {
+	files := []FileRecord{
+		{
+			Path:       "/src/main.go",
+			LinesFound: 5, LinesHit: 2,
+			Lines:          []LineData{{Line: 3, Count: 1}, {Line: 4, Count: 0}, {Line: 6, Count: 0}, {Line: 7, Count: 0}, {Line: 10, Count: 1}},
+			FunctionsFound: 2, FunctionsHit: 1,
+			Functions:     []FunctionData{{Name: "main", Line: 3, Count: 1}, {Name: "init", Line: 6}},
+			BranchesFound: 4, BranchesHit: 1,
+			Branches: []BranchData{
+				{Line: 6, Block: 0, Branch: 0, Taken: 0}, {Line: 6, Block: 0, Branch: 1, Taken: 1},
+				{Line: 10, Block: 0, Branch: 0, Taken: 0}, {Line: 10, Block: 0, Branch: 1, Taken: 0},
+			},
+		},
+		{Path: "/src/other.go", LinesFound: 1, Lines: []LineData{{Line: 4, Count: 0}}},
+	}
+	open := func(path string) (io.ReadCloser, bool) {
+		return io.NopCloser(strings.NewReader(excludedSource)), path == "/src/main.go"
+	}
+
+	kept, err := ExcludeMarked(files, open)
+	require.NoError(t, err)
+	require.Len(t, kept, 2)
+	assert.Equal(t, FileRecord{
+		Path:       "/src/main.go",
+		LinesFound: 2, LinesHit: 2,
+		Lines:          []LineData{{Line: 3, Count: 1}, {Line: 10, Count: 1}},
+		FunctionsFound: 1, FunctionsHit: 1,
+		Functions: []FunctionData{{Name: "main", Line: 3, Count: 1}},
+	}, kept[0])
+	assert.Equal(t, files[1], kept[1])
+	// The records passed in are left untouched
+	assert.Len(t, files[0].Lines, 5)
+}
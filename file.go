@@ -0,0 +1,176 @@
+package lcov
+
+import (
+	"bytes"
+	"math"
+	"sort"
+)
+
+// FileRecord holds the coverage data of a single source file (SF block).
+// The Lines, Functions and Branches details are only populated when parsing WithDetails.
+type FileRecord struct {
+	Path           string
+	TestName       string
+	LinesFound     int64
+	LinesHit       int64
+	FunctionsFound int64
+	FunctionsHit   int64
+	BranchesFound  int64
+	BranchesHit    int64
+
+	Lines     []LineData
+	Functions []FunctionData
+	Branches  []BranchData
+}
+
+// LineData represents a DA record: the execution count of a single line
+type LineData struct {
+	Line  int
+	Count int64
+}
+
+// FunctionData represents an FN record and the execution count from its matching FNDA record
+type FunctionData struct {
+	Name string
+	Line int
+	// EndLine is the last line of the function, written by lcov 2.0 as
+	// FN:line,end_line,name, or 0 when unknown
+	EndLine int
+	Count   int64
+}
+
+// BranchData represents a BRDA record
+type BranchData struct {
+	Line   int
+	Block  int
+	Branch int
+	// Taken is the number of times the branch was taken,
+	// or -1 when its block was never executed ('-' in the tracefile)
+	Taken int64
+}
+
+// clone returns a copy of the record that doesn't share its detail slices
+func (f *FileRecord) clone() *FileRecord {
+	c := *f
+	c.Lines = append([]LineData(nil), f.Lines...)
+	c.Functions = append([]FunctionData(nil), f.Functions...)
+	c.Branches = append([]BranchData(nil), f.Branches...)
+	return &c
+}
+
+// sortDetails sorts the line, function and branch data by line
+func (f *FileRecord) sortDetails() {
+	sort.SliceStable(f.Lines, func(i, j int) bool { return f.Lines[i].Line < f.Lines[j].Line })
+	sort.SliceStable(f.Functions, func(i, j int) bool { return f.Functions[i].Line < f.Functions[j].Line })
+	sort.SliceStable(f.Branches, func(i, j int) bool {
+		a, b := f.Branches[i], f.Branches[j]
+		if a.Line != b.Line {
+			return a.Line < b.Line
+		}
+		if a.Block != b.Block {
+			return a.Block < b.Block
+		}
+		return a.Branch < b.Branch
+	})
+}
+
+// addCount sums execution counts, saturating at math.MaxInt64 instead of
+// overflowing, as counters of long-running processes may get close to it
+func addCount(a, b int64) int64 {
+	if a > 0 && b > math.MaxInt64-a {
+		return math.MaxInt64
+	}
+	return a + b
+}
+
+// countLines returns the number of instrumented and executed lines of line data
+func countLines(lines []LineData) (found, hit int64) {
+	for _, l := range lines {
+		if l.Count > 0 {
+			hit++
+		}
+	}
+	return int64(len(lines)), hit
+}
+
+// countFunctions returns the number of functions and of executed functions of function data
+func countFunctions(functions []FunctionData) (found, hit int64) {
+	for _, fn := range functions {
+		if fn.Count > 0 {
+			hit++
+		}
+	}
+	return int64(len(functions)), hit
+}
+
+// countBranches returns the number of branches and of taken branches of branch data
+func countBranches(branches []BranchData) (found, hit int64) {
+	for _, b := range branches {
+		if b.Taken > 0 {
+			hit++
+		}
+	}
+	return int64(len(branches)), hit
+}
+
+// setFunctionCount records the FNDA execution count of the named function
+func (f *FileRecord) setFunctionCount(name string, count int64) {
+	for i := range f.Functions {
+		if f.Functions[i].Name == name {
+			f.Functions[i].Count = count
+			return
+		}
+	}
+	// FNDA without a preceding FN: keep the data, the line is unknown
+	f.Functions = append(f.Functions, FunctionData{Name: name, Count: count})
+}
+
+// parseLineData parses an already validated DA value (line,count)
+func parseLineData(value []byte) LineData {
+	line, count, _ := bytes.Cut(value, []byte{','})
+	l, _ := atoi(line)
+	c, _ := parseCount(count)
+	return LineData{Line: l, Count: c}
+}
+
+// parseFunctionName parses an already validated FN value (line,name or line,end_line,name)
+func parseFunctionName(value []byte) FunctionData {
+	line, name, _ := bytes.Cut(value, []byte{','})
+	l, _ := atoi(line)
+	end, name := cutEndLine(name)
+	return FunctionData{Name: string(name), Line: l, EndLine: end}
+}
+
+// cutEndLine splits the end line of the lcov 2.0 FN format off the rest of an
+// FN value after its line, returning 0 and the rest as name when there is none.
+// As in lcov, a leading number is taken as the end line when the rest holds a
+// comma, since function names seldom do.
+func cutEndLine(rest []byte) (int, []byte) {
+	end, name, found := bytes.Cut(rest, []byte{','})
+	if !found || len(name) == 0 {
+		return 0, rest
+	}
+	for _, c := range end {
+		if c < '0' || c > '9' {
+			return 0, rest
+		}
+	}
+	if l, ok := atoi(end); ok {
+		return l, name
+	}
+	return 0, rest
+}
+
+// parseBranchData parses an already validated BRDA value (line,block,branch,taken)
+func parseBranchData(value []byte) BranchData {
+	var parts [4][]byte
+	splitFields(value, parts[:])
+	line, _ := atoi(parts[0])
+	block, _ := atoi(parts[1])
+	branch, _ := atoi(parts[2])
+	taken := int64(-1)
+	if string(parts[3]) != "-" {
+		taken, _ = parseCount(parts[3])
+	}
+	return BranchData{Line: line, Block: block, Branch: branch, Taken: taken}
+}
@@ -0,0 +1,87 @@
+package lcov
+
+import (
+	"sort"
+	"strconv"
+)
+
+// FileSummary is the coverage of a single source file, with its computed rates,
+// as returned by Summary.FileSummaries
+type FileSummary struct {
+	Path      string         `json:"path"`
+	Lines     CoverageMetric `json:"lines"`
+	Functions CoverageMetric `json:"functions"`
+	Branches  CoverageMetric `json:"branches"`
+	// UncoveredLines lists the ranges of instrumented lines that were never
+	// executed. Lines without data, such as blanks and comments, don't split a
+	// range, while an executed line does.
+	UncoveredLines []LineRange `json:"uncovered_lines"`
+}
+
+// LineRange is an inclusive range of source lines
+type LineRange struct {
+	Start int `json:"start"`
+	End   int `json:"end"`
+}
+
+// String formats the range as '12' or '12-15'
+func (r LineRange) String() string {
+	if r.Start == r.End {
+		return strconv.Itoa(r.Start)
+	}
+	return strconv.Itoa(r.Start) + "-" + strconv.Itoa(r.End)
+}
+
+// FileSummaries returns the summary of every source file, sorted by path, with
+// the blocks of the same file merged. The summary must have been parsed
+// WithDetails, otherwise there are no files.
+func (s *Summary) FileSummaries() []FileSummary {
+	files := MergeFiles(s.Files)
+	summaries := make([]FileSummary, len(files))
+	for i := range files {
+		summaries[i] = files[i].Summary()
+	}
+	return summaries
+}
+
+// Summary computes the rates and uncovered line ranges of the record
+func (f *FileRecord) Summary() FileSummary {
+	metric := func(hit, found int64) CoverageMetric {
+		r, _ := rate(hit, found)
+		return CoverageMetric{Covered: hit, Total: found, Rate: r}
+	}
+	return FileSummary{
+		Path:           f.Path,
+		Lines:          metric(f.LinesHit, f.LinesFound),
+		Functions:      metric(f.FunctionsHit, f.FunctionsFound),
+		Branches:       metric(f.BranchesHit, f.BranchesFound),
+		UncoveredLines: uncoveredRanges(f.Lines),
+	}
+}
+
+// uncoveredRanges groups the lines never executed in ranges, summing the
+// counts of lines reported several times
+func uncoveredRanges(lines []LineData) []LineRange {
+	sorted := append([]LineData(nil), lines...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Line < sorted[j].Line })
+
+	var ranges []LineRange
+	// open tells whether the last range may still be extended
+	open := false
+	for i := 0; i < len(sorted); {
+		line, count := sorted[i].Line, int64(0)
+		for ; i < len(sorted) && sorted[i].Line == line; i++ {
+			count = addCount(count, sorted[i].Count)
+		}
+		switch {
+		case count > 0:
+			open = false
+		case open:
+			ranges[len(ranges)-1].End = line
+		default:
+			ranges = append(ranges, LineRange{Start: line, End: line})
+			open = true
+		}
+	}
+	return ranges
+}
@@ -0,0 +1,66 @@
+package lcov
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileSummaries(t *testing.T) {
+	input := `SF:b.go
+FN:1,main
+FNDA:1,main
+DA:1,1
+DA:2,0
+DA:3,0
+DA:5,0
+DA:6,2
+DA:8,0
+LF:6
+LH:2
+end_of_record
+SF:a.go
+DA:1,0
+LF:1
+LH:0
+end_of_record
+SF:b.go
+DA:3,4
+LF:1
+LH:1
+end_of_record
+`
+	summary, err := Summarize(strings.NewReader(input), WithDetails())
+	require.NoError(t, err)
+
+	files := summary.FileSummaries()
+	require.Len(t, files, 2)
+	assert.Equal(t, FileSummary{
+		Path:           "a.go",
+		Lines:          CoverageMetric{Covered: 0, Total: 1, Rate: 0},
+		UncoveredLines: []LineRange{{Start: 1, End: 1}},
+	}, files[0])
+
+	b := files[1]
+	assert.Equal(t, "b.go", b.Path)
+	assert.Equal(t, CoverageMetric{Covered: 3, Total: 6, Rate: 50}, b.Lines)
+	assert.Equal(t, CoverageMetric{Covered: 1, Total: 1, Rate: 100}, b.Functions)
+	assert.Equal(t, CoverageMetric{}, b.Branches)
+	// Line 3 was executed by the second block, and line 4 has no data
+	assert.Equal(t, []LineRange{{Start: 2, End: 2}, {Start: 5, End: 5}, {Start: 8, End: 8}}, b.UncoveredLines)
+
+	assert.Empty(t, (&Summary{TotalLines: 3}).FileSummaries())
+}
+
+func TestUncoveredRanges(t *testing.T) {
+	ranges := uncoveredRanges([]LineData{
+		{Line: 7, Count: 0}, {Line: 1, Count: 0}, {Line: 2, Count: 0}, {Line: 4, Count: 0},
+		{Line: 5, Count: 1}, {Line: 6, Count: 0}, {Line: 7, Count: 0},
+	})
+	assert.Equal(t, []LineRange{{Start: 1, End: 4}, {Start: 6, End: 7}}, ranges)
+	assert.Equal(t, "1-4", ranges[0].String())
+	assert.Equal(t, "3", LineRange{Start: 3, End: 3}.String())
+	assert.Empty(t, uncoveredRanges(nil))
+}
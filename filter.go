@@ -0,0 +1,30 @@
+package lcov
+
+// FilterFiles returns the file records to keep according to glob patterns, like
+// 'lcov --extract' and 'lcov --remove': records are kept when their path matches
+// one of the extract patterns, or when there are none, and none of the remove
+// patterns. A pattern matching a directory also matches everything below it, so
+// 'vendor/*' removes nested vendored packages as well.
+func FilterFiles(files []FileRecord, extract, remove []string) []FileRecord {
+	var kept []FileRecord
+	for _, f := range files {
+		if len(extract) > 0 && !matchAnyPath(extract, f.Path) {
+			continue
+		}
+		if matchAnyPath(remove, f.Path) {
+			continue
+		}
+		kept = append(kept, f)
+	}
+	return kept
+}
+
+// matchAnyPath reports whether a file path, or one of its directories, matches any of the patterns
+func matchAnyPath(patterns []string, name string) bool {
+	for _, pattern := range patterns {
+		if matchGlob(pattern, name) || matchGlob(pattern+"/**", name) {
+			return true
+		}
+	}
+	return false
+}
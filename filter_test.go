@@ -0,0 +1,33 @@
+package lcov
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFilterFiles(t *testing.T) {
+	files := []FileRecord{
+		{Path: "/src/repo/main.go"},
+		{Path: "/src/repo/main_test.go"},
+		{Path: "/src/repo/pkg/util.go"},
+		{Path: "/src/repo/vendor/lib/lib.go"},
+		{Path: "/src/repo/vendor/lib/nested/deep.go"},
+	}
+	paths := func(files []FileRecord) []string {
+		var paths []string
+		for _, f := range files {
+			paths = append(paths, f.Path)
+		}
+		return paths
+	}
+
+	assert.Equal(t, paths(files), paths(FilterFiles(files, nil, nil)))
+	assert.Equal(t, []string{"/src/repo/main.go", "/src/repo/pkg/util.go"},
+		paths(FilterFiles(files, nil, []string{"*_test.go", "vendor/*"})))
+	assert.Equal(t, []string{"/src/repo/pkg/util.go"},
+		paths(FilterFiles(files, []string{"pkg"}, nil)))
+	assert.Equal(t, []string{"/src/repo/vendor/lib/lib.go"},
+		paths(FilterFiles(files, []string{"/src/repo/vendor/**"}, []string{"nested"})))
+	assert.Empty(t, FilterFiles(files, []string{"*.c"}, nil))
+}
@@ -0,0 +1,173 @@
+package lcov
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// FunctionThreshold requires the functions matching a pattern to reach minimum coverage rates.
+// A zero rate means no requirement for that metric.
+type FunctionThreshold struct {
+	// Pattern is a file glob such as 'pkg/crypto/*', optionally followed by
+	// ':' and a function name glob, e.g. 'pkg/crypto/*:Encrypt*'
+	Pattern  string
+	Lines    float64
+	Branches float64
+}
+
+// FunctionViolation describes a function below its required coverage
+type FunctionViolation struct {
+	File     string
+	Function string
+	Line     int
+	Metric   string
+	Rate     float64
+	Required float64
+}
+
+// String formats the violation for display
+func (v FunctionViolation) String() string {
+	return fmt.Sprintf("%s:%d: function %s has %.1f%% %s coverage, %.1f%% required",
+		v.File, v.Line, v.Function, v.Rate, v.Metric, v.Required)
+}
+
+// functionExtent is the line range attributed to a function. The end line is only
+// known for some generators, such as lcov 2.0; otherwise a function is assumed to
+// extend up to the line before the next function of the file.
+type functionExtent struct {
+	FunctionData
+	end int
+}
+
+// functionExtents returns the extents of the functions of a detailed file record, sorted by line
+func functionExtents(f *FileRecord) []functionExtent {
+	extents := make([]functionExtent, 0, len(f.Functions))
+	for _, fn := range f.Functions {
+		if fn.Line > 0 {
+			extents = append(extents, functionExtent{FunctionData: fn})
+		}
+	}
+	sort.SliceStable(extents, func(i, j int) bool { return extents[i].Line < extents[j].Line })
+
+	for i := range extents {
+		extents[i].end = math.MaxInt
+		switch {
+		case extents[i].EndLine >= extents[i].Line:
+			extents[i].end = extents[i].EndLine
+		case i+1 < len(extents):
+			extents[i].end = extents[i+1].Line - 1
+		}
+	}
+	return extents
+}
+
+// contains reports whether a line falls within the function
+func (e functionExtent) contains(line int) bool {
+	return line >= e.Line && line <= e.end
+}
+
+// CheckFunctionThresholds evaluates per-function coverage requirements against detailed
+// file records (see WithDetails), attributing DA and BRDA data to functions by line.
+// Functions without data for a metric satisfy its requirement.
+func CheckFunctionThresholds(files []FileRecord, thresholds []FunctionThreshold) []FunctionViolation {
+	var violations []FunctionViolation
+
+	for i := range files {
+		f := &files[i]
+		for _, fn := range functionExtents(f) {
+			for _, threshold := range thresholds {
+				if !threshold.matches(f.Path, fn.Name) {
+					continue
+				}
+				if rate, ok := fn.lineRate(f); ok && threshold.Lines > 0 && rate < threshold.Lines {
+					violations = append(violations, FunctionViolation{
+						File: f.Path, Function: fn.Name, Line: fn.Line,
+						Metric: string(MetricLines), Rate: rate, Required: threshold.Lines,
+					})
+				}
+				if rate, ok := fn.branchRate(f); ok && threshold.Branches > 0 && rate < threshold.Branches {
+					violations = append(violations, FunctionViolation{
+						File: f.Path, Function: fn.Name, Line: fn.Line,
+						Metric: "branch", Rate: rate, Required: threshold.Branches,
+					})
+				}
+			}
+		}
+	}
+
+	return violations
+}
+
+// ParseFunctionThreshold parses a 'pattern=lines[,branches]' function threshold,
+// such as 'pkg/crypto/*:Encrypt*=90,80' or '**:Decrypt=,100', an empty
+// percentage meaning no requirement for its metric.
+func ParseFunctionThreshold(value string) (FunctionThreshold, error) {
+	i := strings.LastIndex(value, "=")
+	if i <= 0 {
+		return FunctionThreshold{}, fmt.Errorf("expected 'pattern=lines[,branches]': %s", value)
+	}
+	threshold := FunctionThreshold{Pattern: value[:i]}
+	lines, branches, _ := strings.Cut(value[i+1:], ",")
+	for _, metric := range []struct {
+		value string
+		rate  *float64
+	}{{lines, &threshold.Lines}, {branches, &threshold.Branches}} {
+		if metric.value == "" {
+			continue
+		}
+		rate, err := strconv.ParseFloat(metric.value, 64)
+		if err != nil {
+			return FunctionThreshold{}, fmt.Errorf("invalid percentage: %s", metric.value)
+		}
+		*metric.rate = rate
+	}
+	return threshold, nil
+}
+
+// matches reports whether the threshold applies to a function of a file
+func (t FunctionThreshold) matches(file, function string) bool {
+	filePattern, functionPattern, hasFunction := strings.Cut(t.Pattern, ":")
+	if !matchGlob(filePattern, file) {
+		return false
+	}
+	return !hasFunction || matchGlob(functionPattern, function)
+}
+
+// lineRate returns the line coverage percentage of the function
+func (e functionExtent) lineRate(f *FileRecord) (float64, bool) {
+	var found, hit int64
+	for _, l := range f.Lines {
+		if e.contains(l.Line) {
+			found++
+			if l.Count > 0 {
+				hit++
+			}
+		}
+	}
+	return rate(hit, found)
+}
+
+// branchRate returns the branch coverage percentage of the function
+func (e functionExtent) branchRate(f *FileRecord) (float64, bool) {
+	var found, hit int64
+	for _, b := range f.Branches {
+		if e.contains(b.Line) {
+			found++
+			if b.Taken > 0 {
+				hit++
+			}
+		}
+	}
+	return rate(hit, found)
+}
+
+// rate computes a coverage percentage, returning false when there is nothing to cover
+func rate(hit, found int64) (float64, bool) {
+	if found == 0 {
+		return 0, false
+	}
+	return float64(hit) / float64(found) * 100, true
+}
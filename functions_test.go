@@ -0,0 +1,89 @@
+package lcov
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckFunctionThresholds(t *testing.T) {
+	file, err := os.Open("testdata/functions.lcov")
+	require.NoError(t, err)
+	defer file.Close()
+
+	summary, err := Summarize(file, WithDetails())
+	require.NoError(t, err)
+
+	twoThirds, _ := rate(2, 3)
+
+	tests := []struct {
+		name       string
+		thresholds []FunctionThreshold
+		expected   []FunctionViolation
+	}{
+		{
+			name:       "package branches",
+			thresholds: []FunctionThreshold{{Pattern: "pkg/crypto/*", Branches: 100}},
+			expected: []FunctionViolation{
+				{File: "/src/repo/pkg/crypto/aes.go", Function: "Encrypt", Line: 1, Metric: "branch", Rate: 50, Required: 100},
+			},
+		},
+		{
+			name:       "function pattern",
+			thresholds: []FunctionThreshold{{Pattern: "**:Dec*", Lines: 100, Branches: 100}},
+		},
+		{
+			name:       "lines and branches",
+			thresholds: []FunctionThreshold{{Pattern: "pkg/**", Lines: 80}},
+			expected: []FunctionViolation{
+				{File: "/src/repo/pkg/crypto/aes.go", Function: "Encrypt", Line: 1, Metric: "line", Rate: twoThirds, Required: 80},
+				{File: "/src/repo/pkg/util/strings.go", Function: "Reverse", Line: 1, Metric: "line", Rate: 0, Required: 80},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, CheckFunctionThresholds(summary.Files, tt.thresholds))
+		})
+	}
+}
+
+func TestCheckFunctionThresholdsEndLines(t *testing.T) {
+	file, err := os.Open("testdata/functions_v2.lcov")
+	require.NoError(t, err)
+	defer file.Close()
+
+	summary, err := Summarize(file, WithDetails())
+	require.NoError(t, err)
+	assert.Equal(t, []FunctionData{{Name: "Encrypt", Line: 1, EndLine: 4, Count: 4}, {Name: "Decrypt", Line: 10, EndLine: 12}}, summary.Files[0].Functions)
+
+	// Line 6 is after the end of Encrypt: it isn't attributed to it
+	twoThirds, _ := rate(2, 3)
+	assert.Equal(t, []FunctionViolation{
+		{File: "/src/repo/pkg/crypto/aes.go", Function: "Encrypt", Line: 1, Metric: "line", Rate: twoThirds, Required: 80},
+		{File: "/src/repo/pkg/crypto/aes.go", Function: "Decrypt", Line: 10, Metric: "line", Rate: 0, Required: 80},
+	}, CheckFunctionThresholds(summary.Files, []FunctionThreshold{{Pattern: "**", Lines: 80}}))
+}
+
+func TestFunctionViolationString(t *testing.T) {
+	v := FunctionViolation{File: "pkg/crypto/aes.go", Function: "Encrypt", Line: 1, Metric: "branch", Rate: 50, Required: 100}
+	assert.Equal(t, "pkg/crypto/aes.go:1: function Encrypt has 50.0% branch coverage, 100.0% required", v.String())
+}
+
+func TestParseFunctionThreshold(t *testing.T) {
+	threshold, err := ParseFunctionThreshold("pkg/crypto/*:Encrypt*=90,80")
+	require.NoError(t, err)
+	assert.Equal(t, FunctionThreshold{Pattern: "pkg/crypto/*:Encrypt*", Lines: 90, Branches: 80}, threshold)
+
+	threshold, err = ParseFunctionThreshold("**:Decrypt=,100")
+	require.NoError(t, err)
+	assert.Equal(t, FunctionThreshold{Pattern: "**:Decrypt", Branches: 100}, threshold)
+
+	_, err = ParseFunctionThreshold("pkg/**")
+	assert.EqualError(t, err, "expected 'pattern=lines[,branches]': pkg/**")
+	_, err = ParseFunctionThreshold("pkg/**=high")
+	assert.EqualError(t, err, "invalid percentage: high")
+}
@@ -0,0 +1,87 @@
+package lcov
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/shastick/go-lcov-summary/lcovtest"
+)
+
+// addTracefileSeeds adds the LCOV tracefiles of testdata and a small generated
+// tracefile to the seed corpus
+func addTracefileSeeds(f *testing.F) {
+	f.Add(lcovtest.Tracefile(lcovtest.Config{Files: 2, Lines: 10, Functions: 2, Branches: 4, Coverage: 0.5, TestName: "seed"}))
+
+	paths, err := filepath.Glob("testdata/*.lcov")
+	require.NoError(f, err)
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		require.NoError(f, err)
+		f.Add(data)
+	}
+}
+
+func FuzzParseRecord(f *testing.F) {
+	for _, seed := range []string{"TN:", "SF:/src/main.go", "DA:1,5", "FN:1,main", "FNDA:1,main", "BRDA:1,0,0,-", "end_of_record", ":", "DA:1:5"} {
+		f.Add([]byte(seed))
+	}
+	f.Fuzz(func(t *testing.T, line []byte) {
+		parser := &Parser{}
+		recordType, value, err := parser.parseRecord(line)
+		if err != nil {
+			return
+		}
+		assert.NotEmpty(t, recordType)
+		parser.isValidLineData(value)
+		parser.isValidFunctionName(value)
+		parser.isValidBranchData(value)
+	})
+}
+
+func FuzzSummarize(f *testing.F) {
+	addTracefileSeeds(f)
+	f.Fuzz(func(t *testing.T, data []byte) {
+		for _, opts := range [][]Option{
+			nil,
+			{WithDetails(), WithDuplicateStrategy(DuplicatesMerge)},
+			{WithDuplicateStrategy(DuplicatesFirst)},
+		} {
+			summary, err := Summarize(bytes.NewReader(data), opts...)
+			if err == nil {
+				assert.NotNil(t, summary)
+			}
+		}
+	})
+}
+
+// FuzzRoundTrip checks that the tracefiles written from parsed records parse
+// back to the same records. The first write normalizes the input, e.g. FN
+// records without a line aren't written, so the check starts from its output.
+func FuzzRoundTrip(f *testing.F) {
+	addTracefileSeeds(f)
+	f.Fuzz(func(t *testing.T, data []byte) {
+		summary, err := Summarize(bytes.NewReader(data), WithDetails())
+		if err != nil {
+			return
+		}
+		normalized := writeParsed(t, summary)
+
+		expected, err := Summarize(bytes.NewReader(normalized), WithDetails())
+		require.NoError(t, err, string(normalized))
+		reparsed, err := Summarize(bytes.NewReader(writeParsed(t, expected)), WithDetails())
+		require.NoError(t, err)
+		assert.Equal(t, expected, reparsed, string(normalized))
+	})
+}
+
+// writeParsed writes the detailed records of a summary as an LCOV tracefile
+func writeParsed(t *testing.T, summary *Summary) []byte {
+	var buf bytes.Buffer
+	require.NoError(t, WriteLCOV(&buf, summary.Files))
+	return buf.Bytes()
+}
@@ -0,0 +1,224 @@
+package lcov
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// ParseGcov summarizes gcov annotated source files (.gcov), as produced by 'gcov'
+// without lcov. Several files may be concatenated, each starting with its
+// '-: 0:Source:' line. Function and branch information is taken into account when
+// gcov was run with -f and -b; branch counts are percentages unless -c was used,
+// which only matters for the taken/not taken distinction. The sections of C++
+// template instantiations and the block lines of gcov -a are understood, the
+// lines of a file being counted once. The parser options apply as for LCOV data.
+func ParseGcov(reader io.Reader, opts ...Option) (*Summary, error) {
+	p := newParser(opts)
+	reader = p.track(reader)
+	files := newFileSet(p.duplicates)
+
+	var current *FileRecord
+	var pendingFunction *FunctionData
+	lastLine, branchLine, block := 0, 0, 0
+	// instantiated holds the lines of the template instantiation sections of
+	// the current file, which follow a separator and a header naming the
+	// instantiation; separated is set on the line following a separator
+	var instantiated []LineData
+	inInstantiation, separated := false, false
+
+	flush := func() {
+		if current == nil {
+			return
+		}
+		lines, functions, branches := current.Lines, current.Functions, current.Branches
+		current.Lines, current.Functions, current.Branches = nil, nil, nil
+		current.mergeLines(lines)
+		current.mergeFunctions(functions)
+		current.mergeBranches(branches)
+		// The lines shown along the source already count the executions of every
+		// instantiation, those of the sections are only kept when missing there
+		instances := &FileRecord{}
+		instances.mergeLines(instantiated)
+		shown := make(map[int]bool, len(current.Lines))
+		for _, l := range current.Lines {
+			shown[l.Line] = true
+		}
+		for _, l := range instances.Lines {
+			if !shown[l.Line] {
+				current.Lines = append(current.Lines, l)
+				current.sortDetails()
+			}
+		}
+		current.recount()
+		files.add(current)
+	}
+
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 0, 64*1024), 10*1024*1024)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		text := scanner.Text()
+		trimmed := strings.TrimSpace(text)
+		afterSeparator := separated
+		separated = false
+
+		switch {
+		case trimmed == "":
+			continue
+
+		case strings.Trim(trimmed, "-") == "":
+			// ------------------ separates the template instantiation sections,
+			// the last one being followed by the rest of the source
+			separated, inInstantiation = true, false
+
+		case afterSeparator && strings.HasSuffix(trimmed, ":"):
+			// The mangled or demangled name of the instantiation, e.g. _Z3maxIiET_S0_S0_:
+			inInstantiation = true
+
+		case strings.HasPrefix(trimmed, "function "):
+			// function NAME called N returned X% blocks executed Y%
+			fields := strings.Fields(trimmed)
+			if len(fields) < 4 || fields[2] != "called" {
+				return nil, fmt.Errorf("invalid gcov function line %d: %s", lineNumber, trimmed)
+			}
+			count, err := parseGcovCount(fields[3])
+			if err != nil {
+				return nil, fmt.Errorf("invalid gcov function line %d: %s", lineNumber, trimmed)
+			}
+			pendingFunction = &FunctionData{Name: fields[1], Count: count}
+
+		case strings.HasPrefix(trimmed, "branch "):
+			// branch N taken X% (fallthrough) / branch N never executed
+			if current == nil || lastLine == 0 {
+				return nil, errorOf(ErrOrphanRecord, "branch data without source line at line %d", lineNumber)
+			}
+			fields := strings.Fields(trimmed)
+			if len(fields) < 3 {
+				return nil, fmt.Errorf("invalid gcov branch line %d: %s", lineNumber, trimmed)
+			}
+			if branchLine != lastLine {
+				branchLine, block = lastLine, 0
+			}
+			branch, err := strconv.Atoi(fields[1])
+			if err != nil {
+				return nil, fmt.Errorf("invalid gcov branch line %d: %s", lineNumber, trimmed)
+			}
+			taken := int64(-1)
+			if fields[2] == "taken" && len(fields) >= 4 {
+				if taken, err = parseGcovCount(strings.TrimSuffix(fields[3], "%")); err != nil {
+					return nil, fmt.Errorf("invalid gcov branch line %d: %s", lineNumber, trimmed)
+				}
+			}
+			current.Branches = append(current.Branches, BranchData{Line: lastLine, Block: block, Branch: branch, Taken: taken})
+
+		case strings.HasPrefix(trimmed, "call "), strings.HasPrefix(trimmed, "unconditional "):
+			// Call and jump statistics are not coverage data
+
+		default:
+			// count:line:source
+			parts := strings.SplitN(text, ":", 3)
+			if len(parts) >= 2 && strings.Contains(parts[1], "-block") {
+				// count:line-block N, the execution counts of the basic blocks (gcov -a)
+				continue
+			}
+			if len(parts) < 3 {
+				return nil, fmt.Errorf("invalid gcov line %d: %s", lineNumber, trimmed)
+			}
+			countField, lineField := strings.TrimSpace(parts[0]), strings.TrimSpace(parts[1])
+			line, err := strconv.Atoi(lineField)
+			if err != nil {
+				return nil, fmt.Errorf("invalid gcov line %d: %s", lineNumber, trimmed)
+			}
+
+			if line == 0 {
+				if source, ok := strings.CutPrefix(parts[2], "Source:"); ok {
+					flush()
+					current = &FileRecord{Path: strings.TrimSpace(source)}
+					lastLine, branchLine = 0, 0
+					instantiated, inInstantiation = nil, false
+				}
+				continue
+			}
+			if current == nil {
+				return nil, errorOf(ErrOrphanRecord, "line data without source file at line %d", lineNumber)
+			}
+
+			lastLine = line
+			if pendingFunction != nil {
+				pendingFunction.Line = line
+				current.Functions = append(current.Functions, *pendingFunction)
+				pendingFunction = nil
+			}
+			if countField == "-" {
+				continue
+			}
+			count, err := parseGcovCount(countField)
+			if err != nil {
+				return nil, fmt.Errorf("invalid gcov execution count at line %d: %s", lineNumber, countField)
+			}
+			if inInstantiation {
+				instantiated = append(instantiated, LineData{Line: line, Count: count})
+			} else {
+				current.Lines = append(current.Lines, LineData{Line: line, Count: count})
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error reading gcov data: %w", err)
+	}
+	flush()
+
+	return p.summarize(&Summary{}, files)
+}
+
+// parseGcovCount parses a gcov execution count: '#####', '=====', '%%%%%' and
+// '$$$$$' mean never executed, a trailing '*' marks partially executed lines,
+// and human readable counts (gcov -H) may carry a k, M or G suffix.
+func parseGcovCount(field string) (int64, error) {
+	field = strings.TrimSuffix(field, "*")
+	if field == "#####" || field == "=====" || field == "%%%%%" || field == "$$$$$" {
+		return 0, nil
+	}
+
+	multiplier := 1.0
+	switch {
+	case strings.HasSuffix(field, "k"):
+		multiplier = 1e3
+	case strings.HasSuffix(field, "M"):
+		multiplier = 1e6
+	case strings.HasSuffix(field, "G"):
+		multiplier = 1e9
+	}
+	if multiplier > 1 {
+		value, err := strconv.ParseFloat(field[:len(field)-1], 64)
+		if err != nil {
+			return 0, err
+		}
+		return int64(value * multiplier), nil
+	}
+	return strconv.ParseInt(field, 10, 64)
+}
+
+// recount derives the counters of the record from its detail data
+func (f *FileRecord) recount() {
+	f.LinesFound, f.LinesHit = int64(len(f.Lines)), 0
+	for _, l := range f.Lines {
+		if l.Count > 0 {
+			f.LinesHit++
+		}
+	}
+	f.FunctionsFound, f.FunctionsHit = int64(len(f.Functions)), 0
+	for _, fn := range f.Functions {
+		if fn.Count > 0 {
+			f.FunctionsHit++
+		}
+	}
+	f.BranchesFound, f.BranchesHit = int64(len(f.Branches)), 0
+	for _, b := range f.Branches {
+		if b.Taken > 0 {
+			f.BranchesHit++
+		}
+	}
+}
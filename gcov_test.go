@@ -0,0 +1,112 @@
+package lcov
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseGcov(t *testing.T) {
+	file, err := os.Open("testdata/sample.gcov")
+	require.NoError(t, err)
+	defer file.Close()
+
+	summary, err := ParseGcov(file, WithDetails())
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, summary.TotalFiles)
+	assert.Equal(t, int64(5), summary.TotalLines)
+	assert.Equal(t, int64(3), summary.CoveredLines)
+	assert.Equal(t, int64(2), summary.TotalFunctions)
+	assert.Equal(t, int64(1), summary.CoveredFunctions)
+	assert.Equal(t, int64(2), summary.TotalBranches)
+	assert.Equal(t, int64(1), summary.CoveredBranches)
+
+	main := summary.Files[0]
+	assert.Equal(t, "src/main.c", main.Path)
+	assert.Equal(t, []FunctionData{{Name: "main", Line: 3, Count: 1}, {Name: "unused", Line: 9, Count: 0}}, main.Functions)
+	assert.Equal(t, []BranchData{{Line: 4, Block: 0, Branch: 0, Taken: 0}, {Line: 4, Block: 0, Branch: 1, Taken: 100}}, main.Branches)
+}
+
+func TestParseGcovTemplates(t *testing.T) {
+	file, err := os.Open("testdata/template.gcov")
+	require.NoError(t, err)
+	defer file.Close()
+
+	summary, err := ParseGcov(file, WithDetails())
+	require.NoError(t, err)
+
+	// The lines of the instantiation sections are already counted along the source
+	assert.Equal(t, int64(8), summary.TotalLines)
+	assert.Equal(t, int64(7), summary.CoveredLines)
+	assert.Equal(t, int64(3), summary.TotalFunctions)
+	assert.Equal(t, int64(3), summary.CoveredFunctions)
+
+	f := summary.Files[0]
+	assert.Equal(t, "src/max.cpp", f.Path)
+	assert.Equal(t, []LineData{{Line: 2, Count: 3}, {Line: 3, Count: 3}, {Line: 6, Count: 1}, {Line: 7, Count: 1},
+		{Line: 8, Count: 1}, {Line: 9, Count: 1}, {Line: 10, Count: 0}, {Line: 11, Count: 1}}, f.Lines)
+	// The branches of the instantiations are merged by line
+	assert.Equal(t, []BranchData{{Line: 3, Block: 0, Branch: 0, Taken: 1}, {Line: 3, Block: 0, Branch: 1, Taken: 2}}, f.Branches)
+}
+
+func TestParseGcovRepeatedLines(t *testing.T) {
+	input := "-:0:Source:a.c\n        1:    3:int x;\n        2:    3:int x;\n    #####:    4:int y;\n"
+	summary, err := ParseGcov(strings.NewReader(input), WithDetails())
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), summary.TotalLines)
+	assert.Equal(t, []LineData{{Line: 3, Count: 3}, {Line: 4, Count: 0}}, summary.Files[0].Lines)
+}
+
+func TestParseGcovCount(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int64
+		err      bool
+	}{
+		{input: "5", expected: 5},
+		{input: "1*", expected: 1},
+		{input: "#####", expected: 0},
+		{input: "=====", expected: 0},
+		{input: "%%%%%", expected: 0},
+		{input: "1.5k", expected: 1500},
+		{input: "2M", expected: 2000000},
+		{input: "x", err: true},
+		{input: "xk", err: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			count, err := parseGcovCount(tt.input)
+			if tt.err {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, count)
+		})
+	}
+}
+
+func TestParseGcovErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		err   string
+	}{
+		{name: "no source", input: "        1:    3:int x;", err: "line data without source file at line 1"},
+		{name: "bad count", input: "-:0:Source:a.c\n  abc:    3:int x;", err: "invalid gcov execution count at line 2: abc"},
+		{name: "bad format", input: "-:0:Source:a.c\nnot gcov", err: "invalid gcov line 2: not gcov"},
+		{name: "orphan branch", input: "branch  0 taken 1", err: "branch data without source line at line 1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseGcov(strings.NewReader(tt.input))
+			assert.EqualError(t, err, tt.err)
+		})
+	}
+}
@@ -0,0 +1,61 @@
+package lcov
+
+import (
+	"bufio"
+	"io"
+	"regexp"
+	"strings"
+)
+
+// DefaultGeneratedPatterns are the glob patterns of the usual generated Go files:
+// protobuf and gRPC stubs, and the output of go:generate tools by convention
+var DefaultGeneratedPatterns = []string{"*.pb.go", "*.pb.gw.go", "*_gen.go", "*_generated.go", "zz_generated*.go"}
+
+// generatedHeader is the comment marking generated Go files, see 'go help generate'
+var generatedHeader = regexp.MustCompile(`^// Code generated .* DO NOT EDIT\.$`)
+
+// IsGeneratedSource reports whether a source file has the standard
+// '// Code generated ... DO NOT EDIT.' comment before its first non-comment,
+// non-blank line.
+func IsGeneratedSource(r io.Reader) (bool, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 10*1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if generatedHeader.MatchString(line) {
+			return true, nil
+		}
+		if trimmed := strings.TrimSpace(line); trimmed != "" && !strings.HasPrefix(trimmed, "//") {
+			return false, nil
+		}
+	}
+	return false, scanner.Err()
+}
+
+// ExcludeGenerated returns the records of the files that aren't generated,
+// and the paths of those that are: files whose path matches one of the glob
+// patterns, e.g. DefaultGeneratedPatterns, or whose source has the generated
+// code header. Sources are read with open, which returns false for the files
+// whose source isn't available; a nil open only checks the patterns.
+func ExcludeGenerated(files []FileRecord, patterns []string, open func(path string) (io.ReadCloser, bool)) (kept []FileRecord, excluded []string) {
+	for _, f := range files {
+		if matchAnyPath(patterns, f.Path) || (open != nil && isGeneratedFile(f.Path, open)) {
+			excluded = append(excluded, f.Path)
+			continue
+		}
+		kept = append(kept, f)
+	}
+	return kept, excluded
+}
+
+// isGeneratedFile reports whether the source of a file has the generated code
+// header, false when it can't be read
+func isGeneratedFile(path string, open func(path string) (io.ReadCloser, bool)) bool {
+	source, ok := open(path)
+	if !ok {
+		return false
+	}
+	defer source.Close()
+	generated, err := IsGeneratedSource(source)
+	return err == nil && generated
+}
@@ -0,0 +1,49 @@
+package lcov
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsGeneratedSource(t *testing.T) {
+	for source, expected := range map[string]bool{
+		"// Code generated by protoc-gen-go. DO NOT EDIT.\n\npackage api\n":                true,
+		"//go:build linux\n\n// Code generated by stringer; DO NOT EDIT.\r\npackage api\n": true,
+		"// Package api does things.\npackage api\n":                                       false,
+		"package api\n\n// Code generated by hand. DO NOT EDIT.\n":                         false,
+		"// Code generated by hand, please DO NOT EDIT\npackage api\n":                     false,
+		"": false,
+	} {
+		generated, err := IsGeneratedSource(strings.NewReader(source))
+		require.NoError(t, err)
+		assert.Equal(t, expected, generated, source)
+	}
+}
+
+func TestExcludeGenerated(t *testing.T) {
+	files := []FileRecord{
+		{Path: "/src/repo/api/api.pb.go"},
+		{Path: "/src/repo/api/api.go"},
+		{Path: "/src/repo/api/enum_string.go"},
+		{Path: "/src/repo/main.go"},
+	}
+	sources := map[string]string{
+		"/src/repo/api/api.go":         "package api\n",
+		"/src/repo/api/enum_string.go": "// Code generated by \"stringer -type=Enum\"; DO NOT EDIT.\n\npackage api\n",
+	}
+	open := func(path string) (io.ReadCloser, bool) {
+		source, ok := sources[path]
+		return io.NopCloser(strings.NewReader(source)), ok
+	}
+
+	kept, excluded := ExcludeGenerated(files, DefaultGeneratedPatterns, open)
+	assert.Equal(t, []FileRecord{{Path: "/src/repo/api/api.go"}, {Path: "/src/repo/main.go"}}, kept)
+	assert.Equal(t, []string{"/src/repo/api/api.pb.go", "/src/repo/api/enum_string.go"}, excluded)
+
+	kept, _ = ExcludeGenerated(files, nil, nil)
+	assert.Equal(t, files, kept)
+}
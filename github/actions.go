@@ -0,0 +1,151 @@
+// Package github integrates coverage summaries with GitHub: Actions job summaries,
+// outputs and annotations, and pull request comments.
+package github
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	lcov "github.com/shastick/go-lcov-summary"
+)
+
+// Actions holds the paths of the files through which a GitHub Actions step
+// reports its job summary and outputs
+type Actions struct {
+	// StepSummary is the markdown file shown on the job summary page ($GITHUB_STEP_SUMMARY)
+	StepSummary string
+	// Output is the file setting the step outputs ($GITHUB_OUTPUT)
+	Output string
+	// Workspace is the checkout directory, stripped from annotated paths ($GITHUB_WORKSPACE)
+	Workspace string
+}
+
+// FromEnv returns the GitHub Actions files of the current step, and false when not running in GitHub Actions
+func FromEnv() (Actions, bool) {
+	a := Actions{
+		StepSummary: os.Getenv("GITHUB_STEP_SUMMARY"),
+		Output:      os.Getenv("GITHUB_OUTPUT"),
+		Workspace:   os.Getenv("GITHUB_WORKSPACE"),
+	}
+	return a, os.Getenv("GITHUB_ACTIONS") == "true"
+}
+
+// Outputs returns the step outputs describing a summary: coverage-lines,
+// coverage-functions and coverage-branches rates, for the metrics with data
+func Outputs(s *lcov.Summary) map[string]string {
+	outputs := make(map[string]string)
+	if s.TotalLines > 0 {
+		outputs["coverage-lines"] = fmt.Sprintf("%.1f", s.LineCoverageRate)
+	}
+	if s.TotalFunctions > 0 {
+		outputs["coverage-functions"] = fmt.Sprintf("%.1f", s.FunctionCoverageRate)
+	}
+	if s.TotalBranches > 0 {
+		outputs["coverage-branches"] = fmt.Sprintf("%.1f", s.BranchCoverageRate)
+	}
+	return outputs
+}
+
+// Report appends a markdown table of the summary to the job summary and sets the
+// step outputs. Files that aren't configured are skipped.
+func (a Actions) Report(s *lcov.Summary) error {
+	if a.StepSummary != "" {
+		if err := appendFile(a.StepSummary, func(w io.Writer) error { return WriteMarkdown(w, s) }); err != nil {
+			return fmt.Errorf("error writing the job summary: %w", err)
+		}
+	}
+	if a.Output != "" {
+		outputs := Outputs(s)
+		names := make([]string, 0, len(outputs))
+		for name := range outputs {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		err := appendFile(a.Output, func(w io.Writer) error {
+			for _, name := range names {
+				if _, err := fmt.Fprintf(w, "%s=%s\n", name, outputs[name]); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return fmt.Errorf("error setting the step outputs: %w", err)
+		}
+	}
+	return nil
+}
+
+// WriteMarkdown writes the summary as a markdown table
+func WriteMarkdown(w io.Writer, s *lcov.Summary) error {
+	var b strings.Builder
+	b.WriteString("### Coverage summary\n\n")
+	b.WriteString("| Metric | Rate | Covered | Total |\n")
+	b.WriteString("| --- | ---: | ---: | ---: |\n")
+	for _, m := range metrics(s, nil) {
+		fmt.Fprintf(&b, "| %s | %s | %d | %d |\n", m.name, formatRate(m.hit, m.found, m.rate), m.hit, m.found)
+	}
+	fmt.Fprintf(&b, "\n%d source files\n\n", s.TotalFiles)
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// WriteAnnotations writes a '::warning' workflow command for every file whose line
+// coverage is below the threshold, which GitHub shows on the file in the run and
+// pull request views. Paths are made relative to the workspace.
+func (a Actions) WriteAnnotations(w io.Writer, files []lcov.FileRecord, threshold float64) error {
+	for _, f := range files {
+		if f.LinesFound == 0 {
+			continue
+		}
+		rate := float64(f.LinesHit) / float64(f.LinesFound) * 100
+		if rate >= threshold {
+			continue
+		}
+		path := f.Path
+		if a.Workspace != "" {
+			path = strings.TrimPrefix(strings.TrimPrefix(path, strings.TrimSuffix(a.Workspace, "/")), "/")
+		}
+		message := fmt.Sprintf("Line coverage %.1f%% (%d of %d lines) is below %.1f%%", rate, f.LinesHit, f.LinesFound, threshold)
+		if _, err := fmt.Fprintf(w, "::warning file=%s,line=1,title=%s::%s\n",
+			escapeProperty(path), escapeProperty("Low coverage"), escapeData(message)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WriteWarning writes a '::warning' workflow command not tied to a file, which
+// GitHub shows on the run summary, e.g. for a coverage check let through
+func WriteWarning(w io.Writer, title, message string) error {
+	_, err := fmt.Fprintf(w, "::warning title=%s::%s\n", escapeProperty(title), escapeData(message))
+	return err
+}
+
+// escapeData escapes the message of a workflow command
+func escapeData(s string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A").Replace(s)
+}
+
+// escapeProperty escapes a property value of a workflow command
+func escapeProperty(s string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A", ":", "%3A", ",", "%2C").Replace(s)
+}
+
+// appendFile appends the output of write to a file, creating it if needed
+func appendFile(path string, write func(w io.Writer) error) error {
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	if err := write(file); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
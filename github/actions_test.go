@@ -0,0 +1,73 @@
+package github
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	lcov "github.com/shastick/go-lcov-summary"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFromEnv(t *testing.T) {
+	t.Setenv("GITHUB_ACTIONS", "true")
+	t.Setenv("GITHUB_STEP_SUMMARY", "/tmp/summary.md")
+	t.Setenv("GITHUB_OUTPUT", "/tmp/output")
+	t.Setenv("GITHUB_WORKSPACE", "/home/runner/work/repo")
+
+	a, ok := FromEnv()
+	assert.True(t, ok)
+	assert.Equal(t, Actions{StepSummary: "/tmp/summary.md", Output: "/tmp/output", Workspace: "/home/runner/work/repo"}, a)
+
+	t.Setenv("GITHUB_ACTIONS", "")
+	_, ok = FromEnv()
+	assert.False(t, ok)
+}
+
+func TestReport(t *testing.T) {
+	dir := t.TempDir()
+	a := Actions{StepSummary: filepath.Join(dir, "summary.md"), Output: filepath.Join(dir, "output")}
+	require.NoError(t, os.WriteFile(a.Output, []byte("previous=1\n"), 0o644))
+
+	summary := &lcov.Summary{TotalFiles: 2, TotalLines: 15, CoveredLines: 11, LineCoverageRate: 11.0 / 15 * 100}
+	require.NoError(t, a.Report(summary))
+
+	markdown, err := os.ReadFile(a.StepSummary)
+	require.NoError(t, err)
+	assert.Equal(t, `### Coverage summary
+
+| Metric | Rate | Covered | Total |
+| --- | ---: | ---: | ---: |
+| Lines | 73.3% | 11 | 15 |
+| Functions | n/a | 0 | 0 |
+| Branches | n/a | 0 | 0 |
+
+2 source files
+
+`, string(markdown))
+
+	outputs, err := os.ReadFile(a.Output)
+	require.NoError(t, err)
+	assert.Equal(t, "previous=1\ncoverage-lines=73.3\n", string(outputs))
+}
+
+func TestWriteAnnotations(t *testing.T) {
+	a := Actions{Workspace: "/home/runner/work/repo/"}
+	files := []lcov.FileRecord{
+		{Path: "/home/runner/work/repo/pkg/a,b.go", LinesFound: 4, LinesHit: 1},
+		{Path: "/home/runner/work/repo/pkg/good.go", LinesFound: 4, LinesHit: 4},
+		{Path: "/elsewhere/empty.go"},
+	}
+
+	var out bytes.Buffer
+	require.NoError(t, a.WriteAnnotations(&out, files, 80))
+	assert.Equal(t, "::warning file=pkg/a%2Cb.go,line=1,title=Low coverage::Line coverage 25.0%25 (1 of 4 lines) is below 80.0%25\n", out.String())
+}
+
+func TestWriteWarning(t *testing.T) {
+	var out bytes.Buffer
+	require.NoError(t, WriteWarning(&out, "Coverage check", "line coverage 50.0% is below 80.0%"))
+	assert.Equal(t, "::warning title=Coverage check::line coverage 50.0%25 is below 80.0%25\n", out.String())
+}
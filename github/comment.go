@@ -0,0 +1,204 @@
+package github
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+
+	lcov "github.com/shastick/go-lcov-summary"
+)
+
+// DefaultAPIURL is the GitHub API used when none is configured
+const DefaultAPIURL = "https://api.github.com"
+
+// commentMarker identifies the comments posted by this package, so they are updated in place
+const commentMarker = "<!-- go-lcov-summary -->"
+
+// PullRequest identifies the pull request to comment on
+type PullRequest struct {
+	// APIURL is the GitHub API base URL, DefaultAPIURL if empty
+	APIURL string
+	Token  string
+	// Repo is the owner/name of the repository
+	Repo   string
+	Number string
+}
+
+// PullRequestFromEnv fills the pull request from GITHUB_TOKEN and the GitHub Actions environment
+func PullRequestFromEnv() PullRequest {
+	pr := PullRequest{
+		APIURL: os.Getenv("GITHUB_API_URL"),
+		Token:  os.Getenv("GITHUB_TOKEN"),
+		Repo:   os.Getenv("GITHUB_REPOSITORY"),
+	}
+	if ref := os.Getenv("GITHUB_REF"); strings.HasPrefix(ref, "refs/pull/") {
+		pr.Number = strings.Split(strings.TrimPrefix(ref, "refs/pull/"), "/")[0]
+	}
+	return pr
+}
+
+// CommentBody renders the pull request comment for a summary, with the delta of
+// every metric against the baseline summary if not nil
+func CommentBody(s, baseline *lcov.Summary) string {
+	var b strings.Builder
+	b.WriteString(commentMarker + "\n")
+	b.WriteString("### Coverage summary\n\n")
+	if baseline != nil {
+		b.WriteString("| Metric | Rate | Delta | Covered | Total |\n")
+		b.WriteString("| --- | ---: | ---: | ---: | ---: |\n")
+	} else {
+		b.WriteString("| Metric | Rate | Covered | Total |\n")
+		b.WriteString("| --- | ---: | ---: | ---: |\n")
+	}
+
+	for _, m := range metrics(s, baseline) {
+		fmt.Fprintf(&b, "| %s | %s |", m.name, formatRate(m.hit, m.found, m.rate))
+		if baseline != nil {
+			delta := "n/a"
+			if m.found > 0 && m.baseFound > 0 {
+				delta = fmt.Sprintf("%+.1f%%", m.rate-m.baseRate)
+			}
+			fmt.Fprintf(&b, " %s |", delta)
+		}
+		fmt.Fprintf(&b, " %d | %d |\n", m.hit, m.found)
+	}
+	fmt.Fprintf(&b, "\n%d source files\n", s.TotalFiles)
+	return b.String()
+}
+
+// metric is a row of the markdown tables
+type metric struct {
+	name       string
+	hit, found int64
+	rate       float64
+	baseFound  int64
+	baseRate   float64
+}
+
+// metrics returns the rows of a summary, with the baseline values if any
+func metrics(s, baseline *lcov.Summary) []metric {
+	rows := []metric{
+		{name: "Lines", hit: s.CoveredLines, found: s.TotalLines, rate: s.LineCoverageRate},
+		{name: "Functions", hit: s.CoveredFunctions, found: s.TotalFunctions, rate: s.FunctionCoverageRate},
+		{name: "Branches", hit: s.CoveredBranches, found: s.TotalBranches, rate: s.BranchCoverageRate},
+	}
+	if baseline != nil {
+		rows[0].baseFound, rows[0].baseRate = baseline.TotalLines, baseline.LineCoverageRate
+		rows[1].baseFound, rows[1].baseRate = baseline.TotalFunctions, baseline.FunctionCoverageRate
+		rows[2].baseFound, rows[2].baseRate = baseline.TotalBranches, baseline.BranchCoverageRate
+	}
+	return rows
+}
+
+// formatRate formats a coverage rate, or 'n/a' for metrics without data
+func formatRate(hit, found int64, rate float64) string {
+	if found == 0 {
+		return "n/a"
+	}
+	return fmt.Sprintf("%.1f%%", rate)
+}
+
+// issueComment is the part of the GitHub issue comment resource used here
+type issueComment struct {
+	ID      int64  `json:"id,omitempty"`
+	Body    string `json:"body"`
+	HTMLURL string `json:"html_url,omitempty"`
+}
+
+// UpsertComment posts the body as a comment on the pull request, or updates the
+// comment previously posted by this package in place, and returns its URL
+func UpsertComment(ctx context.Context, client *http.Client, pr PullRequest, body string) (string, error) {
+	if pr.Repo == "" || pr.Number == "" {
+		return "", fmt.Errorf("missing repository or pull request number")
+	}
+	if !strings.Contains(body, commentMarker) {
+		body = commentMarker + "\n" + body
+	}
+	apiURL := strings.TrimSuffix(pr.APIURL, "/")
+	if apiURL == "" {
+		apiURL = DefaultAPIURL
+	}
+
+	existing, err := findComment(ctx, client, pr, apiURL)
+	if err != nil {
+		return "", err
+	}
+
+	method, endpoint := http.MethodPost, fmt.Sprintf("%s/repos/%s/issues/%s/comments", apiURL, pr.Repo, pr.Number)
+	if existing != 0 {
+		method, endpoint = http.MethodPatch, fmt.Sprintf("%s/repos/%s/issues/comments/%d", apiURL, pr.Repo, existing)
+	}
+	var comment issueComment
+	if err := call(ctx, client, pr, method, endpoint, issueComment{Body: body}, &comment); err != nil {
+		return "", err
+	}
+	return comment.HTMLURL, nil
+}
+
+// findComment returns the ID of the comment previously posted on the pull request, 0 if none
+func findComment(ctx context.Context, client *http.Client, pr PullRequest, apiURL string) (int64, error) {
+	const perPage = 100
+	for page := 1; ; page++ {
+		var comments []issueComment
+		endpoint := fmt.Sprintf("%s/repos/%s/issues/%s/comments?per_page=%d&page=%d", apiURL, pr.Repo, pr.Number, perPage, page)
+		if err := call(ctx, client, pr, http.MethodGet, endpoint, nil, &comments); err != nil {
+			return 0, err
+		}
+		for _, comment := range comments {
+			if strings.Contains(comment.Body, commentMarker) {
+				return comment.ID, nil
+			}
+		}
+		if len(comments) < perPage {
+			return 0, nil
+		}
+	}
+}
+
+// call sends a GitHub API request with an optional JSON body, and decodes the JSON response into result
+func call(ctx context.Context, client *http.Client, pr PullRequest, method, endpoint string, body, result any) error {
+	var reader io.Reader
+	if body != nil {
+		payload, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(payload)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, endpoint, reader)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("X-GitHub-Api-Version", "2022-11-28")
+	if pr.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+pr.Token)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("error calling the GitHub API: %w", err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 10<<20))
+	if err != nil {
+		return fmt.Errorf("error calling the GitHub API: %w", err)
+	}
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("GitHub API request failed with status %s: %s", resp.Status, bytes.TrimSpace(data))
+	}
+	if err := json.Unmarshal(data, result); err != nil {
+		return fmt.Errorf("unexpected GitHub API response: %w", err)
+	}
+	return nil
+}
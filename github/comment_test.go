@@ -0,0 +1,86 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	lcov "github.com/shastick/go-lcov-summary"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPullRequestFromEnv(t *testing.T) {
+	t.Setenv("GITHUB_API_URL", "https://github.example.com/api/v3")
+	t.Setenv("GITHUB_TOKEN", "secret")
+	t.Setenv("GITHUB_REPOSITORY", "owner/repo")
+	t.Setenv("GITHUB_REF", "refs/pull/123/merge")
+
+	assert.Equal(t, PullRequest{APIURL: "https://github.example.com/api/v3", Token: "secret", Repo: "owner/repo", Number: "123"}, PullRequestFromEnv())
+}
+
+func TestCommentBody(t *testing.T) {
+	current := &lcov.Summary{TotalFiles: 2, TotalLines: 10, CoveredLines: 8, LineCoverageRate: 80, TotalBranches: 4, CoveredBranches: 1, BranchCoverageRate: 25}
+	baseline := &lcov.Summary{TotalLines: 10, CoveredLines: 7, LineCoverageRate: 70}
+
+	assert.Equal(t, `<!-- go-lcov-summary -->
+### Coverage summary
+
+| Metric | Rate | Delta | Covered | Total |
+| --- | ---: | ---: | ---: | ---: |
+| Lines | 80.0% | +10.0% | 8 | 10 |
+| Functions | n/a | n/a | 0 | 0 |
+| Branches | 25.0% | n/a | 1 | 4 |
+
+2 source files
+`, CommentBody(current, baseline))
+
+	assert.NotContains(t, CommentBody(current, nil), "Delta")
+}
+
+func TestUpsertComment(t *testing.T) {
+	var comments []issueComment
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	mux.HandleFunc("GET /repos/owner/repo/issues/7/comments", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer secret", r.Header.Get("Authorization"))
+		json.NewEncoder(w).Encode(append([]issueComment{{ID: 1, Body: "LGTM"}}, comments...))
+	})
+	mux.HandleFunc("POST /repos/owner/repo/issues/7/comments", func(w http.ResponseWriter, r *http.Request) {
+		var comment issueComment
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&comment))
+		comment.ID, comment.HTMLURL = 2, "https://github.com/owner/repo/pull/7#issuecomment-2"
+		comments = append(comments, comment)
+		json.NewEncoder(w).Encode(comment)
+	})
+	mux.HandleFunc("PATCH /repos/owner/repo/issues/comments/{id}", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "2", r.PathValue("id"))
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&comments[0]))
+		comments[0].ID = 2
+		comments[0].HTMLURL = fmt.Sprintf("https://github.com/owner/repo/pull/7#issuecomment-%s", r.PathValue("id"))
+		json.NewEncoder(w).Encode(comments[0])
+	})
+
+	pr := PullRequest{APIURL: server.URL, Token: "secret", Repo: "owner/repo", Number: "7"}
+	url, err := UpsertComment(context.Background(), server.Client(), pr, "first")
+	require.NoError(t, err)
+	assert.Equal(t, "https://github.com/owner/repo/pull/7#issuecomment-2", url)
+
+	// The second comment replaces the first one
+	_, err = UpsertComment(context.Background(), server.Client(), pr, "second")
+	require.NoError(t, err)
+	require.Len(t, comments, 1)
+	assert.Equal(t, commentMarker+"\nsecond", comments[0].Body)
+
+	_, err = UpsertComment(context.Background(), server.Client(), PullRequest{APIURL: server.URL}, "body")
+	assert.EqualError(t, err, "missing repository or pull request number")
+
+	pr.Number = "8"
+	_, err = UpsertComment(context.Background(), server.Client(), pr, "body")
+	assert.ErrorContains(t, err, "GitHub API request failed with status 404")
+}
@@ -0,0 +1,144 @@
+// Package gitlab integrates coverage summaries with GitLab: the coverage value
+// parsed from job logs, and merge request notes.
+package gitlab
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+
+	lcov "github.com/shastick/go-lcov-summary"
+)
+
+// DefaultAPIURL is the GitLab API used when none is configured
+const DefaultAPIURL = "https://gitlab.com/api/v4"
+
+// CoverageRegex is the job coverage regex matching CoverageLine, to set as the
+// 'coverage' keyword of the job in .gitlab-ci.yml
+const CoverageRegex = `/^Coverage: \d+\.\d+%/`
+
+// noteMarker identifies the notes posted by this package, so they are updated in place
+const noteMarker = "<!-- go-lcov-summary -->"
+
+// CoverageLine returns the line printed in the job log for GitLab to pick up the
+// line coverage with CoverageRegex
+func CoverageLine(s *lcov.Summary) string {
+	return fmt.Sprintf("Coverage: %.1f%%", s.LineCoverageRate)
+}
+
+// MergeRequest identifies the merge request to comment on
+type MergeRequest struct {
+	// APIURL is the GitLab API v4 base URL, DefaultAPIURL if empty
+	APIURL string
+	Token  string
+	// Project is the numeric ID or the path of the project, e.g. group/project
+	Project string
+	IID     string
+}
+
+// MergeRequestFromEnv fills the merge request from GITLAB_TOKEN and the GitLab CI environment
+func MergeRequestFromEnv() MergeRequest {
+	return MergeRequest{
+		APIURL:  os.Getenv("CI_API_V4_URL"),
+		Token:   os.Getenv("GITLAB_TOKEN"),
+		Project: os.Getenv("CI_PROJECT_ID"),
+		IID:     os.Getenv("CI_MERGE_REQUEST_IID"),
+	}
+}
+
+// note is the part of the GitLab note resource used here
+type note struct {
+	ID   int64  `json:"id,omitempty"`
+	Body string `json:"body"`
+}
+
+// UpsertNote posts the body as a note on the merge request, or updates the note
+// previously posted by this package in place
+func UpsertNote(ctx context.Context, client *http.Client, mr MergeRequest, body string) error {
+	if mr.Project == "" || mr.IID == "" {
+		return fmt.Errorf("missing project or merge request IID")
+	}
+	if !strings.Contains(body, noteMarker) {
+		body = noteMarker + "\n" + body
+	}
+	apiURL := strings.TrimSuffix(mr.APIURL, "/")
+	if apiURL == "" {
+		apiURL = DefaultAPIURL
+	}
+	notes := fmt.Sprintf("%s/projects/%s/merge_requests/%s/notes", apiURL, url.PathEscape(mr.Project), mr.IID)
+
+	existing, err := findNote(ctx, client, mr, notes)
+	if err != nil {
+		return err
+	}
+	if existing != 0 {
+		return call(ctx, client, mr, http.MethodPut, fmt.Sprintf("%s/%d", notes, existing), note{Body: body}, &note{})
+	}
+	return call(ctx, client, mr, http.MethodPost, notes, note{Body: body}, &note{})
+}
+
+// findNote returns the ID of the note previously posted on the merge request, 0 if none
+func findNote(ctx context.Context, client *http.Client, mr MergeRequest, endpoint string) (int64, error) {
+	const perPage = 100
+	for page := 1; ; page++ {
+		var notes []note
+		if err := call(ctx, client, mr, http.MethodGet, fmt.Sprintf("%s?per_page=%d&page=%d", endpoint, perPage, page), nil, &notes); err != nil {
+			return 0, err
+		}
+		for _, n := range notes {
+			if strings.Contains(n.Body, noteMarker) {
+				return n.ID, nil
+			}
+		}
+		if len(notes) < perPage {
+			return 0, nil
+		}
+	}
+}
+
+// call sends a GitLab API request with an optional JSON body, and decodes the JSON response into result
+func call(ctx context.Context, client *http.Client, mr MergeRequest, method, endpoint string, body, result any) error {
+	var reader io.Reader
+	if body != nil {
+		payload, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(payload)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, endpoint, reader)
+	if err != nil {
+		return err
+	}
+	if mr.Token != "" {
+		req.Header.Set("PRIVATE-TOKEN", mr.Token)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("error calling the GitLab API: %w", err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 10<<20))
+	if err != nil {
+		return fmt.Errorf("error calling the GitLab API: %w", err)
+	}
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("GitLab API request failed with status %s: %s", resp.Status, bytes.TrimSpace(data))
+	}
+	if err := json.Unmarshal(data, result); err != nil {
+		return fmt.Errorf("unexpected GitLab API response: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,64 @@
+package gitlab
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+
+	lcov "github.com/shastick/go-lcov-summary"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCoverageLine(t *testing.T) {
+	line := CoverageLine(&lcov.Summary{LineCoverageRate: 73.333})
+	assert.Equal(t, "Coverage: 73.3%", line)
+
+	regex := regexp.MustCompile(strings.Trim(CoverageRegex, "/"))
+	assert.True(t, regex.MatchString(line))
+}
+
+func TestMergeRequestFromEnv(t *testing.T) {
+	t.Setenv("CI_API_V4_URL", "https://gitlab.example.com/api/v4")
+	t.Setenv("GITLAB_TOKEN", "secret")
+	t.Setenv("CI_PROJECT_ID", "42")
+	t.Setenv("CI_MERGE_REQUEST_IID", "7")
+
+	assert.Equal(t, MergeRequest{APIURL: "https://gitlab.example.com/api/v4", Token: "secret", Project: "42", IID: "7"}, MergeRequestFromEnv())
+}
+
+func TestUpsertNote(t *testing.T) {
+	var notes []note
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	mux.HandleFunc("GET /projects/group%2Fproject/merge_requests/7/notes", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "secret", r.Header.Get("PRIVATE-TOKEN"))
+		json.NewEncoder(w).Encode(notes)
+	})
+	mux.HandleFunc("POST /projects/group%2Fproject/merge_requests/7/notes", func(w http.ResponseWriter, r *http.Request) {
+		var n note
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&n))
+		n.ID = 3
+		notes = append(notes, n)
+		json.NewEncoder(w).Encode(n)
+	})
+	mux.HandleFunc("PUT /projects/group%2Fproject/merge_requests/7/notes/3", func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&notes[0]))
+		notes[0].ID = 3
+		json.NewEncoder(w).Encode(notes[0])
+	})
+
+	mr := MergeRequest{APIURL: server.URL, Token: "secret", Project: "group/project", IID: "7"}
+	require.NoError(t, UpsertNote(context.Background(), server.Client(), mr, "first"))
+	require.NoError(t, UpsertNote(context.Background(), server.Client(), mr, "second"))
+	require.Len(t, notes, 1)
+	assert.Equal(t, noteMarker+"\nsecond", notes[0].Body)
+
+	assert.EqualError(t, UpsertNote(context.Background(), server.Client(), MergeRequest{}, "body"), "missing project or merge request IID")
+}
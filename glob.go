@@ -0,0 +1,46 @@
+package lcov
+
+import (
+	"path"
+	"strings"
+)
+
+// matchGlob reports whether a file path matches a glob pattern. Patterns are
+// matched segment by segment with path.Match semantics, and a '**' segment
+// matches any number of directories. Relative patterns may match at any directory
+// boundary, so 'pkg/*.go' matches '/src/repo/pkg/main.go' as SF paths are usually absolute.
+func matchGlob(pattern, name string) bool {
+	patternParts := strings.Split(strings.Trim(pattern, "/"), "/")
+	nameParts := strings.Split(strings.Trim(name, "/"), "/")
+
+	if strings.HasPrefix(pattern, "/") {
+		return matchSegments(patternParts, nameParts)
+	}
+	for i := range nameParts {
+		if matchSegments(patternParts, nameParts[i:]) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchSegments(pattern, name []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			for i := len(name); i >= 0; i-- {
+				if matchSegments(pattern[1:], name[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(name) == 0 {
+			return false
+		}
+		if ok, err := path.Match(pattern[0], name[0]); err != nil || !ok {
+			return false
+		}
+		pattern, name = pattern[1:], name[1:]
+	}
+	return len(name) == 0
+}
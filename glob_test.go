@@ -0,0 +1,32 @@
+package lcov
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMatchGlob(t *testing.T) {
+	tests := []struct {
+		pattern  string
+		name     string
+		expected bool
+	}{
+		{pattern: "pkg/crypto/*", name: "/src/repo/pkg/crypto/aes.go", expected: true},
+		{pattern: "pkg/crypto/*", name: "pkg/crypto/aes.go", expected: true},
+		{pattern: "pkg/crypto/*", name: "/src/repo/pkg/crypto/sub/aes.go", expected: false},
+		{pattern: "pkg/crypto/**", name: "/src/repo/pkg/crypto/sub/aes.go", expected: true},
+		{pattern: "**/*_test.go", name: "/src/repo/pkg/main_test.go", expected: true},
+		{pattern: "*_test.go", name: "/src/repo/pkg/main_test.go", expected: true},
+		{pattern: "vendor/*", name: "/src/repo/vendor/lib.go", expected: true},
+		{pattern: "/src/*/pkg/*.go", name: "/src/repo/pkg/main.go", expected: true},
+		{pattern: "/repo/pkg/*.go", name: "/src/repo/pkg/main.go", expected: false},
+		{pattern: "[", name: "main.go", expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pattern+" "+tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, matchGlob(tt.pattern, tt.name))
+		})
+	}
+}
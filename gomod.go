@@ -0,0 +1,112 @@
+package lcov
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// GoModule is a Go module of a repository
+type GoModule struct {
+	// Path is the module path declared by its go.mod, e.g. 'github.com/me/repo'
+	Path string
+	// Dir is the directory of its go.mod, relative to the repository root, '.'
+	// for the root module
+	Dir string
+}
+
+// ParseGoModPath returns the module path declared by a go.mod file
+func ParseGoModPath(r io.Reader) (string, error) {
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line, _, _ := strings.Cut(scanner.Text(), "//")
+		fields := strings.Fields(line)
+		if len(fields) != 2 || fields[0] != "module" {
+			continue
+		}
+		// The path may be quoted
+		if unquoted, err := strconv.Unquote(fields[1]); err == nil {
+			return unquoted, nil
+		}
+		return fields[1], nil
+	}
+	if err := scanner.Err(); err != nil {
+		return "", err
+	}
+	return "", errors.New("no module directive found in go.mod")
+}
+
+// PackageSummary is the coverage of the files of a Go package
+type PackageSummary struct {
+	// ImportPath is the import path of the package, or the directory of its
+	// files when they are outside of every module
+	ImportPath string
+	*Summary
+}
+
+// SummarizeByPackage computes the coverage summary of every Go package. The
+// path of a file is resolved against the modules: paths already starting with a
+// module path, like those of Go coverprofiles, are import paths, while the
+// others are made relative to root, the repository root, and belong to the
+// module of the closest directory holding a go.mod. Packages are sorted by
+// import path.
+func SummarizeByPackage(files []FileRecord, modules []GoModule, root string) []PackageSummary {
+	// The innermost module wins, so longer paths and directories are tried first
+	byPath := append([]GoModule(nil), modules...)
+	sort.SliceStable(byPath, func(i, j int) bool { return len(byPath[i].Path) > len(byPath[j].Path) })
+	byDir := append([]GoModule(nil), modules...)
+	sort.SliceStable(byDir, func(i, j int) bool { return len(byDir[i].Dir) > len(byDir[j].Dir) })
+
+	byPackage := make(map[string][]FileRecord)
+	for _, f := range MergeFiles(files) {
+		pkg := goPackage(f.Path, byPath, byDir, root)
+		byPackage[pkg] = append(byPackage[pkg], f)
+	}
+
+	summaries := make([]PackageSummary, 0, len(byPackage))
+	for pkg, packaged := range byPackage {
+		summaries = append(summaries, PackageSummary{ImportPath: pkg, Summary: SummarizeFiles(packaged)})
+	}
+	sort.Slice(summaries, func(i, j int) bool { return summaries[i].ImportPath < summaries[j].ImportPath })
+	return summaries
+}
+
+// goPackage returns the import path of the package of a file
+func goPackage(file string, byPath, byDir []GoModule, root string) string {
+	dir := path.Dir(file)
+	for _, m := range byPath {
+		if dir == m.Path || strings.HasPrefix(dir, m.Path+"/") {
+			return dir
+		}
+	}
+
+	relative := path.Dir(strings.TrimPrefix(file, strings.TrimSuffix(root, "/")+"/"))
+	for _, m := range byDir {
+		moduleDir := path.Clean(m.Dir)
+		switch {
+		case moduleDir == ".":
+			if !path.IsAbs(relative) && relative != ".." && !strings.HasPrefix(relative, "../") {
+				return path.Join(m.Path, relative)
+			}
+		case relative == moduleDir:
+			return m.Path
+		case strings.HasPrefix(relative, moduleDir+"/"):
+			return path.Join(m.Path, strings.TrimPrefix(relative, moduleDir+"/"))
+		}
+	}
+	return dir
+}
+
+// RenderPackages writes an aligned table of the coverage of every package, in
+// the layout of RenderList
+func RenderPackages(w io.Writer, packages []PackageSummary, opts ...RenderOption) error {
+	rows := make([]groupRow, len(packages))
+	for i, p := range packages {
+		rows[i] = groupRow{name: p.ImportPath, summary: p.Summary}
+	}
+	return renderGroups(w, "Package", rows, opts)
+}
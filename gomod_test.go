@@ -0,0 +1,58 @@
+package lcov
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseGoModPath(t *testing.T) {
+	path, err := ParseGoModPath(strings.NewReader("// The repository\nmodule github.com/me/repo // main module\n\ngo 1.23\n"))
+	require.NoError(t, err)
+	assert.Equal(t, "github.com/me/repo", path)
+
+	path, err = ParseGoModPath(strings.NewReader(`module "example.com/quoted"`))
+	require.NoError(t, err)
+	assert.Equal(t, "example.com/quoted", path)
+
+	_, err = ParseGoModPath(strings.NewReader("go 1.23\n"))
+	assert.EqualError(t, err, "no module directive found in go.mod")
+}
+
+func TestSummarizeByPackage(t *testing.T) {
+	modules := []GoModule{
+		{Path: "github.com/me/repo", Dir: "."},
+		{Path: "github.com/me/repo/tools", Dir: "tools"},
+	}
+	files := []FileRecord{
+		{Path: "/src/repo/main.go", LinesFound: 2, LinesHit: 2},
+		{Path: "/src/repo/pkg/api/api.go", LinesFound: 10, LinesHit: 8},
+		{Path: "github.com/me/repo/pkg/api/client.go", LinesFound: 10, LinesHit: 2},
+		{Path: "/src/repo/tools/gen/gen.go", LinesFound: 4, LinesHit: 1},
+		{Path: "/usr/lib/go/src/fmt/print.go", LinesFound: 1, LinesHit: 1},
+	}
+	packages := SummarizeByPackage(files, modules, "/src/repo")
+	require.Len(t, packages, 4)
+	assert.Equal(t, []string{"/usr/lib/go/src/fmt", "github.com/me/repo", "github.com/me/repo/pkg/api", "github.com/me/repo/tools/gen"},
+		[]string{packages[0].ImportPath, packages[1].ImportPath, packages[2].ImportPath, packages[3].ImportPath})
+	assert.Equal(t, 2, packages[2].TotalFiles)
+	assert.Equal(t, 50.0, packages[2].LineCoverageRate)
+	assert.Equal(t, 25.0, packages[3].LineCoverageRate)
+
+	// Paths out of the root don't belong to the root module
+	packages = SummarizeByPackage([]FileRecord{{Path: "../other/x.go", LinesFound: 1}}, modules, "/src/repo")
+	require.Len(t, packages, 1)
+	assert.Equal(t, "../other", packages[0].ImportPath)
+
+	var buf bytes.Buffer
+	require.NoError(t, RenderPackages(&buf, SummarizeByPackage(files[:2], modules, "/src/repo/")))
+	assert.Equal(t, ""+
+		"                          |     |Lines     |Functions |Branches\n"+
+		"Package                   |Files|Rate   Num|Rate   Num|Rate   Num\n"+
+		"=================================================================\n"+
+		"github.com/me/repo        |    1|100.0%   2|     -   0|     -   0\n"+
+		"github.com/me/repo/pkg/api|    1| 80.0%  10|     -   0|     -   0\n", buf.String())
+}
@@ -0,0 +1,172 @@
+package lcov
+
+import (
+	"html/template"
+	"strings"
+)
+
+// language is the lexical syntax of the source files highlighted by highlighter
+type language struct {
+	lineComment string
+	// blockComment are the delimiters of comments spanning lines, if any
+	blockComment [2]string
+	// quotes are the string delimiters, and rawQuotes those of strings spanning lines
+	quotes, rawQuotes string
+	keywords          map[string]bool
+}
+
+// keywordSet returns the set of space separated keywords
+func keywordSet(keywords string) map[string]bool {
+	set := make(map[string]bool)
+	for _, k := range strings.Fields(keywords) {
+		set[k] = true
+	}
+	return set
+}
+
+// cLike is the syntax of Go, C, C++, Java, JavaScript, Rust and their relatives,
+// whose keywords are highlighted together as they barely conflict
+var cLike = &language{
+	lineComment:  "//",
+	blockComment: [2]string{"/*", "*/"},
+	quotes:       `"'`,
+	rawQuotes:    "`",
+	keywords: keywordSet(`break case catch chan class const continue default defer do else enum
+		export extends false fallthrough final finally fn for func go goto if impl implements import
+		interface let map match mod mut new nil null package private protected pub public range return
+		select self static struct super switch this throw throws true try type typedef union unsafe use
+		var void volatile while yield async await char int long short float double bool auto unsigned
+		signed sizeof template typename namespace using virtual override delete extern inline`),
+}
+
+// hashComment is the syntax of Python, Ruby, shell and Perl scripts
+var hashComment = &language{
+	lineComment: "#",
+	quotes:      `"'`,
+	keywords: keywordSet(`and as assert async await begin break case class def del do elif else
+		elsif end ensure esac except fi finally for from function global if import in is lambda
+		local module next nil None not or pass raise rescue return self then True False unless until
+		when while with yield`),
+}
+
+// languages are the languages of the source files, by extension
+var languages = map[string]*language{
+	".go": cLike, ".c": cLike, ".h": cLike, ".cc": cLike, ".cpp": cLike, ".cxx": cLike, ".hpp": cLike,
+	".java": cLike, ".kt": cLike, ".scala": cLike, ".cs": cLike, ".swift": cLike, ".dart": cLike,
+	".js": cLike, ".jsx": cLike, ".mjs": cLike, ".ts": cLike, ".tsx": cLike, ".rs": cLike,
+	".py": hashComment, ".rb": hashComment, ".sh": hashComment, ".bash": hashComment, ".pl": hashComment,
+}
+
+// highlighter highlights source files line by line, keywords, strings, comments
+// and numbers being wrapped in spans of the kw, str, com and num classes. It
+// is a lexer of the common syntax of its language rather than a parser, which
+// is enough to make sources readable.
+type highlighter struct {
+	lang *language
+	// closing is the delimiter closing the comment or string the previous line
+	// ended in, if any, and class the class of its span
+	closing, class string
+}
+
+// newHighlighter returns the highlighter of the source files with an
+// extension, which only escapes those of unknown languages
+func newHighlighter(ext string) *highlighter {
+	lang, ok := languages[strings.ToLower(ext)]
+	if !ok {
+		lang = &language{}
+	}
+	return &highlighter{lang: lang}
+}
+
+// line returns the next line of the source file as highlighted HTML
+func (h *highlighter) line(text string) template.HTML {
+	var b strings.Builder
+	lang := h.lang
+	for i := 0; i < len(text); {
+		if h.closing != "" {
+			i = h.span(&b, text, i, i)
+			continue
+		}
+		rest := text[i:]
+		switch c := rest[0]; {
+		case lang.lineComment != "" && strings.HasPrefix(rest, lang.lineComment):
+			writeHTMLSpan(&b, "com", rest)
+			i = len(text)
+		case lang.blockComment[0] != "" && strings.HasPrefix(rest, lang.blockComment[0]):
+			h.closing, h.class = lang.blockComment[1], "com"
+			i = h.span(&b, text, i, i+len(lang.blockComment[0]))
+		case strings.IndexByte(lang.rawQuotes, c) >= 0:
+			h.closing, h.class = string(c), "str"
+			i = h.span(&b, text, i, i+1)
+		case strings.IndexByte(lang.quotes, c) >= 0:
+			end := stringEnd(rest, c)
+			writeHTMLSpan(&b, "str", rest[:end])
+			i += end
+		case isIdentifierByte(c):
+			end := 1
+			for end < len(rest) && isIdentifierByte(rest[end]) {
+				end++
+			}
+			switch word := rest[:end]; {
+			case isDigit(c):
+				writeHTMLSpan(&b, "num", word)
+			case lang.keywords[word]:
+				writeHTMLSpan(&b, "kw", word)
+			default:
+				b.WriteString(word)
+			}
+			i += end
+		default:
+			writeEscaped(&b, rest[:1])
+			i++
+		}
+	}
+	return template.HTML(b.String())
+}
+
+// span writes the comment or string spanning lines that starts at start, its
+// closing delimiter being looked for from from, and returns the index of the
+// text following it, which is the end of the line if it goes on
+func (h *highlighter) span(b *strings.Builder, text string, start, from int) int {
+	end := len(text)
+	if j := strings.Index(text[from:], h.closing); j >= 0 {
+		end = from + j + len(h.closing)
+		h.closing = ""
+	}
+	writeHTMLSpan(b, h.class, text[start:end])
+	return end
+}
+
+// stringEnd returns the length of the string literal opened by quote at the
+// start of text, backslash escapes included, or the length of text if it isn't closed
+func stringEnd(text string, quote byte) int {
+	for i := 1; i < len(text); i++ {
+		switch text[i] {
+		case '\\':
+			i++
+		case quote:
+			return i + 1
+		}
+	}
+	return len(text)
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isIdentifierByte(c byte) bool {
+	return c == '_' || isDigit(c) || (c|0x20 >= 'a' && c|0x20 <= 'z') || c >= 0x80
+}
+
+// writeHTMLSpan writes the escaped text in a span of the class
+func writeHTMLSpan(b *strings.Builder, class, text string) {
+	b.WriteString(`<span class="` + class + `">`)
+	writeEscaped(b, text)
+	b.WriteString("</span>")
+}
+
+// writeEscaped writes the text escaped for HTML
+func writeEscaped(b *strings.Builder, text string) {
+	b.WriteString(template.HTMLEscapeString(text))
+}
@@ -0,0 +1,195 @@
+package history
+
+import (
+	"fmt"
+	"html"
+	"io"
+	"math"
+	"strings"
+
+	lcov "github.com/shastick/go-lcov-summary"
+)
+
+// Dimensions of the trend chart, in pixels
+const (
+	chartWidth  = 640
+	chartHeight = 320
+	// Margins around the plot, for the axis labels and the legend
+	chartLeft   = 44
+	chartRight  = 16
+	chartTop    = 32
+	chartBottom = 28
+)
+
+// chartColors are the colors of the series, by metric
+var chartColors = map[lcov.Metric]string{
+	lcov.MetricLines:     "#1f77b4",
+	lcov.MetricFunctions: "#ff7f0e",
+	lcov.MetricBranches:  "#9467bd",
+}
+
+// chartLabels are the legends of the series, by metric
+var chartLabels = map[lcov.Metric]string{
+	lcov.MetricLines:     "lines",
+	lcov.MetricFunctions: "functions",
+	lcov.MetricBranches:  "branches",
+}
+
+// WriteTrendSVG writes an SVG line chart of the coverage of the entries over
+// time, with a series per metric having data, suitable for embedding in a
+// README or a dashboard. The plot is shaded red, yellow and green below, between
+// and above the thresholds, in the manner of genhtml, unless they are zero. The
+// vertical axis spans the recorded rates up to 100%. Entries are expected in
+// chronological order and are placed by time, or evenly when they share it.
+func WriteTrendSVG(w io.Writer, entries []Entry, thresholds lcov.ColorThresholds) error {
+	c := newChart(entries)
+	out := &chartWriter{w: w}
+	out.printf(`<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %[1]d %[2]d" role="img" aria-label="Coverage trend">`+"\n",
+		chartWidth, chartHeight)
+	out.printf(`<g font-family="Verdana,Geneva,DejaVu Sans,sans-serif" font-size="11" fill="#555">` + "\n")
+	out.printf(`<rect width="%d" height="%d" fill="#fff"/>`+"\n", chartWidth, chartHeight)
+
+	if thresholds != (lcov.ColorThresholds{}) {
+		bands := []struct {
+			from, to float64
+			color    string
+		}{
+			{0, thresholds.Medium, "#fde2e1"},
+			{thresholds.Medium, thresholds.High, "#fdf3d0"},
+			{thresholds.High, 100, "#e2f5de"},
+		}
+		for _, b := range bands {
+			from, to := max(b.from, c.low), min(b.to, 100)
+			if to > from {
+				out.printf(`<rect x="%d" y="%.1f" width="%d" height="%.1f" fill="%s"/>`+"\n",
+					chartLeft, c.y(to), chartWidth-chartLeft-chartRight, c.y(from)-c.y(to), b.color)
+			}
+		}
+	}
+
+	// Horizontal grid lines and their labels, every 10 or 20%
+	step := 10.0
+	if 100-c.low > 50 {
+		step = 20
+	}
+	for rate := 100.0; rate >= c.low; rate -= step {
+		out.printf(`<line x1="%d" y1="%.1f" x2="%d" y2="%.1f" stroke="#ccc" stroke-width="1"/>`+"\n",
+			chartLeft, c.y(rate), chartWidth-chartRight, c.y(rate))
+		out.printf(`<text x="%d" y="%.1f" text-anchor="end">%.0f%%</text>`+"\n", chartLeft-6, c.y(rate)+4, rate)
+	}
+
+	if len(entries) == 0 {
+		out.printf(`<text x="%d" y="%d" text-anchor="middle">No coverage recorded</text>`+"\n", chartWidth/2, chartHeight/2)
+	} else {
+		first, last := entries[0].Time.Format("2006-01-02"), entries[len(entries)-1].Time.Format("2006-01-02")
+		out.printf(`<text x="%d" y="%d">%s</text>`+"\n", chartLeft, chartHeight-8, first)
+		if len(entries) > 1 {
+			out.printf(`<text x="%d" y="%d" text-anchor="end">%s</text>`+"\n", chartWidth-chartRight, chartHeight-8, last)
+		}
+	}
+
+	legend := chartLeft
+	for _, metric := range lcov.Metrics {
+		points, path := c.series(metric)
+		if len(points) == 0 {
+			continue
+		}
+		color := chartColors[metric]
+		out.printf(`<path d="%s" fill="none" stroke="%s" stroke-width="2" stroke-linejoin="round"/>`+"\n", path, color)
+		for _, p := range points {
+			out.printf(`<circle cx="%.1f" cy="%.1f" r="3" fill="%s"><title>%s</title></circle>`+"\n", p.x, p.y, color, html.EscapeString(p.title))
+		}
+		out.printf(`<rect x="%d" y="12" width="12" height="3" fill="%s"/>`+"\n", legend, color)
+		out.printf(`<text x="%d" y="18">%s</text>`+"\n", legend+16, chartLabels[metric])
+		legend += 16 + len(chartLabels[metric])*7 + 20
+	}
+
+	out.printf("</g>\n</svg>\n")
+	return out.err
+}
+
+// chart maps the entries to the coordinates of the plot
+type chart struct {
+	entries []Entry
+	// low is the rate at the bottom of the plot
+	low float64
+}
+
+// newChart returns the chart of the entries, whose vertical axis starts at the
+// ten below their lowest rate, with some room
+func newChart(entries []Entry) *chart {
+	low := 100.0
+	for _, e := range entries {
+		for _, metric := range lcov.Metrics {
+			if rate, ok := e.Rate(metric); ok {
+				low = min(low, rate)
+			}
+		}
+	}
+	low = max(0, math.Floor((low-5)/10)*10)
+	return &chart{entries: entries, low: low}
+}
+
+// x returns the horizontal coordinate of the i-th entry
+func (c *chart) x(i int) float64 {
+	width := float64(chartWidth - chartLeft - chartRight)
+	if len(c.entries) < 2 {
+		return chartLeft + width/2
+	}
+	first, last := c.entries[0].Time, c.entries[len(c.entries)-1].Time
+	position := float64(i) / float64(len(c.entries)-1)
+	if span := last.Sub(first); span > 0 {
+		position = float64(c.entries[i].Time.Sub(first)) / float64(span)
+	}
+	return chartLeft + position*width
+}
+
+// y returns the vertical coordinate of a rate
+func (c *chart) y(rate float64) float64 {
+	height := float64(chartHeight - chartTop - chartBottom)
+	return chartTop + (100-rate)/(100-c.low)*height
+}
+
+// chartPoint is a data point of a series, with its tooltip
+type chartPoint struct {
+	x, y  float64
+	title string
+}
+
+// series returns the data points of a metric and the SVG path joining them,
+// interrupted by the entries without data for it
+func (c *chart) series(metric lcov.Metric) ([]chartPoint, string) {
+	var points []chartPoint
+	var path strings.Builder
+	command := "M"
+	for i, e := range c.entries {
+		rate, ok := e.Rate(metric)
+		if !ok {
+			command = "M"
+			continue
+		}
+		commit := e.Commit
+		if len(commit) > 12 {
+			commit = commit[:12]
+		}
+		p := chartPoint{x: c.x(i), y: c.y(rate), title: fmt.Sprintf("%s %s: %s coverage %.1f%%", commit, e.Time.Format("2006-01-02"), chartLabels[metric], rate)}
+		points = append(points, p)
+		fmt.Fprintf(&path, "%s%.1f %.1f ", command, p.x, p.y)
+		command = "L"
+	}
+	return points, strings.TrimSpace(path.String())
+}
+
+// chartWriter keeps the first write error, so that the chart is written without
+// checking every call
+type chartWriter struct {
+	w   io.Writer
+	err error
+}
+
+func (cw *chartWriter) printf(format string, args ...any) {
+	if cw.err != nil {
+		return
+	}
+	_, cw.err = fmt.Fprintf(cw.w, format, args...)
+}
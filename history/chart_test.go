@@ -0,0 +1,51 @@
+package history
+
+import (
+	"bytes"
+	"encoding/xml"
+	"strings"
+	"testing"
+
+	lcov "github.com/shastick/go-lcov-summary"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteTrendSVG(t *testing.T) {
+	history := entries(50, 60, 80, 70)
+	history[1].TotalBranches, history[1].CoveredBranches = 10, 9
+	history[2].TotalBranches, history[2].CoveredBranches = 10, 8
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteTrendSVG(&buf, history, lcov.DefaultColorThresholds))
+	svg := buf.String()
+	require.NoError(t, xml.Unmarshal(buf.Bytes(), new(struct{})), "well-formed SVG")
+
+	// The axis starts at 40%, below the lowest rate, and spans 640x320 pixels
+	assert.Contains(t, svg, `<text x="38" y="36.0" text-anchor="end">100%</text>`)
+	assert.Contains(t, svg, `<text x="38" y="296.0" text-anchor="end">40%</text>`)
+	assert.NotContains(t, svg, ">30%<")
+
+	// Bands below 75%, up to 90% and above
+	assert.Contains(t, svg, `<rect x="44" y="140.3" width="580" height="151.7" fill="#fde2e1"/>`)
+	assert.Contains(t, svg, `<rect x="44" y="75.3" width="580" height="65.0" fill="#fdf3d0"/>`)
+	assert.Contains(t, svg, `<rect x="44" y="32.0" width="580" height="43.3" fill="#e2f5de"/>`)
+
+	// A series per metric with data, placed by date
+	assert.Contains(t, svg, `<path d="M44.0 248.7 L237.3 205.3 L430.7 118.7 L624.0 162.0" fill="none" stroke="#1f77b4"`)
+	assert.Contains(t, svg, `<path d="M237.3 75.3 L430.7 118.7" fill="none" stroke="#9467bd"`)
+	assert.NotContains(t, svg, "#ff7f0e")
+	assert.Contains(t, svg, `<title>c 2024-01-03: lines coverage 80.0%</title>`)
+	assert.Contains(t, svg, `>2024-01-01</text>`)
+	assert.Contains(t, svg, `>2024-01-04</text>`)
+	assert.Equal(t, 2, strings.Count(svg, "<path "))
+
+	// Without thresholds, there are no bands
+	buf.Reset()
+	require.NoError(t, WriteTrendSVG(&buf, history, lcov.ColorThresholds{}))
+	assert.NotContains(t, buf.String(), "#fde2e1")
+
+	buf.Reset()
+	require.NoError(t, WriteTrendSVG(&buf, nil, lcov.DefaultColorThresholds))
+	assert.Contains(t, buf.String(), "No coverage recorded")
+}
@@ -0,0 +1,99 @@
+// Package history keeps track of coverage summaries over successive runs and
+// gates new results against them. Histories are stored as JSON-lines files,
+// one Entry per line, rather than in a database.
+package history
+
+import (
+	"fmt"
+	"time"
+
+	lcov "github.com/shastick/go-lcov-summary"
+)
+
+// Entry is the coverage recorded for a single run
+type Entry struct {
+	Commit           string    `json:"commit"`
+	Time             time.Time `json:"time"`
+	TotalLines       int64     `json:"total_lines"`
+	CoveredLines     int64     `json:"covered_lines"`
+	TotalFunctions   int64     `json:"total_functions"`
+	CoveredFunctions int64     `json:"covered_functions"`
+	TotalBranches    int64     `json:"total_branches"`
+	CoveredBranches  int64     `json:"covered_branches"`
+}
+
+// NewEntry creates an entry from a summary
+func NewEntry(commit string, t time.Time, s *lcov.Summary) Entry {
+	return Entry{
+		Commit:           commit,
+		Time:             t,
+		TotalLines:       s.TotalLines,
+		CoveredLines:     s.CoveredLines,
+		TotalFunctions:   s.TotalFunctions,
+		CoveredFunctions: s.CoveredFunctions,
+		TotalBranches:    s.TotalBranches,
+		CoveredBranches:  s.CoveredBranches,
+	}
+}
+
+// Rate returns the coverage percentage of a metric, and false when the entry has no data for it
+func (e Entry) Rate(metric lcov.Metric) (float64, bool) {
+	var covered, total int64
+	switch metric {
+	case lcov.MetricLines:
+		covered, total = e.CoveredLines, e.TotalLines
+	case lcov.MetricFunctions:
+		covered, total = e.CoveredFunctions, e.TotalFunctions
+	case lcov.MetricBranches:
+		covered, total = e.CoveredBranches, e.TotalBranches
+	}
+	if total == 0 {
+		return 0, false
+	}
+	return float64(covered) / float64(total) * 100, true
+}
+
+// MovingAverage returns the average coverage percentage of a metric over the last n
+// entries having data for it. Entries are expected in chronological order.
+// It returns false when none of the entries has data for the metric.
+func MovingAverage(entries []Entry, metric lcov.Metric, n int) (float64, bool) {
+	var sum float64
+	var count int
+	for i := len(entries) - 1; i >= 0 && count < n; i-- {
+		if rate, ok := entries[i].Rate(metric); ok {
+			sum += rate
+			count++
+		}
+	}
+	if count == 0 {
+		return 0, false
+	}
+	return sum / float64(count), true
+}
+
+// GateMovingAverage compares a summary against the moving average of the last n
+// entries instead of a single baseline, which smooths out the noise of slightly
+// nondeterministic coverage. A metric fails when it is more than tolerance
+// percentage points below its average, the violation requiring the average less
+// the tolerance. Metrics without data on either side are skipped.
+func GateMovingAverage(s *lcov.Summary, entries []Entry, n int, tolerance float64) ([]lcov.ThresholdViolation, error) {
+	if n <= 0 {
+		return nil, fmt.Errorf("invalid moving average window: %d", n)
+	}
+
+	var violations []lcov.ThresholdViolation
+	for _, metric := range lcov.Metrics {
+		rate, ok := s.Rate(metric)
+		if !ok {
+			continue
+		}
+		average, ok := MovingAverage(entries, metric, n)
+		if !ok {
+			continue
+		}
+		if required := average - tolerance; rate < required {
+			violations = append(violations, lcov.ThresholdViolation{Metric: string(metric), Rate: rate, Required: required})
+		}
+	}
+	return violations, nil
+}
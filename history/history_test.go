@@ -0,0 +1,67 @@
+package history
+
+import (
+	"testing"
+	"time"
+
+	lcov "github.com/shastick/go-lcov-summary"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func entries(lineRates ...int64) []Entry {
+	var result []Entry
+	for i, covered := range lineRates {
+		result = append(result, Entry{
+			Commit:       string(rune('a' + i)),
+			Time:         time.Date(2024, 1, i+1, 0, 0, 0, 0, time.UTC),
+			TotalLines:   100,
+			CoveredLines: covered,
+		})
+	}
+	return result
+}
+
+func TestMovingAverage(t *testing.T) {
+	history := entries(50, 80, 82, 84)
+
+	average, ok := MovingAverage(history, lcov.MetricLines, 3)
+	require.True(t, ok)
+	assert.InDelta(t, 82.0, average, 0.001)
+
+	// Window larger than the history uses every entry
+	average, ok = MovingAverage(history, lcov.MetricLines, 10)
+	require.True(t, ok)
+	assert.InDelta(t, 74.0, average, 0.001)
+
+	_, ok = MovingAverage(history, lcov.MetricBranches, 3)
+	assert.False(t, ok)
+}
+
+func TestGateMovingAverage(t *testing.T) {
+	history := entries(80, 82, 84)
+
+	tests := []struct {
+		name       string
+		covered    int64
+		tolerance  float64
+		violations []lcov.ThresholdViolation
+	}{
+		{name: "above average", covered: 83},
+		{name: "within tolerance", covered: 81, tolerance: 1},
+		{name: "regression", covered: 79, tolerance: 1,
+			violations: []lcov.ThresholdViolation{{Metric: "line", Rate: 79, Required: 81}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			summary := &lcov.Summary{TotalLines: 100, CoveredLines: tt.covered}
+			violations, err := GateMovingAverage(summary, history, 3, tt.tolerance)
+			require.NoError(t, err)
+			assert.Equal(t, tt.violations, violations)
+		})
+	}
+
+	_, err := GateMovingAverage(&lcov.Summary{}, history, 0, 0)
+	assert.EqualError(t, err, "invalid moving average window: 0")
+}
@@ -0,0 +1,50 @@
+package history
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecordAndLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.jsonl")
+
+	loaded, err := Load(path)
+	require.NoError(t, err)
+	assert.Empty(t, loaded)
+
+	history := entries(50, 60, 70)
+	for _, e := range history {
+		require.NoError(t, Record(path, e))
+	}
+	loaded, err = Load(path)
+	require.NoError(t, err)
+	assert.Equal(t, history, loaded)
+
+	// The history is readable by others, such as a web server, and no temporary file is left
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o644), info.Mode().Perm())
+	files, err := os.ReadDir(filepath.Dir(path))
+	require.NoError(t, err)
+	assert.Len(t, files, 1)
+
+	// Recording a commit again replaces its entry
+	again := history[1]
+	again.CoveredLines = 65
+	require.NoError(t, Record(path, again))
+	loaded, err = Load(path)
+	require.NoError(t, err)
+	require.Len(t, loaded, 3)
+	assert.Equal(t, int64(65), loaded[1].CoveredLines)
+}
+
+func TestLoadInvalid(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.jsonl")
+	require.NoError(t, os.WriteFile(path, []byte("{}\nnot json\n"), 0o644))
+	_, err := Load(path)
+	assert.ErrorContains(t, err, "history.jsonl:2: invalid history entry")
+}
@@ -0,0 +1,79 @@
+package history
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	lcov "github.com/shastick/go-lcov-summary"
+)
+
+// sparkBars are the bars of a sparkline, from lowest to highest
+var sparkBars = []rune("▁▂▃▄▅▆▇█")
+
+// WriteTrend writes a table of the coverage of the last n entries, n <= 0 meaning all
+// of them, followed by a sparkline of the line coverage.
+func WriteTrend(w io.Writer, entries []Entry, n int) error {
+	if n > 0 && len(entries) > n {
+		entries = entries[len(entries)-n:]
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "Commit\tDate\tLines\tFunctions\tBranches\n")
+	for _, e := range entries {
+		commit := e.Commit
+		if len(commit) > 12 {
+			commit = commit[:12]
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", commit, e.Time.Format("2006-01-02"),
+			formatRate(e, lcov.MetricLines), formatRate(e, lcov.MetricFunctions), formatRate(e, lcov.MetricBranches))
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	if spark := Sparkline(entries, lcov.MetricLines); spark != "" {
+		if _, err := fmt.Fprintf(w, "\nLine coverage: %s\n", spark); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Sparkline draws the coverage of a metric over the entries, scaled between its
+// lowest and highest values. Entries without data for the metric are drawn as a space.
+func Sparkline(entries []Entry, metric lcov.Metric) string {
+	low, high := 100.0, 0.0
+	for _, e := range entries {
+		if rate, ok := e.Rate(metric); ok {
+			low, high = min(low, rate), max(high, rate)
+		}
+	}
+	if low > high {
+		return ""
+	}
+
+	var b strings.Builder
+	for _, e := range entries {
+		rate, ok := e.Rate(metric)
+		switch {
+		case !ok:
+			b.WriteRune(' ')
+		case high == low:
+			b.WriteRune(sparkBars[len(sparkBars)-1])
+		default:
+			b.WriteRune(sparkBars[int((rate-low)/(high-low)*float64(len(sparkBars)-1))])
+		}
+	}
+	return b.String()
+}
+
+// formatRate formats the coverage of a metric, '-' when the entry has no data for it
+func formatRate(e Entry, metric lcov.Metric) string {
+	rate, ok := e.Rate(metric)
+	if !ok {
+		return "-"
+	}
+	return fmt.Sprintf("%.1f%%", rate)
+}
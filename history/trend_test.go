@@ -0,0 +1,28 @@
+package history
+
+import (
+	"bytes"
+	"testing"
+
+	lcov "github.com/shastick/go-lcov-summary"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteTrend(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, WriteTrend(&buf, entries(50, 60, 80, 70), 3))
+	assert.Equal(t, ""+
+		"Commit  Date        Lines  Functions  Branches\n"+
+		"b       2024-01-02  60.0%  -          -\n"+
+		"c       2024-01-03  80.0%  -          -\n"+
+		"d       2024-01-04  70.0%  -          -\n"+
+		"\n"+
+		"Line coverage: ▁█▄\n", buf.String())
+}
+
+func TestSparkline(t *testing.T) {
+	assert.Equal(t, "▁▄█", Sparkline(entries(10, 55, 100), lcov.MetricLines))
+	assert.Equal(t, "██", Sparkline(entries(40, 40), lcov.MetricLines))
+	assert.Empty(t, Sparkline(entries(40, 40), lcov.MetricBranches))
+}
@@ -0,0 +1,76 @@
+package lcov
+
+import (
+	"sort"
+)
+
+// Hotspot is a range of uncovered lines next to executed code. Code skipped
+// right next to lines run millions of times is more likely a missing test of a
+// hot path than dead code.
+type Hotspot struct {
+	File  string
+	Lines LineRange
+	// Heat is the execution count of the most executed of the instrumented
+	// lines right before and after the range, 0 when the range isn't next to
+	// any executed line
+	Heat int64
+}
+
+// Hotspots returns the ranges of uncovered lines of the files whose heat is at
+// least minHeat, hottest first, then by file and line. Unlike the coverage
+// rates, which only tell whether a line was executed, the heat makes use of
+// the execution counts of the DA records. The files must have been parsed
+// WithDetails; blocks of the same file are merged first.
+func Hotspots(files []FileRecord, minHeat int64) []Hotspot {
+	var hotspots []Hotspot
+	for _, f := range MergeFiles(files) {
+		for _, h := range fileHotspots(f.Path, f.Lines) {
+			if h.Heat >= minHeat {
+				hotspots = append(hotspots, h)
+			}
+		}
+	}
+	sort.SliceStable(hotspots, func(i, j int) bool {
+		if hotspots[i].Heat != hotspots[j].Heat {
+			return hotspots[i].Heat > hotspots[j].Heat
+		}
+		if hotspots[i].File != hotspots[j].File {
+			return hotspots[i].File < hotspots[j].File
+		}
+		return hotspots[i].Lines.Start < hotspots[j].Lines.Start
+	})
+	return hotspots
+}
+
+// fileHotspots returns the uncovered ranges of the lines of a file, sorted by
+// line, with the counts of their neighbours as heat
+func fileHotspots(path string, lines []LineData) []Hotspot {
+	sorted := append([]LineData(nil), lines...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Line < sorted[j].Line })
+
+	var hotspots []Hotspot
+	// previous is the count of the last executed line
+	previous := int64(0)
+	open := false
+	for i := 0; i < len(sorted); {
+		line, count := sorted[i].Line, int64(0)
+		for ; i < len(sorted) && sorted[i].Line == line; i++ {
+			count = addCount(count, sorted[i].Count)
+		}
+		switch {
+		case count > 0:
+			if open {
+				last := &hotspots[len(hotspots)-1]
+				last.Heat = max(last.Heat, count)
+				open = false
+			}
+			previous = count
+		case open:
+			hotspots[len(hotspots)-1].Lines.End = line
+		default:
+			hotspots = append(hotspots, Hotspot{File: path, Lines: LineRange{Start: line, End: line}, Heat: previous})
+			open = true
+		}
+	}
+	return hotspots
+}
@@ -0,0 +1,29 @@
+package lcov
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHotspots(t *testing.T) {
+	files := []FileRecord{
+		{Path: "b.go", Lines: []LineData{{Line: 1, Count: 0}, {Line: 2, Count: 7}}},
+		{Path: "a.go", Lines: []LineData{
+			{Line: 1, Count: 1000}, {Line: 2, Count: 0}, {Line: 4, Count: 0}, {Line: 5, Count: 5},
+			{Line: 6, Count: 0}, {Line: 7, Count: 3},
+		}},
+		// Blocks of the same file are merged before looking for uncovered lines
+		{Path: "a.go", Lines: []LineData{{Line: 7, Count: 0}, {Line: 9, Count: 0}}},
+		{Path: "c.go", Lines: []LineData{{Line: 1, Count: 0}}},
+	}
+	assert.Equal(t, []Hotspot{
+		{File: "a.go", Lines: LineRange{Start: 2, End: 4}, Heat: 1000},
+		{File: "b.go", Lines: LineRange{Start: 1, End: 1}, Heat: 7},
+		{File: "a.go", Lines: LineRange{Start: 6, End: 6}, Heat: 5},
+		{File: "a.go", Lines: LineRange{Start: 9, End: 9}, Heat: 3},
+		{File: "c.go", Lines: LineRange{Start: 1, End: 1}, Heat: 0},
+	}, Hotspots(files, 0))
+
+	assert.Len(t, Hotspots(files, 6), 2)
+}
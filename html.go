@@ -0,0 +1,278 @@
+package lcov
+
+import (
+	"bufio"
+	"fmt"
+	"html/template"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// WriteHTMLReport writes an HTML report of a summary to dir, in the manner of
+// genhtml: index.html lists the source files with their coverage, and links to
+// a page per file showing its source, highlighted, with the execution count of
+// every line and the branches of every line, taken (+), not taken (-) or never
+// evaluated (#), in the gutter, and an anchor per line, e.g. main.go.html#L12.
+// Sources are read with open, the files whose source isn't found showing their
+// line data alone. The pages are laid out as the source tree, below the
+// directory shared by every file, and need nothing but the directory to be
+// browsed. The summary must have been parsed WithDetails.
+func WriteHTMLReport(dir string, s *Summary, open func(path string) (io.ReadCloser, bool)) error {
+	files := MergeFiles(s.Files)
+	prefix := commonDir(files)
+
+	index := htmlIndex{Title: "Coverage report", Totals: newHTMLRates(s.TotalLines, s.CoveredLines, s.TotalFunctions, s.CoveredFunctions, s.TotalBranches, s.CoveredBranches)}
+	for i := range files {
+		f := &files[i]
+		name := htmlPageName(strings.TrimPrefix(f.Path, prefix))
+		page, err := newHTMLFile(f, name, open)
+		if err != nil {
+			return err
+		}
+		if err := writeHTMLPage(filepath.Join(dir, filepath.FromSlash(name)), htmlFileTemplate, page); err != nil {
+			return err
+		}
+		index.Files = append(index.Files, htmlIndexFile{Path: strings.TrimPrefix(f.Path, prefix), Link: name, Rates: page.Rates})
+	}
+	return writeHTMLPage(filepath.Join(dir, "index.html"), htmlIndexTemplate, index)
+}
+
+// commonDir returns the directory shared by the paths of the files, with a
+// trailing slash, or "" if there is none
+func commonDir(files []FileRecord) string {
+	if len(files) == 0 {
+		return ""
+	}
+	prefix := path.Dir(files[0].Path) + "/"
+	for _, f := range files[1:] {
+		for !strings.HasPrefix(f.Path, prefix) {
+			parent := path.Dir(strings.TrimSuffix(prefix, "/"))
+			if parent == "." || parent+"/" == prefix {
+				return ""
+			}
+			prefix = strings.TrimSuffix(parent, "/") + "/"
+		}
+	}
+	if prefix == "./" {
+		return ""
+	}
+	return prefix
+}
+
+// htmlPageName returns the name of the page of a source file, relative to the
+// report directory, whose path is kept within it
+func htmlPageName(file string) string {
+	components := strings.Split(strings.TrimLeft(path.Clean("/"+file), "/"), "/")
+	for i, c := range components {
+		if c == ".." {
+			components[i] = "__"
+		}
+	}
+	return strings.Join(components, "/") + ".html"
+}
+
+// writeHTMLPage executes a page template to the file at name, creating its directory
+func writeHTMLPage(name string, tmpl *template.Template, data any) error {
+	if err := os.MkdirAll(filepath.Dir(name), 0o755); err != nil {
+		return err
+	}
+	out, err := os.Create(name)
+	if err != nil {
+		return err
+	}
+	if err := tmpl.Execute(out, data); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+// htmlRate is a coverage metric of a page
+type htmlRate struct {
+	Covered, Total int64
+	// Rate is the formatted rate, "-" without data, and Class its color class
+	Rate, Class string
+}
+
+// htmlRates are the coverage metrics of a page, by lines, functions and branches
+type htmlRates [3]htmlRate
+
+func newHTMLRates(lines, linesHit, functions, functionsHit, branches, branchesHit int64) htmlRates {
+	metric := func(hit, found int64) htmlRate {
+		r, ok := rate(hit, found)
+		if !ok {
+			return htmlRate{Rate: "-", Class: "none"}
+		}
+		class := "hi"
+		switch {
+		case r < DefaultColorThresholds.Medium:
+			class = "lo"
+		case r < DefaultColorThresholds.High:
+			class = "med"
+		}
+		return htmlRate{Covered: hit, Total: found, Rate: fmt.Sprintf("%.1f%%", r), Class: class}
+	}
+	return htmlRates{metric(linesHit, lines), metric(functionsHit, functions), metric(branchesHit, branches)}
+}
+
+// htmlIndex are the values of htmlIndexTemplate
+type htmlIndex struct {
+	Title  string
+	Totals htmlRates
+	Files  []htmlIndexFile
+}
+
+type htmlIndexFile struct {
+	Path, Link string
+	Rates      htmlRates
+}
+
+// htmlFile are the values of htmlFileTemplate
+type htmlFile struct {
+	Path string
+	// Index is the link to the index page
+	Index   string
+	Rates   htmlRates
+	Missing bool
+	Lines   []htmlLine
+}
+
+// htmlLine is a source line of a file page
+type htmlLine struct {
+	Number int
+	// Count is the formatted execution count, and Class "hit", "miss" or "" when not instrumented
+	Count, Class string
+	Branches     []htmlBranch
+	Source       template.HTML
+}
+
+// htmlBranch is a branch marker of the gutter
+type htmlBranch struct {
+	Mark, Class, Title string
+}
+
+// newHTMLFile returns the page of a merged, detailed file record, named name
+func newHTMLFile(f *FileRecord, name string, open func(path string) (io.ReadCloser, bool)) (*htmlFile, error) {
+	page := &htmlFile{
+		Path:  f.Path,
+		Index: strings.Repeat("../", strings.Count(name, "/")) + "index.html",
+		Rates: newHTMLRates(f.LinesFound, f.LinesHit, f.FunctionsFound, f.FunctionsHit, f.BranchesFound, f.BranchesHit),
+	}
+
+	var source []string
+	if reader, ok := open(f.Path); ok {
+		defer reader.Close()
+		scanner := bufio.NewScanner(reader)
+		scanner.Buffer(make([]byte, 0, 64*1024), 10*1024*1024)
+		for scanner.Scan() {
+			source = append(source, scanner.Text())
+		}
+		if err := scanner.Err(); err != nil {
+			return nil, fmt.Errorf("error reading source of %s: %w", f.Path, err)
+		}
+	} else {
+		page.Missing = true
+	}
+
+	counts := make(map[int]int64, len(f.Lines))
+	last := len(source)
+	for _, l := range f.Lines {
+		counts[l.Line] = addCount(counts[l.Line], l.Count)
+		last = max(last, l.Line)
+	}
+	branches := make(map[int][]htmlBranch)
+	for _, b := range f.Branches {
+		marker := htmlBranch{Mark: "+", Class: "taken", Title: fmt.Sprintf("Branch %d of block %d taken %d times", b.Branch, b.Block, b.Taken)}
+		switch {
+		case b.Taken < 0:
+			marker = htmlBranch{Mark: "#", Class: "none", Title: fmt.Sprintf("Branch %d of block %d never evaluated", b.Branch, b.Block)}
+		case b.Taken == 0:
+			marker = htmlBranch{Mark: "-", Class: "untaken", Title: fmt.Sprintf("Branch %d of block %d not taken", b.Branch, b.Block)}
+		}
+		branches[b.Line] = append(branches[b.Line], marker)
+	}
+
+	h := newHighlighter(path.Ext(f.Path))
+	for number := 1; number <= last; number++ {
+		line := htmlLine{Number: number, Branches: branches[number]}
+		if count, ok := counts[number]; ok {
+			line.Count, line.Class = fmt.Sprint(count), "hit"
+			if count == 0 {
+				line.Count, line.Class = "0", "miss"
+			}
+		}
+		if number <= len(source) {
+			line.Source = h.line(source[number-1])
+		}
+		page.Lines = append(page.Lines, line)
+	}
+	return page, nil
+}
+
+// htmlStyle is the style sheet of the pages, inlined so that they are self-contained
+const htmlStyle = `<style>
+body { font-family: sans-serif; margin: 2em; color: #222; }
+table { border-collapse: collapse; }
+th, td { padding: .3em 1em; text-align: right; border-bottom: 1px solid #ddd; }
+th:first-child, td:first-child { text-align: left; }
+.hi { background: #a7fc9d; } .med { background: #ffea20; } .lo { background: #ff8c8c; } .none { color: #888; }
+table.source { width: 100%; font-family: monospace; font-size: 13px; }
+table.source td { padding: 0 .5em; border: 0; white-space: pre; vertical-align: top; }
+table.source td.number { text-align: right; color: #888; user-select: none; }
+table.source td.number a { color: inherit; text-decoration: none; }
+table.source td.branches, table.source td.count { text-align: right; user-select: none; }
+table.source td.code { text-align: left; width: 100%; }
+table.source tr:target { outline: 2px solid #4a90d9; }
+tr.hit td.count, tr.hit td.code { background: #dcfcd9; }
+tr.miss td.count, tr.miss td.code { background: #ffd6d6; }
+span.taken { color: #2a7d2a; } span.untaken { color: #c00; font-weight: bold; } span.none { color: #888; }
+.kw { color: #0033b3; font-weight: bold; } .str { color: #067d17; } .com { color: #8c8c8c; font-style: italic; } .num { color: #1750eb; }
+</style>`
+
+// htmlRatesCells are the cells of the coverage metrics of a row
+const htmlRatesCells = `{{define "rates"}}{{range .}}<td class="{{.Class}}">{{.Rate}}</td><td>{{if .Total}}{{.Covered}} / {{.Total}}{{end}}</td>{{end}}{{end}}`
+
+const htmlRatesHeader = `<tr><th></th><th colspan="2">Lines</th><th colspan="2">Functions</th><th colspan="2">Branches</th></tr>`
+
+var htmlIndexTemplate = template.Must(template.New("index").Parse(htmlRatesCells + `<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+` + htmlStyle + `
+</head>
+<body>
+<h1>{{.Title}}</h1>
+<table>
+` + htmlRatesHeader + `
+<tr><th>Total</th>{{template "rates" .Totals}}</tr>
+{{range .Files}}<tr><td><a href="{{.Link}}">{{.Path}}</a></td>{{template "rates" .Rates}}</tr>
+{{end}}</table>
+</body>
+</html>
+`))
+
+var htmlFileTemplate = template.Must(template.New("file").Parse(htmlRatesCells + `<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>{{.Path}} - Coverage report</title>
+` + htmlStyle + `
+</head>
+<body>
+<p><a href="{{.Index}}">Coverage report</a></p>
+<h1>{{.Path}}</h1>
+<table>
+` + htmlRatesHeader + `
+<tr><th>Total</th>{{template "rates" .Rates}}</tr>
+</table>
+{{if .Missing}}<p class="none">Source not found, only the line data is shown.</p>
+{{end}}<table class="source">
+{{range .Lines}}<tr id="L{{.Number}}"{{with .Class}} class="{{.}}"{{end}}><td class="number"><a href="#L{{.Number}}">{{.Number}}</a></td><td class="branches">{{range .Branches}}<span class="{{.Class}}" title="{{.Title}}">{{.Mark}}</span>{{end}}</td><td class="count">{{.Count}}</td><td class="code">{{.Source}}</td></tr>
+{{end}}</table>
+</body>
+</html>
+`))
@@ -0,0 +1,80 @@
+package lcov
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteHTMLReport(t *testing.T) {
+	tracefile := "" +
+		"SF:/src/project/cmd/main.go\nDA:3,12\nDA:4,0\nBRDA:3,0,0,5\nBRDA:3,0,1,0\nBRDA:4,1,0,-\nLF:2\nLH:1\nend_of_record\n" +
+		"SF:/src/project/pkg/lib.go\nDA:1,1\nLF:1\nLH:1\nend_of_record\n"
+	summary, err := Summarize(strings.NewReader(tracefile), WithDetails())
+	require.NoError(t, err)
+	sources := map[string]string{"/src/project/cmd/main.go": "package main\n\nfunc main() {\n\tprintln(\"<hi>\")\n}\n"}
+	open := func(path string) (io.ReadCloser, bool) {
+		source, ok := sources[path]
+		return io.NopCloser(strings.NewReader(source)), ok
+	}
+
+	dir := t.TempDir()
+	require.NoError(t, WriteHTMLReport(dir, summary, open))
+
+	index, err := os.ReadFile(filepath.Join(dir, "index.html"))
+	require.NoError(t, err)
+	assert.Contains(t, string(index), `<tr><th>Total</th><td class="lo">66.7%</td><td>2 / 3</td>`)
+	assert.Contains(t, string(index), `<td><a href="cmd/main.go.html">cmd/main.go</a></td><td class="lo">50.0%</td>`)
+	assert.Contains(t, string(index), `<td><a href="pkg/lib.go.html">pkg/lib.go</a></td><td class="hi">100.0%</td>`)
+
+	page, err := os.ReadFile(filepath.Join(dir, "cmd", "main.go.html"))
+	require.NoError(t, err)
+	assert.Contains(t, string(page), `<a href="../index.html">`)
+	assert.Contains(t, string(page), `<tr id="L1"><td class="number"><a href="#L1">1</a></td><td class="branches"></td><td class="count"></td><td class="code"><span class="kw">package</span> main</td></tr>`)
+	assert.Contains(t, string(page), `<tr id="L3" class="hit"><td class="number"><a href="#L3">3</a></td><td class="branches">`+
+		`<span class="taken" title="Branch 0 of block 0 taken 5 times">&#43;</span><span class="untaken" title="Branch 1 of block 0 not taken">-</span></td>`+
+		`<td class="count">12</td><td class="code"><span class="kw">func</span> main() {</td></tr>`)
+	assert.Contains(t, string(page), `<tr id="L4" class="miss">`)
+	assert.Contains(t, string(page), `<span class="none" title="Branch 0 of block 1 never evaluated">#</span>`)
+	assert.Contains(t, string(page), `println(<span class="str">&#34;&lt;hi&gt;&#34;</span>)`)
+	assert.NotContains(t, string(page), "Source not found")
+
+	// Files without source show their line data
+	page, err = os.ReadFile(filepath.Join(dir, "pkg", "lib.go.html"))
+	require.NoError(t, err)
+	assert.Contains(t, string(page), "Source not found")
+	assert.Contains(t, string(page), `<tr id="L1" class="hit"><td class="number"><a href="#L1">1</a></td><td class="branches"></td><td class="count">1</td><td class="code"></td></tr>`)
+}
+
+func TestHTMLPageName(t *testing.T) {
+	assert.Equal(t, "pkg/a.go.html", htmlPageName("pkg/a.go"))
+	assert.Equal(t, "a.go.html", htmlPageName("/a.go"))
+	assert.Equal(t, "lib/a.go.html", htmlPageName("../../lib/a.go"))
+	assert.Equal(t, "/src/", commonDir([]FileRecord{{Path: "/src/a/x.go"}, {Path: "/src/b/y.go"}, {Path: "/src/z.go"}}))
+	assert.Equal(t, "", commonDir([]FileRecord{{Path: "a/x.go"}, {Path: "b/y.go"}}))
+	assert.Equal(t, "", commonDir([]FileRecord{{Path: "x.go"}}))
+}
+
+func TestHighlighter(t *testing.T) {
+	h := newHighlighter(".go")
+	assert.Equal(t, `<span class="kw">for</span> i := <span class="num">0</span>; i &lt; n; i++ { <span class="com">// loop &amp; count</span>`, string(h.line("for i := 0; i < n; i++ { // loop & count")))
+	assert.Equal(t, `s := <span class="str">&#34;a \&#34;b\&#34;&#34;</span> + <span class="str">&#39;c&#39;</span>`, string(h.line(`s := "a \"b\"" + 'c'`)))
+
+	// Comments and raw strings span lines
+	assert.Equal(t, `x <span class="com">/* a</span>`, string(h.line("x /* a")))
+	assert.Equal(t, `<span class="com">b */</span> y`, string(h.line("b */ y")))
+	assert.Equal(t, `q := <span class="str">`+"`"+`raw</span>`, string(h.line("q := `raw")))
+	assert.Equal(t, `<span class="str">`+"`"+`</span>`, string(h.line("`")))
+
+	h = newHighlighter(".py")
+	assert.Equal(t, `<span class="kw">def</span> f(): <span class="com"># note</span>`, string(h.line("def f(): # note")))
+
+	// Unknown languages are only escaped
+	h = newHighlighter(".txt")
+	assert.Equal(t, `for &lt;all&gt; // x`, string(h.line("for <all> // x")))
+}
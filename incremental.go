@@ -0,0 +1,165 @@
+package lcov
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+)
+
+// headerSize is the number of leading bytes used to detect that a tracefile was rewritten
+const headerSize = 4096
+
+// Incremental keeps the summary of an append-only tracefile up to date by parsing
+// only the complete blocks appended since the previous update. When the file
+// shrinks or its beginning changes, it is considered rewritten and parsed again
+// from scratch. The summary is the one of parsing the whole file at once: the
+// duplicate strategy applies to the blocks of a source file repeated across
+// updates, and a TN record applies to the blocks appended after it.
+type Incremental struct {
+	path   string
+	opts   []Option
+	offset int64
+	header [sha256.Size]byte
+
+	// details keeps the file records in the summary, as set by the options
+	details bool
+	// parser parses the appended blocks into files, keeping the warnings about
+	// their records and the counts of the unknown records along the updates
+	parser *Parser
+	files  *fileSet
+	// testName is the name of the last TN record parsed
+	testName string
+}
+
+// NewIncremental creates an incremental summarizer for the tracefile at path
+func NewIncremental(path string, opts ...Option) *Incremental {
+	inc := &Incremental{path: path, opts: opts, details: newParser(opts).details}
+	inc.reset()
+	return inc
+}
+
+// Update parses the data appended to the tracefile since the last call and returns
+// the up to date summary. A trailing incomplete block is left for the next update.
+func (inc *Incremental) Update() (*Summary, error) {
+	file, err := os.Open(inc.path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return nil, err
+	}
+
+	header, err := readHeader(file, min(info.Size(), inc.offset, headerSize))
+	if err != nil {
+		return nil, err
+	}
+	if info.Size() < inc.offset || header != inc.header {
+		inc.reset()
+	}
+
+	if _, err := file.Seek(inc.offset, io.SeekStart); err != nil {
+		return nil, err
+	}
+	tail, err := io.ReadAll(file)
+	if err != nil {
+		return nil, fmt.Errorf("error reading LCOV data: %w", err)
+	}
+
+	// Only parse up to the last complete block
+	end := lastBlockEnd(tail)
+	if end > 0 {
+		chunk := tail[:end]
+		if inc.testName != "" {
+			chunk = append([]byte(string(RecordTestName)+":"+inc.testName+"\n"), chunk...)
+		}
+		inc.parser.scanner = bufio.NewScanner(inc.parser.track(bytes.NewReader(chunk)))
+		if err := inc.parser.parse(inc.files); err != nil {
+			// Some records of the chunk may already have been added
+			inc.reset()
+			return nil, err
+		}
+		inc.testName = lastTestName(tail[:end], inc.testName)
+		inc.offset += int64(end)
+		if inc.header, err = readHeader(file, min(inc.offset, headerSize)); err != nil {
+			return nil, err
+		}
+	}
+	return inc.summarize()
+}
+
+// summarize computes the summary of the records parsed so far, as Parser.Parse
+// does once the whole file is read: the warnings about the totals, the duplicate
+// and the unknown records are those of the whole file, not of the last update.
+func (inc *Incremental) summarize() (*Summary, error) {
+	p := *inc.parser
+	p.details = inc.details
+	p.warnings = slices.Clone(inc.parser.warnings)
+	files := *inc.files
+	if p.details {
+		// The records are merged with those of the next updates
+		files.files = make([]FileRecord, len(inc.files.files))
+		for i := range inc.files.files {
+			files.files[i] = *inc.files.files[i].clone()
+		}
+	}
+	return p.summarize(&Summary{}, &files)
+}
+
+// reset forgets everything parsed so far
+func (inc *Incremental) reset() {
+	inc.offset = 0
+	inc.header = [sha256.Size]byte{}
+	inc.testName = ""
+	// Duplicate blocks are merged from their detail data
+	inc.parser = newParser(append(inc.opts[:len(inc.opts):len(inc.opts)], WithDetails()))
+	inc.files = newFileSet(inc.parser.duplicates)
+}
+
+// lastTestName returns the name of the last TN record of the data, or name if it has none
+func lastTestName(data []byte, name string) string {
+	prefix := []byte(string(RecordTestName) + ":")
+	for _, line := range bytes.Split(data, []byte("\n")) {
+		if value, ok := bytes.CutPrefix(bytes.TrimSpace(line), prefix); ok {
+			name = string(value)
+		}
+	}
+	return name
+}
+
+// readHeader hashes the first n bytes of the file, the zero value when n is 0
+func readHeader(file *os.File, n int64) ([sha256.Size]byte, error) {
+	if n == 0 {
+		return [sha256.Size]byte{}, nil
+	}
+	buf := make([]byte, n)
+	if _, err := file.ReadAt(buf, 0); err != nil {
+		return [sha256.Size]byte{}, fmt.Errorf("error reading LCOV data: %w", err)
+	}
+	return sha256.Sum256(buf), nil
+}
+
+// lastBlockEnd returns the offset just after the last end_of_record line, or 0
+func lastBlockEnd(data []byte) int {
+	marker := []byte(RecordEndOfRecord)
+	for end := len(data); end > 0; {
+		i := bytes.LastIndex(data[:end], marker)
+		if i < 0 {
+			return 0
+		}
+		lineEnd := bytes.IndexByte(data[i:], '\n')
+		if lineEnd < 0 {
+			// The final line may still be being written
+			end = i
+			continue
+		}
+		return i + lineEnd + 1
+	}
+	return 0
+}
@@ -0,0 +1,146 @@
+package lcov
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	blockMain  = "SF:/src/main.go\nDA:1,1\nDA:2,0\nLF:2\nLH:1\nend_of_record\n"
+	blockUtils = "SF:/src/utils.go\nDA:1,1\nLF:1\nLH:1\nend_of_record\n"
+)
+
+func appendFile(t *testing.T, path, data string) {
+	t.Helper()
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	require.NoError(t, err)
+	_, err = file.WriteString(data)
+	require.NoError(t, err)
+	require.NoError(t, file.Close())
+}
+
+func TestIncrementalUpdate(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "coverage.info")
+	appendFile(t, path, blockMain)
+
+	inc := NewIncremental(path)
+	summary, err := inc.Update()
+	require.NoError(t, err)
+	assert.Equal(t, 1, summary.TotalFiles)
+	assert.Equal(t, int64(2), summary.TotalLines)
+
+	// An incomplete block is left for later
+	appendFile(t, path, "SF:/src/utils.go\nDA:1,1\n")
+	summary, err = inc.Update()
+	require.NoError(t, err)
+	assert.Equal(t, 1, summary.TotalFiles)
+
+	appendFile(t, path, "LF:1\nLH:1\nend_of_record\n")
+	summary, err = inc.Update()
+	require.NoError(t, err)
+	assert.Equal(t, 2, summary.TotalFiles)
+	assert.Equal(t, int64(3), summary.TotalLines)
+	assert.Equal(t, int64(2), summary.CoveredLines)
+	assert.InDelta(t, 66.67, summary.LineCoverageRate, 0.01)
+
+	// Nothing appended, nothing changes
+	summary, err = inc.Update()
+	require.NoError(t, err)
+	assert.Equal(t, 2, summary.TotalFiles)
+}
+
+func TestIncrementalRewrite(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "coverage.info")
+	appendFile(t, path, blockMain+blockUtils)
+
+	inc := NewIncremental(path)
+	summary, err := inc.Update()
+	require.NoError(t, err)
+	assert.Equal(t, 2, summary.TotalFiles)
+
+	// Truncated and rewritten with different content
+	require.NoError(t, os.WriteFile(path, []byte(blockUtils), 0o644))
+	summary, err = inc.Update()
+	require.NoError(t, err)
+	assert.Equal(t, 1, summary.TotalFiles)
+	assert.Equal(t, int64(1), summary.TotalLines)
+
+	// Rewritten with a longer, different content
+	require.NoError(t, os.WriteFile(path, []byte(blockMain+blockMain), 0o644))
+	summary, err = inc.Update()
+	require.NoError(t, err)
+	assert.Equal(t, 2, summary.TotalFiles)
+	assert.Equal(t, int64(4), summary.TotalLines)
+}
+
+func TestIncrementalErrors(t *testing.T) {
+	_, err := NewIncremental(filepath.Join(t.TempDir(), "missing.info")).Update()
+	assert.Error(t, err)
+
+	path := filepath.Join(t.TempDir(), "invalid.info")
+	appendFile(t, path, "DA:1,1\nend_of_record\n")
+	_, err = NewIncremental(path).Update()
+	assert.EqualError(t, err, "line data without source file")
+}
+
+func TestIncrementalAcrossUpdates(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "coverage.info")
+	appendFile(t, path, "TN:unit\n"+blockMain)
+
+	inc := NewIncremental(path, WithDetails(), WithDuplicateStrategy(DuplicatesMerge))
+	_, err := inc.Update()
+	require.NoError(t, err)
+
+	// The block of a later update is merged with the earlier one, and keeps its test name
+	appendFile(t, path, "SF:/src/main.go\nDA:1,0\nDA:2,3\nLF:2\nLH:1\nend_of_record\n")
+	summary, err := inc.Update()
+	require.NoError(t, err)
+	assert.Equal(t, 1, summary.TotalFiles)
+	assert.Equal(t, int64(2), summary.TotalLines)
+	assert.Equal(t, int64(2), summary.CoveredLines)
+	require.Len(t, summary.Files, 1)
+	assert.Equal(t, "unit", summary.Files[0].TestName)
+	assert.Equal(t, []LineData{{Line: 1, Count: 1}, {Line: 2, Count: 3}}, summary.Files[0].Lines)
+	assert.Equal(t, []Warning{{Message: "1 duplicate source file blocks handled with the 'merge' strategy"}}, summary.Warnings)
+
+	// Same as parsing the whole file at once
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	whole, err := Summarize(strings.NewReader(string(data)), WithDetails(), WithDuplicateStrategy(DuplicatesMerge))
+	require.NoError(t, err)
+	assert.Equal(t, whole.Files, summary.Files)
+	assert.Equal(t, whole.LineCoverageRate, summary.LineCoverageRate)
+}
+
+func TestIncrementalWarnings(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "coverage.info")
+	appendFile(t, path, "SF:/src/unused.go\nDA:1,0\nLF:1\nLH:0\nXYZ:1\nend_of_record\n")
+
+	inc := NewIncremental(path, WithUnknownRecords(UnknownRecordsWarn))
+	summary, err := inc.Update()
+	require.NoError(t, err)
+	assert.Equal(t, []Warning{
+		{Message: "none of the 1 lines of the 1 source files was executed, the tests may not have run instrumented"},
+		{Message: "1 records of unknown type XYZ ignored"},
+	}, summary.Warnings)
+
+	// The warnings about the totals and the unknown records are those of the whole file
+	appendFile(t, path, "XYZ:2\n"+blockMain)
+	summary, err = inc.Update()
+	require.NoError(t, err)
+	assert.Equal(t, []Warning{{Message: "2 records of unknown type XYZ ignored"}}, summary.Warnings)
+	summary, err = inc.Update()
+	require.NoError(t, err)
+	assert.Equal(t, []Warning{{Message: "2 records of unknown type XYZ ignored"}}, summary.Warnings)
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	whole, err := Summarize(strings.NewReader(string(data)), WithUnknownRecords(UnknownRecordsWarn))
+	require.NoError(t, err)
+	assert.Equal(t, whole.Warnings, summary.Warnings)
+}
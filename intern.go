@@ -0,0 +1,24 @@
+package lcov
+
+import "unique"
+
+// intern returns the canonical copy of a string, so that the paths of the
+// records of a file share their memory across parses and merged inputs.
+// The copies are released once no record uses them anymore.
+func intern(s string) string {
+	return unique.Make(s).Value()
+}
+
+// internBytes is intern for the bytes of a line. The strings already seen by
+// the parser are looked up without being copied.
+func (p *Parser) internBytes(b []byte) string {
+	if s, ok := p.interned[string(b)]; ok {
+		return s
+	}
+	if p.interned == nil {
+		p.interned = make(map[string]string)
+	}
+	s := intern(string(b))
+	p.interned[s] = s
+	return s
+}
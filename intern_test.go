@@ -0,0 +1,27 @@
+package lcov
+
+import (
+	"bytes"
+	"os"
+	"testing"
+	"unsafe"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPathsAreInterned(t *testing.T) {
+	data, err := os.ReadFile("testdata/concatenated.lcov")
+	require.NoError(t, err)
+
+	first, err := Summarize(bytes.NewReader(data), WithDetails())
+	require.NoError(t, err)
+	second, err := Summarize(bytes.NewReader(data), WithDetails())
+	require.NoError(t, err)
+
+	require.NotEmpty(t, first.Files)
+	for i := range first.Files {
+		assert.Equal(t, first.Files[i].Path, second.Files[i].Path)
+		assert.Equal(t, unsafe.StringData(first.Files[i].Path), unsafe.StringData(second.Files[i].Path))
+	}
+}
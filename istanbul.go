@@ -0,0 +1,122 @@
+package lcov
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+)
+
+// istanbulFile mirrors a file entry of an Istanbul coverage-final.json report
+type istanbulFile struct {
+	Path         string                      `json:"path"`
+	StatementMap map[string]istanbulLocation `json:"statementMap"`
+	FnMap        map[string]istanbulFunction `json:"fnMap"`
+	BranchMap    map[string]istanbulBranch   `json:"branchMap"`
+	S            map[string]int64            `json:"s"`
+	F            map[string]int64            `json:"f"`
+	B            map[string][]int64          `json:"b"`
+}
+
+type istanbulLocation struct {
+	Start istanbulPosition `json:"start"`
+	End   istanbulPosition `json:"end"`
+}
+
+type istanbulPosition struct {
+	Line int `json:"line"`
+}
+
+type istanbulFunction struct {
+	Name string           `json:"name"`
+	Decl istanbulLocation `json:"decl"`
+	Loc  istanbulLocation `json:"loc"`
+}
+
+type istanbulBranch struct {
+	Line int              `json:"line"`
+	Loc  istanbulLocation `json:"loc"`
+}
+
+// ParseIstanbul summarizes an Istanbul/nyc JSON report (coverage-final.json).
+// Lines get the highest count of the statements starting on them, as Istanbul's
+// own LCOV reporter does. The parser options apply as for LCOV data.
+func ParseIstanbul(reader io.Reader, opts ...Option) (*Summary, error) {
+	p := newParser(opts)
+	reader = p.track(reader)
+
+	var report map[string]istanbulFile
+	if err := json.NewDecoder(reader).Decode(&report); err != nil {
+		return nil, fmt.Errorf("invalid Istanbul report: %w", err)
+	}
+
+	paths := make([]string, 0, len(report))
+	for key := range report {
+		paths = append(paths, key)
+	}
+	sort.Strings(paths)
+
+	files := newFileSet(p.duplicates)
+	for _, key := range paths {
+		entry := report[key]
+		f := &FileRecord{Path: entry.Path}
+		if f.Path == "" {
+			f.Path = key
+		}
+
+		lines := make(map[int]int64)
+		for id, location := range entry.StatementMap {
+			line := location.Start.Line
+			if count, ok := lines[line]; !ok || entry.S[id] > count {
+				lines[line] = entry.S[id]
+			}
+		}
+		for line, count := range lines {
+			f.Lines = append(f.Lines, LineData{Line: line, Count: count})
+		}
+
+		for _, id := range sortedIDs(entry.FnMap) {
+			fn := entry.FnMap[id]
+			line := fn.Decl.Start.Line
+			if line == 0 {
+				line = fn.Loc.Start.Line
+			}
+			f.Functions = append(f.Functions, FunctionData{Name: fn.Name, Line: line, Count: entry.F[id]})
+		}
+
+		for block, id := range sortedIDs(entry.BranchMap) {
+			branch := entry.BranchMap[id]
+			line := branch.Line
+			if line == 0 {
+				line = branch.Loc.Start.Line
+			}
+			for i, taken := range entry.B[id] {
+				f.Branches = append(f.Branches, BranchData{Line: line, Block: block, Branch: i, Taken: taken})
+			}
+		}
+
+		f.sortDetails()
+		f.recount()
+		files.add(f)
+	}
+
+	return p.summarize(&Summary{}, files)
+}
+
+// sortedIDs returns the keys of an Istanbul map, which are numbers, in numeric order
+func sortedIDs[T any](m map[string]T) []string {
+	ids := make([]string, 0, len(m))
+	for id := range m {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		a, errA := strconv.Atoi(ids[i])
+		b, errB := strconv.Atoi(ids[j])
+		if errA != nil || errB != nil {
+			return ids[i] < ids[j]
+		}
+		return a < b
+	})
+	return ids
+}
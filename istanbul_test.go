@@ -0,0 +1,36 @@
+package lcov
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseIstanbul(t *testing.T) {
+	file, err := os.Open("testdata/istanbul.json")
+	require.NoError(t, err)
+	defer file.Close()
+
+	summary, err := ParseIstanbul(file, WithDetails())
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, summary.TotalFiles)
+	assert.Equal(t, int64(3), summary.TotalLines)
+	assert.Equal(t, int64(2), summary.CoveredLines)
+	assert.Equal(t, int64(1), summary.CoveredFunctions)
+	assert.Equal(t, int64(2), summary.TotalBranches)
+	assert.Equal(t, int64(1), summary.CoveredBranches)
+
+	index := summary.Files[0]
+	assert.Equal(t, "/src/app/index.js", index.Path)
+	// Line 2 holds an uncovered and a covered statement
+	assert.Equal(t, []LineData{{Line: 1, Count: 1}, {Line: 2, Count: 3}, {Line: 4, Count: 0}}, index.Lines)
+}
+
+func TestParseIstanbulInvalid(t *testing.T) {
+	_, err := ParseIstanbul(strings.NewReader("[1, 2]"))
+	assert.ErrorContains(t, err, "invalid Istanbul report")
+}
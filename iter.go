@@ -0,0 +1,47 @@
+package lcov
+
+import (
+	"bytes"
+	"fmt"
+	"iter"
+)
+
+// Records iterates over the records of the input, such as {Type: "DA", Value:
+// "12,1"}, without summarizing them. Blank lines are skipped. An invalid line or
+// a read error is yielded along with a zero Record and ends the iteration, and
+// breaking out of the loop stops reading the input. The records can only be
+// iterated once, and are not parsed by Parse.
+func (p *Parser) Records() iter.Seq2[Record, error] {
+	return func(yield func(Record, error) bool) {
+		for p.scanner.Scan() {
+			line := bytes.TrimSpace(p.scanner.Bytes())
+			if len(line) == 0 {
+				continue
+			}
+			recordType, value, err := p.parseRecord(line)
+			if err != nil {
+				yield(Record{}, fmt.Errorf("failed to parse line '%s': %w", line, err))
+				return
+			}
+			if !yield(Record{Type: recordType, Value: string(value)}, nil) {
+				return
+			}
+		}
+		if err := p.scanner.Err(); err != nil {
+			yield(Record{}, fmt.Errorf("error reading LCOV data: %w", err))
+		}
+	}
+}
+
+// AllFiles iterates over the summaries of the file records, in the order of
+// Files, computing them one at a time. Unlike FileSummaries, the blocks of the
+// same file are not merged, unless parsed WithDuplicateStrategy(DuplicatesMerge).
+func (s *Summary) AllFiles() iter.Seq[FileSummary] {
+	return func(yield func(FileSummary) bool) {
+		for i := range s.Files {
+			if !yield(s.Files[i].Summary()) {
+				return
+			}
+		}
+	}
+}
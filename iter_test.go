@@ -0,0 +1,72 @@
+package lcov
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParserRecords(t *testing.T) {
+	input := "TN:\nSF:a.go\n\nDA:1,1\n  DA:2,0\nend_of_record\n"
+	var records []Record
+	for record, err := range NewParser(strings.NewReader(input)).Records() {
+		require.NoError(t, err)
+		records = append(records, record)
+	}
+	assert.Equal(t, []Record{
+		{Type: "TN", Value: ""},
+		{Type: "SF", Value: "a.go"},
+		{Type: "DA", Value: "1,1"},
+		{Type: "DA", Value: "2,0"},
+		{Type: "end_of_record", Value: ""},
+	}, records)
+
+	// Breaking out of the loop stops the iteration
+	count := 0
+	for range NewParser(strings.NewReader(input)).Records() {
+		count++
+		break
+	}
+	assert.Equal(t, 1, count)
+}
+
+func TestParserRecordsError(t *testing.T) {
+	var errs []error
+	for record, err := range NewParser(strings.NewReader("SF:a.go\ngarbage\nDA:1,1\n")).Records() {
+		if err != nil {
+			assert.Equal(t, Record{}, record)
+			errs = append(errs, err)
+		}
+	}
+	require.Len(t, errs, 1)
+	assert.EqualError(t, errs[0], "failed to parse line 'garbage': invalid record format: garbage")
+
+	readErr := errors.New("connection reset")
+	for _, err := range NewParser(iotest.ErrReader(readErr)).Records() {
+		assert.ErrorIs(t, err, readErr)
+	}
+}
+
+func TestSummaryAllFiles(t *testing.T) {
+	summary := &Summary{Files: []FileRecord{
+		{Path: "b.go", LinesFound: 2, LinesHit: 1},
+		{Path: "a.go", LinesFound: 4, LinesHit: 4},
+	}}
+	var paths []string
+	for f := range summary.AllFiles() {
+		paths = append(paths, f.Path)
+		if f.Path == "b.go" {
+			assert.Equal(t, 50.0, f.Lines.Rate)
+		}
+	}
+	assert.Equal(t, []string{"b.go", "a.go"}, paths)
+
+	for f := range summary.AllFiles() {
+		assert.Equal(t, "b.go", f.Path)
+		break
+	}
+}
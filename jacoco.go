@@ -0,0 +1,144 @@
+package lcov
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+)
+
+// jacocoReport mirrors the parts of the JaCoCo XML report layout used for summaries.
+// The counters are only used when writing reports.
+type jacocoReport struct {
+	XMLName  xml.Name        `xml:"report"`
+	Name     string          `xml:"name,attr"`
+	Groups   []jacocoGroup   `xml:"group"`
+	Packages []jacocoPackage `xml:"package"`
+	Counters []jacocoCounter `xml:"counter"`
+}
+
+type jacocoGroup struct {
+	Groups   []jacocoGroup   `xml:"group"`
+	Packages []jacocoPackage `xml:"package"`
+}
+
+type jacocoPackage struct {
+	Name        string             `xml:"name,attr"`
+	Classes     []jacocoClass      `xml:"class"`
+	SourceFiles []jacocoSourceFile `xml:"sourcefile"`
+	Counters    []jacocoCounter    `xml:"counter"`
+}
+
+type jacocoClass struct {
+	Name           string          `xml:"name,attr"`
+	SourceFileName string          `xml:"sourcefilename,attr"`
+	Methods        []jacocoMethod  `xml:"method"`
+	Counters       []jacocoCounter `xml:"counter"`
+}
+
+type jacocoMethod struct {
+	Name     string          `xml:"name,attr"`
+	Desc     string          `xml:"desc,attr"`
+	Line     int             `xml:"line,attr"`
+	Counters []jacocoCounter `xml:"counter"`
+}
+
+type jacocoCounter struct {
+	Type    string `xml:"type,attr"`
+	Missed  int    `xml:"missed,attr"`
+	Covered int    `xml:"covered,attr"`
+}
+
+type jacocoSourceFile struct {
+	Name     string          `xml:"name,attr"`
+	Lines    []jacocoLine    `xml:"line"`
+	Counters []jacocoCounter `xml:"counter"`
+}
+
+type jacocoLine struct {
+	Number             int `xml:"nr,attr"`
+	MissedInstructions int `xml:"mi,attr"`
+	CoveredInstruction int `xml:"ci,attr"`
+	MissedBranches     int `xml:"mb,attr"`
+	CoveredBranches    int `xml:"cb,attr"`
+}
+
+// ParseJaCoCo summarizes a JaCoCo XML report. Lines with instructions become line
+// data (covered when at least one instruction was executed), branches become branch
+// data and methods become functions. Source files are identified by their package
+// path, e.g. 'org/example/Foo.java'. The parser options apply as for LCOV data.
+func ParseJaCoCo(reader io.Reader, opts ...Option) (*Summary, error) {
+	p := newParser(opts)
+	reader = p.track(reader)
+
+	var report jacocoReport
+	if err := xml.NewDecoder(reader).Decode(&report); err != nil {
+		return nil, fmt.Errorf("invalid JaCoCo report: %w", err)
+	}
+
+	files := newFileSet(p.duplicates)
+	packages := report.Packages
+	groups := report.Groups
+	for len(groups) > 0 {
+		packages = append(packages, groups[0].Packages...)
+		groups = append(groups[1:], groups[0].Groups...)
+	}
+	for _, pkg := range packages {
+		for _, source := range pkg.SourceFiles {
+			files.add(jacocoFileRecord(pkg, source))
+		}
+	}
+
+	return p.summarize(&Summary{}, files)
+}
+
+// jacocoFileRecord converts a JaCoCo source file and the methods of its classes to a detailed file record
+func jacocoFileRecord(pkg jacocoPackage, source jacocoSourceFile) *FileRecord {
+	f := &FileRecord{Path: path.Join(pkg.Name, source.Name)}
+
+	for _, line := range source.Lines {
+		if line.MissedInstructions+line.CoveredInstruction > 0 {
+			f.Lines = append(f.Lines, LineData{Line: line.Number, Count: int64(line.CoveredInstruction)})
+			f.LinesFound++
+			if line.CoveredInstruction > 0 {
+				f.LinesHit++
+			}
+		}
+
+		branch := 0
+		for i := 0; i < line.CoveredBranches; i++ {
+			f.Branches = append(f.Branches, BranchData{Line: line.Number, Branch: branch, Taken: 1})
+			branch++
+		}
+		for i := 0; i < line.MissedBranches; i++ {
+			f.Branches = append(f.Branches, BranchData{Line: line.Number, Branch: branch, Taken: 0})
+			branch++
+		}
+		f.BranchesFound += int64(line.CoveredBranches + line.MissedBranches)
+		f.BranchesHit += int64(line.CoveredBranches)
+	}
+
+	for _, class := range pkg.Classes {
+		if class.SourceFileName != source.Name {
+			continue
+		}
+		className := path.Base(class.Name)
+		for _, method := range class.Methods {
+			count := int64(0)
+			for _, counter := range method.Counters {
+				if counter.Type == "METHOD" && counter.Covered > 0 {
+					count = 1
+				}
+			}
+			name := className + "." + strings.TrimSpace(method.Name) + method.Desc
+			f.Functions = append(f.Functions, FunctionData{Name: name, Line: method.Line, Count: count})
+			f.FunctionsFound++
+			if count > 0 {
+				f.FunctionsHit++
+			}
+		}
+	}
+
+	return f
+}
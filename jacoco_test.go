@@ -0,0 +1,40 @@
+package lcov
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseJaCoCo(t *testing.T) {
+	file, err := os.Open("testdata/jacoco.xml")
+	require.NoError(t, err)
+	defer file.Close()
+
+	summary, err := ParseJaCoCo(file, WithDetails())
+	require.NoError(t, err)
+
+	assert.Equal(t, 2, summary.TotalFiles)
+	assert.Equal(t, int64(6), summary.TotalLines)
+	assert.Equal(t, int64(4), summary.CoveredLines)
+	assert.Equal(t, int64(3), summary.TotalFunctions)
+	assert.Equal(t, int64(2), summary.CoveredFunctions)
+	assert.Equal(t, int64(2), summary.TotalBranches)
+	assert.Equal(t, int64(1), summary.CoveredBranches)
+	assert.InDelta(t, 66.67, summary.LineCoverageRate, 0.01)
+
+	require.Len(t, summary.Files, 2)
+	calculator := summary.Files[0]
+	assert.Equal(t, "org/example/Calculator.java", calculator.Path)
+	assert.Equal(t, FunctionData{Name: "Calculator.divide(II)I", Line: 5, Count: 1}, calculator.Functions[1])
+	assert.Equal(t, "org/example/util/Strings.java", summary.Files[1].Path)
+}
+
+func TestParseJaCoCoInvalid(t *testing.T) {
+	summary, err := ParseJaCoCo(strings.NewReader("<coverage></coverage>"))
+	assert.ErrorContains(t, err, "invalid JaCoCo report")
+	assert.Nil(t, summary)
+}
@@ -0,0 +1,202 @@
+package lcov
+
+import (
+	"encoding/xml"
+	"io"
+	"path"
+	"sort"
+	"strings"
+)
+
+// Document types of the reports read by the Jenkins Coverage Plugin, which
+// tells the formats apart by them
+const (
+	coberturaDoctype = `<!DOCTYPE coverage SYSTEM "http://cobertura.sourceforge.net/xml/coverage-04.dtd">` + "\n"
+	jacocoDoctype    = `<!DOCTYPE report PUBLIC "-//JACOCO//DTD Report 1.1//EN" "report.dtd">` + "\n"
+)
+
+// WriteJenkinsCobertura writes a summary as a Cobertura report for the Jenkins
+// Coverage Plugin. Unlike WriteCobertura, the report declares the Cobertura
+// document type and, when root is set, lists root as its only source directory
+// with the file names made relative to it: the plugin resolves the sources it
+// renders from those, and absolute paths of another machine would not match
+// the Jenkins workspace. The summary must have been parsed WithDetails to
+// report anything but the totals.
+func WriteJenkinsCobertura(w io.Writer, s *Summary, root string) error {
+	coverage := newCoberturaCoverage(s, relativeFiles(MergeFiles(s.Files), root))
+	if root != "" {
+		coverage.Sources = &coberturaSources{Source: []string{root}}
+	}
+	return encodeCobertura(w, xml.Header+coberturaDoctype, coverage)
+}
+
+// WriteJenkinsJaCoCo writes a summary as a JaCoCo report for the Jenkins
+// Coverage Plugin, which locates the sources of a JaCoCo report by package and
+// file name: source files are made relative to root, if set, their directories
+// becoming packages, and each file becomes a class of its functions. As LCOV
+// has no instruction data, every instrumented line counts as one instruction.
+// The summary must have been parsed WithDetails to report anything but the
+// totals.
+func WriteJenkinsJaCoCo(w io.Writer, s *Summary, root string) error {
+	report := jacocoReport{Name: "go-lcov-summary"}
+
+	// Source files are grouped in packages by directory
+	packages := make(map[string][]FileRecord)
+	var names []string
+	for _, f := range relativeFiles(MergeFiles(s.Files), root) {
+		name := jacocoPackageName(f.Path)
+		if _, ok := packages[name]; !ok {
+			names = append(names, name)
+		}
+		packages[name] = append(packages[name], f)
+	}
+	sort.Strings(names)
+
+	var total jacocoTotals
+	for _, name := range names {
+		pkg := jacocoPackage{Name: name}
+		var totals jacocoTotals
+		for i := range packages[name] {
+			class, source, counts := jacocoClassOf(&packages[name][i])
+			pkg.Classes = append(pkg.Classes, class)
+			pkg.SourceFiles = append(pkg.SourceFiles, source)
+			totals.add(counts)
+		}
+		pkg.Counters = totals.counters()
+		report.Packages = append(report.Packages, pkg)
+		total.add(totals)
+	}
+	report.Counters = total.counters()
+
+	if _, err := io.WriteString(w, xml.Header+jacocoDoctype); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(report); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// relativeFiles makes the paths of records below root relative to it
+func relativeFiles(files []FileRecord, root string) []FileRecord {
+	if root == "" {
+		return files
+	}
+	prefix := strings.TrimSuffix(root, "/") + "/"
+	for i := range files {
+		files[i].Path = strings.TrimPrefix(files[i].Path, prefix)
+	}
+	return files
+}
+
+// jacocoPackageName returns the package of a source file, the default package
+// being the empty name
+func jacocoPackageName(file string) string {
+	dir := path.Dir(file)
+	if dir == "." {
+		return ""
+	}
+	return strings.TrimPrefix(dir, "/")
+}
+
+// Indexes of the counters of jacocoTotals
+const (
+	counterInstruction = iota
+	counterBranch
+	counterLine
+	counterMethod
+	counterClass
+)
+
+// jacocoCounterTypes are the types of the counters of jacocoTotals
+var jacocoCounterTypes = [...]string{"INSTRUCTION", "BRANCH", "LINE", "METHOD", "CLASS"}
+
+// jacocoTotals are the counters of an element of a JaCoCo report
+type jacocoTotals [len(jacocoCounterTypes)]jacocoCounter
+
+// count adds an item to a counter
+func (t *jacocoTotals) count(counter int, covered bool) {
+	if covered {
+		t[counter].Covered++
+	} else {
+		t[counter].Missed++
+	}
+}
+
+// add adds the counters of other to those of t
+func (t *jacocoTotals) add(other jacocoTotals) {
+	for i := range t {
+		t[i].Missed += other[i].Missed
+		t[i].Covered += other[i].Covered
+	}
+}
+
+// counters returns the counters that counted anything, as JaCoCo only reports those
+func (t *jacocoTotals) counters() []jacocoCounter {
+	var counters []jacocoCounter
+	for i, c := range t {
+		if c.Missed+c.Covered > 0 {
+			c.Type = jacocoCounterTypes[i]
+			counters = append(counters, c)
+		}
+	}
+	return counters
+}
+
+// jacocoClassOf converts a merged, detailed file record to a JaCoCo class, its
+// source file and their counters
+func jacocoClassOf(f *FileRecord) (jacocoClass, jacocoSourceFile, jacocoTotals) {
+	base := path.Base(f.Path)
+	class := jacocoClass{
+		Name:           path.Join(jacocoPackageName(f.Path), strings.TrimSuffix(base, path.Ext(base))),
+		SourceFileName: base,
+	}
+	source := jacocoSourceFile{Name: base}
+	var totals jacocoTotals
+
+	// Branches are reported per line as missed and covered branches
+	branches := make(map[int]*jacocoLine)
+	for _, b := range f.Branches {
+		line, ok := branches[b.Line]
+		if !ok {
+			line = &jacocoLine{}
+			branches[b.Line] = line
+		}
+		if b.Taken > 0 {
+			line.CoveredBranches++
+		} else {
+			line.MissedBranches++
+		}
+		totals.count(counterBranch, b.Taken > 0)
+	}
+
+	for _, l := range f.Lines {
+		line := jacocoLine{Number: l.Line}
+		if l.Count > 0 {
+			line.CoveredInstruction = 1
+		} else {
+			line.MissedInstructions = 1
+		}
+		if b, ok := branches[l.Line]; ok {
+			line.MissedBranches, line.CoveredBranches = b.MissedBranches, b.CoveredBranches
+		}
+		source.Lines = append(source.Lines, line)
+		totals.count(counterInstruction, l.Count > 0)
+		totals.count(counterLine, l.Count > 0)
+	}
+
+	for _, fn := range f.Functions {
+		var counts jacocoTotals
+		counts.count(counterMethod, fn.Count > 0)
+		class.Methods = append(class.Methods, jacocoMethod{Name: fn.Name, Desc: "()", Line: fn.Line, Counters: counts.counters()})
+		totals.count(counterMethod, fn.Count > 0)
+	}
+
+	totals.count(counterClass, totals[counterLine].Covered > 0 || totals[counterMethod].Covered > 0)
+	class.Counters = totals.counters()
+	source.Counters = class.Counters
+	return class, source, totals
+}
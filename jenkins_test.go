@@ -0,0 +1,92 @@
+package lcov
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// jenkinsSummary returns the detailed summary of the test tracefile with functions and branches
+func jenkinsSummary(t *testing.T) *Summary {
+	file, err := os.Open("testdata/functions.lcov")
+	require.NoError(t, err)
+	defer file.Close()
+
+	summary, err := Summarize(file, WithDetails())
+	require.NoError(t, err)
+	return summary
+}
+
+// assertSameTotals checks that a converted summary has the totals of the original
+func assertSameTotals(t *testing.T, summary, converted *Summary) {
+	t.Helper()
+	assert.Equal(t, summary.TotalFiles, converted.TotalFiles)
+	assert.Equal(t, summary.TotalLines, converted.TotalLines)
+	assert.Equal(t, summary.CoveredLines, converted.CoveredLines)
+	assert.Equal(t, summary.TotalFunctions, converted.TotalFunctions)
+	assert.Equal(t, summary.CoveredFunctions, converted.CoveredFunctions)
+	assert.Equal(t, summary.TotalBranches, converted.TotalBranches)
+	assert.Equal(t, summary.CoveredBranches, converted.CoveredBranches)
+}
+
+func TestWriteJenkinsCobertura(t *testing.T) {
+	summary := jenkinsSummary(t)
+
+	var out bytes.Buffer
+	require.NoError(t, WriteJenkinsCobertura(&out, summary, "/src/repo/"))
+	report := out.String()
+	assert.True(t, strings.HasPrefix(report, "<?xml version=\"1.0\" encoding=\"UTF-8\"?>\n<!DOCTYPE coverage SYSTEM"))
+	assert.Contains(t, report, "<sources>\n    <source>/src/repo/</source>\n  </sources>")
+	assert.Contains(t, report, `<package name="pkg/crypto"`)
+	assert.Contains(t, report, `filename="pkg/crypto/aes.go"`)
+	assert.Equal(t, FormatCobertura, DetectFormat(out.Bytes()))
+
+	converted, err := ParseCobertura(&out)
+	require.NoError(t, err)
+	assertSameTotals(t, summary, converted)
+	// The paths of the summary are left alone
+	assert.Equal(t, "/src/repo/pkg/crypto/aes.go", summary.Files[0].Path)
+
+	out.Reset()
+	require.NoError(t, WriteJenkinsCobertura(&out, summary, ""))
+	assert.NotContains(t, out.String(), "<sources>")
+	assert.Contains(t, out.String(), `filename="/src/repo/pkg/crypto/aes.go"`)
+}
+
+func TestWriteJenkinsJaCoCo(t *testing.T) {
+	summary := jenkinsSummary(t)
+
+	var out bytes.Buffer
+	require.NoError(t, WriteJenkinsJaCoCo(&out, summary, "/src/repo"))
+	report := out.String()
+	assert.True(t, strings.HasPrefix(report, "<?xml version=\"1.0\" encoding=\"UTF-8\"?>\n<!DOCTYPE report PUBLIC"))
+	assert.Contains(t, report, `<package name="pkg/crypto">`)
+	assert.Contains(t, report, `<class name="pkg/crypto/aes" sourcefilename="aes.go">`)
+	assert.Contains(t, report, `<method name="Encrypt" desc="()" line="1">`)
+	assert.Contains(t, report, `<line nr="3" mi="0" ci="1" mb="1" cb="1"></line>`)
+	assert.Equal(t, FormatJaCoCo, DetectFormat(out.Bytes()))
+
+	converted, err := ParseJaCoCo(&out, WithDetails())
+	require.NoError(t, err)
+	assertSameTotals(t, summary, converted)
+	assert.Equal(t, "pkg/crypto/aes.go", converted.Files[0].Path)
+}
+
+func TestJaCoCoTotals(t *testing.T) {
+	var totals jacocoTotals
+	totals.count(counterLine, true)
+	totals.count(counterLine, false)
+	totals.count(counterClass, true)
+
+	var sum jacocoTotals
+	sum.add(totals)
+	sum.add(totals)
+	assert.Equal(t, []jacocoCounter{
+		{Type: "LINE", Missed: 2, Covered: 2},
+		{Type: "CLASS", Missed: 0, Covered: 2},
+	}, sum.counters())
+}
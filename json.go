@@ -0,0 +1,65 @@
+package lcov
+
+import (
+	_ "embed"
+	"encoding/json"
+	"io"
+)
+
+func init() {
+	RegisterRenderer("json", RendererFunc(RenderJSON))
+}
+
+// JSONSchemaVersion is the version of the JSON Schema of JSONSummary, part of
+// its $id. Properties may be added within a version, while removing or
+// changing one requires a new version.
+const JSONSchemaVersion = 1
+
+//go:embed summary.schema.json
+var jsonSchema []byte
+
+// JSONSchema returns the JSON Schema (draft 2020-12) of the JSON output, for
+// validating it or generating code from it
+func JSONSchema() []byte {
+	return append([]byte(nil), jsonSchema...)
+}
+
+// JSONSummary is the JSON representation of a summary written by RenderJSON
+type JSONSummary struct {
+	Files     int            `json:"files"`
+	Lines     CoverageMetric `json:"lines"`
+	Functions CoverageMetric `json:"functions"`
+	Branches  CoverageMetric `json:"branches"`
+}
+
+// NewJSONSummary converts a summary to its JSON representation
+func NewJSONSummary(s *Summary) JSONSummary {
+	return JSONSummary{
+		Files:     s.TotalFiles,
+		Lines:     CoverageMetric{Covered: s.CoveredLines, Total: s.TotalLines, Rate: s.LineCoverageRate},
+		Functions: CoverageMetric{Covered: s.CoveredFunctions, Total: s.TotalFunctions, Rate: s.FunctionCoverageRate},
+		Branches:  CoverageMetric{Covered: s.CoveredBranches, Total: s.TotalBranches, Rate: s.BranchCoverageRate},
+	}
+}
+
+// Summary converts the JSON representation back to a summary of the totals, without file records
+func (j JSONSummary) Summary() *Summary {
+	s := &Summary{
+		TotalFiles:       j.Files,
+		TotalLines:       j.Lines.Total,
+		CoveredLines:     j.Lines.Covered,
+		TotalFunctions:   j.Functions.Total,
+		CoveredFunctions: j.Functions.Covered,
+		TotalBranches:    j.Branches.Total,
+		CoveredBranches:  j.Branches.Covered,
+	}
+	s.computeRates()
+	return s
+}
+
+// RenderJSON writes the summary totals as an indented JSON object, for scripts
+func RenderJSON(w io.Writer, s *Summary) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(NewJSONSummary(s))
+}
@@ -0,0 +1,67 @@
+package lcov
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRenderJSON(t *testing.T) {
+	summary := &Summary{TotalFiles: 2, TotalLines: 10, CoveredLines: 7, LineCoverageRate: 70}
+
+	var out bytes.Buffer
+	require.NoError(t, RenderJSON(&out, summary))
+	assert.JSONEq(t, `{
+		"files": 2,
+		"lines": {"covered": 7, "total": 10, "rate": 70},
+		"functions": {"covered": 0, "total": 0, "rate": 0},
+		"branches": {"covered": 0, "total": 0, "rate": 0}
+	}`, out.String())
+
+	var decoded JSONSummary
+	require.NoError(t, json.Unmarshal(out.Bytes(), &decoded))
+	assert.Equal(t, NewJSONSummary(summary), decoded)
+	assert.Equal(t, summary, decoded.Summary())
+}
+
+// jsonFields returns the JSON names of the fields of a struct type
+func jsonFields(typ reflect.Type) []string {
+	var names []string
+	for i := 0; i < typ.NumField(); i++ {
+		name, _, _ := strings.Cut(typ.Field(i).Tag.Get("json"), ",")
+		names = append(names, name)
+	}
+	return names
+}
+
+func TestJSONSchema(t *testing.T) {
+	type object struct {
+		Required   []string                   `json:"required"`
+		Properties map[string]json.RawMessage `json:"properties"`
+	}
+	var schema struct {
+		object
+		ID   string            `json:"$id"`
+		Defs map[string]object `json:"$defs"`
+	}
+	require.NoError(t, json.Unmarshal(JSONSchema(), &schema))
+	assert.True(t, strings.HasSuffix(schema.ID, "/v"+strconv.Itoa(JSONSchemaVersion)+".json"), schema.ID)
+
+	// The schema must describe every field of the output
+	summaryFields := jsonFields(reflect.TypeOf(JSONSummary{}))
+	assert.ElementsMatch(t, summaryFields, schema.Required)
+	assert.Len(t, schema.Properties, len(summaryFields))
+	metricFields := jsonFields(reflect.TypeOf(CoverageMetric{}))
+	assert.ElementsMatch(t, metricFields, schema.Defs["metric"].Required)
+	assert.Len(t, schema.Defs["metric"].Properties, len(metricFields))
+
+	// Callers can't alter the embedded schema
+	JSONSchema()[0] = 'x'
+	assert.True(t, json.Valid(JSONSchema()))
+}
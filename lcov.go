@@ -3,275 +3,478 @@ package lcov
 
 import (
 	"bufio"
+	"bytes"
+	"errors"
 	"fmt"
 	"io"
-	"strconv"
-	"strings"
+	"log/slog"
+	"time"
 )
 
 // Summarize processes LCOV data from an io.Reader and returns summary information.
 // This function is the main public API for the lcov package.
-func Summarize(reader io.Reader) (*Summary, error) {
-	parser := NewParser(reader)
+func Summarize(reader io.Reader, opts ...Option) (*Summary, error) {
+	parser := NewParser(reader, opts...)
 	return parser.Parse()
 }
 
 // RecordType represents the type of LCOV record
 type RecordType string
 
+// The record types of the LCOV format
 const (
-	recordTestName     RecordType = "TN"
-	recordSourceFile   RecordType = "SF"
-	recordLineData     RecordType = "DA"
-	recordLinesFound   RecordType = "LF"
-	recordLinesHit     RecordType = "LH"
-	recordFunctionName RecordType = "FN"
-	recordFunctionData RecordType = "FNDA"
-	recordBranchData   RecordType = "BRDA"
-	recordBranchFound  RecordType = "BRF"
-	recordBranchHit    RecordType = "BRH"
-	recordEndOfRecord  RecordType = "end_of_record"
+	RecordTestName     RecordType = "TN"
+	RecordSourceFile   RecordType = "SF"
+	RecordLineData     RecordType = "DA"
+	RecordLinesFound   RecordType = "LF"
+	RecordLinesHit     RecordType = "LH"
+	RecordFunctionName RecordType = "FN"
+	RecordFunctionData RecordType = "FNDA"
+	// FNF and FNH are not used by Parser: the function counts are derived from FN and FNDA records
+	RecordFunctionsFound RecordType = "FNF"
+	RecordFunctionsHit   RecordType = "FNH"
+	RecordBranchData     RecordType = "BRDA"
+	RecordBranchFound    RecordType = "BRF"
+	RecordBranchHit      RecordType = "BRH"
+	RecordEndOfRecord    RecordType = "end_of_record"
 )
 
 // Summary represents the overall coverage summary
 type Summary struct {
 	TotalFiles           int
-	TotalLines           int
-	CoveredLines         int
+	TotalLines           int64
+	CoveredLines         int64
 	LineCoverageRate     float64
-	TotalFunctions       int
-	CoveredFunctions     int
+	TotalFunctions       int64
+	CoveredFunctions     int64
 	FunctionCoverageRate float64
-	TotalBranches        int
-	CoveredBranches      int
+	TotalBranches        int64
+	CoveredBranches      int64
 	BranchCoverageRate   float64
+
+	// Files holds every parsed file record. Only populated when parsing WithDetails.
+	Files []FileRecord
+	// Warnings lists the non-fatal issues encountered while parsing
+	Warnings []Warning
+	// UnknownRecords lists the types of the records the parser doesn't know, sorted
+	UnknownRecords []string
+}
+
+// addFile adds the counters of a single file record to the summary totals
+func (s *Summary) addFile(f *FileRecord) {
+	s.TotalFiles++
+	s.TotalLines += f.LinesFound
+	s.CoveredLines += f.LinesHit
+	s.TotalFunctions += f.FunctionsFound
+	s.CoveredFunctions += f.FunctionsHit
+	s.TotalBranches += f.BranchesFound
+	s.CoveredBranches += f.BranchesHit
+}
+
+// addTotals adds the totals of another summary to the summary totals
+func (s *Summary) addTotals(o *Summary) {
+	s.TotalFiles += o.TotalFiles
+	s.TotalLines += o.TotalLines
+	s.CoveredLines += o.CoveredLines
+	s.TotalFunctions += o.TotalFunctions
+	s.CoveredFunctions += o.CoveredFunctions
+	s.TotalBranches += o.TotalBranches
+	s.CoveredBranches += o.CoveredBranches
+}
+
+// computeRates derives the coverage percentages from the summary totals
+func (s *Summary) computeRates() {
+	s.LineCoverageRate, s.FunctionCoverageRate, s.BranchCoverageRate = 0, 0, 0
+	if s.TotalLines > 0 {
+		s.LineCoverageRate = float64(s.CoveredLines) / float64(s.TotalLines) * 100
+	}
+	if s.TotalFunctions > 0 {
+		s.FunctionCoverageRate = float64(s.CoveredFunctions) / float64(s.TotalFunctions) * 100
+	}
+	if s.TotalBranches > 0 {
+		s.BranchCoverageRate = float64(s.CoveredBranches) / float64(s.TotalBranches) * 100
+	}
 }
 
 // Parser represents an LCOV file parser
 type Parser struct {
-	scanner *bufio.Scanner
+	scanner     *bufio.Scanner
+	details     bool
+	failOnEmpty bool
+	strict      bool
+	clampHits   bool
+	// deriveTotals ignores the LF, LH, BRF and BRH records, see WithDeriveTotals
+	deriveTotals bool
+	// checkCounts reports every counter not matching the detail records, see WithCheckCounts
+	checkCounts bool
+	duplicates  DuplicateStrategy
+	// block counts the detail records of the current SF block, see checkStatedCounts
+	block blockCounts
+	// unknownPolicy handles the records of unknown type, counted by type in unknown
+	unknownPolicy UnknownRecordPolicy
+	unknown       map[RecordType]int
+	warnings      []Warning
+	// warningHandler receives the warnings as they are encountered, see WithWarningHandler
+	warningHandler func(Warning)
+	// logger receives the events of the parsing, see WithLogger, which started at started
+	logger   *slog.Logger
+	started  time.Time
+	progress func(Progress)
+	// parsed tracks the progress of the parser
+	parsed Progress
+	// sink receives the file records when set, see WithFileSink
+	sink func(FileRecord) error
+	// interned holds the paths and test names seen by the parser, see internBytes
+	interned map[string]string
+}
+
+// Option configures optional Parser behavior
+type Option func(*Parser)
+
+// WithDetails makes the parser retain per-file records, including individual
+// line, function and branch data, in Summary.Files.
+func WithDetails() Option {
+	return func(p *Parser) {
+		p.details = true
+	}
+}
+
+// ErrNoData is returned by parsers configured WithFailOnEmpty when the input holds no file record
+var ErrNoData = errors.New("no coverage data found")
+
+// WithFailOnEmpty makes parsing fail with ErrNoData when no file record was parsed,
+// as an empty tracefile usually means that the pipeline producing it is broken
+// rather than that there is nothing to cover.
+func WithFailOnEmpty() Option {
+	return func(p *Parser) {
+		p.failOnEmpty = true
+	}
+}
+
+// WithDeriveTotals, when derive is true, makes the parser ignore the LF, LH, BRF
+// and BRH records and compute the line and branch counters of every file from
+// its DA and BRDA records instead, as the function counters already are from
+// the FN and FNDA records. Some generators write wrong counter records, while
+// the records of the individual lines and branches are right.
+func WithDeriveTotals(derive bool) Option {
+	return func(p *Parser) {
+		p.deriveTotals = derive
+	}
 }
 
 // NewParser creates a new LCOV parser
-func NewParser(reader io.Reader) *Parser {
-	return &Parser{
-		scanner: bufio.NewScanner(reader),
+func NewParser(reader io.Reader, opts ...Option) *Parser {
+	p := newParser(opts)
+	p.scanner = bufio.NewScanner(p.track(reader))
+	return p
+}
+
+// newParser creates a parser with its options applied, but no input.
+// It is used as is by the parsers of the other supported coverage formats.
+func newParser(opts []Option) *Parser {
+	p := &Parser{started: time.Now()}
+	for _, opt := range opts {
+		opt(p)
 	}
+	return p
 }
 
 // Parse reads and parses the entire LCOV file
 func (p *Parser) Parse() (*Summary, error) {
-	summary := &Summary{}
-
-	// Current file counters
-	var currentFileLinesFound, currentFileLinesHit int
-	var currentFileFunctions, currentFileFunctionsHit int
-	var currentFileBranchesFound, currentFileBranchesHit int
-	var inFile bool
+	files := newFileSet(p.duplicates)
+	files.sink = p.sink
+	if err := p.parse(files); err != nil {
+		return nil, err
+	}
+	return p.summarize(&Summary{}, files)
+}
 
-	for p.scanner.Scan() && p.scanner.Err() == nil {
-		line := strings.TrimSpace(p.scanner.Text())
-		if line == "" {
+// parse adds the file records of the LCOV input to files, without summarizing
+// them, so that Incremental parses the appended data into the records it keeps
+func (p *Parser) parse(files *fileSet) error {
+	// Detailed data is needed to merge duplicate blocks, even when not returned
+	collect := p.details || p.duplicates == DuplicatesMerge || p.sink != nil || p.deriveTotals
+
+	// Current file record, nil when outside of an SF block. The record is reused
+	// from one block to the next, files.add keeping a copy of it.
+	var current *FileRecord
+	var record FileRecord
+	var testName string
+
+	// Lines are handled as the scanner's bytes: only the values that are kept,
+	// like paths and names, are copied to strings.
+	for p.scanner.Scan() {
+		line := bytes.TrimSpace(p.scanner.Bytes())
+		if len(line) == 0 {
 			continue
 		}
 
-		record, err := p.parseRecord(line)
+		recordType, value, err := p.parseRecord(line)
 		if err != nil {
-			return nil, fmt.Errorf("failed to parse line '%s': %w", line, err)
+			return fmt.Errorf("failed to parse line '%s': %w", line, err)
 		}
 
-		switch record.Type {
-		case recordTestName:
-			// Test name - just continue, we don't need to store it
+		switch recordType {
+		case RecordTestName:
+			// Test name applies to all following files until the next TN record
+			testName = p.internBytes(value)
 
-		case recordSourceFile:
+		case RecordSourceFile:
+			if current != nil {
+				if err := p.endUnterminated(files, current); err != nil {
+					return err
+				}
+			}
 			// Start of a new file
-			inFile = true
-			currentFileLinesFound = 0
-			currentFileLinesHit = 0
-			currentFileFunctions = 0
-			currentFileFunctionsHit = 0
-			currentFileBranchesFound = 0
-			currentFileBranchesHit = 0
-
-		case recordLineData:
-			if !inFile {
-				return nil, fmt.Errorf("line data without source file")
+			record = FileRecord{Path: p.internBytes(value), TestName: testName}
+			current = &record
+			p.block = blockCounts{}
+			// Checked here so that the event costs nothing without a logger
+			if p.logger != nil {
+				p.logger.Debug("source file started", "file", record.Path)
 			}
-			// We don't need to store individual line data, just validate the format
-			if !p.isValidLineData(record.Value) {
-				return nil, fmt.Errorf("invalid line data format: %s", record.Value)
+
+		case RecordLineData:
+			if current == nil {
+				return errorOf(ErrOrphanRecord, "line data without source file")
+			}
+			if !p.isValidLineData(value) {
+				return fmt.Errorf("%w: %s", ErrInvalidLineData, value)
+			}
+			data := parseLineData(value)
+			p.block.lines.count(data.Count > 0)
+			if collect {
+				current.Lines = append(current.Lines, data)
 			}
 
-		case recordLinesFound:
-			if !inFile {
-				return nil, fmt.Errorf("lines found without source file")
+		case RecordLinesFound:
+			if current == nil {
+				return errorOf(ErrOrphanRecord, "lines found without source file")
 			}
-			linesFound, err := strconv.Atoi(record.Value)
-			if err != nil {
-				return nil, fmt.Errorf("invalid lines found value: %s", record.Value)
+			linesFound, ok := atoi64(value)
+			if !ok {
+				return errorOf(ErrInvalidCounter, "invalid lines found value: %s", value)
 			}
-			currentFileLinesFound = linesFound
+			current.LinesFound = linesFound
+			p.block.lines.statedFound = true
 
-		case recordLinesHit:
-			if !inFile {
-				return nil, fmt.Errorf("lines hit without source file")
+		case RecordLinesHit:
+			if current == nil {
+				return errorOf(ErrOrphanRecord, "lines hit without source file")
 			}
-			linesHit, err := strconv.Atoi(record.Value)
-			if err != nil {
-				return nil, fmt.Errorf("invalid lines hit value: %s", record.Value)
+			linesHit, ok := atoi64(value)
+			if !ok {
+				return errorOf(ErrInvalidCounter, "invalid lines hit value: %s", value)
 			}
-			currentFileLinesHit = linesHit
+			current.LinesHit = linesHit
+			p.block.lines.statedHit = true
 
-		case recordFunctionName:
-			if !inFile {
-				return nil, fmt.Errorf("function name without source file")
+		case RecordFunctionName:
+			if current == nil {
+				return errorOf(ErrOrphanRecord, "function name without source file")
+			}
+			if !p.isValidFunctionName(value) {
+				return fmt.Errorf("%w: %s", ErrInvalidFunctionName, value)
 			}
-			// We don't need to store function data, just validate and count
-			if !p.isValidFunctionName(record.Value) {
-				return nil, fmt.Errorf("invalid function name format: %s", record.Value)
+			current.FunctionsFound++
+			if collect {
+				current.Functions = append(current.Functions, parseFunctionName(value))
 			}
-			currentFileFunctions++
 
-		case recordFunctionData:
-			if !inFile {
-				return nil, fmt.Errorf("function data without source file")
+		case RecordFunctionData:
+			if current == nil {
+				return errorOf(ErrOrphanRecord, "function data without source file")
 			}
 			// FNDA records are matched with FN records by name
 			// For simplicity, we'll just count functions that were executed
-			parts := strings.Split(record.Value, ",")
-			if len(parts) == 2 {
-				execCount, err := strconv.Atoi(parts[0])
-				if err == nil && execCount > 0 {
-					currentFileFunctionsHit++
+			count, name, found := bytes.Cut(value, []byte{','})
+			if found && bytes.IndexByte(name, ',') < 0 {
+				execCount, ok := parseCount(count)
+				if ok && execCount > 0 {
+					current.FunctionsHit++
+				}
+				if ok && collect {
+					current.setFunctionCount(string(name), execCount)
 				}
 			}
 
-		case recordBranchData:
-			if !inFile {
-				return nil, fmt.Errorf("branch data without source file")
+		case RecordFunctionsFound, RecordFunctionsHit:
+			// Function counts are derived from the FN and FNDA records
+
+		case RecordBranchData:
+			if current == nil {
+				return errorOf(ErrOrphanRecord, "branch data without source file")
+			}
+			if !p.isValidBranchData(value) {
+				return fmt.Errorf("%w: %s", ErrInvalidBranchData, value)
 			}
-			// We don't need to store branch data, just validate the format
-			if !p.isValidBranchData(record.Value) {
-				return nil, fmt.Errorf("invalid branch data format: %s", record.Value)
+			data := parseBranchData(value)
+			p.block.branches.count(data.Taken > 0)
+			if collect {
+				current.Branches = append(current.Branches, data)
 			}
 
-		case recordBranchFound:
-			if !inFile {
-				return nil, fmt.Errorf("branch found without source file")
+		case RecordBranchFound:
+			if current == nil {
+				return errorOf(ErrOrphanRecord, "branch found without source file")
 			}
-			branchesFound, err := strconv.Atoi(record.Value)
-			if err != nil {
-				return nil, fmt.Errorf("invalid branches found value: %s", record.Value)
+			branchesFound, ok := atoi64(value)
+			if !ok {
+				return errorOf(ErrInvalidCounter, "invalid branches found value: %s", value)
 			}
-			currentFileBranchesFound = branchesFound
+			current.BranchesFound = branchesFound
+			p.block.branches.statedFound = true
 
-		case recordBranchHit:
-			if !inFile {
-				return nil, fmt.Errorf("branch hit without source file")
+		case RecordBranchHit:
+			if current == nil {
+				return errorOf(ErrOrphanRecord, "branch hit without source file")
 			}
-			branchesHit, err := strconv.Atoi(record.Value)
-			if err != nil {
-				return nil, fmt.Errorf("invalid branches hit value: %s", record.Value)
+			branchesHit, ok := atoi64(value)
+			if !ok {
+				return errorOf(ErrInvalidCounter, "invalid branches hit value: %s", value)
 			}
-			currentFileBranchesHit = branchesHit
-
-		case recordEndOfRecord:
-			if inFile {
-				// Add current file's data to totals
-				summary.TotalFiles++
-				summary.TotalLines += currentFileLinesFound
-				summary.CoveredLines += currentFileLinesHit
-				summary.TotalFunctions += currentFileFunctions
-				summary.CoveredFunctions += currentFileFunctionsHit
-				summary.TotalBranches += currentFileBranchesFound
-				summary.CoveredBranches += currentFileBranchesHit
-				inFile = false
+			current.BranchesHit = branchesHit
+			p.block.branches.statedHit = true
+
+		case RecordEndOfRecord:
+			if current != nil {
+				if err := p.endBlock(files, current); err != nil {
+					return err
+				}
+				current = nil
+			}
+
+		default:
+			if err := p.unknownRecord(recordType); err != nil {
+				return fmt.Errorf("failed to parse line '%s': %w", line, err)
 			}
 		}
 	}
 
 	if p.scanner.Err() != nil {
-		return nil, fmt.Errorf("error reading LCOV data: %w", p.scanner.Err())
+		return fmt.Errorf("error reading LCOV data: %w", p.scanner.Err())
+	}
+	// The data of truncated tracefiles, e.g. written by killed jobs, is kept
+	if current != nil {
+		if err := p.endUnterminated(files, current); err != nil {
+			return err
+		}
 	}
 
-	// Calculate coverage rates
-	if summary.TotalLines > 0 {
-		summary.LineCoverageRate = float64(summary.CoveredLines) / float64(summary.TotalLines) * 100
+	return nil
+}
+
+// endBlock adds the record of a complete SF block to the parsed files
+func (p *Parser) endBlock(files *fileSet, f *FileRecord) error {
+	if p.deriveTotals {
+		f.recount()
 	}
-	if summary.TotalFunctions > 0 {
-		summary.FunctionCoverageRate = float64(summary.CoveredFunctions) / float64(summary.TotalFunctions) * 100
+	if err := p.checkHits(f); err != nil {
+		return err
 	}
-	if summary.TotalBranches > 0 {
-		summary.BranchCoverageRate = float64(summary.CoveredBranches) / float64(summary.TotalBranches) * 100
+	if !p.deriveTotals {
+		p.checkStatedCounts(f)
 	}
+	files.add(f)
+	p.parsed.Files++
+	return files.err
+}
 
-	return summary, p.scanner.Err()
+// summarize adds the collected files to the summary totals and computes its rates,
+// or streams them to the sink of the parser. It fails with ErrNoData when there
+// are none and the parser is WithFailOnEmpty.
+func (p *Parser) summarize(summary *Summary, files *fileSet) (*Summary, error) {
+	if p.progress != nil {
+		// Formats other than LCOV only know their files once fully read
+		p.parsed.Files = max(p.parsed.Files, files.len())
+		p.progress(p.parsed)
+	}
+	if p.failOnEmpty && files.len() == 0 {
+		return nil, ErrNoData
+	}
+	files.sink = p.sink
+	if err := files.flush(); err != nil {
+		return nil, err
+	}
+	summary.addTotals(&files.streamed)
+	for i := range files.files {
+		summary.addFile(&files.files[i])
+	}
+	p.checkTotals(summary)
+	if p.details && p.sink == nil {
+		summary.Files = files.files
+	}
+	summary.UnknownRecords = p.unknownRecords()
+	if files.duplicates > 0 {
+		p.warn("", "%d duplicate source file blocks handled with the '%s' strategy", files.duplicates, p.duplicates)
+	}
+	summary.Warnings = p.warnings
+
+	summary.computeRates()
+	p.logParsed(summary)
+
+	return summary, nil
 }
 
 // Record represents a parsed LCOV record
 type Record struct {
 	Type  RecordType
 	Value string
+	// Data is the parsed value of the records returned by a Decoder, see Decoder.Next
+	Data any
 }
 
-// parseRecord parses a single line into a Record
-func (p *Parser) parseRecord(line string) (*Record, error) {
-	if line == "end_of_record" {
-		return &Record{Type: recordEndOfRecord, Value: ""}, nil
+// parseRecord splits a line into its record type and value. The value is a
+// slice of the line, so that records are parsed without copying them.
+func (p *Parser) parseRecord(line []byte) (RecordType, []byte, error) {
+	if string(line) == string(RecordEndOfRecord) {
+		return RecordEndOfRecord, nil, nil
 	}
 
-	parts := strings.SplitN(line, ":", 2)
+	recordType, value, found := bytes.Cut(line, []byte{':'})
 	// TN allowed to be empty
-	if len(parts) != 2 || parts[0] == "" || (parts[1] == "" && parts[0] != "TN") {
-		return nil, fmt.Errorf("invalid record format: %s", line)
+	if !found || len(recordType) == 0 || (len(value) == 0 && string(recordType) != string(RecordTestName)) {
+		return "", nil, fmt.Errorf("%w: %s", ErrInvalidRecord, line)
 	}
-
-	recordType := RecordType(parts[0])
-	value := parts[1]
-
-	return &Record{Type: recordType, Value: value}, nil
+	return recordTypeOf(recordType), value, nil
 }
 
 // isValidLineData validates a line data record (DA:line,count)
-func (p *Parser) isValidLineData(value string) bool {
-	parts := strings.Split(value, ",")
-	if len(parts) != 2 {
+func (p *Parser) isValidLineData(value []byte) bool {
+	var parts [2][]byte
+	if !splitFields(value, parts[:]) {
 		return false
 	}
 
-	_, err1 := strconv.Atoi(parts[0])
-	_, err2 := strconv.Atoi(parts[1])
-	return err1 == nil && err2 == nil
+	_, ok1 := atoi(parts[0])
+	_, ok2 := parseCount(parts[1])
+	return ok1 && ok2
 }
 
 // isValidFunctionName validates a function name record (FN:line,name)
-func (p *Parser) isValidFunctionName(value string) bool {
-	parts := strings.SplitN(value, ",", 2)
-	if len(parts) != 2 || parts[1] == "" {
+func (p *Parser) isValidFunctionName(value []byte) bool {
+	line, name, found := bytes.Cut(value, []byte{','})
+	if !found || len(name) == 0 {
 		return false
 	}
 
-	_, err := strconv.Atoi(parts[0])
-	return err == nil
+	_, ok := atoi(line)
+	return ok
 }
 
 // isValidBranchData validates a branch data record (BRDA:line,block,branch,count)
-func (p *Parser) isValidBranchData(value string) bool {
-	parts := strings.Split(value, ",")
-	if len(parts) != 4 {
+func (p *Parser) isValidBranchData(value []byte) bool {
+	var parts [4][]byte
+	if !splitFields(value, parts[:]) {
 		return false
 	}
 
-	_, err1 := strconv.Atoi(parts[0])
-	_, err2 := strconv.Atoi(parts[1])
-	_, err3 := strconv.Atoi(parts[2])
+	_, ok1 := atoi(parts[0])
+	_, ok2 := atoi(parts[1])
+	_, ok3 := atoi(parts[2])
 
 	// The fourth part can be a number or "-"
-	var err4 error
-	if parts[3] != "-" {
-		_, err4 = strconv.Atoi(parts[3])
-	}
+	_, ok4 := parseCount(parts[3])
 
-	return err1 == nil && err2 == nil && err3 == nil && (parts[3] == "-" || err4 == nil)
+	return ok1 && ok2 && ok3 && (string(parts[3]) == "-" || ok4)
 }
@@ -2,6 +2,7 @@ package lcov
 
 import (
 	"fmt"
+	"math"
 	"os"
 	"strings"
 	"testing"
@@ -22,8 +23,8 @@ func TestSummarize(t *testing.T) {
 
 	// Verify summary data
 	assert.Equal(t, 2, summary.TotalFiles)
-	assert.Equal(t, 9, summary.TotalLines)                   // 5 + 4
-	assert.Equal(t, 6, summary.CoveredLines)                 // 3 + 3
+	assert.Equal(t, int64(9), summary.TotalLines)            // 5 + 4
+	assert.Equal(t, int64(6), summary.CoveredLines)          // 3 + 3
 	assert.InDelta(t, 66.67, summary.LineCoverageRate, 0.01) // 6/9 * 100
 
 	// Verify summary statistics only (no individual file details)
@@ -41,8 +42,8 @@ func TestSummarizeComplex(t *testing.T) {
 
 	// Verify summary data
 	assert.Equal(t, 3, summary.TotalFiles)
-	assert.Equal(t, 15, summary.TotalLines)                  // 7 + 5 + 3
-	assert.Equal(t, 11, summary.CoveredLines)                // 5 + 3 + 3
+	assert.Equal(t, int64(15), summary.TotalLines)           // 7 + 5 + 3
+	assert.Equal(t, int64(11), summary.CoveredLines)         // 5 + 3 + 3
 	assert.InDelta(t, 73.33, summary.LineCoverageRate, 0.01) // 11/15 * 100
 
 	// Verify summary statistics only (no individual file details)
@@ -60,25 +61,25 @@ func TestParserParseRecord(t *testing.T) {
 		{
 			name:     "valid test name",
 			input:    "TN:TestName",
-			expected: &Record{Type: recordTestName, Value: "TestName"},
+			expected: &Record{Type: RecordTestName, Value: "TestName"},
 			err:      "",
 		},
 		{
 			name:     "valid source file",
 			input:    "SF:/path/to/file.go",
-			expected: &Record{Type: recordSourceFile, Value: "/path/to/file.go"},
+			expected: &Record{Type: RecordSourceFile, Value: "/path/to/file.go"},
 			err:      "",
 		},
 		{
 			name:     "valid line data",
 			input:    "DA:1,5",
-			expected: &Record{Type: recordLineData, Value: "1,5"},
+			expected: &Record{Type: RecordLineData, Value: "1,5"},
 			err:      "",
 		},
 		{
 			name:     "valid end of record",
 			input:    "end_of_record",
-			expected: &Record{Type: recordEndOfRecord, Value: ""},
+			expected: &Record{Type: RecordEndOfRecord, Value: ""},
 			err:      "",
 		},
 		// New invalid cases
@@ -96,20 +97,21 @@ func TestParserParseRecord(t *testing.T) {
 			name:     "colon in value",
 			input:    "DA:1:5",
 			err:      "", // Should parse as DA with value "1:5"
-			expected: &Record{Type: recordLineData, Value: "1:5"},
+			expected: &Record{Type: RecordLineData, Value: "1:5"},
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			record, err := parser.parseRecord(tt.input)
+			recordType, value, err := parser.parseRecord([]byte(tt.input))
 			if tt.err != "" {
 				assert.Error(t, err)
 				assert.Contains(t, err.Error(), tt.err)
-				assert.Nil(t, record)
+				assert.Empty(t, recordType)
+				assert.Nil(t, value)
 			} else {
 				assert.NoError(t, err)
-				assert.Equal(t, tt.expected, record)
+				assert.Equal(t, tt.expected, &Record{Type: recordType, Value: string(value)})
 			}
 		})
 	}
@@ -135,7 +137,7 @@ func TestParserParseLineData(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			assert.Equal(t, tt.expected, parser.isValidLineData(tt.input))
+			assert.Equal(t, tt.expected, parser.isValidLineData([]byte(tt.input)))
 		})
 	}
 }
@@ -152,14 +154,14 @@ func TestSummarizeWithFunctionsAndBranches(t *testing.T) {
 
 	// Verify summary data
 	assert.Equal(t, 2, summary.TotalFiles)
-	assert.Equal(t, 10, summary.TotalLines)                     // 6 + 4
-	assert.Equal(t, 7, summary.CoveredLines)                    // 4 + 3
+	assert.Equal(t, int64(10), summary.TotalLines)              // 6 + 4
+	assert.Equal(t, int64(7), summary.CoveredLines)             // 4 + 3
 	assert.InDelta(t, 70.0, summary.LineCoverageRate, 0.01)     // 7/10 * 100
-	assert.Equal(t, 4, summary.TotalFunctions)                  // 2 + 2
-	assert.Equal(t, 3, summary.CoveredFunctions)                // 1 + 2 (functions with exec count > 0)
+	assert.Equal(t, int64(4), summary.TotalFunctions)           // 2 + 2
+	assert.Equal(t, int64(3), summary.CoveredFunctions)         // 1 + 2 (functions with exec count > 0)
 	assert.InDelta(t, 75.0, summary.FunctionCoverageRate, 0.01) // 3/4 * 100
-	assert.Equal(t, 2, summary.TotalBranches)                   // 0 + 2
-	assert.Equal(t, 2, summary.CoveredBranches)                 // 0 + 2
+	assert.Equal(t, int64(2), summary.TotalBranches)            // 0 + 2
+	assert.Equal(t, int64(2), summary.CoveredBranches)          // 0 + 2
 	assert.InDelta(t, 100.0, summary.BranchCoverageRate, 0.01)  // 2/2 * 100
 
 	// Verify summary statistics only (no individual file details)
@@ -184,7 +186,7 @@ func TestParserParseFunctionName(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			assert.Equal(t, tt.expected, parser.isValidFunctionName(tt.input))
+			assert.Equal(t, tt.expected, parser.isValidFunctionName([]byte(tt.input)))
 		})
 	}
 }
@@ -211,7 +213,7 @@ func TestParserParseBranchData(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			assert.Equal(t, tt.expected, parser.isValidBranchData(tt.input))
+			assert.Equal(t, tt.expected, parser.isValidBranchData([]byte(tt.input)))
 		})
 	}
 }
@@ -328,3 +330,97 @@ func TestSummarizeScannerError(t *testing.T) {
 	assert.Contains(t, err.Error(), "simulated read error")
 	assert.Nil(t, summary)
 }
+
+func TestSummarizeWithDetails(t *testing.T) {
+	file, err := os.Open("testdata/with_functions_and_branches.lcov")
+	require.NoError(t, err)
+	defer file.Close()
+
+	summary, err := Summarize(file, WithDetails())
+	require.NoError(t, err)
+	require.Len(t, summary.Files, 2)
+
+	main := summary.Files[0]
+	assert.Equal(t, "/path/to/source/main.go", main.Path)
+	assert.Equal(t, "TestSuite", main.TestName)
+	assert.Equal(t, int64(6), main.LinesFound)
+	assert.Equal(t, int64(4), main.LinesHit)
+	assert.Len(t, main.Lines, 6)
+	assert.Equal(t, LineData{Line: 3, Count: 0}, main.Lines[2])
+	assert.Equal(t, []FunctionData{{Name: "main", Line: 1, Count: 1}, {Name: "helper", Line: 5, Count: 0}}, main.Functions)
+
+	utils := summary.Files[1]
+	assert.Equal(t, BranchData{Line: 1, Block: 1, Branch: 1, Taken: 2}, utils.Branches[3])
+}
+
+func TestSummarizeLargeCounts(t *testing.T) {
+	input := `SF:main.go
+FNDA:9000000000,main
+BRDA:1,0,0,9223372036854775807
+DA:1,4294967296
+LF:5000000000
+LH:4000000000
+end_of_record
+`
+	summary, err := Summarize(strings.NewReader(input), WithDetails())
+	require.NoError(t, err)
+	assert.Equal(t, int64(5000000000), summary.TotalLines)
+	assert.Equal(t, int64(4000000000), summary.CoveredLines)
+	assert.InDelta(t, 80, summary.LineCoverageRate, 0.001)
+
+	f := summary.Files[0]
+	assert.Equal(t, []LineData{{Line: 1, Count: 4294967296}}, f.Lines)
+	assert.Equal(t, []FunctionData{{Name: "main", Count: 9000000000}}, f.Functions)
+	assert.Equal(t, int64(math.MaxInt64), f.Branches[0].Taken)
+}
+
+func TestParseFunctionName(t *testing.T) {
+	assert.Equal(t, FunctionData{Name: "main", Line: 3}, parseFunctionName([]byte("3,main")))
+	assert.Equal(t, FunctionData{Name: "main", Line: 3, EndLine: 9}, parseFunctionName([]byte("3,9,main")))
+	assert.Equal(t, FunctionData{Name: "std::pair<int, int> f()", Line: 3}, parseFunctionName([]byte("3,std::pair<int, int> f()")))
+	assert.Equal(t, FunctionData{Name: "9,", Line: 3}, parseFunctionName([]byte("3,9,")))
+	assert.Equal(t, FunctionData{Name: "42", Line: 3}, parseFunctionName([]byte("3,42")))
+}
+
+func TestSummarizeSaturatedCounts(t *testing.T) {
+	input := `SF:main.go
+FN:1,main
+FNDA:1.8e+19,main
+DA:1,18446744073709551615
+DA:2,9.2e+18
+DA:3,0.0
+BRDA:1,0,0,1e400
+end_of_record
+`
+	summary, err := Summarize(strings.NewReader(input), WithDetails())
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), summary.CoveredFunctions)
+
+	f := summary.Files[0]
+	assert.Equal(t, []LineData{{Line: 1, Count: math.MaxInt64}, {Line: 2, Count: 9200000000000000000}, {Line: 3, Count: 0}}, f.Lines)
+	assert.Equal(t, []FunctionData{{Name: "main", Line: 1, Count: math.MaxInt64}}, f.Functions)
+	assert.Equal(t, int64(math.MaxInt64), f.Branches[0].Taken)
+
+	for _, invalid := range []string{"DA:1,Inf", "DA:1,0x10", "DA:1,1e", "BRDA:1,0,0,NaN"} {
+		_, err := Summarize(strings.NewReader("SF:main.go\n" + invalid + "\nend_of_record\n"))
+		assert.Error(t, err, invalid)
+	}
+}
+
+func TestSummarizeFailOnEmpty(t *testing.T) {
+	summary, err := Summarize(strings.NewReader(""))
+	require.NoError(t, err)
+	assert.Equal(t, 0, summary.TotalFiles)
+
+	for _, input := range []string{"", "TN:\n", "\n\n"} {
+		_, err = Summarize(strings.NewReader(input), WithFailOnEmpty())
+		assert.ErrorIs(t, err, ErrNoData, "%q", input)
+	}
+
+	_, err = ParseCoverprofile(strings.NewReader("mode: set\n"), WithFailOnEmpty())
+	assert.ErrorIs(t, err, ErrNoData)
+
+	summary, err = Summarize(strings.NewReader("SF:a.go\nDA:1,1\nend_of_record\n"), WithFailOnEmpty())
+	require.NoError(t, err)
+	assert.Equal(t, 1, summary.TotalFiles)
+}
@@ -0,0 +1,98 @@
+// Package lcovgate fails Go test suites whose coverage is below policy, from a
+// tracefile they just generated, without wiring the CLI into the build:
+//
+//	func TestCoverage(t *testing.T) {
+//		lcovgate.Require(t, "coverage.info", lcovgate.Lines(80), lcovgate.Branches(60))
+//	}
+//
+// As the tracefile is usually written once the tests ran, the gate fits best in
+// TestMain, with Check:
+//
+//	func TestMain(m *testing.M) {
+//		code := m.Run()
+//		if err := lcovgate.Check("coverage.info", lcovgate.Lines(80)); code == 0 && err != nil {
+//			fmt.Fprintln(os.Stderr, err)
+//			code = 1
+//		}
+//		os.Exit(code)
+//	}
+package lcovgate
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	lcov "github.com/shastick/go-lcov-summary"
+)
+
+// Policy is a coverage requirement of the gate
+type Policy func(*lcov.Thresholds)
+
+// Lines requires the line coverage to be at least percent
+func Lines(percent float64) Policy {
+	return func(t *lcov.Thresholds) { t.Lines = percent }
+}
+
+// Functions requires the function coverage to be at least percent
+func Functions(percent float64) Policy {
+	return func(t *lcov.Thresholds) { t.Functions = percent }
+}
+
+// Branches requires the branch coverage to be at least percent
+func Branches(percent float64) Policy {
+	return func(t *lcov.Thresholds) { t.Branches = percent }
+}
+
+// Error is the error of a tracefile whose coverage is below policy
+type Error struct {
+	Path       string
+	Violations []lcov.ThresholdViolation
+}
+
+func (e *Error) Error() string {
+	violations := make([]string, len(e.Violations))
+	for i, v := range e.Violations {
+		violations[i] = v.String()
+	}
+	return fmt.Sprintf("%s: %s", e.Path, strings.Join(violations, ", "))
+}
+
+// Check summarizes the tracefile at path, in any format of lcov.SummarizeAny,
+// and returns an *Error if its coverage is below the policies. A tracefile
+// that can't be read or holds no coverage data fails the check as well.
+func Check(path string, policies ...Policy) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("error opening tracefile: %w", err)
+	}
+	defer file.Close()
+
+	summary, _, err := lcov.SummarizeAny(file, lcov.WithFailOnEmpty())
+	if err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+
+	var thresholds lcov.Thresholds
+	for _, policy := range policies {
+		policy(&thresholds)
+	}
+	if violations := lcov.CheckThresholds(summary, thresholds); len(violations) > 0 {
+		return &Error{Path: path, Violations: violations}
+	}
+	return nil
+}
+
+// TB is the part of testing.TB used by Require
+type TB interface {
+	Helper()
+	Fatalf(format string, args ...any)
+}
+
+// Require fails the test right away if Check fails
+func Require(t TB, path string, policies ...Policy) {
+	t.Helper()
+	if err := Check(path, policies...); err != nil {
+		t.Fatalf("coverage gate: %v", err)
+	}
+}
@@ -0,0 +1,56 @@
+package lcovgate_test
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	lcov "github.com/shastick/go-lcov-summary"
+	"github.com/shastick/go-lcov-summary/lcovgate"
+)
+
+const sample = "../testdata/sample.lcov"
+
+// recorder records the failure of Require
+type recorder struct {
+	failure string
+}
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Fatalf(format string, args ...any) {
+	r.failure = fmt.Sprintf(format, args...)
+}
+
+func TestCheck(t *testing.T) {
+	require.NoError(t, lcovgate.Check(sample, lcovgate.Lines(60)))
+	// Metrics without data aren't checked
+	require.NoError(t, lcovgate.Check(sample, lcovgate.Lines(60), lcovgate.Functions(90), lcovgate.Branches(90)))
+
+	err := lcovgate.Check(sample, lcovgate.Lines(80))
+	assert.EqualError(t, err, sample+": line coverage 66.7% is below the required 80.0%")
+	var gateErr *lcovgate.Error
+	require.True(t, errors.As(err, &gateErr))
+	require.Len(t, gateErr.Violations, 1)
+	assert.Equal(t, "line", gateErr.Violations[0].Metric)
+	assert.Equal(t, 80.0, gateErr.Violations[0].Required)
+
+	assert.ErrorIs(t, lcovgate.Check("missing.info"), os.ErrNotExist)
+
+	empty := filepath.Join(t.TempDir(), "empty.info")
+	require.NoError(t, os.WriteFile(empty, nil, 0o644))
+	assert.ErrorIs(t, lcovgate.Check(empty), lcov.ErrNoData)
+}
+
+func TestRequire(t *testing.T) {
+	lcovgate.Require(t, sample, lcovgate.Lines(60))
+
+	r := &recorder{}
+	lcovgate.Require(r, sample, lcovgate.Lines(70))
+	assert.Equal(t, "coverage gate: "+sample+": line coverage 66.7% is below the required 70.0%", r.failure)
+}
@@ -0,0 +1,188 @@
+// Package lcovtest generates synthetic LCOV tracefiles for tests, benchmarks and
+// fuzz seeds. The tracefiles are deterministic: the same Config always produces
+// the same bytes.
+package lcovtest
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"strconv"
+)
+
+// Config describes a generated tracefile
+type Config struct {
+	// Files is the number of SF blocks
+	Files int
+	// Lines, Functions and Branches are the number of DA, FN and BRDA records per file
+	Lines     int
+	Functions int
+	Branches  int
+	// Coverage is the probability, from 0 to 1, of each line, function and branch to be hit
+	Coverage float64
+	// TestName is written as the TN record of the tracefile, if not empty
+	TestName string
+	// Seed selects the execution counts and which records are hit
+	Seed uint64
+}
+
+// Totals are the counters of a generated tracefile, as a parser should find them
+type Totals struct {
+	Files        int
+	Lines        int64
+	LinesHit     int64
+	Functions    int64
+	FunctionsHit int64
+	Branches     int64
+	BranchesHit  int64
+}
+
+// Generate writes the tracefile described by cfg and returns its totals
+func Generate(w io.Writer, cfg Config) (Totals, error) {
+	g := &generator{
+		w:    bufio.NewWriter(w),
+		rand: rand.New(rand.NewPCG(cfg.Seed, 0)),
+		cfg:  cfg,
+	}
+	if cfg.TestName != "" {
+		g.record("TN:", cfg.TestName)
+	}
+	for i := 0; i < cfg.Files; i++ {
+		g.file(i)
+	}
+	return g.totals, g.w.Flush()
+}
+
+// Tracefile returns the tracefile described by cfg
+func Tracefile(cfg Config) []byte {
+	var buf bytes.Buffer
+	// Writes to a bytes.Buffer don't fail
+	_, _ = Generate(&buf, cfg)
+	return buf.Bytes()
+}
+
+// generator writes the records of a tracefile, reusing a single line buffer
+type generator struct {
+	w      *bufio.Writer
+	rand   *rand.Rand
+	cfg    Config
+	totals Totals
+	line   []byte
+}
+
+// file writes the SF block of the i-th file
+func (g *generator) file(i int) {
+	cfg := g.cfg
+	g.record("SF:", fmt.Sprintf("src/pkg%d/file%d.go", i/10, i))
+
+	counts := make([]int, cfg.Functions)
+	for j := range counts {
+		counts[j] = g.count()
+		g.function("FN:", spread(j, cfg.Functions, cfg.Lines), j)
+	}
+	hit := 0
+	for j, count := range counts {
+		g.function("FNDA:", count, j)
+		if count > 0 {
+			hit++
+		}
+	}
+	g.numbers("FNF:", cfg.Functions)
+	g.writeLine()
+	g.numbers("FNH:", hit)
+	g.writeLine()
+	g.totals.Functions += int64(cfg.Functions)
+	g.totals.FunctionsHit += int64(hit)
+
+	hit = 0
+	for j := 0; j < cfg.Branches; j++ {
+		line := spread(j/2, (cfg.Branches+1)/2, cfg.Lines)
+		count := g.count()
+		if count == 0 && g.rand.IntN(2) == 0 {
+			// Branch of a line that was never executed
+			g.numbers("BRDA:", line, 0, j%2)
+			g.line = append(g.line, ",-"...)
+			g.writeLine()
+			continue
+		}
+		g.numbers("BRDA:", line, 0, j%2, count)
+		g.writeLine()
+		if count > 0 {
+			hit++
+		}
+	}
+	g.numbers("BRF:", cfg.Branches)
+	g.writeLine()
+	g.numbers("BRH:", hit)
+	g.writeLine()
+	g.totals.Branches += int64(cfg.Branches)
+	g.totals.BranchesHit += int64(hit)
+
+	hit = 0
+	for j := 1; j <= cfg.Lines; j++ {
+		count := g.count()
+		g.numbers("DA:", j, count)
+		g.writeLine()
+		if count > 0 {
+			hit++
+		}
+	}
+	g.numbers("LF:", cfg.Lines)
+	g.writeLine()
+	g.numbers("LH:", hit)
+	g.writeLine()
+	g.totals.Lines += int64(cfg.Lines)
+	g.totals.LinesHit += int64(hit)
+
+	g.record("end_of_record", "")
+	g.totals.Files++
+}
+
+// count returns the execution count of a record, 0 if it isn't hit
+func (g *generator) count() int {
+	if g.rand.Float64() >= g.cfg.Coverage {
+		return 0
+	}
+	return 1 + g.rand.IntN(100)
+}
+
+// record writes a record with a string value
+func (g *generator) record(prefix, value string) {
+	g.line = append(append(g.line[:0], prefix...), value...)
+	g.writeLine()
+}
+
+// numbers starts a record of comma separated numbers, to be completed before writeLine
+func (g *generator) numbers(prefix string, values ...int) {
+	g.line = append(g.line[:0], prefix...)
+	for i, v := range values {
+		if i > 0 {
+			g.line = append(g.line, ',')
+		}
+		g.line = strconv.AppendInt(g.line, int64(v), 10)
+	}
+}
+
+// function writes an FN or FNDA record of the j-th function
+func (g *generator) function(prefix string, n, j int) {
+	g.numbers(prefix, n)
+	g.line = append(g.line, ",function"...)
+	g.line = strconv.AppendInt(g.line, int64(j), 10)
+	g.writeLine()
+}
+
+// writeLine writes the current record
+func (g *generator) writeLine() {
+	g.line = append(g.line, '\n')
+	g.w.Write(g.line)
+}
+
+// spread returns the line of the i-th of n records spread over the lines of a file
+func spread(i, n, lines int) int {
+	if n == 0 || lines < n {
+		return i + 1
+	}
+	return 1 + i*(lines/n)
+}
@@ -0,0 +1,43 @@
+package lcovtest_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	lcov "github.com/shastick/go-lcov-summary"
+	"github.com/shastick/go-lcov-summary/lcovtest"
+)
+
+func TestGenerate(t *testing.T) {
+	cfg := lcovtest.Config{Files: 20, Lines: 100, Functions: 10, Branches: 15, Coverage: 0.7, TestName: "synthetic", Seed: 42}
+
+	var buf bytes.Buffer
+	totals, err := lcovtest.Generate(&buf, cfg)
+	require.NoError(t, err)
+
+	summary, err := lcov.Summarize(bytes.NewReader(buf.Bytes()), lcov.WithDetails())
+	require.NoError(t, err)
+	assert.Empty(t, summary.Warnings)
+	assert.Equal(t, totals.Files, summary.TotalFiles)
+	assert.Equal(t, int64(2000), summary.TotalLines)
+	assert.Equal(t, totals.Lines, summary.TotalLines)
+	assert.Equal(t, totals.LinesHit, summary.CoveredLines)
+	assert.Equal(t, totals.Functions, summary.TotalFunctions)
+	assert.Equal(t, totals.FunctionsHit, summary.CoveredFunctions)
+	assert.Equal(t, totals.Branches, summary.TotalBranches)
+	assert.Equal(t, totals.BranchesHit, summary.CoveredBranches)
+	assert.InDelta(t, 70, summary.LineCoverageRate, 5)
+	assert.Equal(t, "synthetic", summary.Files[0].TestName)
+}
+
+func TestTracefileIsDeterministic(t *testing.T) {
+	cfg := lcovtest.Config{Files: 5, Lines: 50, Functions: 5, Branches: 4, Coverage: 0.5, Seed: 1}
+	first := lcovtest.Tracefile(cfg)
+	assert.Equal(t, first, lcovtest.Tracefile(cfg))
+
+	cfg.Seed = 2
+	assert.NotEqual(t, first, lcovtest.Tracefile(cfg))
+}
@@ -0,0 +1,235 @@
+package lcov
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+)
+
+// LintRule identifies the check a lint finding comes from
+type LintRule string
+
+// Lint rules
+const (
+	// LintInvalidRecord reports records that the parser rejects
+	LintInvalidRecord LintRule = "invalid-record"
+	// LintUnknownRecord reports record types the parser ignores
+	LintUnknownRecord LintRule = "unknown-record"
+	// LintOrphanRecord reports records outside of an SF block
+	LintOrphanRecord LintRule = "orphan-record"
+	// LintMissingEndOfRecord reports SF blocks not terminated by end_of_record
+	LintMissingEndOfRecord LintRule = "missing-end-of-record"
+	// LintDuplicateSourceFile reports SF blocks of a source file that already had one
+	LintDuplicateSourceFile LintRule = "duplicate-source-file"
+	// LintCountMismatch reports LF, LH, FNF, FNH, BRF and BRH counters that
+	// don't match the DA, FN, FNDA and BRDA records of their block
+	LintCountMismatch LintRule = "count-mismatch"
+)
+
+// Finding is a structural issue of a tracefile found by Lint
+type Finding struct {
+	// Line is the line of the tracefile the finding is about, starting at 1
+	Line int `json:"line"`
+	// File is the source file of the SF block the finding is in, empty outside of blocks
+	File    string   `json:"file,omitempty"`
+	Rule    LintRule `json:"rule"`
+	Message string   `json:"message"`
+}
+
+// String formats the finding as 'line: file: message [rule]'
+func (f Finding) String() string {
+	if f.File == "" {
+		return fmt.Sprintf("%d: %s [%s]", f.Line, f.Message, f.Rule)
+	}
+	return fmt.Sprintf("%d: %s: %s [%s]", f.Line, f.File, f.Message, f.Rule)
+}
+
+// Lint checks the structure of LCOV data without summarizing it, reporting every
+// issue rather than stopping at the first one as parsers do. It returns an error
+// only when the data can't be read.
+func Lint(reader io.Reader) ([]Finding, error) {
+	l := &linter{seen: make(map[string]int)}
+	p := &Parser{}
+
+	scanner := bufio.NewScanner(reader)
+	for l.line = 1; scanner.Scan(); l.line++ {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		recordType, value, err := p.parseRecord(line)
+		if err != nil {
+			l.report(LintInvalidRecord, "%v", err)
+			continue
+		}
+		l.record(p, recordType, value)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error reading LCOV data: %w", err)
+	}
+
+	if l.block != nil {
+		l.unterminated()
+	}
+	return l.findings, nil
+}
+
+// linter holds the state of Lint
+type linter struct {
+	findings []Finding
+	line     int
+	// block is the SF block being read, nil outside of blocks
+	block *lintBlock
+	// seen maps the source files to the line of their first SF block
+	seen map[string]int
+}
+
+// lintBlock accumulates the records of an SF block
+type lintBlock struct {
+	path  string
+	start int
+	// counted are the numbers of DA, FN and BRDA records, and of those hit
+	counted map[RecordType]int64
+	// stated are the LF, LH, FNF, FNH, BRF and BRH values of the block
+	stated map[RecordType]int64
+	// executed are the functions with an FNDA count above zero
+	executed map[string]bool
+}
+
+// record checks a record of valid format
+func (l *linter) record(p *Parser, recordType RecordType, value []byte) {
+	switch recordType {
+	case RecordTestName:
+		return
+	case RecordSourceFile:
+		if l.block != nil {
+			l.unterminated()
+		}
+		path := string(value)
+		l.block = &lintBlock{
+			path:     path,
+			start:    l.line,
+			counted:  make(map[RecordType]int64),
+			stated:   make(map[RecordType]int64),
+			executed: make(map[string]bool),
+		}
+		if first, ok := l.seen[path]; ok {
+			l.report(LintDuplicateSourceFile, "source file already has an SF block at line %d", first)
+		} else {
+			l.seen[path] = l.line
+		}
+		return
+	case RecordLineData, RecordLinesFound, RecordLinesHit, RecordFunctionName, RecordFunctionData,
+		RecordFunctionsFound, RecordFunctionsHit, RecordBranchData, RecordBranchFound, RecordBranchHit, RecordEndOfRecord:
+		if l.block == nil {
+			l.report(LintOrphanRecord, "%s record outside of an SF block", recordType)
+			return
+		}
+	default:
+		l.report(LintUnknownRecord, "unknown record type %s", recordType)
+		return
+	}
+
+	b := l.block
+	switch recordType {
+	case RecordLineData:
+		if !p.isValidLineData(value) {
+			l.report(LintInvalidRecord, "invalid line data format: %s", value)
+			return
+		}
+		b.count(RecordLineData, RecordLinesHit, parseLineData(value).Count > 0)
+
+	case RecordFunctionName:
+		if !p.isValidFunctionName(value) {
+			l.report(LintInvalidRecord, "invalid function name format: %s", value)
+			return
+		}
+		b.counted[RecordFunctionName]++
+
+	case RecordFunctionData:
+		count, name, found := bytes.Cut(value, []byte{','})
+		n, ok := parseCount(count)
+		if !found || !ok || len(name) == 0 {
+			l.report(LintInvalidRecord, "invalid function data format: %s", value)
+			return
+		}
+		if n > 0 {
+			b.executed[string(name)] = true
+		}
+
+	case RecordBranchData:
+		if !p.isValidBranchData(value) {
+			l.report(LintInvalidRecord, "invalid branch data format: %s", value)
+			return
+		}
+		b.count(RecordBranchData, RecordBranchHit, parseBranchData(value).Taken > 0)
+
+	case RecordLinesFound, RecordLinesHit, RecordFunctionsFound, RecordFunctionsHit, RecordBranchFound, RecordBranchHit:
+		n, ok := atoi64(value)
+		if !ok || n < 0 {
+			l.report(LintInvalidRecord, "invalid %s value: %s", recordType, value)
+			return
+		}
+		b.stated[recordType] = n
+
+	case RecordEndOfRecord:
+		l.checkCounts()
+		l.block = nil
+	}
+}
+
+// count counts a detail record of a block, and its hit if any
+func (b *lintBlock) count(recordType, hitType RecordType, hit bool) {
+	b.counted[recordType]++
+	if hit {
+		b.counted[hitType]++
+	}
+}
+
+// checkCounts compares the counters stated by the current block with its detail records
+func (l *linter) checkCounts() {
+	b := l.block
+	b.counted[RecordFunctionsHit] = int64(len(b.executed))
+	checks := []struct {
+		stated  RecordType
+		counted RecordType
+		what    string
+	}{
+		{RecordLinesFound, RecordLineData, "DA records"},
+		{RecordLinesHit, RecordLinesHit, "DA records with a count"},
+		{RecordFunctionsFound, RecordFunctionName, "FN records"},
+		{RecordFunctionsHit, RecordFunctionsHit, "functions with an FNDA count"},
+		{RecordBranchFound, RecordBranchData, "BRDA records"},
+		{RecordBranchHit, RecordBranchHit, "BRDA records taken"},
+	}
+	for _, check := range checks {
+		stated, ok := b.stated[check.stated]
+		if !ok {
+			// Counters are optional
+			continue
+		}
+		if counted := b.counted[check.counted]; stated != counted {
+			l.report(LintCountMismatch, "%s is %d but the block has %d %s", check.stated, stated, counted, check.what)
+		}
+	}
+}
+
+// unterminated reports the current block, which isn't terminated by end_of_record
+func (l *linter) unterminated() {
+	l.findings = append(l.findings, Finding{
+		Line:    l.block.start,
+		File:    l.block.path,
+		Rule:    LintMissingEndOfRecord,
+		Message: "SF block not terminated by end_of_record",
+	})
+}
+
+// report adds a finding about the current line
+func (l *linter) report(rule LintRule, format string, args ...any) {
+	f := Finding{Line: l.line, Rule: rule, Message: fmt.Sprintf(format, args...)}
+	if l.block != nil {
+		f.File = l.block.path
+	}
+	l.findings = append(l.findings, f)
+}
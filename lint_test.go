@@ -0,0 +1,65 @@
+package lcov
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLint(t *testing.T) {
+	tracefile := `TN:unit
+DA:1,1
+SF:/src/a.go
+FN:1,main
+FNDA:2,main
+DA:1,2
+DA:2,0
+DA:x,1
+VER:2
+LF:3
+LH:1
+FNF:1
+FNH:1
+end_of_record
+SF:/src/b.go
+DA:1,1
+SF:/src/a.go
+BRDA:1,0,0,1
+BRDA:1,0,1,-
+BRF:2
+BRH:2
+end_of_record
+SF:/src/c.go
+`
+	findings, err := Lint(strings.NewReader(tracefile))
+	require.NoError(t, err)
+	assert.Equal(t, []Finding{
+		{Line: 2, Rule: LintOrphanRecord, Message: "DA record outside of an SF block"},
+		{Line: 8, File: "/src/a.go", Rule: LintInvalidRecord, Message: "invalid line data format: x,1"},
+		{Line: 9, File: "/src/a.go", Rule: LintUnknownRecord, Message: "unknown record type VER"},
+		{Line: 14, File: "/src/a.go", Rule: LintCountMismatch, Message: "LF is 3 but the block has 2 DA records"},
+		{Line: 15, File: "/src/b.go", Rule: LintMissingEndOfRecord, Message: "SF block not terminated by end_of_record"},
+		{Line: 17, File: "/src/a.go", Rule: LintDuplicateSourceFile, Message: "source file already has an SF block at line 3"},
+		{Line: 22, File: "/src/a.go", Rule: LintCountMismatch, Message: "BRH is 2 but the block has 1 BRDA records taken"},
+		{Line: 23, File: "/src/c.go", Rule: LintMissingEndOfRecord, Message: "SF block not terminated by end_of_record"},
+	}, findings)
+	assert.Equal(t, "8: /src/a.go: invalid line data format: x,1 [invalid-record]", findings[1].String())
+	assert.Equal(t, "2: DA record outside of an SF block [orphan-record]", findings[0].String())
+}
+
+func TestLintValidTracefiles(t *testing.T) {
+	for _, name := range []string{"sample.lcov", "complex.lcov", "functions.lcov"} {
+		t.Run(name, func(t *testing.T) {
+			file, err := os.Open("testdata/" + name)
+			require.NoError(t, err)
+			defer file.Close()
+
+			findings, err := Lint(file)
+			require.NoError(t, err)
+			assert.Empty(t, findings)
+		})
+	}
+}
@@ -0,0 +1,76 @@
+package lcov
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+func init() {
+	RegisterRenderer("list", optionsRenderer(RenderList))
+}
+
+// RenderList writes an aligned table of every source file with its line, function
+// and branch coverage rates and counts, followed by the totals, like 'lcov --list'.
+// The summary must have been parsed WithDetails, otherwise only the totals are written.
+func RenderList(w io.Writer, s *Summary, opts ...RenderOption) error {
+	cfg := newRenderConfig(opts)
+	ew := &errWriter{w: w}
+	files := MergeFiles(s.Files)
+
+	// Columns are sized for the longest path, the rate 100% and the largest count
+	table := listTable{
+		rateWidth: len(listRate(cfg, 1, 1)),
+		numWidth:  max(len("Num"), len(strconv.FormatInt(max(s.TotalLines, s.TotalFunctions, s.TotalBranches), 10))),
+	}
+	width := len("Filename")
+	for _, f := range files {
+		width = max(width, len(f.Path))
+	}
+	cell := table.rateWidth + 1 + table.numWidth
+	separator := strings.Repeat("=", width+3*(cell+1))
+
+	ew.printf("%-*s|%-*s|%-*s|%s\n", width, "", cell, "Lines", cell, "Functions", "Branches")
+	header := fmt.Sprintf("%-*s %*s", table.rateWidth, "Rate", table.numWidth, "Num")
+	ew.printf("%-*s|%s|%s|%s\n", width, "Filename", header, header, header)
+	ew.printf("%s\n", separator)
+	for _, f := range files {
+		ew.printf("%-*s|%s|%s|%s\n", width, f.Path,
+			table.cell(cfg, f.LinesHit, f.LinesFound),
+			table.cell(cfg, f.FunctionsHit, f.FunctionsFound),
+			table.cell(cfg, f.BranchesHit, f.BranchesFound))
+	}
+	ew.printf("%s\n", separator)
+	ew.printf("%*s|%s|%s|%s\n", width, "Total:",
+		table.cell(cfg, s.CoveredLines, s.TotalLines),
+		table.cell(cfg, s.CoveredFunctions, s.TotalFunctions),
+		table.cell(cfg, s.CoveredBranches, s.TotalBranches))
+
+	return ew.err
+}
+
+// listTable holds the column widths of a metric in the file list
+type listTable struct {
+	rateWidth int
+	numWidth  int
+}
+
+// cell formats the rate and count of a metric, right aligned in its column
+func (t listTable) cell(cfg *renderConfig, hit, found int64) string {
+	cell := fmt.Sprintf("%*s", t.rateWidth, listRate(cfg, hit, found))
+	// Colors are applied after padding, escape codes having no width
+	if r, ok := rate(hit, found); ok {
+		cell = cfg.colorize(cell, r)
+	}
+	return fmt.Sprintf("%s %*d", cell, t.numWidth, found)
+}
+
+// listRate formats a coverage rate, or '-' when there is no data
+func listRate(cfg *renderConfig, hit, found int64) string {
+	r, ok := rate(hit, found)
+	if !ok {
+		return "-"
+	}
+	return strconv.FormatFloat(r, 'f', cfg.precision, 64) + "%"
+}
@@ -0,0 +1,47 @@
+package lcov
+
+import (
+	"bytes"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRenderList(t *testing.T) {
+	file, err := os.Open("testdata/functions.lcov")
+	require.NoError(t, err)
+	defer file.Close()
+
+	summary, err := Summarize(file, WithDetails())
+	require.NoError(t, err)
+
+	var out bytes.Buffer
+	require.NoError(t, RenderList(&out, summary))
+	assert.Equal(t, `                             |Lines     |Functions |Branches
+Filename                     |Rate   Num|Rate   Num|Rate   Num
+==============================================================
+/src/repo/pkg/crypto/aes.go  | 80.0%   5|100.0%   2| 75.0%   4
+/src/repo/pkg/util/strings.go|  0.0%   1|  0.0%   1|  0.0%   2
+==============================================================
+                       Total:| 66.7%   6| 66.7%   3| 50.0%   6
+`, out.String())
+}
+
+func TestRenderListNoData(t *testing.T) {
+	var out bytes.Buffer
+	require.NoError(t, RenderList(&out, &Summary{}, WithPrecision(0)))
+	assert.Contains(t, out.String(), "  Total:|   -   0|   -   0|   -   0\n")
+}
+
+func TestRenderListColor(t *testing.T) {
+	summary := &Summary{
+		Files:      []FileRecord{{Path: "a.go", LinesFound: 4, LinesHit: 4}},
+		TotalFiles: 1, TotalLines: 4, CoveredLines: 4,
+	}
+
+	var out bytes.Buffer
+	require.NoError(t, RenderList(&out, summary, WithColor(DefaultColorThresholds)))
+	assert.Contains(t, out.String(), "a.go    |\x1b[32m100.0%\x1b[0m   4|     -   0|     -   0\n")
+}
@@ -0,0 +1,43 @@
+package lcov
+
+import (
+	"log/slog"
+	"time"
+)
+
+// WithLogger emits structured events of the parsing to logger, for services
+// collecting the logs of the embedded parser: a debug event when the block of a
+// source file starts and when a record of unknown type is skipped, a warning
+// for every warning of the summary, and a debug event with the totals and the
+// duration of the parsing once done.
+func WithLogger(logger *slog.Logger) Option {
+	return func(p *Parser) {
+		p.logger = logger
+	}
+}
+
+// logDebug emits a debug event, if the parser has a logger
+func (p *Parser) logDebug(msg string, args ...any) {
+	if p.logger != nil {
+		p.logger.Debug(msg, args...)
+	}
+}
+
+// logWarning emits a warning of the summary, if the parser has a logger
+func (p *Parser) logWarning(w Warning) {
+	if p.logger == nil {
+		return
+	}
+	if w.File == "" {
+		p.logger.Warn(w.Message)
+	} else {
+		p.logger.Warn(w.Message, "file", w.File)
+	}
+}
+
+// logParsed emits the totals of a parsed summary and the duration of the parsing
+func (p *Parser) logParsed(s *Summary) {
+	p.logDebug("coverage data parsed",
+		"files", s.TotalFiles, "lines", s.TotalLines, "functions", s.TotalFunctions, "branches", s.TotalBranches,
+		"warnings", len(s.Warnings), "duration", time.Since(p.started))
+}
@@ -0,0 +1,41 @@
+package lcov
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithLogger(t *testing.T) {
+	var out bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&out, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	_, err := Summarize(strings.NewReader(inconsistentTracefile+"VER:2\n"),
+		WithLogger(logger), WithUnknownRecords(UnknownRecordsWarn))
+	require.NoError(t, err)
+
+	var events []map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(out.String()), "\n") {
+		var event map[string]any
+		require.NoError(t, json.Unmarshal([]byte(line), &event))
+		delete(event, "time")
+		events = append(events, event)
+	}
+	require.Len(t, events, 6)
+	assert.Equal(t, map[string]any{"level": "DEBUG", "msg": "source file started", "file": "/src/main.go"}, events[0])
+	assert.Equal(t, map[string]any{"level": "WARN", "msg": "3 lines hit (LH) but only 2 lines found (LF)", "file": "/src/main.go"}, events[1])
+	assert.Equal(t, "WARN", events[2]["level"])
+	assert.Equal(t, map[string]any{"level": "DEBUG", "msg": "record of unknown type skipped", "type": "VER"}, events[3])
+	assert.Equal(t, map[string]any{"level": "WARN", "msg": "1 records of unknown type VER ignored"}, events[4])
+
+	parsed := events[5]
+	assert.Equal(t, "coverage data parsed", parsed["msg"])
+	assert.Equal(t, 1.0, parsed["files"])
+	assert.Equal(t, 2.0, parsed["lines"])
+	assert.Equal(t, 3.0, parsed["warnings"])
+	assert.Contains(t, parsed, "duration")
+}
@@ -0,0 +1,120 @@
+package lcov
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+func init() {
+	RegisterRenderer("markdown", optionsRenderer(RenderMarkdown))
+}
+
+// Threshold emojis of the markdown tables
+const (
+	emojiRed    = "🔴"
+	emojiYellow = "🟡"
+	emojiGreen  = "🟢"
+)
+
+// WithBaseline adds the difference of every coverage rate with the baseline summary,
+// in formats supporting it
+func WithBaseline(baseline *Summary) RenderOption {
+	return func(c *renderConfig) {
+		c.baseline = baseline
+	}
+}
+
+// WithFileTable adds the coverage of every source file, in formats supporting it.
+// The summary must have been parsed WithDetails.
+func WithFileTable() RenderOption {
+	return func(c *renderConfig) {
+		c.fileTable = true
+	}
+}
+
+// RenderMarkdown writes the summary as a GitHub-flavored markdown table, ready to
+// paste into pull request descriptions or job summaries. Rates are marked with an
+// emoji according to the WithColor thresholds, DefaultColorThresholds otherwise.
+// WithBaseline adds a delta column and WithFileTable a collapsible per-file table.
+func RenderMarkdown(w io.Writer, s *Summary, opts ...RenderOption) error {
+	cfg := newRenderConfig(opts)
+	ew := &errWriter{w: w}
+
+	ew.printf("### Coverage summary\n\n")
+	if cfg.baseline != nil {
+		ew.printf("| Metric | Rate | Delta | Covered | Total |\n")
+		ew.printf("| --- | ---: | ---: | ---: | ---: |\n")
+	} else {
+		ew.printf("| Metric | Rate | Covered | Total |\n")
+		ew.printf("| --- | ---: | ---: | ---: |\n")
+	}
+
+	baseline := cfg.baseline
+	if baseline == nil {
+		baseline = &Summary{}
+	}
+	rows := []struct {
+		name               string
+		hit, found         int64
+		baseHit, baseFound int64
+	}{
+		{"Lines", s.CoveredLines, s.TotalLines, baseline.CoveredLines, baseline.TotalLines},
+		{"Functions", s.CoveredFunctions, s.TotalFunctions, baseline.CoveredFunctions, baseline.TotalFunctions},
+		{"Branches", s.CoveredBranches, s.TotalBranches, baseline.CoveredBranches, baseline.TotalBranches},
+	}
+	for _, row := range rows {
+		ew.printf("| %s | %s |", row.name, cfg.markdownRate(row.hit, row.found))
+		if cfg.baseline != nil {
+			ew.printf(" %s |", cfg.markdownDelta(row.hit, row.found, row.baseHit, row.baseFound))
+		}
+		ew.printf(" %d | %d |\n", row.hit, row.found)
+	}
+	ew.printf("\n%d source files\n", s.TotalFiles)
+
+	if cfg.fileTable {
+		ew.printf("\n<details><summary>Files</summary>\n\n")
+		ew.printf("| File | Lines | Functions | Branches |\n")
+		ew.printf("| --- | ---: | ---: | ---: |\n")
+		for _, f := range MergeFiles(s.Files) {
+			ew.printf("| %s | %s | %s | %s |\n", strings.ReplaceAll(f.Path, "|", `\|`),
+				cfg.markdownRate(f.LinesHit, f.LinesFound),
+				cfg.markdownRate(f.FunctionsHit, f.FunctionsFound),
+				cfg.markdownRate(f.BranchesHit, f.BranchesFound))
+		}
+		ew.printf("\n</details>\n")
+	}
+
+	return ew.err
+}
+
+// markdownRate formats a coverage rate preceded by its threshold emoji, or 'n/a' without data
+func (c *renderConfig) markdownRate(hit, found int64) string {
+	r, ok := rate(hit, found)
+	if !ok {
+		return "n/a"
+	}
+	thresholds := DefaultColorThresholds
+	if c.colors != nil {
+		thresholds = *c.colors
+	}
+	emoji := emojiGreen
+	switch {
+	case r < thresholds.Medium:
+		emoji = emojiRed
+	case r < thresholds.High:
+		emoji = emojiYellow
+	}
+	return fmt.Sprintf("%s %.*f%%", emoji, c.precision, r)
+}
+
+// markdownDelta formats the difference between a coverage rate and its baseline,
+// or 'n/a' when either has no data
+func (c *renderConfig) markdownDelta(hit, found, baseHit, baseFound int64) string {
+	r, ok := rate(hit, found)
+	base, baseOK := rate(baseHit, baseFound)
+	if !ok || !baseOK {
+		return "n/a"
+	}
+	return fmt.Sprintf("%+.*f%%", c.precision, r-base)
+}
@@ -0,0 +1,45 @@
+package lcov
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRenderMarkdown(t *testing.T) {
+	summary := SummarizeFiles([]FileRecord{
+		{Path: "a|b.go", LinesFound: 10, LinesHit: 8, FunctionsFound: 2, FunctionsHit: 2},
+		{Path: "c.go", LinesFound: 10, LinesHit: 9},
+	})
+
+	var buf bytes.Buffer
+	require.NoError(t, RenderMarkdown(&buf, summary))
+	assert.Equal(t, ""+
+		"### Coverage summary\n\n"+
+		"| Metric | Rate | Covered | Total |\n"+
+		"| --- | ---: | ---: | ---: |\n"+
+		"| Lines | 🟡 85.0% | 17 | 20 |\n"+
+		"| Functions | 🟢 100.0% | 2 | 2 |\n"+
+		"| Branches | n/a | 0 | 0 |\n"+
+		"\n2 source files\n", buf.String())
+
+	baseline := SummarizeFiles([]FileRecord{{Path: "a|b.go", LinesFound: 10, LinesHit: 9}})
+	buf.Reset()
+	require.NoError(t, RenderMarkdown(&buf, summary, WithBaseline(baseline), WithFileTable(), WithColor(ColorThresholds{Medium: 85, High: 95})))
+	assert.Equal(t, ""+
+		"### Coverage summary\n\n"+
+		"| Metric | Rate | Delta | Covered | Total |\n"+
+		"| --- | ---: | ---: | ---: | ---: |\n"+
+		"| Lines | 🟡 85.0% | -5.0% | 17 | 20 |\n"+
+		"| Functions | 🟢 100.0% | n/a | 2 | 2 |\n"+
+		"| Branches | n/a | n/a | 0 | 0 |\n"+
+		"\n2 source files\n"+
+		"\n<details><summary>Files</summary>\n\n"+
+		"| File | Lines | Functions | Branches |\n"+
+		"| --- | ---: | ---: | ---: |\n"+
+		"| a\\|b.go | 🔴 80.0% | 🟢 100.0% | n/a |\n"+
+		"| c.go | 🟡 90.0% | n/a | n/a |\n"+
+		"\n</details>\n", buf.String())
+}
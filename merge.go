@@ -0,0 +1,211 @@
+package lcov
+
+import (
+	"fmt"
+	"sort"
+)
+
+// DuplicateStrategy defines how SF blocks appearing several times for the same
+// source file are handled, as found in tracefiles naively concatenated with 'cat'.
+type DuplicateStrategy int
+
+const (
+	// DuplicatesKeep counts every block as a distinct file (default)
+	DuplicatesKeep DuplicateStrategy = iota
+	// DuplicatesMerge merges the blocks into a single file: line, function and
+	// branch data are unioned and their execution counts summed, saturating at
+	// math.MaxInt64
+	DuplicatesMerge
+	// DuplicatesFirst keeps the first block of each file and drops the others
+	DuplicatesFirst
+)
+
+// String returns the name of the strategy
+func (s DuplicateStrategy) String() string {
+	switch s {
+	case DuplicatesKeep:
+		return "keep"
+	case DuplicatesMerge:
+		return "merge"
+	case DuplicatesFirst:
+		return "first"
+	}
+	return fmt.Sprintf("DuplicateStrategy(%d)", int(s))
+}
+
+// ParseDuplicateStrategy returns the strategy matching a name as returned by String
+func ParseDuplicateStrategy(name string) (DuplicateStrategy, error) {
+	for _, s := range []DuplicateStrategy{DuplicatesKeep, DuplicatesMerge, DuplicatesFirst} {
+		if s.String() == name {
+			return s, nil
+		}
+	}
+	return DuplicatesKeep, fmt.Errorf("unknown duplicate strategy: %s", name)
+}
+
+// WithDuplicateStrategy configures how repeated SF blocks for the same file are handled
+func WithDuplicateStrategy(strategy DuplicateStrategy) Option {
+	return func(p *Parser) {
+		p.duplicates = strategy
+	}
+}
+
+// Merge folds the data of another record of the same source file into f.
+// Detailed data is unioned with execution counts summed, and the counters are
+// recomputed from it. Counters without detailed data fall back to the highest
+// of the two stated values, as both records describe the same file.
+func (f *FileRecord) Merge(other *FileRecord) {
+	if f.TestName != other.TestName {
+		f.TestName = ""
+	}
+
+	linesFound, linesHit := max(f.LinesFound, other.LinesFound), max(f.LinesHit, other.LinesHit)
+	functionsFound := max(f.FunctionsFound, other.FunctionsFound)
+	functionsHit := max(f.FunctionsHit, other.FunctionsHit)
+	branchesFound, branchesHit := max(f.BranchesFound, other.BranchesFound), max(f.BranchesHit, other.BranchesHit)
+
+	f.mergeLines(other.Lines)
+	f.mergeFunctions(other.Functions)
+	f.mergeBranches(other.Branches)
+
+	f.LinesFound, f.LinesHit = linesFound, linesHit
+	if len(f.Lines) > 0 {
+		f.LinesFound, f.LinesHit = countLines(f.Lines)
+	}
+	f.FunctionsFound, f.FunctionsHit = functionsFound, functionsHit
+	if len(f.Functions) > 0 {
+		f.FunctionsFound, f.FunctionsHit = countFunctions(f.Functions)
+	}
+	f.BranchesFound, f.BranchesHit = branchesFound, branchesHit
+	if len(f.Branches) > 0 {
+		f.BranchesFound, f.BranchesHit = countBranches(f.Branches)
+	}
+}
+
+func (f *FileRecord) mergeLines(lines []LineData) {
+	index := make(map[int]int, len(f.Lines))
+	for i, l := range f.Lines {
+		index[l.Line] = i
+	}
+	for _, l := range lines {
+		if i, ok := index[l.Line]; ok {
+			f.Lines[i].Count = addCount(f.Lines[i].Count, l.Count)
+			continue
+		}
+		index[l.Line] = len(f.Lines)
+		f.Lines = append(f.Lines, l)
+	}
+}
+
+func (f *FileRecord) mergeFunctions(functions []FunctionData) {
+	index := make(map[string]int, len(f.Functions))
+	for i, fn := range f.Functions {
+		index[fn.Name] = i
+	}
+	for _, fn := range functions {
+		if i, ok := index[fn.Name]; ok {
+			f.Functions[i].Count = addCount(f.Functions[i].Count, fn.Count)
+			if f.Functions[i].Line == 0 {
+				f.Functions[i].Line = fn.Line
+			}
+			if f.Functions[i].EndLine == 0 {
+				f.Functions[i].EndLine = fn.EndLine
+			}
+			continue
+		}
+		index[fn.Name] = len(f.Functions)
+		f.Functions = append(f.Functions, fn)
+	}
+}
+
+func (f *FileRecord) mergeBranches(branches []BranchData) {
+	type key struct{ line, block, branch int }
+	index := make(map[key]int, len(f.Branches))
+	for i, b := range f.Branches {
+		index[key{b.Line, b.Block, b.Branch}] = i
+	}
+	for _, b := range branches {
+		k := key{b.Line, b.Block, b.Branch}
+		i, ok := index[k]
+		if !ok {
+			index[k] = len(f.Branches)
+			f.Branches = append(f.Branches, b)
+			continue
+		}
+		// -1 marks a never executed block, it only survives if neither side executed it
+		switch {
+		case f.Branches[i].Taken < 0:
+			f.Branches[i].Taken = b.Taken
+		case b.Taken > 0:
+			f.Branches[i].Taken = addCount(f.Branches[i].Taken, b.Taken)
+		}
+	}
+}
+
+// fileSet collects the file records of a parse, deduplicating them by path
+// according to the configured strategy.
+type fileSet struct {
+	strategy   DuplicateStrategy
+	files      []FileRecord
+	index      map[string]int
+	duplicates int
+
+	// sink receives the records instead of files when set, see WithFileSink
+	sink func(FileRecord) error
+	// streamed holds the totals of the records passed to sink
+	streamed Summary
+	// err is the first error returned by sink
+	err error
+}
+
+func newFileSet(strategy DuplicateStrategy) *fileSet {
+	return &fileSet{strategy: strategy, index: make(map[string]int)}
+}
+
+// add appends a record, or merges it into a previous record of the same path
+func (s *fileSet) add(f *FileRecord) {
+	f.Path = intern(f.Path)
+	if s.strategy != DuplicatesKeep {
+		if i, ok := s.index[f.Path]; ok {
+			s.duplicates++
+			if s.strategy == DuplicatesMerge {
+				s.files[i].Merge(f)
+			}
+			return
+		}
+		s.index[f.Path] = len(s.files)
+	}
+	// Merged records can only be streamed once complete
+	if s.sink != nil && s.strategy != DuplicatesMerge {
+		s.stream(f)
+		return
+	}
+	s.files = append(s.files, *f)
+}
+
+// MergeFiles returns copies of the records with those of the same path merged,
+// sorted by path and with their detail data sorted by line.
+func MergeFiles(files []FileRecord) []FileRecord {
+	merged := newFileSet(DuplicatesMerge)
+	for i := range files {
+		merged.add(files[i].clone())
+	}
+
+	records := merged.files
+	sort.Slice(records, func(i, j int) bool { return records[i].Path < records[j].Path })
+	for i := range records {
+		records[i].sortDetails()
+	}
+	return records
+}
+
+// SummarizeFiles computes the summary of already parsed file records, e.g. the
+// result of MergeFiles. The records are kept in Summary.Files.
+func SummarizeFiles(files []FileRecord) *Summary {
+	summary := &Summary{Files: files}
+	for i := range files {
+		summary.addFile(&files[i])
+	}
+	summary.computeRates()
+	return summary
+}
@@ -0,0 +1,122 @@
+package lcov
+
+import (
+	"math"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSummarizeDuplicateStrategies(t *testing.T) {
+	tests := []struct {
+		strategy       DuplicateStrategy
+		files          int
+		lines          int64
+		coveredLines   int64
+		functionsHit   int64
+		branchesHit    int64
+		warningMessage string
+	}{
+		// Every block counted: 3 + 2 + 3 lines
+		{strategy: DuplicatesKeep, files: 3, lines: 8, coveredLines: 5, functionsHit: 1, branchesHit: 1},
+		// main.go lines 1-4 unioned, lines 1 and 2 hit
+		{strategy: DuplicatesMerge, files: 2, lines: 6, coveredLines: 4, functionsHit: 1, branchesHit: 1,
+			warningMessage: "1 duplicate source file blocks handled with the 'merge' strategy"},
+		// Only the first main.go block is kept
+		{strategy: DuplicatesFirst, files: 2, lines: 5, coveredLines: 3, functionsHit: 0, branchesHit: 0,
+			warningMessage: "1 duplicate source file blocks handled with the 'first' strategy"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.strategy.String(), func(t *testing.T) {
+			file, err := os.Open("testdata/concatenated.lcov")
+			require.NoError(t, err)
+			defer file.Close()
+
+			summary, err := Summarize(file, WithDuplicateStrategy(tt.strategy))
+			require.NoError(t, err)
+			assert.Equal(t, tt.files, summary.TotalFiles)
+			assert.Equal(t, tt.lines, summary.TotalLines)
+			assert.Equal(t, tt.coveredLines, summary.CoveredLines)
+			assert.Equal(t, tt.functionsHit, summary.CoveredFunctions)
+			assert.Equal(t, tt.branchesHit, summary.CoveredBranches)
+			assert.Nil(t, summary.Files)
+
+			if tt.warningMessage == "" {
+				assert.Empty(t, summary.Warnings)
+			} else {
+				assert.Equal(t, []Warning{{Message: tt.warningMessage}}, summary.Warnings)
+			}
+		})
+	}
+}
+
+func TestFileRecordMerge(t *testing.T) {
+	record := FileRecord{
+		Path:      "main.go",
+		TestName:  "unit",
+		Lines:     []LineData{{Line: 1, Count: 1}, {Line: 2, Count: 0}},
+		Functions: []FunctionData{{Name: "main", Line: 1, Count: 0}},
+		Branches:  []BranchData{{Line: 2, Block: 0, Branch: 0, Taken: -1}, {Line: 2, Block: 0, Branch: 1, Taken: -1}},
+	}
+	record.Merge(&FileRecord{
+		Path:      "main.go",
+		TestName:  "integration",
+		Lines:     []LineData{{Line: 2, Count: 4}, {Line: 3, Count: 0}},
+		Functions: []FunctionData{{Name: "main", Line: 1, Count: 2}, {Name: "helper", Line: 3, Count: 0}},
+		Branches:  []BranchData{{Line: 2, Block: 0, Branch: 0, Taken: 0}, {Line: 2, Block: 0, Branch: 1, Taken: -1}},
+	})
+
+	assert.Equal(t, "", record.TestName)
+	assert.Equal(t, []LineData{{Line: 1, Count: 1}, {Line: 2, Count: 4}, {Line: 3, Count: 0}}, record.Lines)
+	assert.Equal(t, int64(3), record.LinesFound)
+	assert.Equal(t, int64(2), record.LinesHit)
+	assert.Equal(t, int64(2), record.FunctionsFound)
+	assert.Equal(t, int64(1), record.FunctionsHit)
+	assert.Equal(t, []BranchData{{Line: 2, Block: 0, Branch: 0, Taken: 0}, {Line: 2, Block: 0, Branch: 1, Taken: -1}}, record.Branches)
+	assert.Equal(t, int64(2), record.BranchesFound)
+	assert.Equal(t, int64(0), record.BranchesHit)
+}
+
+func TestFileRecordMergeSaturates(t *testing.T) {
+	record := FileRecord{
+		Lines:     []LineData{{Line: 1, Count: math.MaxInt64 - 1}},
+		Functions: []FunctionData{{Name: "main", Line: 1, Count: math.MaxInt64}},
+		Branches:  []BranchData{{Line: 1, Block: 0, Branch: 0, Taken: math.MaxInt64 / 2}},
+	}
+	record.Merge(&FileRecord{
+		Lines:     []LineData{{Line: 1, Count: 2}},
+		Functions: []FunctionData{{Name: "main", Line: 1, Count: math.MaxInt64}},
+		Branches:  []BranchData{{Line: 1, Block: 0, Branch: 0, Taken: math.MaxInt64/2 + 2}},
+	})
+
+	assert.Equal(t, int64(math.MaxInt64), record.Lines[0].Count)
+	assert.Equal(t, int64(math.MaxInt64), record.Functions[0].Count)
+	assert.Equal(t, int64(math.MaxInt64), record.Branches[0].Taken)
+}
+
+func TestParseDuplicateStrategy(t *testing.T) {
+	strategy, err := ParseDuplicateStrategy("merge")
+	require.NoError(t, err)
+	assert.Equal(t, DuplicatesMerge, strategy)
+
+	_, err = ParseDuplicateStrategy("sum")
+	assert.EqualError(t, err, "unknown duplicate strategy: sum")
+}
+
+func TestSummarizeFiles(t *testing.T) {
+	first, err := Summarize(strings.NewReader("SF:a.go\nDA:1,1\nDA:2,0\nLF:2\nLH:1\nend_of_record\n"), WithDetails())
+	require.NoError(t, err)
+	second, err := Summarize(strings.NewReader("SF:a.go\nDA:2,3\nLF:1\nLH:1\nend_of_record\nSF:b.go\nDA:1,0\nLF:1\nLH:0\nend_of_record\n"), WithDetails())
+	require.NoError(t, err)
+
+	summary := SummarizeFiles(MergeFiles(append(first.Files, second.Files...)))
+	assert.Equal(t, 2, summary.TotalFiles)
+	assert.Equal(t, int64(3), summary.TotalLines)
+	assert.Equal(t, int64(2), summary.CoveredLines)
+	assert.InDelta(t, 66.67, summary.LineCoverageRate, 0.01)
+	assert.Len(t, summary.Files, 2)
+}
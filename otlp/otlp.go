@@ -0,0 +1,261 @@
+// Package otlp exports coverage metrics to OpenTelemetry collectors and backends
+// with the OTLP/HTTP protocol, in its JSON encoding, so that coverage can be
+// shipped into an existing observability stack.
+package otlp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/shastick/go-lcov-summary"
+)
+
+// DefaultEndpoint is the metrics endpoint of a local collector, used when none is configured
+const DefaultEndpoint = "http://localhost:4318/v1/metrics"
+
+// scopeName is the instrumentation scope of the exported metrics
+const scopeName = "github.com/shastick/go-lcov-summary"
+
+// Resource attributes of the semantic conventions describing the measured code
+const (
+	AttributeServiceName = "service.name"
+	AttributeRepository  = "vcs.repository.url.full"
+	AttributeBranch      = "vcs.ref.head.name"
+	AttributeCommit      = "vcs.ref.head.revision"
+)
+
+// Exporter sends the metrics of summaries to an OTLP/HTTP endpoint. Every export
+// records the gauges coverage.files, coverage.instrumented and coverage.covered,
+// and coverage.ratio between 0 and 1 for the metrics with data, the last three
+// with a coverage.metric attribute of line, function or branch.
+type Exporter struct {
+	Client *http.Client
+	// Endpoint is the URL the metrics are posted to, DefaultEndpoint when empty
+	Endpoint string
+	// Headers are added to the requests, e.g. for authentication
+	Headers map[string]string
+	// Resource holds the resource attributes of the metrics
+	Resource map[string]string
+}
+
+// FromEnv configures an exporter from the standard OpenTelemetry variables:
+// OTEL_EXPORTER_OTLP_METRICS_ENDPOINT or OTEL_EXPORTER_OTLP_ENDPOINT,
+// OTEL_EXPORTER_OTLP_HEADERS, OTEL_RESOURCE_ATTRIBUTES and OTEL_SERVICE_NAME.
+// The repository, branch and commit default to those of the GitHub Actions or
+// GitLab CI job, and the service name to go-lcov-summary.
+func FromEnv() (*Exporter, error) {
+	e := &Exporter{Client: http.DefaultClient, Endpoint: os.Getenv("OTEL_EXPORTER_OTLP_METRICS_ENDPOINT")}
+	if e.Endpoint == "" {
+		if base := os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT"); base != "" {
+			e.Endpoint = strings.TrimSuffix(base, "/") + "/v1/metrics"
+		}
+	}
+
+	var err error
+	if e.Headers, err = ParseAttributes(os.Getenv("OTEL_EXPORTER_OTLP_HEADERS")); err != nil {
+		return nil, fmt.Errorf("invalid OTEL_EXPORTER_OTLP_HEADERS: %w", err)
+	}
+
+	e.Resource = ciAttributes()
+	e.Resource[AttributeServiceName] = "go-lcov-summary"
+	attributes, err := ParseAttributes(os.Getenv("OTEL_RESOURCE_ATTRIBUTES"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid OTEL_RESOURCE_ATTRIBUTES: %w", err)
+	}
+	for key, value := range attributes {
+		e.Resource[key] = value
+	}
+	if name := os.Getenv("OTEL_SERVICE_NAME"); name != "" {
+		e.Resource[AttributeServiceName] = name
+	}
+	return e, nil
+}
+
+// ciAttributes returns the repository, branch and commit of the CI job, if any
+func ciAttributes() map[string]string {
+	attributes := make(map[string]string)
+	set := func(key, value string) {
+		if value != "" {
+			attributes[key] = value
+		}
+	}
+	switch {
+	case os.Getenv("GITHUB_ACTIONS") == "true":
+		if server, repository := os.Getenv("GITHUB_SERVER_URL"), os.Getenv("GITHUB_REPOSITORY"); server != "" && repository != "" {
+			set(AttributeRepository, server+"/"+repository)
+		}
+		branch := os.Getenv("GITHUB_HEAD_REF")
+		if branch == "" {
+			branch = os.Getenv("GITHUB_REF_NAME")
+		}
+		set(AttributeBranch, branch)
+		set(AttributeCommit, os.Getenv("GITHUB_SHA"))
+	case os.Getenv("GITLAB_CI") == "true":
+		set(AttributeRepository, os.Getenv("CI_PROJECT_URL"))
+		set(AttributeBranch, os.Getenv("CI_COMMIT_REF_NAME"))
+		set(AttributeCommit, os.Getenv("CI_COMMIT_SHA"))
+	}
+	return attributes
+}
+
+// ParseAttributes parses a list of comma separated key=value pairs with
+// percent-encoded values, the format of OTEL_RESOURCE_ATTRIBUTES
+func ParseAttributes(list string) (map[string]string, error) {
+	attributes := make(map[string]string)
+	for _, pair := range strings.Split(list, ",") {
+		if strings.TrimSpace(pair) == "" {
+			continue
+		}
+		key, value, ok := strings.Cut(pair, "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" {
+			return nil, fmt.Errorf("expected key=value: %s", pair)
+		}
+		value, err := url.PathUnescape(strings.TrimSpace(value))
+		if err != nil {
+			return nil, fmt.Errorf("invalid value of %s: %w", key, err)
+		}
+		attributes[key] = value
+	}
+	return attributes, nil
+}
+
+// Export records the metrics of a summary as of now
+func (e *Exporter) Export(ctx context.Context, s *lcov.Summary) error {
+	body, err := json.Marshal(newExportRequest(s, e.Resource, time.Now()))
+	if err != nil {
+		return err
+	}
+
+	endpoint := e.Endpoint
+	if endpoint == "" {
+		endpoint = DefaultEndpoint
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	for key, value := range e.Headers {
+		req.Header.Set(key, value)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := e.Client.Do(req)
+	if err != nil {
+		return fmt.Errorf("error exporting metrics: %w", err)
+	}
+	defer resp.Body.Close()
+	response, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return fmt.Errorf("error exporting metrics: %w", err)
+	}
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("metrics export failed with status %s: %s", resp.Status, bytes.TrimSpace(response))
+	}
+	return nil
+}
+
+// The messages of the OTLP metrics service, in their JSON encoding
+type (
+	exportRequest struct {
+		ResourceMetrics []resourceMetrics `json:"resourceMetrics"`
+	}
+	resourceMetrics struct {
+		Resource     resource       `json:"resource"`
+		ScopeMetrics []scopeMetrics `json:"scopeMetrics"`
+	}
+	resource struct {
+		Attributes []keyValue `json:"attributes"`
+	}
+	scopeMetrics struct {
+		Scope   scope    `json:"scope"`
+		Metrics []metric `json:"metrics"`
+	}
+	scope struct {
+		Name string `json:"name"`
+	}
+	metric struct {
+		Name        string `json:"name"`
+		Description string `json:"description"`
+		Unit        string `json:"unit"`
+		Gauge       gauge  `json:"gauge"`
+	}
+	gauge struct {
+		DataPoints []dataPoint `json:"dataPoints"`
+	}
+	dataPoint struct {
+		Attributes   []keyValue `json:"attributes,omitempty"`
+		TimeUnixNano string     `json:"timeUnixNano"`
+		// 64-bit integers are encoded as strings
+		AsInt    string   `json:"asInt,omitempty"`
+		AsDouble *float64 `json:"asDouble,omitempty"`
+	}
+	keyValue struct {
+		Key   string   `json:"key"`
+		Value anyValue `json:"value"`
+	}
+	anyValue struct {
+		StringValue string `json:"stringValue"`
+	}
+)
+
+// newExportRequest returns the request recording the metrics of a summary at t
+func newExportRequest(s *lcov.Summary, attributes map[string]string, t time.Time) *exportRequest {
+	timestamp := strconv.FormatInt(t.UnixNano(), 10)
+	intPoint := func(value int64, attributes ...keyValue) dataPoint {
+		return dataPoint{Attributes: attributes, TimeUnixNano: timestamp, AsInt: strconv.FormatInt(value, 10)}
+	}
+
+	files := metric{Name: "coverage.files", Description: "Number of source files with coverage data.", Unit: "{file}"}
+	files.Gauge.DataPoints = []dataPoint{intPoint(int64(s.TotalFiles))}
+	instrumented := metric{Name: "coverage.instrumented", Description: "Number of instrumented items, by metric.", Unit: "{item}"}
+	covered := metric{Name: "coverage.covered", Description: "Number of executed items, by metric.", Unit: "{item}"}
+	ratio := metric{Name: "coverage.ratio", Description: "Ratio of executed to instrumented items, by metric.", Unit: "1"}
+
+	for _, m := range []struct {
+		name       string
+		hit, found int64
+	}{
+		{"line", s.CoveredLines, s.TotalLines},
+		{"function", s.CoveredFunctions, s.TotalFunctions},
+		{"branch", s.CoveredBranches, s.TotalBranches},
+	} {
+		attribute := keyValue{Key: "coverage.metric", Value: anyValue{StringValue: m.name}}
+		instrumented.Gauge.DataPoints = append(instrumented.Gauge.DataPoints, intPoint(m.found, attribute))
+		covered.Gauge.DataPoints = append(covered.Gauge.DataPoints, intPoint(m.hit, attribute))
+		if m.found > 0 {
+			r := float64(m.hit) / float64(m.found)
+			ratio.Gauge.DataPoints = append(ratio.Gauge.DataPoints,
+				dataPoint{Attributes: []keyValue{attribute}, TimeUnixNano: timestamp, AsDouble: &r})
+		}
+	}
+	metrics := []metric{files, instrumented, covered}
+	if len(ratio.Gauge.DataPoints) > 0 {
+		metrics = append(metrics, ratio)
+	}
+
+	keys := make([]string, 0, len(attributes))
+	for key := range attributes {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	var resourceAttributes []keyValue
+	for _, key := range keys {
+		resourceAttributes = append(resourceAttributes, keyValue{Key: key, Value: anyValue{StringValue: attributes[key]}})
+	}
+
+	return &exportRequest{ResourceMetrics: []resourceMetrics{{
+		Resource:     resource{Attributes: resourceAttributes},
+		ScopeMetrics: []scopeMetrics{{Scope: scope{Name: scopeName}, Metrics: metrics}},
+	}}}
+}
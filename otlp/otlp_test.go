@@ -0,0 +1,133 @@
+package otlp
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/shastick/go-lcov-summary"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseAttributes(t *testing.T) {
+	attributes, err := ParseAttributes("service.name=coverage, vcs.ref.head.name=feature%2Fx,,team = core")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"service.name": "coverage", "vcs.ref.head.name": "feature/x", "team": "core"}, attributes)
+
+	attributes, err = ParseAttributes("")
+	require.NoError(t, err)
+	assert.Empty(t, attributes)
+
+	_, err = ParseAttributes("service.name")
+	assert.EqualError(t, err, "expected key=value: service.name")
+	_, err = ParseAttributes("team=%zz")
+	assert.EqualError(t, err, `invalid value of team: invalid URL escape "%zz"`)
+}
+
+func TestFromEnv(t *testing.T) {
+	t.Setenv("GITLAB_CI", "")
+	t.Setenv("GITHUB_ACTIONS", "true")
+	t.Setenv("GITHUB_SERVER_URL", "https://github.com")
+	t.Setenv("GITHUB_REPOSITORY", "owner/repo")
+	t.Setenv("GITHUB_HEAD_REF", "")
+	t.Setenv("GITHUB_REF_NAME", "main")
+	t.Setenv("GITHUB_SHA", "abc123")
+	t.Setenv("OTEL_EXPORTER_OTLP_METRICS_ENDPOINT", "")
+	t.Setenv("OTEL_EXPORTER_OTLP_ENDPOINT", "https://collector.example.com/")
+	t.Setenv("OTEL_EXPORTER_OTLP_HEADERS", "Authorization=Bearer%20secret")
+	t.Setenv("OTEL_RESOURCE_ATTRIBUTES", "service.name=ignored,team=core")
+	t.Setenv("OTEL_SERVICE_NAME", "coverage")
+
+	e, err := FromEnv()
+	require.NoError(t, err)
+	assert.Equal(t, "https://collector.example.com/v1/metrics", e.Endpoint)
+	assert.Equal(t, map[string]string{"Authorization": "Bearer secret"}, e.Headers)
+	assert.Equal(t, map[string]string{
+		AttributeServiceName: "coverage",
+		AttributeRepository:  "https://github.com/owner/repo",
+		AttributeBranch:      "main",
+		AttributeCommit:      "abc123",
+		"team":               "core",
+	}, e.Resource)
+
+	t.Setenv("OTEL_RESOURCE_ATTRIBUTES", "team")
+	_, err = FromEnv()
+	assert.EqualError(t, err, "invalid OTEL_RESOURCE_ATTRIBUTES: expected key=value: team")
+}
+
+func TestNewExportRequest(t *testing.T) {
+	summary := &lcov.Summary{TotalFiles: 2, TotalLines: 8, CoveredLines: 6, TotalFunctions: 4, CoveredFunctions: 1}
+	request := newExportRequest(summary, map[string]string{"service.name": "coverage", "team": "core"}, time.Unix(1700000000, 0))
+
+	data, err := json.Marshal(request)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"resourceMetrics": [{
+		"resource": {"attributes": [
+			{"key": "service.name", "value": {"stringValue": "coverage"}},
+			{"key": "team", "value": {"stringValue": "core"}}
+		]},
+		"scopeMetrics": [{
+			"scope": {"name": "github.com/shastick/go-lcov-summary"},
+			"metrics": [
+				{"name": "coverage.files", "description": "Number of source files with coverage data.", "unit": "{file}",
+					"gauge": {"dataPoints": [{"timeUnixNano": "1700000000000000000", "asInt": "2"}]}},
+				{"name": "coverage.instrumented", "description": "Number of instrumented items, by metric.", "unit": "{item}",
+					"gauge": {"dataPoints": [
+						{"attributes": [{"key": "coverage.metric", "value": {"stringValue": "line"}}], "timeUnixNano": "1700000000000000000", "asInt": "8"},
+						{"attributes": [{"key": "coverage.metric", "value": {"stringValue": "function"}}], "timeUnixNano": "1700000000000000000", "asInt": "4"},
+						{"attributes": [{"key": "coverage.metric", "value": {"stringValue": "branch"}}], "timeUnixNano": "1700000000000000000", "asInt": "0"}
+					]}},
+				{"name": "coverage.covered", "description": "Number of executed items, by metric.", "unit": "{item}",
+					"gauge": {"dataPoints": [
+						{"attributes": [{"key": "coverage.metric", "value": {"stringValue": "line"}}], "timeUnixNano": "1700000000000000000", "asInt": "6"},
+						{"attributes": [{"key": "coverage.metric", "value": {"stringValue": "function"}}], "timeUnixNano": "1700000000000000000", "asInt": "1"},
+						{"attributes": [{"key": "coverage.metric", "value": {"stringValue": "branch"}}], "timeUnixNano": "1700000000000000000", "asInt": "0"}
+					]}},
+				{"name": "coverage.ratio", "description": "Ratio of executed to instrumented items, by metric.", "unit": "1",
+					"gauge": {"dataPoints": [
+						{"attributes": [{"key": "coverage.metric", "value": {"stringValue": "line"}}], "timeUnixNano": "1700000000000000000", "asDouble": 0.75},
+						{"attributes": [{"key": "coverage.metric", "value": {"stringValue": "function"}}], "timeUnixNano": "1700000000000000000", "asDouble": 0.25}
+					]}}
+			]
+		}]
+	}]}`, string(data))
+}
+
+func TestExport(t *testing.T) {
+	var received exportRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/metrics", r.URL.Path)
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		assert.Equal(t, "secret", r.Header.Get("Api-Key"))
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&received))
+		io.WriteString(w, "{}")
+	}))
+	defer server.Close()
+
+	e := &Exporter{
+		Client:   server.Client(),
+		Endpoint: server.URL + "/v1/metrics",
+		Headers:  map[string]string{"api-key": "secret"},
+		Resource: map[string]string{AttributeCommit: "abc123"},
+	}
+	require.NoError(t, e.Export(context.Background(), &lcov.Summary{TotalFiles: 1, TotalLines: 2, CoveredLines: 1}))
+	require.Len(t, received.ResourceMetrics, 1)
+	assert.Equal(t, []keyValue{{Key: AttributeCommit, Value: anyValue{StringValue: "abc123"}}}, received.ResourceMetrics[0].Resource.Attributes)
+	assert.Len(t, received.ResourceMetrics[0].ScopeMetrics[0].Metrics, 4)
+}
+
+func TestExportError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+	}))
+	defer server.Close()
+
+	e := &Exporter{Client: server.Client(), Endpoint: server.URL}
+	err := e.Export(context.Background(), &lcov.Summary{})
+	assert.EqualError(t, err, "metrics export failed with status 401 Unauthorized: unauthorized")
+}
@@ -0,0 +1,176 @@
+package lcov
+
+import (
+	"encoding/json"
+	"io"
+)
+
+// UncoveredArtifactVersion is the version of the UncoveredArtifact JSON layout
+const UncoveredArtifactVersion = 1
+
+// UncoveredArtifact lists every added line of a change that is instrumented but was
+// never executed. It is meant to be stored per pull request and consumed by
+// follow-up automation such as bots or ticket creation.
+type UncoveredArtifact struct {
+	Version int `json:"version"`
+	// ChangedLines is the number of added lines that carry line coverage data
+	ChangedLines int `json:"changed_lines"`
+	// CoveredLines is the number of those lines that were executed
+	CoveredLines int               `json:"covered_lines"`
+	Uncovered    []UncoveredChange `json:"uncovered"`
+}
+
+// UncoveredChange describes a single uncovered added line along with its hunk context
+type UncoveredChange struct {
+	File string `json:"file"`
+	Line int    `json:"line"`
+	Hunk string `json:"hunk"`
+	Text string `json:"text"`
+}
+
+// NewUncoveredArtifact matches the added lines of a diff against the line data of
+// detailed file records (see WithDetails) and collects the uncovered ones.
+// Added lines without line data are not executable and are ignored.
+func NewUncoveredArtifact(files []FileRecord, diff []DiffFile) *UncoveredArtifact {
+	return NewPatchSummary(files, diff).UncoveredArtifact(diff)
+}
+
+// UncoveredArtifact lists the uncovered lines of the patch along with their
+// hunk context, taken from the diff the patch was computed from
+func (p *PatchSummary) UncoveredArtifact(diff []DiffFile) *UncoveredArtifact {
+	artifact := &UncoveredArtifact{
+		Version:      UncoveredArtifactVersion,
+		ChangedLines: p.ChangedLines,
+		CoveredLines: p.CoveredLines,
+		Uncovered:    []UncoveredChange{},
+	}
+	uncovered := make(map[string]map[int]bool, len(p.Files))
+	for _, file := range p.Files {
+		if uncovered[file.Path] == nil {
+			uncovered[file.Path] = make(map[int]bool, len(file.Uncovered))
+		}
+		for _, line := range file.Uncovered {
+			uncovered[file.Path][line] = true
+		}
+	}
+	for _, changed := range diff {
+		for _, hunk := range changed.Hunks {
+			for _, added := range hunk.Added {
+				if !uncovered[changed.Path][added.Line] {
+					continue
+				}
+				// A path listed twice by the diff is reported once
+				delete(uncovered[changed.Path], added.Line)
+				artifact.Uncovered = append(artifact.Uncovered, UncoveredChange{
+					File: changed.Path,
+					Line: added.Line,
+					Hunk: hunk.Header,
+					Text: added.Text,
+				})
+			}
+		}
+	}
+	return artifact
+}
+
+// WriteJSON writes the artifact as indented JSON
+func (a *UncoveredArtifact) WriteJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(a)
+}
+
+// lineCounts returns the execution count of every instrumented line of a file,
+// summed over all the records matching the path.
+func lineCounts(files []FileRecord, path string) map[int]int64 {
+	var counts map[int]int64
+	for _, f := range files {
+		if !pathMatches(f.Path, path) {
+			continue
+		}
+		if counts == nil {
+			counts = make(map[int]int64, len(f.Lines))
+		}
+		for _, l := range f.Lines {
+			counts[l.Line] = addCount(counts[l.Line], l.Count)
+		}
+	}
+	return counts
+}
+
+// PatchSummary is the coverage of the lines added by a change, the patch
+// coverage. Added lines without line data are not executable and are not
+// counted.
+type PatchSummary struct {
+	// ChangedLines is the number of added lines that carry line coverage data
+	ChangedLines int `json:"changed_lines"`
+	// CoveredLines is the number of those lines that were executed
+	CoveredLines int `json:"covered_lines"`
+	// Files are the changed files with such lines, in the order of the diff
+	Files []PatchFile `json:"files"`
+}
+
+// PatchFile is the patch coverage of a changed file
+type PatchFile struct {
+	// Path is the path of the file in the diff, relative to the repository root
+	Path string `json:"path"`
+	// Covered and Uncovered are the numbers of the added lines that were, or
+	// were never, executed
+	Covered   []int `json:"covered"`
+	Uncovered []int `json:"uncovered"`
+}
+
+// Rate returns the percentage of the changed lines that were executed, false
+// when no added line has line data
+func (p *PatchSummary) Rate() (float64, bool) {
+	return rate(int64(p.CoveredLines), int64(p.ChangedLines))
+}
+
+// PatchCoverage parses an LCOV tracefile and a unified diff, such as the output
+// of 'git diff', and returns the coverage of the added lines of the diff. The
+// paths of the diff are matched against the source files of the tracefile by
+// their trailing path components.
+func PatchCoverage(tracefile io.Reader, diff io.Reader) (*PatchSummary, error) {
+	summary, err := Summarize(tracefile, WithDetails())
+	if err != nil {
+		return nil, err
+	}
+	changes, err := ParseUnifiedDiff(diff)
+	if err != nil {
+		return nil, err
+	}
+	return NewPatchSummary(summary.Files, changes), nil
+}
+
+// NewPatchSummary matches the added lines of a parsed diff against the line data
+// of detailed file records (see WithDetails), as PatchCoverage does
+func NewPatchSummary(files []FileRecord, diff []DiffFile) *PatchSummary {
+	patch := &PatchSummary{Files: []PatchFile{}}
+	for _, changed := range diff {
+		counts := lineCounts(files, changed.Path)
+		if len(counts) == 0 {
+			continue
+		}
+
+		for _, hunk := range changed.Hunks {
+			for _, added := range hunk.Added {
+				count, ok := counts[added.Line]
+				if !ok {
+					continue
+				}
+				if len(patch.Files) == 0 || patch.Files[len(patch.Files)-1].Path != changed.Path {
+					patch.Files = append(patch.Files, PatchFile{Path: changed.Path, Covered: []int{}, Uncovered: []int{}})
+				}
+				file := &patch.Files[len(patch.Files)-1]
+				patch.ChangedLines++
+				if count > 0 {
+					patch.CoveredLines++
+					file.Covered = append(file.Covered, added.Line)
+				} else {
+					file.Uncovered = append(file.Uncovered, added.Line)
+				}
+			}
+		}
+	}
+	return patch
+}
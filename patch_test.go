@@ -0,0 +1,60 @@
+package lcov
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewUncoveredArtifact(t *testing.T) {
+	file, err := os.Open("testdata/with_functions_and_branches.lcov")
+	require.NoError(t, err)
+	defer file.Close()
+
+	summary, err := Summarize(file, WithDetails())
+	require.NoError(t, err)
+
+	diff, err := ParseUnifiedDiff(strings.NewReader(sampleDiff))
+	require.NoError(t, err)
+
+	// Lines 2 and 3 of main.go are instrumented (hit once / never), line 5 was never executed
+	artifact := NewUncoveredArtifact(summary.Files, diff)
+	assert.Equal(t, UncoveredArtifactVersion, artifact.Version)
+	assert.Equal(t, 3, artifact.ChangedLines)
+	assert.Equal(t, 1, artifact.CoveredLines)
+	assert.Equal(t, []UncoveredChange{
+		{File: "source/main.go", Line: 3, Hunk: "@@ -1,3 +1,5 @@ package main", Text: "added three"},
+		{File: "source/main.go", Line: 5, Hunk: "@@ -1,3 +1,5 @@ package main", Text: "++ added five looks like a header"},
+	}, artifact.Uncovered)
+
+	var out bytes.Buffer
+	require.NoError(t, artifact.WriteJSON(&out))
+	assert.Contains(t, out.String(), `"file": "source/main.go"`)
+}
+
+func TestPatchCoverage(t *testing.T) {
+	file, err := os.Open("testdata/with_functions_and_branches.lcov")
+	require.NoError(t, err)
+	defer file.Close()
+
+	patch, err := PatchCoverage(file, strings.NewReader(sampleDiff))
+	require.NoError(t, err)
+	assert.Equal(t, &PatchSummary{
+		ChangedLines: 3,
+		CoveredLines: 1,
+		Files:        []PatchFile{{Path: "source/main.go", Covered: []int{2}, Uncovered: []int{3, 5}}},
+	}, patch)
+	rate, ok := patch.Rate()
+	assert.True(t, ok)
+	assert.InDelta(t, 33.3, rate, 0.1)
+
+	_, ok = NewPatchSummary(nil, nil).Rate()
+	assert.False(t, ok)
+
+	_, err = PatchCoverage(strings.NewReader("DA:1,1\n"), strings.NewReader(sampleDiff))
+	assert.ErrorIs(t, err, ErrOrphanRecord)
+}
@@ -0,0 +1,43 @@
+package lcov
+
+import "io"
+
+// Progress reports how far the parsing of coverage data went
+type Progress struct {
+	// Bytes is the number of bytes read from the input
+	Bytes int64
+	// Files is the number of file records parsed. Only LCOV data is parsed file
+	// by file; the other formats only report their files once fully read.
+	Files int
+}
+
+// WithProgress calls fn as the input is read, with the progress made so far, and
+// once more when parsing completes. It lets programs show the status of the
+// parsing of large inputs. fn is called from the parsing goroutine and should
+// return quickly.
+func WithProgress(fn func(Progress)) Option {
+	return func(p *Parser) {
+		p.progress = fn
+	}
+}
+
+// track wraps the input of the parser to report its progress, if requested
+func (p *Parser) track(reader io.Reader) io.Reader {
+	if p.progress == nil {
+		return reader
+	}
+	return &progressReader{r: reader, p: p}
+}
+
+// progressReader reports the progress of a parser every time its input is read
+type progressReader struct {
+	r io.Reader
+	p *Parser
+}
+
+func (r *progressReader) Read(b []byte) (int, error) {
+	n, err := r.r.Read(b)
+	r.p.parsed.Bytes += int64(n)
+	r.p.progress(r.p.parsed)
+	return n, err
+}
@@ -0,0 +1,37 @@
+package lcov
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithProgress(t *testing.T) {
+	data, err := os.ReadFile("testdata/sample.lcov")
+	require.NoError(t, err)
+
+	var updates []Progress
+	file, err := os.Open("testdata/sample.lcov")
+	require.NoError(t, err)
+	defer file.Close()
+	_, err = Summarize(file, WithProgress(func(p Progress) { updates = append(updates, p) }))
+	require.NoError(t, err)
+
+	require.NotEmpty(t, updates)
+	assert.Equal(t, Progress{Bytes: int64(len(data)), Files: 2}, updates[len(updates)-1])
+	for i := 1; i < len(updates); i++ {
+		assert.GreaterOrEqual(t, updates[i].Bytes, updates[i-1].Bytes)
+	}
+
+	// Other formats report their files at the end
+	file, err = os.Open("testdata/coverage.out")
+	require.NoError(t, err)
+	defer file.Close()
+	var last Progress
+	_, _, err = SummarizeAny(file, WithProgress(func(p Progress) { last = p }))
+	require.NoError(t, err)
+	assert.Equal(t, 2, last.Files)
+	assert.Positive(t, last.Bytes)
+}
@@ -0,0 +1,57 @@
+package lcov
+
+import "io"
+
+func init() {
+	RegisterRenderer("openmetrics", RendererFunc(WriteOpenMetrics))
+}
+
+// WritePrometheus writes the summary as gauges in the Prometheus text exposition format:
+// coverage_files, coverage_<metric>_total and coverage_<metric>_covered for lines,
+// functions and branches, and coverage_ratio{metric="line|function|branch"}
+// between 0 and 1. Ratios of metrics without data are omitted.
+func WritePrometheus(w io.Writer, s *Summary) error {
+	ew := &errWriter{w: w}
+
+	ew.printf("# HELP coverage_files Number of source files with coverage data.\n")
+	ew.printf("# TYPE coverage_files gauge\n")
+	ew.printf("coverage_files %d\n", s.TotalFiles)
+
+	metrics := []struct {
+		name, label string
+		hit, found  int64
+	}{
+		{"lines", "line", s.CoveredLines, s.TotalLines},
+		{"functions", "function", s.CoveredFunctions, s.TotalFunctions},
+		{"branches", "branch", s.CoveredBranches, s.TotalBranches},
+	}
+	for _, m := range metrics {
+		ew.printf("# HELP coverage_%s_total Number of instrumented %s.\n", m.name, m.name)
+		ew.printf("# TYPE coverage_%s_total gauge\n", m.name)
+		ew.printf("coverage_%s_total %d\n", m.name, m.found)
+		ew.printf("# HELP coverage_%s_covered Number of executed %s.\n", m.name, m.name)
+		ew.printf("# TYPE coverage_%s_covered gauge\n", m.name)
+		ew.printf("coverage_%s_covered %d\n", m.name, m.hit)
+	}
+
+	ew.printf("# HELP coverage_ratio Ratio of executed to instrumented items, by metric.\n")
+	ew.printf("# TYPE coverage_ratio gauge\n")
+	for _, m := range metrics {
+		if m.found > 0 {
+			ew.printf("coverage_ratio{metric=%q} %g\n", m.label, float64(m.hit)/float64(m.found))
+		}
+	}
+
+	return ew.err
+}
+
+// WriteOpenMetrics writes the gauges of WritePrometheus in the OpenMetrics text
+// format, terminated by '# EOF'. The output suits the textfile collector of
+// node_exporter, for coverage reported without running serve mode.
+func WriteOpenMetrics(w io.Writer, s *Summary) error {
+	if err := WritePrometheus(w, s); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "# EOF\n")
+	return err
+}
@@ -0,0 +1,55 @@
+package lcov
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWritePrometheus(t *testing.T) {
+	summary := &Summary{TotalFiles: 2, TotalLines: 8, CoveredLines: 6, TotalFunctions: 4, CoveredFunctions: 1}
+
+	var out bytes.Buffer
+	require.NoError(t, WritePrometheus(&out, summary))
+	assert.Equal(t, `# HELP coverage_files Number of source files with coverage data.
+# TYPE coverage_files gauge
+coverage_files 2
+# HELP coverage_lines_total Number of instrumented lines.
+# TYPE coverage_lines_total gauge
+coverage_lines_total 8
+# HELP coverage_lines_covered Number of executed lines.
+# TYPE coverage_lines_covered gauge
+coverage_lines_covered 6
+# HELP coverage_functions_total Number of instrumented functions.
+# TYPE coverage_functions_total gauge
+coverage_functions_total 4
+# HELP coverage_functions_covered Number of executed functions.
+# TYPE coverage_functions_covered gauge
+coverage_functions_covered 1
+# HELP coverage_branches_total Number of instrumented branches.
+# TYPE coverage_branches_total gauge
+coverage_branches_total 0
+# HELP coverage_branches_covered Number of executed branches.
+# TYPE coverage_branches_covered gauge
+coverage_branches_covered 0
+# HELP coverage_ratio Ratio of executed to instrumented items, by metric.
+# TYPE coverage_ratio gauge
+coverage_ratio{metric="line"} 0.75
+coverage_ratio{metric="function"} 0.25
+`, out.String())
+}
+
+func TestWriteOpenMetrics(t *testing.T) {
+	summary := &Summary{TotalFiles: 1, TotalLines: 4, CoveredLines: 1}
+
+	var out bytes.Buffer
+	require.NoError(t, RenderFormat("openmetrics", &out, summary))
+	assert.True(t, strings.HasSuffix(out.String(), "coverage_ratio{metric=\"line\"} 0.25\n# EOF\n"), out.String())
+
+	var prometheus bytes.Buffer
+	require.NoError(t, WritePrometheus(&prometheus, summary))
+	assert.Equal(t, prometheus.String()+"# EOF\n", out.String())
+}
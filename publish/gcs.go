@@ -0,0 +1,102 @@
+package publish
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+)
+
+// DefaultGCSEndpoint is the Google Cloud Storage API used when none is configured
+const DefaultGCSEndpoint = "https://storage.googleapis.com"
+
+// metadataTokenURL is the metadata server endpoint returning an access token of
+// the service account of the instance, on Google Cloud runners
+const metadataTokenURL = "http://metadata.google.internal/computeMetadata/v1/instance/service-accounts/default/token"
+
+// GCS is a Google Cloud Storage bucket, accessed through the JSON API with an
+// OAuth 2.0 access token
+type GCS struct {
+	Client *http.Client
+	Bucket string
+	// Token is the access token, e.g. from 'gcloud auth print-access-token'. When
+	// empty, a token of the service account of the instance is requested from the
+	// metadata server.
+	Token string
+	// Endpoint is the URL of the API, DefaultGCSEndpoint when empty
+	Endpoint string
+
+	// metadataURL overrides metadataTokenURL
+	metadataURL string
+}
+
+// GCSFromEnv configures a bucket with the access token of GOOGLE_OAUTH_ACCESS_TOKEN, if set
+func GCSFromEnv(bucket string) *GCS {
+	return &GCS{Client: http.DefaultClient, Bucket: bucket, Token: os.Getenv("GOOGLE_OAUTH_ACCESS_TOKEN")}
+}
+
+func (g *GCS) endpoint() string {
+	if g.Endpoint == "" {
+		return DefaultGCSEndpoint
+	}
+	return strings.TrimSuffix(g.Endpoint, "/")
+}
+
+// URL returns the address of the object stored under key
+func (g *GCS) URL(key string) string {
+	return g.endpoint() + "/" + g.Bucket + "/" + escapePath(key)
+}
+
+// Put uploads an object with a simple media upload
+func (g *GCS) Put(ctx context.Context, key string, object Object) error {
+	if g.Token == "" {
+		token, err := g.metadataToken(ctx)
+		if err != nil {
+			return fmt.Errorf("missing Google Cloud access token, set GOOGLE_OAUTH_ACCESS_TOKEN: %w", err)
+		}
+		g.Token = token
+	}
+
+	query := url.Values{"uploadType": {"media"}, "name": {key}}
+	uploadURL := g.endpoint() + "/upload/storage/v1/b/" + url.PathEscape(g.Bucket) + "/o?" + query.Encode()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, uploadURL, bytes.NewReader(object.Data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", object.ContentType)
+	req.Header.Set("Authorization", "Bearer "+g.Token)
+	return do(g.Client, req, "GCS")
+}
+
+// metadataToken requests an access token from the metadata server
+func (g *GCS) metadataToken(ctx context.Context) (string, error) {
+	tokenURL := metadataTokenURL
+	if g.metadataURL != "" {
+		tokenURL = g.metadataURL
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, tokenURL, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Metadata-Flavor", "Google")
+	resp, err := g.Client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("metadata server returned status %s", resp.Status)
+	}
+
+	var token struct {
+		AccessToken string `json:"access_token"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&token); err != nil {
+		return "", fmt.Errorf("invalid metadata server response: %w", err)
+	}
+	return token.AccessToken, nil
+}
@@ -0,0 +1,66 @@
+package publish
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGCSPut(t *testing.T) {
+	var stored string
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Google", r.Header.Get("Metadata-Flavor"))
+		io.WriteString(w, `{"access_token": "metadata-token", "expires_in": 3599, "token_type": "Bearer"}`)
+	})
+	mux.HandleFunc("POST /upload/storage/v1/b/reports/o", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "media", r.URL.Query().Get("uploadType"))
+		assert.Equal(t, "coverage/summary.json", r.URL.Query().Get("name"))
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		assert.Equal(t, "Bearer metadata-token", r.Header.Get("Authorization"))
+		body, _ := io.ReadAll(r.Body)
+		stored = string(body)
+	})
+
+	g := &GCS{Client: server.Client(), Bucket: "reports", Endpoint: server.URL, metadataURL: server.URL + "/token"}
+	require.NoError(t, g.Put(context.Background(), "coverage/summary.json", Object{ContentType: "application/json", Data: []byte("{}")}))
+	assert.Equal(t, "{}", stored)
+	assert.Equal(t, "metadata-token", g.Token)
+	assert.Equal(t, server.URL+"/reports/coverage/summary.json", g.URL("coverage/summary.json"))
+}
+
+func TestGCSPutErrors(t *testing.T) {
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		http.NotFound(w, r)
+	})
+	mux.HandleFunc("/upload/storage/v1/b/reports/o", func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "insufficient permissions", http.StatusForbidden)
+	})
+
+	g := &GCS{Client: server.Client(), Bucket: "reports", Endpoint: server.URL, metadataURL: server.URL + "/token"}
+	err := g.Put(context.Background(), "index.html", Object{})
+	assert.EqualError(t, err, "missing Google Cloud access token, set GOOGLE_OAUTH_ACCESS_TOKEN: metadata server returned status 404 Not Found")
+
+	g.Token = "token"
+	err = g.Put(context.Background(), "index.html", Object{})
+	assert.EqualError(t, err, "GCS upload failed with status 403 Forbidden: insufficient permissions")
+}
+
+func TestGCSFromEnv(t *testing.T) {
+	t.Setenv("GOOGLE_OAUTH_ACCESS_TOKEN", "token")
+	g := GCSFromEnv("reports")
+	assert.Equal(t, "token", g.Token)
+	assert.Equal(t, "https://storage.googleapis.com/reports/index.html", g.URL("index.html"))
+}
@@ -0,0 +1,89 @@
+// Package publish uploads static coverage reports to Amazon S3 and Google Cloud
+// Storage buckets, so that they can be linked from CI without extra tooling.
+package publish
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+	"strings"
+)
+
+// Object is a file of a published report
+type Object struct {
+	// Name is the path of the object below the prefix of the location
+	Name        string
+	ContentType string
+	Data        []byte
+}
+
+// Bucket stores objects in a cloud storage bucket
+type Bucket interface {
+	// Put stores an object under key, replacing any previous version
+	Put(ctx context.Context, key string, object Object) error
+	// URL returns the address the object stored under key is served at
+	URL(key string) string
+}
+
+// ParseLocation splits a 'bucket/prefix' location into the bucket name and the
+// prefix of the object keys, which may be empty
+func ParseLocation(location string) (bucket, prefix string, err error) {
+	location = strings.TrimPrefix(strings.TrimPrefix(location, "s3://"), "gs://")
+	bucket, prefix, _ = strings.Cut(location, "/")
+	if bucket == "" {
+		return "", "", fmt.Errorf("invalid location %q, expected bucket/prefix", location)
+	}
+	return bucket, strings.Trim(prefix, "/"), nil
+}
+
+// Publish stores the objects below prefix in the bucket, and returns the URL of
+// each of them in order. It stops at the first failing upload.
+func Publish(ctx context.Context, b Bucket, prefix string, objects []Object) ([]string, error) {
+	var urls []string
+	for _, object := range objects {
+		key := path.Join(prefix, object.Name)
+		if err := b.Put(ctx, key, object); err != nil {
+			return urls, fmt.Errorf("error publishing %s: %w", object.Name, err)
+		}
+		urls = append(urls, b.URL(key))
+	}
+	return urls, nil
+}
+
+// escapePath escapes the segments of an object key for use in a URL path,
+// leaving only the unreserved characters of RFC 3986 and the slashes unescaped
+func escapePath(key string) string {
+	var b strings.Builder
+	for i := 0; i < len(key); i++ {
+		c := key[i]
+		switch {
+		case 'A' <= c && c <= 'Z', 'a' <= c && c <= 'z', '0' <= c && c <= '9',
+			c == '-', c == '.', c == '_', c == '~', c == '/':
+			b.WriteByte(c)
+		default:
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
+
+// do sends a request, failing on non 2xx statuses with the body of the response
+func do(client *http.Client, req *http.Request, service string) error {
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("%s upload failed with status %s: %s", service, resp.Status, bytes.TrimSpace(body))
+	}
+	return nil
+}
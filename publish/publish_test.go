@@ -0,0 +1,75 @@
+package publish
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseLocation(t *testing.T) {
+	tests := []struct {
+		location, bucket, prefix string
+	}{
+		{"reports", "reports", ""},
+		{"reports/coverage/main", "reports", "coverage/main"},
+		{"s3://reports/coverage/", "reports", "coverage"},
+		{"gs://reports", "reports", ""},
+	}
+	for _, tt := range tests {
+		bucket, prefix, err := ParseLocation(tt.location)
+		require.NoError(t, err)
+		assert.Equal(t, tt.bucket, bucket, tt.location)
+		assert.Equal(t, tt.prefix, prefix, tt.location)
+	}
+
+	_, _, err := ParseLocation("/coverage")
+	assert.EqualError(t, err, `invalid location "/coverage", expected bucket/prefix`)
+}
+
+func TestEscapePath(t *testing.T) {
+	assert.Equal(t, "coverage/main/index.html", escapePath("coverage/main/index.html"))
+	assert.Equal(t, "feature%2Bx/test%24file%20name.txt", escapePath("feature+x/test$file name.txt"))
+}
+
+// memoryBucket stores objects in memory, failing for the key in fail
+type memoryBucket struct {
+	objects map[string]Object
+	fail    string
+}
+
+func (b *memoryBucket) Put(_ context.Context, key string, object Object) error {
+	if key == b.fail {
+		return errors.New("access denied")
+	}
+	b.objects[key] = object
+	return nil
+}
+
+func (b *memoryBucket) URL(key string) string {
+	return "mem://" + key
+}
+
+func TestPublish(t *testing.T) {
+	bucket := &memoryBucket{objects: make(map[string]Object)}
+	objects := []Object{
+		{Name: "index.html", ContentType: "text/html; charset=utf-8", Data: []byte("<html>")},
+		{Name: "badge.svg", ContentType: "image/svg+xml", Data: []byte("<svg>")},
+	}
+
+	urls, err := Publish(context.Background(), bucket, "coverage/main", objects)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"mem://coverage/main/index.html", "mem://coverage/main/badge.svg"}, urls)
+	assert.Equal(t, objects[1], bucket.objects["coverage/main/badge.svg"])
+
+	urls, err = Publish(context.Background(), bucket, "", objects)
+	require.NoError(t, err)
+	assert.Equal(t, "mem://index.html", urls[0])
+
+	bucket.fail = "badge.svg"
+	urls, err = Publish(context.Background(), bucket, "", objects)
+	assert.EqualError(t, err, "error publishing badge.svg: access denied")
+	assert.Equal(t, []string{"mem://index.html"}, urls)
+}
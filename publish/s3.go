@@ -0,0 +1,150 @@
+package publish
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Credentials are the AWS access keys requests are signed with
+type Credentials struct {
+	AccessKeyID     string
+	SecretAccessKey string
+	// SessionToken is only set for temporary credentials
+	SessionToken string
+}
+
+// S3 is an Amazon S3 bucket, or a bucket of an S3 compatible service, accessed
+// with requests signed with AWS Signature Version 4
+type S3 struct {
+	Client      *http.Client
+	Bucket      string
+	Region      string
+	Credentials Credentials
+	// Endpoint is the URL of an S3 compatible service such as MinIO, whose
+	// buckets are addressed with path-style URLs. Amazon S3 is used when empty.
+	Endpoint string
+
+	// now returns the signing time, time.Now when nil
+	now func() time.Time
+}
+
+// S3FromEnv configures a bucket from the standard variables of the AWS tools:
+// AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY, AWS_SESSION_TOKEN, AWS_REGION (or
+// AWS_DEFAULT_REGION, us-east-1 by default) and AWS_ENDPOINT_URL_S3 (or AWS_ENDPOINT_URL)
+func S3FromEnv(bucket string) *S3 {
+	s := &S3{
+		Client: http.DefaultClient,
+		Bucket: bucket,
+		Region: firstEnv("AWS_REGION", "AWS_DEFAULT_REGION"),
+		Credentials: Credentials{
+			AccessKeyID:     os.Getenv("AWS_ACCESS_KEY_ID"),
+			SecretAccessKey: os.Getenv("AWS_SECRET_ACCESS_KEY"),
+			SessionToken:    os.Getenv("AWS_SESSION_TOKEN"),
+		},
+		Endpoint: firstEnv("AWS_ENDPOINT_URL_S3", "AWS_ENDPOINT_URL"),
+	}
+	if s.Region == "" {
+		s.Region = "us-east-1"
+	}
+	return s
+}
+
+// URL returns the address of the object stored under key
+func (s *S3) URL(key string) string {
+	if s.Endpoint != "" {
+		return strings.TrimSuffix(s.Endpoint, "/") + "/" + s.Bucket + "/" + escapePath(key)
+	}
+	return "https://" + s.Bucket + ".s3." + s.Region + ".amazonaws.com/" + escapePath(key)
+}
+
+// Put uploads an object with a PutObject request
+func (s *S3) Put(ctx context.Context, key string, object Object) error {
+	if s.Credentials.AccessKeyID == "" || s.Credentials.SecretAccessKey == "" {
+		return errors.New("missing AWS credentials, set AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY")
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, s.URL(key), bytes.NewReader(object.Data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", object.ContentType)
+
+	now := time.Now
+	if s.now != nil {
+		now = s.now
+	}
+	signV4(req, object.Data, s.Credentials, s.Region, "s3", now())
+	return do(s.Client, req, "S3")
+}
+
+// signV4 signs a request and all its headers with AWS Signature Version 4. The
+// URL path of the request must already be escaped as its canonical URI.
+func signV4(req *http.Request, payload []byte, creds Credentials, region, service string, t time.Time) {
+	payloadHash := hexSHA256(payload)
+	amzDate := t.UTC().Format("20060102T150405Z")
+	date := amzDate[:8]
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+	if creds.SessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", creds.SessionToken)
+	}
+
+	headers := map[string]string{"host": req.URL.Host}
+	for name, values := range req.Header {
+		headers[strings.ToLower(name)] = strings.TrimSpace(strings.Join(values, ","))
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method, req.URL.EscapedPath(), req.URL.RawQuery, canonicalHeaders.String(), signedHeaders, payloadHash,
+	}, "\n")
+	scope := date + "/" + region + "/" + service + "/aws4_request"
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hexSHA256([]byte(canonicalRequest))
+
+	key := []byte("AWS4" + creds.SecretAccessKey)
+	for _, part := range []string{date, region, service, "aws4_request"} {
+		key = hmacSHA256(key, part)
+	}
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		creds.AccessKeyID, scope, signedHeaders, signature))
+}
+
+func hexSHA256(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+// firstEnv returns the value of the first of the variables that is set
+func firstEnv(names ...string) string {
+	for _, name := range names {
+		if value := os.Getenv(name); value != "" {
+			return value
+		}
+	}
+	return ""
+}
@@ -0,0 +1,125 @@
+package lcov
+
+import (
+	"bytes"
+	"errors"
+	"math"
+	"strconv"
+)
+
+// recordTypeOf returns the type of a record. The known types are returned as
+// their constant, so that matching them doesn't allocate.
+func recordTypeOf(b []byte) RecordType {
+	switch RecordType(b) {
+	case RecordTestName:
+		return RecordTestName
+	case RecordSourceFile:
+		return RecordSourceFile
+	case RecordLineData:
+		return RecordLineData
+	case RecordLinesFound:
+		return RecordLinesFound
+	case RecordLinesHit:
+		return RecordLinesHit
+	case RecordFunctionName:
+		return RecordFunctionName
+	case RecordFunctionData:
+		return RecordFunctionData
+	case RecordFunctionsFound:
+		return RecordFunctionsFound
+	case RecordFunctionsHit:
+		return RecordFunctionsHit
+	case RecordBranchData:
+		return RecordBranchData
+	case RecordBranchFound:
+		return RecordBranchFound
+	case RecordBranchHit:
+		return RecordBranchHit
+	}
+	return RecordType(b)
+}
+
+// splitFields splits a comma separated value into parts, returning false when
+// it doesn't have exactly len(parts) fields
+func splitFields(value []byte, parts [][]byte) bool {
+	for i := range parts[:len(parts)-1] {
+		var found bool
+		if parts[i], value, found = bytes.Cut(value, []byte{','}); !found {
+			return false
+		}
+	}
+	parts[len(parts)-1] = value
+	return bytes.IndexByte(value, ',') < 0
+}
+
+// atoi parses a decimal integer as strconv.Atoi does, without converting it to a string
+func atoi(b []byte) (int, bool) {
+	n, ok := parseInt(b, strconv.IntSize)
+	return int(n), ok
+}
+
+// atoi64 parses a decimal execution count or counter, which may exceed 32 bits
+func atoi64(b []byte) (int64, bool) {
+	return parseInt(b, 64)
+}
+
+// parseCount parses an execution count. Besides decimal integers, it accepts
+// the values geninfo writes for saturated counters, beyond 64 bits like
+// 18446744073709551615 or in exponent notation like 9.2e+18, clamped to the
+// int64 range: only whether an item was executed matters for the summary.
+func parseCount(b []byte) (int64, bool) {
+	if n, ok := atoi64(b); ok {
+		return n, true
+	}
+	// Leave out the other syntaxes of ParseFloat, such as 'Inf' or hexadecimal
+	for _, c := range b {
+		if (c < '0' || c > '9') && c != '.' && c != 'e' && c != 'E' && c != '+' && c != '-' {
+			return 0, false
+		}
+	}
+	f, err := strconv.ParseFloat(string(b), 64)
+	// Out of range values are returned as infinities, which are clamped as well
+	if err != nil && !errors.Is(err, strconv.ErrRange) {
+		return 0, false
+	}
+	switch {
+	case f >= math.MaxInt64:
+		return math.MaxInt64, true
+	case f <= math.MinInt64:
+		return math.MinInt64, true
+	}
+	return int64(f), true
+}
+
+// parseInt parses a decimal integer fitting in bitSize bits as strconv.ParseInt
+// does, without converting it to a string
+func parseInt(b []byte, bitSize int) (int64, bool) {
+	negative := false
+	if len(b) > 0 && (b[0] == '-' || b[0] == '+') {
+		negative = b[0] == '-'
+		b = b[1:]
+	}
+	if len(b) == 0 {
+		return 0, false
+	}
+
+	limit := uint64(1)<<(bitSize-1) - 1
+	if negative {
+		limit++
+	}
+	var n uint64
+	for _, c := range b {
+		if c < '0' || c > '9' {
+			return 0, false
+		}
+		digit := uint64(c - '0')
+		if n > (limit-digit)/10 {
+			return 0, false
+		}
+		n = n*10 + digit
+	}
+	if negative {
+		return -int64(n), true
+	}
+	return int64(n), true
+}
@@ -0,0 +1,27 @@
+package lcov
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAtoi(t *testing.T) {
+	inputs := []string{
+		"0", "42", "+7", "-7", "007", "", "-", "+", "1a", " 1", "1.5",
+		"9223372036854775807", "9223372036854775808",
+		"-9223372036854775808", "-9223372036854775809",
+		"99999999999999999999",
+	}
+	for _, input := range inputs {
+		t.Run(input, func(t *testing.T) {
+			expected, err := strconv.Atoi(input)
+			n, ok := atoi([]byte(input))
+			assert.Equal(t, err == nil, ok)
+			if ok {
+				assert.Equal(t, expected, n)
+			}
+		})
+	}
+}
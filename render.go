@@ -0,0 +1,135 @@
+package lcov
+
+import (
+	"fmt"
+	"io"
+)
+
+// RenderOption configures how a summary is rendered
+type RenderOption func(*renderConfig)
+
+type renderConfig struct {
+	precision int
+	colors    *ColorThresholds
+	baseline  *Summary
+	fileTable bool
+	patch     *PatchSummary
+}
+
+func newRenderConfig(opts []RenderOption) *renderConfig {
+	cfg := &renderConfig{precision: 1}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	return cfg
+}
+
+// WithPrecision sets the number of decimals of rendered coverage rates (1 by default, like lcov)
+func WithPrecision(digits int) RenderOption {
+	return func(c *renderConfig) {
+		c.precision = max(digits, 0)
+	}
+}
+
+// WithPatch adds the patch coverage, the coverage of the lines added by a
+// change, to the summary block of the text format
+func WithPatch(patch *PatchSummary) RenderOption {
+	return func(c *renderConfig) {
+		c.patch = patch
+	}
+}
+
+// ColorThresholds are the coverage rates, in percent, from which rendered rates
+// turn from red to yellow (Medium) and from yellow to green (High)
+type ColorThresholds struct {
+	Medium float64
+	High   float64
+}
+
+// DefaultColorThresholds are the thresholds used by genhtml
+var DefaultColorThresholds = ColorThresholds{Medium: 75, High: 90}
+
+// WithColor colors the rendered coverage rates with ANSI escape codes according to the thresholds.
+// Only formats meant for terminals support colors.
+func WithColor(thresholds ColorThresholds) RenderOption {
+	return func(c *renderConfig) {
+		c.colors = &thresholds
+	}
+}
+
+// ANSI escape codes of the rate colors
+const (
+	ansiRed    = "\x1b[31m"
+	ansiYellow = "\x1b[33m"
+	ansiGreen  = "\x1b[32m"
+	ansiReset  = "\x1b[0m"
+)
+
+// colorize wraps a rendered rate in the color of its threshold, if colors are enabled
+func (c *renderConfig) colorize(text string, rate float64) string {
+	if c.colors == nil {
+		return text
+	}
+	color := ansiGreen
+	switch {
+	case rate < c.colors.Medium:
+		color = ansiRed
+	case rate < c.colors.High:
+		color = ansiYellow
+	}
+	return color + text + ansiReset
+}
+
+// rate formats a coverage rate with the configured precision and color
+func (c *renderConfig) rate(rate float64) string {
+	return c.colorize(fmt.Sprintf("%.*f%%", c.precision, rate), rate)
+}
+
+// RenderText writes the summary in the same format as 'lcov --summary'
+func RenderText(w io.Writer, s *Summary, opts ...RenderOption) error {
+	cfg := newRenderConfig(opts)
+	ew := &errWriter{w: w}
+
+	ew.printf("Summary coverage rate:\n")
+	ew.printf("  source files: %d\n", s.TotalFiles)
+	ew.printf("  lines.......: %s (%d of %d lines)\n",
+		cfg.rate(s.LineCoverageRate), s.CoveredLines, s.TotalLines)
+
+	if s.TotalFunctions > 0 {
+		ew.printf("  functions...: %s (%d of %d functions)\n",
+			cfg.rate(s.FunctionCoverageRate), s.CoveredFunctions, s.TotalFunctions)
+	} else {
+		ew.printf("  functions...: no data found\n")
+	}
+
+	if s.TotalBranches > 0 {
+		ew.printf("  branches....: %s (%d of %d branches)\n",
+			cfg.rate(s.BranchCoverageRate), s.CoveredBranches, s.TotalBranches)
+	} else {
+		ew.printf("  branches....: no data found\n")
+	}
+
+	if cfg.patch != nil {
+		if rate, ok := cfg.patch.Rate(); ok {
+			ew.printf("  patch.......: %s (%d of %d changed lines)\n",
+				cfg.rate(rate), cfg.patch.CoveredLines, cfg.patch.ChangedLines)
+		} else {
+			ew.printf("  patch.......: no changed lines with coverage data\n")
+		}
+	}
+
+	return ew.err
+}
+
+// errWriter remembers the first write error so rendering code can print unconditionally
+type errWriter struct {
+	w   io.Writer
+	err error
+}
+
+func (ew *errWriter) printf(format string, args ...any) {
+	if ew.err != nil {
+		return
+	}
+	_, ew.err = fmt.Fprintf(ew.w, format, args...)
+}
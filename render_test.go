@@ -0,0 +1,86 @@
+package lcov
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRenderText(t *testing.T) {
+	file, err := os.Open("testdata/with_functions_and_branches.lcov")
+	require.NoError(t, err)
+	defer file.Close()
+
+	summary, err := Summarize(file)
+	require.NoError(t, err)
+
+	var out bytes.Buffer
+	require.NoError(t, RenderText(&out, summary))
+	assert.Equal(t, `Summary coverage rate:
+  source files: 2
+  lines.......: 70.0% (7 of 10 lines)
+  functions...: 75.0% (3 of 4 functions)
+  branches....: 100.0% (2 of 2 branches)
+`, out.String())
+}
+
+func TestRenderTextNoData(t *testing.T) {
+	var out bytes.Buffer
+	summary := &Summary{TotalFiles: 1, TotalLines: 3, CoveredLines: 2, LineCoverageRate: 200.0 / 3}
+	require.NoError(t, RenderText(&out, summary, WithPrecision(2)))
+	assert.Equal(t, `Summary coverage rate:
+  source files: 1
+  lines.......: 66.67% (2 of 3 lines)
+  functions...: no data found
+  branches....: no data found
+`, out.String())
+}
+
+func TestRenderTextPatch(t *testing.T) {
+	var out bytes.Buffer
+	summary := &Summary{TotalFiles: 1, TotalLines: 3, CoveredLines: 2, LineCoverageRate: 200.0 / 3}
+	require.NoError(t, RenderText(&out, summary, WithPatch(&PatchSummary{ChangedLines: 20, CoveredLines: 17})))
+	assert.Equal(t, `Summary coverage rate:
+  source files: 1
+  lines.......: 66.7% (2 of 3 lines)
+  functions...: no data found
+  branches....: no data found
+  patch.......: 85.0% (17 of 20 changed lines)
+`, out.String())
+
+	out.Reset()
+	require.NoError(t, RenderText(&out, summary, WithPatch(&PatchSummary{})))
+	assert.Contains(t, out.String(), "  patch.......: no changed lines with coverage data\n")
+}
+
+type failingWriter struct{}
+
+func (w *failingWriter) Write([]byte) (int, error) {
+	return 0, errors.New("simulated write error")
+}
+
+func TestRenderTextWriteError(t *testing.T) {
+	err := RenderText(&failingWriter{}, &Summary{})
+	assert.EqualError(t, err, "simulated write error")
+}
+
+func TestRenderTextColor(t *testing.T) {
+	summary := &Summary{
+		TotalFiles: 1,
+		TotalLines: 10, CoveredLines: 7, LineCoverageRate: 70,
+		TotalFunctions: 5, CoveredFunctions: 4, FunctionCoverageRate: 80,
+		TotalBranches: 2, CoveredBranches: 2, BranchCoverageRate: 100,
+	}
+
+	var out bytes.Buffer
+	require.NoError(t, RenderText(&out, summary, WithColor(DefaultColorThresholds)))
+	assert.Equal(t, "Summary coverage rate:\n"+
+		"  source files: 1\n"+
+		"  lines.......: \x1b[31m70.0%\x1b[0m (7 of 10 lines)\n"+
+		"  functions...: \x1b[33m80.0%\x1b[0m (4 of 5 functions)\n"+
+		"  branches....: \x1b[32m100.0%\x1b[0m (2 of 2 branches)\n", out.String())
+}
@@ -0,0 +1,99 @@
+package lcov
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+)
+
+// Renderer writes a summary in a given output format
+type Renderer interface {
+	Render(w io.Writer, s *Summary) error
+}
+
+// RendererFunc adapts an ordinary function to the Renderer interface
+type RendererFunc func(w io.Writer, s *Summary) error
+
+// Render calls f(w, s)
+func (f RendererFunc) Render(w io.Writer, s *Summary) error {
+	return f(w, s)
+}
+
+// OptionsRenderer is implemented by renderers supporting render options, such as
+// the precision or colors of the rates
+type OptionsRenderer interface {
+	Renderer
+	RenderWithOptions(w io.Writer, s *Summary, opts ...RenderOption) error
+}
+
+// optionsRenderer adapts a render function taking options to the OptionsRenderer interface
+type optionsRenderer func(w io.Writer, s *Summary, opts ...RenderOption) error
+
+func (f optionsRenderer) Render(w io.Writer, s *Summary) error {
+	return f(w, s)
+}
+
+func (f optionsRenderer) RenderWithOptions(w io.Writer, s *Summary, opts ...RenderOption) error {
+	return f(w, s, opts...)
+}
+
+// RenderFormat writes a summary with the renderer registered under the given format
+// name. The options are passed to renderers implementing OptionsRenderer, and ignored by the others.
+func RenderFormat(name string, w io.Writer, s *Summary, opts ...RenderOption) error {
+	r, ok := LookupRenderer(name)
+	if !ok {
+		return fmt.Errorf("unknown output format: %s", name)
+	}
+	if or, ok := r.(OptionsRenderer); ok {
+		return or.RenderWithOptions(w, s, opts...)
+	}
+	return r.Render(w, s)
+}
+
+var (
+	renderersMu sync.RWMutex
+	renderers   = make(map[string]Renderer)
+)
+
+func init() {
+	RegisterRenderer("text", optionsRenderer(RenderText))
+}
+
+// RegisterRenderer makes a renderer available under the given format name,
+// which is the value accepted by the CLI's format selection.
+// It panics if the name is already registered or if the renderer is nil.
+func RegisterRenderer(name string, r Renderer) {
+	renderersMu.Lock()
+	defer renderersMu.Unlock()
+
+	if r == nil {
+		panic("lcov: RegisterRenderer renderer is nil")
+	}
+	if _, dup := renderers[name]; dup {
+		panic("lcov: RegisterRenderer called twice for format " + name)
+	}
+	renderers[name] = r
+}
+
+// LookupRenderer returns the renderer registered under the given format name
+func LookupRenderer(name string) (Renderer, bool) {
+	renderersMu.RLock()
+	defer renderersMu.RUnlock()
+
+	r, ok := renderers[name]
+	return r, ok
+}
+
+// Renderers returns the sorted names of the registered formats
+func Renderers() []string {
+	renderersMu.RLock()
+	defer renderersMu.RUnlock()
+
+	names := make([]string, 0, len(renderers))
+	for name := range renderers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
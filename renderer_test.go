@@ -0,0 +1,58 @@
+package lcov
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRendererRegistry(t *testing.T) {
+	RegisterRenderer("test-lines", RendererFunc(func(w io.Writer, s *Summary) error {
+		_, err := fmt.Fprintf(w, "%d/%d", s.CoveredLines, s.TotalLines)
+		return err
+	}))
+	t.Cleanup(func() { unregisterRenderer("test-lines") })
+
+	assert.Contains(t, Renderers(), "text")
+	assert.Contains(t, Renderers(), "test-lines")
+
+	renderer, ok := LookupRenderer("test-lines")
+	require.True(t, ok)
+	var out bytes.Buffer
+	require.NoError(t, renderer.Render(&out, &Summary{TotalLines: 4, CoveredLines: 3}))
+	assert.Equal(t, "3/4", out.String())
+
+	_, ok = LookupRenderer("unknown")
+	assert.False(t, ok)
+
+	assert.PanicsWithValue(t, "lcov: RegisterRenderer called twice for format text", func() {
+		RegisterRenderer("text", renderer)
+	})
+	assert.Panics(t, func() { RegisterRenderer("nil", nil) })
+}
+
+// unregisterRenderer removes a renderer registered by a test, so that it can run again
+func unregisterRenderer(name string) {
+	renderersMu.Lock()
+	defer renderersMu.Unlock()
+	delete(renderers, name)
+}
+
+func TestRenderFormat(t *testing.T) {
+	summary := &Summary{TotalLines: 3, CoveredLines: 1, LineCoverageRate: 100.0 / 3}
+
+	var out bytes.Buffer
+	require.NoError(t, RenderFormat("text", &out, summary, WithPrecision(3)))
+	assert.Contains(t, out.String(), "33.333% (1 of 3 lines)")
+
+	// Renderers without options support ignore them
+	out.Reset()
+	require.NoError(t, RenderFormat("csv", &out, summary, WithPrecision(3)))
+	assert.Contains(t, out.String(), "lines,1,3,")
+
+	assert.EqualError(t, RenderFormat("unknown", &out, summary), "unknown output format: unknown")
+}
@@ -0,0 +1,154 @@
+package lcov
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrInconsistent is returned by parsers configured WithStrict when a file
+// record claims more hits than it has lines, functions or branches
+var ErrInconsistent = errors.New("inconsistent coverage data")
+
+// WithStrict makes parsing fail with ErrInconsistent on file records claiming
+// more hits than found lines or branches (LH > LF, BRH > BRF), or more executed
+// functions than functions, and on SF blocks not terminated by end_of_record.
+// Such records, typically from corrupted, hand-edited or truncated tracefiles,
+// are otherwise kept and reported in Summary.Warnings.
+func WithStrict() Option {
+	return func(p *Parser) {
+		p.strict = true
+	}
+}
+
+// WithClampHits lowers the hits of inconsistent file records to their found
+// counts, so that no coverage rate exceeds 100%. The records are still reported
+// in Summary.Warnings.
+func WithClampHits() Option {
+	return func(p *Parser) {
+		p.clampHits = true
+	}
+}
+
+// checkHits reports the counters of a file record claiming more hits than found
+// items, clamping them if configured
+func (p *Parser) checkHits(f *FileRecord) error {
+	checks := []struct {
+		hit, found *int64
+		what       string
+	}{
+		{&f.LinesHit, &f.LinesFound, "lines hit (LH) but only %d lines found (LF)"},
+		{&f.FunctionsHit, &f.FunctionsFound, "functions executed (FNDA) but only %d functions found (FN)"},
+		{&f.BranchesHit, &f.BranchesFound, "branches hit (BRH) but only %d branches found (BRF)"},
+	}
+	for _, check := range checks {
+		if *check.hit <= *check.found {
+			continue
+		}
+		message := fmt.Sprintf("%d "+check.what, *check.hit, *check.found)
+		if p.strict {
+			return fmt.Errorf("%w: %s: %s", ErrInconsistent, f.Path, message)
+		}
+		p.warn(f.Path, "%s", message)
+		if p.clampHits {
+			*check.hit = *check.found
+		}
+	}
+	return nil
+}
+
+// endUnterminated adds the record of an SF block not terminated by end_of_record
+// to the parsed files, with a warning, or fails in strict mode
+func (p *Parser) endUnterminated(files *fileSet, f *FileRecord) error {
+	if p.strict {
+		return fmt.Errorf("%w: %s: SF block not terminated by end_of_record", ErrInconsistent, f.Path)
+	}
+	p.warn(f.Path, "SF block not terminated by end_of_record, the tracefile may be truncated")
+	return p.endBlock(files, f)
+}
+
+// blockCounts are the numbers of DA and BRDA records of an SF block, to be
+// compared with the counters it states
+type blockCounts struct {
+	lines, branches detailCount
+}
+
+// detailCount is the number of detail records of a metric, of those hit, and
+// whether the block states its found and hit counters
+type detailCount struct {
+	records, hit           int64
+	statedFound, statedHit bool
+}
+
+// count counts a detail record
+func (c *detailCount) count(hit bool) {
+	c.records++
+	if hit {
+		c.hit++
+	}
+}
+
+// lineCountRatio is the factor between the LF record of a block and its number
+// of DA records from which they are deemed wildly different
+const lineCountRatio = 2
+
+// WithCheckCounts makes the parser warn about every LF, LH, BRF and BRH record
+// that doesn't match the DA or BRDA records of its SF block, as the lint
+// count-mismatch rule does. Such mismatches usually come from buggy merges
+// upstream and silently skew the coverage rates, while only wildly different
+// LF records are reported by default. Counters of blocks without detail
+// records of their metric are not checked.
+func WithCheckCounts() Option {
+	return func(p *Parser) {
+		p.checkCounts = true
+	}
+}
+
+// checkStatedCounts warns about an SF block whose counters don't match its
+// detail records: those stating no LF at all, and either those whose LF is
+// wildly different from their DA records, the sign of a generator that doesn't
+// count the lines it instruments, or every mismatch WithCheckCounts.
+func (p *Parser) checkStatedCounts(f *FileRecord) {
+	lines, branches := p.block.lines, p.block.branches
+	switch {
+	case lines.records == 0:
+	case f.LinesFound == 0:
+		p.warn(f.Path, "%d line data records (DA) but no lines found (LF)", lines.records)
+	case p.checkCounts:
+	case lines.records >= lineCountRatio*f.LinesFound || f.LinesFound >= lineCountRatio*lines.records:
+		p.warn(f.Path, "%d lines found (LF) but %d line data records (DA)", f.LinesFound, lines.records)
+	}
+	if !p.checkCounts {
+		return
+	}
+
+	if lines.records > 0 {
+		p.checkCount(f, lines.statedFound && f.LinesFound != 0, "LF", f.LinesFound, lines.records, "DA records")
+		p.checkCount(f, lines.statedHit, "LH", f.LinesHit, lines.hit, "DA records with a count")
+	}
+	if branches.records > 0 {
+		p.checkCount(f, branches.statedFound, "BRF", f.BranchesFound, branches.records, "BRDA records")
+		p.checkCount(f, branches.statedHit, "BRH", f.BranchesHit, branches.hit, "BRDA records taken")
+	}
+}
+
+// checkCount warns about a counter of a file record, if stated, that doesn't
+// match the count of its detail records
+func (p *Parser) checkCount(f *FileRecord, stated bool, record string, value, counted int64, what string) {
+	if stated && value != counted {
+		p.warn(f.Path, "%s is %d but the block has %d %s", record, value, counted, what)
+	}
+}
+
+// checkTotals warns about the totals of an input that are typical of a
+// misconfigured instrumentation step rather than of actual test runs: no line
+// executed at all, or every line of several files executed, as when only the
+// executed lines are instrumented.
+func (p *Parser) checkTotals(s *Summary) {
+	switch {
+	case s.TotalLines == 0:
+	case s.CoveredLines == 0:
+		p.warn("", "none of the %d lines of the %d source files was executed, the tests may not have run instrumented", s.TotalLines, s.TotalFiles)
+	case s.CoveredLines == s.TotalLines && s.TotalFiles > 1:
+		p.warn("", "every source file reports 100%% line coverage, the instrumentation may only report executed lines")
+	}
+}
@@ -0,0 +1,163 @@
+package lcov
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const inconsistentTracefile = `SF:/src/main.go
+FN:1,main
+FNDA:1,main
+FNDA:1,helper
+DA:1,1
+DA:2,0
+LF:2
+LH:3
+BRF:2
+BRH:1
+end_of_record
+`
+
+func TestInconsistentHitsWarning(t *testing.T) {
+	summary, err := Summarize(strings.NewReader(inconsistentTracefile))
+	require.NoError(t, err)
+	assert.Equal(t, []Warning{
+		{File: "/src/main.go", Message: "3 lines hit (LH) but only 2 lines found (LF)"},
+		{File: "/src/main.go", Message: "2 functions executed (FNDA) but only 1 functions found (FN)"},
+	}, summary.Warnings)
+	assert.InDelta(t, 150.0, summary.LineCoverageRate, 0.01)
+}
+
+func TestWithWarningHandler(t *testing.T) {
+	var handled []Warning
+	summary, err := Summarize(strings.NewReader(inconsistentTracefile+"SF:/src/other.go\nDA:1,1\n"),
+		WithWarningHandler(func(w Warning) { handled = append(handled, w) }))
+	require.NoError(t, err)
+	require.Len(t, handled, 4)
+	assert.Equal(t, "/src/other.go", handled[2].File)
+	assert.Equal(t, summary.Warnings, handled)
+}
+
+func TestWithClampHits(t *testing.T) {
+	summary, err := Summarize(strings.NewReader(inconsistentTracefile), WithClampHits())
+	require.NoError(t, err)
+	assert.Len(t, summary.Warnings, 2)
+	assert.Equal(t, int64(2), summary.CoveredLines)
+	assert.Equal(t, int64(1), summary.CoveredFunctions)
+	assert.Equal(t, int64(1), summary.CoveredBranches)
+	assert.InDelta(t, 100.0, summary.LineCoverageRate, 0.01)
+}
+
+func TestWithStrict(t *testing.T) {
+	_, err := Summarize(strings.NewReader(inconsistentTracefile), WithStrict())
+	assert.ErrorIs(t, err, ErrInconsistent)
+	assert.EqualError(t, err, "inconsistent coverage data: /src/main.go: 3 lines hit (LH) but only 2 lines found (LF)")
+
+	_, err = Summarize(strings.NewReader("SF:/src/main.go\nDA:1,1\nLF:1\nLH:1\nend_of_record\n"), WithStrict())
+	assert.NoError(t, err)
+}
+
+func TestUnterminatedBlocks(t *testing.T) {
+	truncated := "SF:/src/a.go\nDA:1,1\nLF:1\nLH:1\nSF:/src/b.go\nDA:1,1\nDA:2,0\nLF:2\nLH:1\n"
+
+	summary, err := Summarize(strings.NewReader(truncated), WithDetails())
+	require.NoError(t, err)
+	assert.Equal(t, 2, summary.TotalFiles)
+	assert.Equal(t, int64(3), summary.TotalLines)
+	assert.Equal(t, int64(2), summary.CoveredLines)
+	assert.Equal(t, "/src/a.go", summary.Files[0].Path)
+	assert.Equal(t, []Warning{
+		{File: "/src/a.go", Message: "SF block not terminated by end_of_record, the tracefile may be truncated"},
+		{File: "/src/b.go", Message: "SF block not terminated by end_of_record, the tracefile may be truncated"},
+	}, summary.Warnings)
+
+	_, err = Summarize(strings.NewReader(truncated), WithStrict())
+	assert.ErrorIs(t, err, ErrInconsistent)
+	assert.EqualError(t, err, "inconsistent coverage data: /src/a.go: SF block not terminated by end_of_record")
+}
+
+func TestLineCountWarnings(t *testing.T) {
+	tracefile := "" +
+		"SF:/src/nolf.go\nDA:1,1\nDA:2,0\nend_of_record\n" +
+		"SF:/src/wild.go\nDA:1,1\nDA:2,0\nLF:10\nLH:1\nend_of_record\n" +
+		"SF:/src/close.go\nDA:1,1\nDA:2,0\nDA:3,0\nLF:2\nLH:1\nend_of_record\n" +
+		"SF:/src/nolines.go\nFN:1,f\nFNDA:1,f\nend_of_record\n"
+	summary, err := Summarize(strings.NewReader(tracefile))
+	require.NoError(t, err)
+	assert.Equal(t, []Warning{
+		{File: "/src/nolf.go", Message: "2 line data records (DA) but no lines found (LF)"},
+		{File: "/src/wild.go", Message: "10 lines found (LF) but 2 line data records (DA)"},
+	}, summary.Warnings)
+}
+
+func TestWithCheckCounts(t *testing.T) {
+	tracefile := "" +
+		"SF:/src/close.go\nDA:1,1\nDA:2,0\nDA:3,0\nLF:2\nLH:2\nend_of_record\n" +
+		"SF:/src/branches.go\nDA:1,1\nBRDA:1,0,0,1\nBRDA:1,0,1,-\nLF:1\nLH:1\nBRF:3\nBRH:1\nend_of_record\n" +
+		"SF:/src/totals.go\nLF:10\nLH:5\nBRF:4\nBRH:2\nend_of_record\n"
+	summary, err := Summarize(strings.NewReader(tracefile), WithCheckCounts())
+	require.NoError(t, err)
+	assert.Equal(t, []Warning{
+		{File: "/src/close.go", Message: "LF is 2 but the block has 3 DA records"},
+		{File: "/src/close.go", Message: "LH is 2 but the block has 1 DA records with a count"},
+		{File: "/src/branches.go", Message: "BRF is 3 but the block has 2 BRDA records"},
+	}, summary.Warnings)
+
+	// Deriving the totals leaves nothing to compare
+	summary, err = Summarize(strings.NewReader(tracefile), WithCheckCounts(), WithDeriveTotals(true))
+	require.NoError(t, err)
+	assert.Empty(t, summary.Warnings)
+}
+
+func TestTotalsWarnings(t *testing.T) {
+	summary, err := Summarize(strings.NewReader("SF:/src/a.go\nDA:1,0\nLF:1\nLH:0\nend_of_record\nSF:/src/b.go\nDA:1,0\nLF:1\nLH:0\nend_of_record\n"))
+	require.NoError(t, err)
+	assert.Equal(t, []Warning{
+		{Message: "none of the 2 lines of the 2 source files was executed, the tests may not have run instrumented"},
+	}, summary.Warnings)
+
+	summary, err = Summarize(strings.NewReader("SF:/src/a.go\nDA:1,1\nLF:1\nLH:1\nend_of_record\nSF:/src/b.go\nDA:1,3\nLF:1\nLH:1\nend_of_record\n"))
+	require.NoError(t, err)
+	assert.Equal(t, []Warning{
+		{Message: "every source file reports 100% line coverage, the instrumentation may only report executed lines"},
+	}, summary.Warnings)
+
+	// A single, fully covered file is common enough
+	summary, err = Summarize(strings.NewReader("SF:/src/a.go\nDA:1,1\nLF:1\nLH:1\nend_of_record\n"))
+	require.NoError(t, err)
+	assert.Empty(t, summary.Warnings)
+
+	// Other formats are checked as well
+	summary, err = ParseCoverprofile(strings.NewReader("mode: set\nexample.com/m/a.go:1.1,2.2 1 0\n"))
+	require.NoError(t, err)
+	assert.Len(t, summary.Warnings, 1)
+}
+
+func TestWithDeriveTotals(t *testing.T) {
+	tracefile := "SF:/src/main.go\nFN:1,main\nFNDA:1,main\nDA:1,1\nDA:2,0\nDA:3,4\nLF:7\nLH:7\nBRDA:1,0,0,1\nBRDA:1,0,1,-\nBRF:9\nBRH:0\nend_of_record\n"
+
+	summary, err := Summarize(strings.NewReader(tracefile))
+	require.NoError(t, err)
+	assert.Equal(t, int64(7), summary.TotalLines)
+	assert.Equal(t, int64(9), summary.TotalBranches)
+
+	for _, opts := range [][]Option{{WithDeriveTotals(true)}, {WithDeriveTotals(true), WithDetails()}} {
+		summary, err = Summarize(strings.NewReader(tracefile), opts...)
+		require.NoError(t, err)
+		assert.Equal(t, int64(3), summary.TotalLines)
+		assert.Equal(t, int64(2), summary.CoveredLines)
+		assert.Equal(t, int64(1), summary.TotalFunctions)
+		assert.Equal(t, int64(1), summary.CoveredFunctions)
+		assert.Equal(t, int64(2), summary.TotalBranches)
+		assert.Equal(t, int64(1), summary.CoveredBranches)
+		// The stated counters are ignored, so they aren't reported either
+		assert.Empty(t, summary.Warnings)
+	}
+
+	summary, err = Summarize(strings.NewReader(tracefile), WithDeriveTotals(false))
+	require.NoError(t, err)
+	assert.Equal(t, int64(7), summary.TotalLines)
+}
@@ -0,0 +1,38 @@
+package lcov
+
+// WithFileSink passes every parsed file record to sink, with its details, instead
+// of retaining them in Summary.Files. The summary totals still account for them,
+// so programs consuming the records of huge tracefiles don't hold all of them in
+// memory. LCOV data is streamed file by file, except when duplicate blocks are
+// merged: the merged records are then passed once the input is fully read, as are
+// the records of the other formats. Parsing stops with the first error of sink.
+func WithFileSink(sink func(FileRecord) error) Option {
+	return func(p *Parser) {
+		p.sink = sink
+	}
+}
+
+// stream passes a record to the sink of the set, adding it to the streamed totals
+func (s *fileSet) stream(f *FileRecord) {
+	if s.err != nil {
+		return
+	}
+	s.streamed.addFile(f)
+	s.err = s.sink(*f)
+}
+
+// flush streams the records retained by the set, when it has a sink
+func (s *fileSet) flush() error {
+	if s.sink != nil {
+		for i := range s.files {
+			s.stream(&s.files[i])
+		}
+		s.files = nil
+	}
+	return s.err
+}
+
+// len returns the number of records of the set, streamed or retained
+func (s *fileSet) len() int {
+	return s.streamed.TotalFiles + len(s.files)
+}
@@ -0,0 +1,77 @@
+package lcov
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/shastick/go-lcov-summary/lcovtest"
+)
+
+func TestWithFileSink(t *testing.T) {
+	data := lcovtest.Tracefile(lcovtest.Config{Files: 20, Lines: 30, Functions: 3, Branches: 4, Coverage: 0.6})
+	expected, err := Summarize(bytes.NewReader(data), WithDetails())
+	require.NoError(t, err)
+
+	var streamed []FileRecord
+	summary, err := Summarize(bytes.NewReader(data), WithFileSink(func(f FileRecord) error {
+		streamed = append(streamed, f)
+		return nil
+	}))
+	require.NoError(t, err)
+	assert.Equal(t, expected.Files, streamed)
+	assert.Nil(t, summary.Files)
+	expected.Files = nil
+	assert.Equal(t, expected, summary)
+}
+
+func TestWithFileSinkMergesDuplicates(t *testing.T) {
+	file, err := os.Open("testdata/concatenated.lcov")
+	require.NoError(t, err)
+	defer file.Close()
+
+	paths := make(map[string]int)
+	summary, err := Summarize(file, WithDuplicateStrategy(DuplicatesMerge), WithFileSink(func(f FileRecord) error {
+		paths[f.Path]++
+		return nil
+	}))
+	require.NoError(t, err)
+	assert.Equal(t, summary.TotalFiles, len(paths))
+	for path, n := range paths {
+		assert.Equal(t, 1, n, path)
+	}
+	assert.Len(t, summary.Warnings, 1)
+}
+
+func TestWithFileSinkError(t *testing.T) {
+	data := lcovtest.Tracefile(lcovtest.Config{Files: 5, Lines: 10})
+	errStop := errors.New("stop")
+
+	calls := 0
+	_, err := Summarize(bytes.NewReader(data), WithFileSink(func(FileRecord) error {
+		calls++
+		return errStop
+	}))
+	assert.ErrorIs(t, err, errStop)
+	assert.Equal(t, 1, calls)
+}
+
+func TestWithFileSinkOtherFormats(t *testing.T) {
+	file, err := os.Open("testdata/coverage.out")
+	require.NoError(t, err)
+	defer file.Close()
+
+	var streamed []FileRecord
+	summary, err := ParseCoverprofile(file, WithFileSink(func(f FileRecord) error {
+		streamed = append(streamed, f)
+		return nil
+	}))
+	require.NoError(t, err)
+	assert.Len(t, streamed, summary.TotalFiles)
+	assert.NotEmpty(t, streamed[0].Lines)
+	assert.Nil(t, summary.Files)
+}
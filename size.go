@@ -0,0 +1,178 @@
+package lcov
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// SizeReport breaks down the size of a tracefile by record type and by source file
+type SizeReport struct {
+	TotalBytes  int64
+	Records     []RecordSize
+	Files       []FileSize
+	Suggestions []string
+}
+
+// RecordSize is the number of bytes and lines taken by a record type
+type RecordSize struct {
+	Type  RecordType
+	Bytes int64
+	Count int
+}
+
+// FileSize is the number of bytes taken by the blocks of a source file
+type FileSize struct {
+	Path   string
+	Bytes  int64
+	Blocks int
+}
+
+// AnalyzeSize reports which record types and files dominate the size of a
+// tracefile, along with suggestions to reduce it. The data is not validated.
+func AnalyzeSize(reader io.Reader) (*SizeReport, error) {
+	report := &SizeReport{}
+	records := make(map[RecordType]*RecordSize)
+	files := make(map[string]*FileSize)
+	var current *FileSize
+
+	// size is the number of bytes of the line last scanned, its terminator
+	// included whether it is \n, \r\n or missing at the end of the data
+	var size int64
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 0, 64*1024), 10*1024*1024)
+	scanner.Split(func(data []byte, atEOF bool) (int, []byte, error) {
+		advance, token, err := bufio.ScanLines(data, atEOF)
+		if token != nil {
+			size = int64(advance)
+		}
+		return advance, token, err
+	})
+	for scanner.Scan() {
+		line := scanner.Text()
+		report.TotalBytes += size
+
+		recordType, value, _ := strings.Cut(strings.TrimSpace(line), ":")
+		if recordType == "" {
+			continue
+		}
+		r, ok := records[RecordType(recordType)]
+		if !ok {
+			r = &RecordSize{Type: RecordType(recordType)}
+			records[r.Type] = r
+		}
+		r.Bytes += size
+		r.Count++
+
+		if RecordType(recordType) == RecordSourceFile {
+			if current = files[value]; current == nil {
+				current = &FileSize{Path: value}
+				files[value] = current
+			}
+			current.Blocks++
+		}
+		if current != nil {
+			current.Bytes += size
+		}
+		if RecordType(recordType) == RecordEndOfRecord {
+			current = nil
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error reading LCOV data: %w", err)
+	}
+
+	for _, r := range records {
+		report.Records = append(report.Records, *r)
+	}
+	sort.Slice(report.Records, func(i, j int) bool {
+		if report.Records[i].Bytes != report.Records[j].Bytes {
+			return report.Records[i].Bytes > report.Records[j].Bytes
+		}
+		return report.Records[i].Type < report.Records[j].Type
+	})
+	for _, f := range files {
+		report.Files = append(report.Files, *f)
+	}
+	sort.Slice(report.Files, func(i, j int) bool {
+		if report.Files[i].Bytes != report.Files[j].Bytes {
+			return report.Files[i].Bytes > report.Files[j].Bytes
+		}
+		return report.Files[i].Path < report.Files[j].Path
+	})
+
+	report.suggest()
+	return report, nil
+}
+
+// Share returns the percentage of the total size taken by the given number of bytes
+func (r *SizeReport) Share(bytes int64) float64 {
+	if r.TotalBytes == 0 {
+		return 0
+	}
+	return float64(bytes) / float64(r.TotalBytes) * 100
+}
+
+// suggest derives size reduction suggestions from the breakdown: the data the
+// coverage tool can be told not to generate, and the duplicate blocks the merge
+// subcommand merges
+func (r *SizeReport) suggest() {
+	shares := make(map[RecordType]float64)
+	for _, rec := range r.Records {
+		shares[rec.Type] = r.Share(rec.Bytes)
+	}
+
+	if share := shares[RecordBranchData]; share >= 25 {
+		r.Suggestions = append(r.Suggestions, fmt.Sprintf(
+			"BRDA records are %.0f%% of the data: if branch coverage is not used, disable it when capturing, e.g. lcov --rc branch_coverage=0", share))
+	}
+	if share := shares[RecordFunctionName] + shares[RecordFunctionData]; share >= 25 {
+		r.Suggestions = append(r.Suggestions, fmt.Sprintf(
+			"FN/FNDA records are %.0f%% of the data: if function coverage is not used, disable it when capturing, e.g. lcov --rc function_coverage=0", share))
+	}
+	if share := shares[RecordTestName]; share >= 5 {
+		r.Suggestions = append(r.Suggestions, fmt.Sprintf(
+			"TN records are %.0f%% of the data: if per-test coverage is not used, capture without --test-name", share))
+	}
+
+	duplicated := 0
+	for _, f := range r.Files {
+		if f.Blocks > 1 {
+			duplicated++
+		}
+	}
+	if duplicated > 0 {
+		r.Suggestions = append(r.Suggestions, fmt.Sprintf(
+			"%d source files appear in several blocks: go-lcov-summary merge writes a single block per file", duplicated))
+	}
+}
+
+// WriteText writes the report as a human readable breakdown, listing at most topFiles files
+func (r *SizeReport) WriteText(w io.Writer, topFiles int) error {
+	ew := &errWriter{w: w}
+
+	ew.printf("Total size: %d bytes\n", r.TotalBytes)
+	ew.printf("Records:\n")
+	for _, rec := range r.Records {
+		ew.printf("  %-13s %5.1f%% %12d bytes %10d records\n", rec.Type, r.Share(rec.Bytes), rec.Bytes, rec.Count)
+	}
+
+	ew.printf("Largest files:\n")
+	for i, f := range r.Files {
+		if i >= topFiles {
+			break
+		}
+		ew.printf("  %5.1f%% %12d bytes  %s\n", r.Share(f.Bytes), f.Bytes, f.Path)
+	}
+
+	if len(r.Suggestions) > 0 {
+		ew.printf("Suggestions:\n")
+		for _, s := range r.Suggestions {
+			ew.printf("  - %s\n", s)
+		}
+	}
+
+	return ew.err
+}
@@ -0,0 +1,52 @@
+package lcov
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAnalyzeSize(t *testing.T) {
+	file, err := os.Open("testdata/concatenated.lcov")
+	require.NoError(t, err)
+	defer file.Close()
+
+	report, err := AnalyzeSize(file)
+	require.NoError(t, err)
+
+	info, err := os.Stat("testdata/concatenated.lcov")
+	require.NoError(t, err)
+	assert.Equal(t, info.Size(), report.TotalBytes)
+
+	assert.Equal(t, RecordSize{Type: RecordSourceFile, Bytes: 82, Count: 3}, report.Records[0])
+	require.Len(t, report.Files, 2)
+	assert.Equal(t, "/path/to/source/main.go", report.Files[0].Path)
+	assert.Equal(t, 2, report.Files[0].Blocks)
+	assert.Contains(t, report.Suggestions, "1 source files appear in several blocks: go-lcov-summary merge writes a single block per file")
+
+	var out bytes.Buffer
+	require.NoError(t, report.WriteText(&out, 1))
+	assert.Contains(t, out.String(), "Total size: 361 bytes\n")
+	assert.Contains(t, out.String(), "/path/to/source/main.go")
+	assert.NotContains(t, out.String(), "/path/to/source/utils.go")
+}
+
+func TestAnalyzeSizeCRLF(t *testing.T) {
+	input := "SF:a.go\r\nDA:1,1\r\nend_of_record"
+	report, err := AnalyzeSize(strings.NewReader(input))
+	require.NoError(t, err)
+	assert.Equal(t, int64(len(input)), report.TotalBytes)
+	assert.Equal(t, RecordSize{Type: RecordSourceFile, Bytes: 9, Count: 1}, report.Records[1])
+}
+
+func TestAnalyzeSizeBranchHeavy(t *testing.T) {
+	input := "SF:a.go\nBRDA:1,0,0,1\nBRDA:1,0,1,0\nBRDA:2,0,0,1\nBRDA:2,0,1,0\nend_of_record\n"
+	report, err := AnalyzeSize(strings.NewReader(input))
+	require.NoError(t, err)
+	require.Len(t, report.Suggestions, 1)
+	assert.Contains(t, report.Suggestions[0], "BRDA records are 70% of the data")
+}
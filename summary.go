@@ -0,0 +1,68 @@
+package lcov
+
+import (
+	"strings"
+)
+
+// Metric identifies a coverage metric. Its values are the names used in
+// threshold violations and metric exports.
+type Metric string
+
+const (
+	MetricLines     Metric = "line"
+	MetricFunctions Metric = "function"
+	MetricBranches  Metric = "branch"
+)
+
+// Metrics lists the coverage metrics in display order
+var Metrics = []Metric{MetricLines, MetricFunctions, MetricBranches}
+
+// Counts returns the number of executed and instrumented items of a metric,
+// zero for unknown metrics
+func (s *Summary) Counts(m Metric) (covered, total int64) {
+	switch m {
+	case MetricLines:
+		return s.CoveredLines, s.TotalLines
+	case MetricFunctions:
+		return s.CoveredFunctions, s.TotalFunctions
+	case MetricBranches:
+		return s.CoveredBranches, s.TotalBranches
+	}
+	return 0, 0
+}
+
+// Rate returns the coverage percentage of a metric, and false when the summary
+// has no data for it. Unlike the rate fields, it is computed from the counts,
+// so it is also right for summaries built by hand.
+func (s *Summary) Rate(m Metric) (float64, bool) {
+	return rate(s.Counts(m))
+}
+
+// Uncovered returns the number of instrumented items of a metric that were never executed
+func (s *Summary) Uncovered(m Metric) int64 {
+	covered, total := s.Counts(m)
+	return max(total-covered, 0)
+}
+
+// UncoveredLines returns the number of instrumented lines that were never executed
+func (s *Summary) UncoveredLines() int64 {
+	return s.Uncovered(MetricLines)
+}
+
+// UncoveredFunctions returns the number of functions that were never called
+func (s *Summary) UncoveredFunctions() int64 {
+	return s.Uncovered(MetricFunctions)
+}
+
+// UncoveredBranches returns the number of branches that were never taken
+func (s *Summary) UncoveredBranches() int64 {
+	return s.Uncovered(MetricBranches)
+}
+
+// String formats the summary like 'lcov --summary' and the text format of the CLI
+func (s *Summary) String() string {
+	var b strings.Builder
+	// Writing to a strings.Builder doesn't fail
+	_ = RenderText(&b, s)
+	return b.String()
+}
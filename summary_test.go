@@ -0,0 +1,49 @@
+package lcov
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSummaryMetrics(t *testing.T) {
+	summary := &Summary{TotalLines: 10, CoveredLines: 7, TotalFunctions: 4, CoveredFunctions: 4}
+
+	covered, total := summary.Counts(MetricLines)
+	assert.Equal(t, int64(7), covered)
+	assert.Equal(t, int64(10), total)
+	assert.Equal(t, int64(3), summary.UncoveredLines())
+	assert.Equal(t, int64(0), summary.UncoveredFunctions())
+	assert.Equal(t, int64(0), summary.UncoveredBranches())
+
+	// The rate fields aren't set, the rates are computed from the counts
+	r, ok := summary.Rate(MetricLines)
+	assert.True(t, ok)
+	assert.InDelta(t, 70.0, r, 1e-9)
+	r, ok = summary.Rate(MetricFunctions)
+	assert.True(t, ok)
+	assert.Equal(t, 100.0, r)
+	_, ok = summary.Rate(MetricBranches)
+	assert.False(t, ok)
+
+	_, ok = summary.Rate(Metric("statement"))
+	assert.False(t, ok)
+	assert.Equal(t, int64(0), summary.Uncovered(Metric("statement")))
+}
+
+func TestSummaryString(t *testing.T) {
+	file, err := os.Open("testdata/with_functions_and_branches.lcov")
+	require.NoError(t, err)
+	defer file.Close()
+
+	summary, err := Summarize(file)
+	require.NoError(t, err)
+	assert.Equal(t, `Summary coverage rate:
+  source files: 2
+  lines.......: 70.0% (7 of 10 lines)
+  functions...: 75.0% (3 of 4 functions)
+  branches....: 100.0% (2 of 2 branches)
+`, summary.String())
+}
@@ -0,0 +1,92 @@
+package lcov
+
+import (
+	"slices"
+	"sort"
+)
+
+// TestCoverage is the coverage of the tests named by a TN record, over the
+// records of every tracefile carrying that name
+type TestCoverage struct {
+	// Name is the test name, empty for the records preceding any TN record
+	Name string
+	// CoveredLines is the number of lines the test executes, and UniqueLines
+	// the number of those no other test executes
+	CoveredLines int64
+	UniqueLines  int64
+	// Files are the files with lines the test executes, sorted by path
+	Files []TestFileCoverage
+}
+
+// TestFileCoverage is the coverage of a source file by a test
+type TestFileCoverage struct {
+	Path         string
+	CoveredLines int64
+	UniqueLines  int64
+}
+
+// CoverageByTest returns the coverage of every test name of detailed records
+// (see WithDetails), sorted by name, such as tracefiles of several test runs
+// captured with geninfo --test-name and combined: which lines each test
+// executes, and which it is the only one to execute. Tests without unique
+// lines are candidates for removal, and tests covering much of the code are
+// often too broad. The records must not have been merged across test names,
+// which loses them.
+func CoverageByTest(files []FileRecord) []TestCoverage {
+	type line struct {
+		path string
+		line int
+	}
+	// Tests executing every line, by index in tests
+	executed := make(map[line][]int)
+	index := make(map[string]int)
+	var tests []TestCoverage
+	for i := range files {
+		f := &files[i]
+		test, ok := index[f.TestName]
+		if !ok {
+			test = len(tests)
+			index[f.TestName] = test
+			tests = append(tests, TestCoverage{Name: f.TestName})
+		}
+		for _, l := range f.Lines {
+			if l.Count <= 0 {
+				continue
+			}
+			key := line{f.Path, l.Line}
+			if !slices.Contains(executed[key], test) {
+				executed[key] = append(executed[key], test)
+			}
+		}
+	}
+
+	type testFile struct {
+		test int
+		path string
+	}
+	counts := make(map[testFile]*TestFileCoverage)
+	for key, by := range executed {
+		for _, test := range by {
+			c, ok := counts[testFile{test, key.path}]
+			if !ok {
+				c = &TestFileCoverage{Path: key.path}
+				counts[testFile{test, key.path}] = c
+			}
+			c.CoveredLines++
+			tests[test].CoveredLines++
+			if len(by) == 1 {
+				c.UniqueLines++
+				tests[test].UniqueLines++
+			}
+		}
+	}
+	for key, c := range counts {
+		tests[key.test].Files = append(tests[key.test].Files, *c)
+	}
+
+	for i := range tests {
+		sort.Slice(tests[i].Files, func(a, b int) bool { return tests[i].Files[a].Path < tests[i].Files[b].Path })
+	}
+	sort.Slice(tests, func(i, j int) bool { return tests[i].Name < tests[j].Name })
+	return tests
+}
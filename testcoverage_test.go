@@ -0,0 +1,30 @@
+package lcov
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCoverageByTest(t *testing.T) {
+	tracefile := "" +
+		"SF:/src/a.go\nDA:1,1\nDA:2,0\nend_of_record\n" +
+		"TN:unit\nSF:/src/a.go\nDA:1,3\nDA:2,1\nDA:3,0\nend_of_record\nSF:/src/b.go\nDA:1,1\nend_of_record\n" +
+		"TN:e2e\nSF:/src/a.go\nDA:1,1\nDA:3,0\nend_of_record\n" +
+		"TN:unit\nSF:/src/a.go\nDA:2,5\nend_of_record\n"
+	summary, err := Summarize(strings.NewReader(tracefile), WithDetails())
+	require.NoError(t, err)
+
+	assert.Equal(t, []TestCoverage{
+		{Name: "", CoveredLines: 1, Files: []TestFileCoverage{{Path: "/src/a.go", CoveredLines: 1}}},
+		{Name: "e2e", CoveredLines: 1, Files: []TestFileCoverage{{Path: "/src/a.go", CoveredLines: 1}}},
+		{Name: "unit", CoveredLines: 3, UniqueLines: 2, Files: []TestFileCoverage{
+			{Path: "/src/a.go", CoveredLines: 2, UniqueLines: 1},
+			{Path: "/src/b.go", CoveredLines: 1, UniqueLines: 1},
+		}},
+	}, CoverageByTest(summary.Files))
+
+	assert.Empty(t, CoverageByTest(nil))
+}
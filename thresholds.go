@@ -0,0 +1,158 @@
+package lcov
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Thresholds are the minimum coverage rates, in percent, required from a summary.
+// A zero rate means no requirement for that metric.
+type Thresholds struct {
+	Lines     float64
+	Functions float64
+	Branches  float64
+}
+
+// ThresholdViolation describes a metric of a summary below its required coverage
+type ThresholdViolation struct {
+	Metric   string
+	Rate     float64
+	Required float64
+}
+
+// String formats the violation for display
+func (v ThresholdViolation) String() string {
+	return fmt.Sprintf("%s coverage %.1f%% is below the required %.1f%%", v.Metric, v.Rate, v.Required)
+}
+
+// CheckThresholds returns the metrics of a summary below their required coverage.
+// Metrics without any data are not checked.
+func CheckThresholds(s *Summary, t Thresholds) []ThresholdViolation {
+	metrics := []struct {
+		name     string
+		hit      int64
+		found    int64
+		required float64
+	}{
+		{string(MetricLines), s.CoveredLines, s.TotalLines, t.Lines},
+		{string(MetricFunctions), s.CoveredFunctions, s.TotalFunctions, t.Functions},
+		{string(MetricBranches), s.CoveredBranches, s.TotalBranches, t.Branches},
+	}
+
+	var violations []ThresholdViolation
+	for _, m := range metrics {
+		if m.required <= 0 {
+			continue
+		}
+		if r, ok := rate(m.hit, m.found); ok && r < m.required {
+			violations = append(violations, ThresholdViolation{Metric: m.name, Rate: r, Required: m.required})
+		}
+	}
+	return violations
+}
+
+// FileViolation describes a source file below its required line coverage
+type FileViolation struct {
+	File string
+	ThresholdViolation
+}
+
+// String formats the violation for display
+func (v FileViolation) String() string {
+	return v.File + ": " + v.ThresholdViolation.String()
+}
+
+// CheckFileThresholds returns the source files of detailed file records (see
+// WithDetails) whose line coverage is below required, sorted by path, so that
+// a new untested file can't hide behind the totals of well tested ones. The
+// blocks of the same file are merged first, and files without line data are
+// not checked.
+func CheckFileThresholds(files []FileRecord, required float64) []FileViolation {
+	if required <= 0 {
+		return nil
+	}
+	var violations []FileViolation
+	for _, f := range MergeFiles(files) {
+		if r, ok := rate(f.LinesHit, f.LinesFound); ok && r < required {
+			violations = append(violations, FileViolation{
+				File:               f.Path,
+				ThresholdViolation: ThresholdViolation{Metric: string(MetricLines), Rate: r, Required: required},
+			})
+		}
+	}
+	return violations
+}
+
+// PathThreshold requires the source files matching a glob pattern, taken
+// together, to reach a minimum coverage rate, e.g. 'pkg/payments/**' at 90%.
+// The rate applies to every metric, like a combined threshold.
+type PathThreshold struct {
+	Pattern string
+	Rate    float64
+}
+
+// PathViolation describes the files of a path threshold below its required coverage
+type PathViolation struct {
+	Pattern string
+	ThresholdViolation
+}
+
+// String formats the violation for display
+func (v PathViolation) String() string {
+	return v.Pattern + ": " + v.ThresholdViolation.String()
+}
+
+// ParsePathThresholds parses a thresholds file: one 'pattern: percentage' rule
+// per line, such as 'pkg/experimental/**: 40'. Blank lines and '#' comments
+// are ignored.
+func ParsePathThresholds(r io.Reader) ([]PathThreshold, error) {
+	var thresholds []PathThreshold
+	scanner := bufio.NewScanner(r)
+	for n := 1; scanner.Scan(); n++ {
+		line, _, _ := strings.Cut(scanner.Text(), "#")
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		pattern, percentage, ok := strings.Cut(line, ":")
+		pattern, percentage = strings.TrimSpace(pattern), strings.TrimSpace(percentage)
+		if !ok || pattern == "" {
+			return nil, fmt.Errorf("line %d: expected 'pattern: percentage'", n)
+		}
+		rate, err := strconv.ParseFloat(percentage, 64)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid percentage: %s", n, percentage)
+		}
+		thresholds = append(thresholds, PathThreshold{Pattern: pattern, Rate: rate})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error reading thresholds: %w", err)
+	}
+	return thresholds, nil
+}
+
+// CheckPathThresholds evaluates every path threshold against the rollup of the
+// files matching its pattern, in the order of the thresholds. A pattern also
+// matches the files below a directory, so 'pkg/payments' is the same as
+// 'pkg/payments/**'. Thresholds without any matching file are not checked.
+func CheckPathThresholds(files []FileRecord, thresholds []PathThreshold) []PathViolation {
+	var violations []PathViolation
+	for _, threshold := range thresholds {
+		var matching []FileRecord
+		for i := range files {
+			if matchGlob(threshold.Pattern, files[i].Path) || matchGlob(threshold.Pattern+"/**", files[i].Path) {
+				matching = append(matching, files[i])
+			}
+		}
+		if len(matching) == 0 {
+			continue
+		}
+		rollup := SummarizeFiles(MergeFiles(matching))
+		for _, violation := range CheckThresholds(rollup, Thresholds{Lines: threshold.Rate, Functions: threshold.Rate, Branches: threshold.Rate}) {
+			violations = append(violations, PathViolation{Pattern: threshold.Pattern, ThresholdViolation: violation})
+		}
+	}
+	return violations
+}
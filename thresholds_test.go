@@ -0,0 +1,71 @@
+package lcov
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckThresholds(t *testing.T) {
+	summary := &Summary{
+		TotalLines: 10, CoveredLines: 7,
+		TotalFunctions: 4, CoveredFunctions: 3,
+	}
+
+	assert.Empty(t, CheckThresholds(summary, Thresholds{}))
+	assert.Empty(t, CheckThresholds(summary, Thresholds{Lines: 70, Functions: 75}))
+	// No branch data: the branch threshold doesn't apply
+	assert.Empty(t, CheckThresholds(summary, Thresholds{Branches: 100}))
+
+	violations := CheckThresholds(summary, Thresholds{Lines: 80, Functions: 50})
+	assert.Equal(t, []ThresholdViolation{{Metric: "line", Rate: 70, Required: 80}}, violations)
+	assert.Equal(t, "line coverage 70.0% is below the required 80.0%", violations[0].String())
+}
+
+func TestCheckFileThresholds(t *testing.T) {
+	files := []FileRecord{
+		{Path: "b.go", LinesFound: 2, LinesHit: 1, Lines: []LineData{{Line: 1, Count: 1}, {Line: 2, Count: 0}}},
+		{Path: "a.go", LinesFound: 1, LinesHit: 0, Lines: []LineData{{Line: 1, Count: 0}}},
+		// The blocks of b.go are merged: line 4 is the only one uncovered
+		{Path: "b.go", LinesFound: 3, LinesHit: 2, Lines: []LineData{{Line: 2, Count: 1}, {Line: 3, Count: 1}, {Line: 4, Count: 0}}},
+		{Path: "doc.go"},
+	}
+	assert.Empty(t, CheckFileThresholds(files, 0))
+
+	violations := CheckFileThresholds(files, 80)
+	assert.Equal(t, []FileViolation{
+		{File: "a.go", ThresholdViolation: ThresholdViolation{Metric: "line", Rate: 0, Required: 80}},
+		{File: "b.go", ThresholdViolation: ThresholdViolation{Metric: "line", Rate: 75, Required: 80}},
+	}, violations)
+	assert.Equal(t, "a.go: line coverage 0.0% is below the required 80.0%", violations[0].String())
+}
+
+func TestParsePathThresholds(t *testing.T) {
+	thresholds, err := ParsePathThresholds(strings.NewReader("# Critical code\npkg/payments/**: 90\n\npkg/experimental: 40 # for now\n"))
+	require.NoError(t, err)
+	assert.Equal(t, []PathThreshold{{Pattern: "pkg/payments/**", Rate: 90}, {Pattern: "pkg/experimental", Rate: 40}}, thresholds)
+
+	_, err = ParsePathThresholds(strings.NewReader("pkg/payments 90\n"))
+	assert.EqualError(t, err, "line 1: expected 'pattern: percentage'")
+	_, err = ParsePathThresholds(strings.NewReader("\npkg/payments: high\n"))
+	assert.EqualError(t, err, "line 2: invalid percentage: high")
+}
+
+func TestCheckPathThresholds(t *testing.T) {
+	files := []FileRecord{
+		{Path: "/src/repo/pkg/payments/card.go", LinesFound: 2, LinesHit: 2, Lines: []LineData{{Line: 1, Count: 1}, {Line: 2, Count: 1}}},
+		{Path: "/src/repo/pkg/payments/bank/iban.go", LinesFound: 2, LinesHit: 0, Lines: []LineData{{Line: 1, Count: 0}, {Line: 2, Count: 0}}},
+		{Path: "/src/repo/pkg/experimental/new.go", LinesFound: 4, LinesHit: 2, Lines: []LineData{{Line: 1, Count: 1}, {Line: 2, Count: 1}, {Line: 3, Count: 0}, {Line: 4, Count: 0}}},
+	}
+	violations := CheckPathThresholds(files, []PathThreshold{
+		{Pattern: "pkg/payments/**", Rate: 90},
+		{Pattern: "pkg/experimental", Rate: 40},
+		{Pattern: "pkg/legacy/**", Rate: 100},
+	})
+	assert.Equal(t, []PathViolation{
+		{Pattern: "pkg/payments/**", ThresholdViolation: ThresholdViolation{Metric: "line", Rate: 50, Required: 90}},
+	}, violations)
+	assert.Equal(t, "pkg/payments/**: line coverage 50.0% is below the required 90.0%", violations[0].String())
+}
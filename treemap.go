@@ -0,0 +1,236 @@
+package lcov
+
+import (
+	"fmt"
+	"html"
+	"io"
+	"sort"
+	"strings"
+)
+
+func init() {
+	RegisterRenderer("treemap", optionsRenderer(RenderTreemap))
+}
+
+// Dimensions of the treemap, in pixels
+const (
+	treemapWidth  = 960
+	treemapHeight = 600
+	// treemapHeader is the height of the directory name above its files
+	treemapHeader  = 16
+	treemapPadding = 2
+)
+
+// RenderTreemap writes an SVG treemap of the source files, grouped by
+// directory, where the area of every file is proportional to its instrumented
+// lines and its color follows its line coverage rate: red, yellow or green
+// according to the color thresholds, DefaultColorThresholds unless configured
+// WithColor. Hovering a rectangle shows its path and rate. The summary must have
+// been parsed WithDetails.
+func RenderTreemap(w io.Writer, s *Summary, opts ...RenderOption) error {
+	cfg := newRenderConfig(opts)
+	thresholds := DefaultColorThresholds
+	if cfg.colors != nil {
+		thresholds = *cfg.colors
+	}
+
+	root := newTreemapTree(MergeFiles(s.Files))
+	out := errWriter{w: w}
+	out.printf(`<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %[1]d %[2]d" role="img" aria-label="Line coverage treemap">`+"\n",
+		treemapWidth, treemapHeight)
+	out.printf(`<g font-family="Verdana,Geneva,DejaVu Sans,sans-serif" font-size="11" stroke="#fff">` + "\n")
+	if root.found == 0 {
+		out.printf(`<text x="%d" y="%d" text-anchor="middle" stroke="none" fill="#555">No line coverage data</text>`+"\n",
+			treemapWidth/2, treemapHeight/2)
+	} else {
+		t := &treemap{out: &out, cfg: cfg, thresholds: thresholds}
+		t.renderChildren(root, treemapRect{w: treemapWidth, h: treemapHeight}, root.name)
+	}
+	out.printf("</g>\n</svg>\n")
+	return out.err
+}
+
+// treemapNode is a directory, with children, or a source file
+type treemapNode struct {
+	// name is the path relative to the parent, which has several components
+	// when directories with a single subdirectory are collapsed. The name of
+	// the root is the directory shared by every file, with a trailing slash.
+	name       string
+	found, hit int64
+	children   []*treemapNode
+}
+
+// newTreemapTree returns the directory tree of the files with instrumented lines,
+// the children of every directory sorted by decreasing size
+func newTreemapTree(files []FileRecord) *treemapNode {
+	root := &treemapNode{}
+	index := map[string]*treemapNode{"": root}
+	for _, f := range files {
+		if f.LinesFound == 0 {
+			continue
+		}
+		parent, dir := root, ""
+		components := strings.Split(f.Path, "/")
+		for i, name := range components {
+			dir += "/" + name
+			node, ok := index[dir]
+			if !ok || i == len(components)-1 {
+				node = &treemapNode{name: name}
+				index[dir] = node
+				parent.children = append(parent.children, node)
+			}
+			node.found += f.LinesFound
+			node.hit += f.LinesHit
+			parent = node
+		}
+		root.found += f.LinesFound
+		root.hit += f.LinesHit
+	}
+	root.collapse()
+
+	// Leading directories shared by every file only take room
+	for len(root.children) == 1 && len(root.children[0].children) > 0 {
+		root.name += root.children[0].name + "/"
+		root.children = root.children[0].children
+	}
+	return root
+}
+
+// collapse merges directories having a single subdirectory and no files with
+// that subdirectory, and sorts the children
+func (n *treemapNode) collapse() {
+	for _, child := range n.children {
+		for len(child.children) == 1 && len(child.children[0].children) > 0 {
+			child.name += "/" + child.children[0].name
+			child.children = child.children[0].children
+		}
+		child.collapse()
+	}
+	sort.SliceStable(n.children, func(i, j int) bool {
+		if n.children[i].found != n.children[j].found {
+			return n.children[i].found > n.children[j].found
+		}
+		return n.children[i].name < n.children[j].name
+	})
+}
+
+type treemapRect struct {
+	x, y, w, h float64
+}
+
+type treemap struct {
+	out        *errWriter
+	cfg        *renderConfig
+	thresholds ColorThresholds
+}
+
+// render draws a node in r, path being its full path
+func (t *treemap) render(n *treemapNode, r treemapRect, path string) {
+	lineRate, _ := rate(n.hit, n.found)
+	title := html.EscapeString(fmt.Sprintf("%s: %s (%d of %d lines)", path, t.rateText(lineRate), n.hit, n.found))
+
+	if len(n.children) == 0 {
+		t.out.printf(`<g><title>%s</title><rect x="%.1f" y="%.1f" width="%.1f" height="%.1f" fill="%s"/>`,
+			title, r.x, r.y, r.w, r.h, badgeColor(lineRate, t.thresholds))
+		if label := html.EscapeString(n.name); treemapLabelFits(n.name, r) {
+			t.out.printf(`<text x="%.1f" y="%.1f" stroke="none" fill="#fff">%s</text>`, r.x+4, r.y+13, label)
+		}
+		t.out.printf("</g>\n")
+		return
+	}
+
+	// Directories are framed, with their name on top when there is room
+	t.out.printf(`<g><title>%s</title><rect x="%.1f" y="%.1f" width="%.1f" height="%.1f" fill="#555"/>`,
+		title, r.x, r.y, r.w, r.h)
+	inner := treemapRect{x: r.x + treemapPadding, y: r.y + treemapPadding, w: r.w - 2*treemapPadding, h: r.h - 2*treemapPadding}
+	if r.h > 3*treemapHeader && treemapLabelFits(n.name+"/", r) {
+		t.out.printf(`<text x="%.1f" y="%.1f" stroke="none" fill="#fff">%s/</text>`, r.x+4, r.y+12, html.EscapeString(n.name))
+		inner.y += treemapHeader - treemapPadding
+		inner.h -= treemapHeader - treemapPadding
+	}
+	t.out.printf("</g>\n")
+	if inner.w > 0 && inner.h > 0 {
+		t.renderChildren(n, inner, path+"/")
+	}
+}
+
+// renderChildren lays out the children of a directory in r, prefix being the
+// path of the directory with a trailing slash
+func (t *treemap) renderChildren(n *treemapNode, r treemapRect, prefix string) {
+	sizes := make([]float64, len(n.children))
+	for i, child := range n.children {
+		sizes[i] = float64(child.found)
+	}
+	for i, rect := range squarify(sizes, r) {
+		t.render(n.children[i], rect, prefix+n.children[i].name)
+	}
+}
+
+// rateText formats a rate like the other renderers
+func (t *treemap) rateText(rate float64) string {
+	return fmt.Sprintf("%.*f%%", t.cfg.precision, rate)
+}
+
+// treemapLabelFits approximates whether a label fits in a rectangle, with the
+// average width of 11px Verdana characters
+func treemapLabelFits(label string, r treemapRect) bool {
+	return r.h >= treemapHeader && float64(len([]rune(label))*7+8) <= r.w
+}
+
+// squarify lays out rectangles of areas proportional to the sizes, sorted in
+// decreasing order, in r with the squarified algorithm of Bruls, Huizing and
+// van Wijk: rows are filled along the shorter side of the remaining space while
+// that improves the worst aspect ratio of their rectangles.
+func squarify(sizes []float64, r treemapRect) []treemapRect {
+	rects := make([]treemapRect, 0, len(sizes))
+	var total float64
+	for _, size := range sizes {
+		total += size
+	}
+	if total <= 0 {
+		return append(rects, make([]treemapRect, len(sizes))...)
+	}
+	scale := r.w * r.h / total
+
+	for len(sizes) > 0 {
+		short := min(r.w, r.h)
+		n := 1
+		for n < len(sizes) && worstRatio(sizes[:n+1], short, scale) <= worstRatio(sizes[:n], short, scale) {
+			n++
+		}
+
+		var rowArea float64
+		for _, size := range sizes[:n] {
+			rowArea += size * scale
+		}
+		thickness := rowArea / short
+		offset := 0.0
+		for _, size := range sizes[:n] {
+			length := size * scale / thickness
+			if r.w >= r.h {
+				rects = append(rects, treemapRect{x: r.x, y: r.y + offset, w: thickness, h: length})
+			} else {
+				rects = append(rects, treemapRect{x: r.x + offset, y: r.y, w: length, h: thickness})
+			}
+			offset += length
+		}
+		if r.w >= r.h {
+			r.x, r.w = r.x+thickness, r.w-thickness
+		} else {
+			r.y, r.h = r.y+thickness, r.h-thickness
+		}
+		sizes = sizes[n:]
+	}
+	return rects
+}
+
+// worstRatio returns the largest aspect ratio of the rectangles of a row laid
+// along a side of length short
+func worstRatio(row []float64, short, scale float64) float64 {
+	var area float64
+	for _, size := range row {
+		area += size * scale
+	}
+	largest, smallest := row[0]*scale, row[len(row)-1]*scale
+	return max(short*short*largest/(area*area), area*area/(short*short*smallest))
+}
@@ -0,0 +1,102 @@
+package lcov
+
+import (
+	"bytes"
+	"encoding/xml"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSquarify(t *testing.T) {
+	// The example of the paper
+	sizes := []float64{6, 6, 4, 3, 2, 2, 1}
+	rects := squarify(sizes, treemapRect{w: 6, h: 4})
+	require.Len(t, rects, len(sizes))
+	for i, r := range rects {
+		assert.InDelta(t, sizes[i], r.w*r.h, 1e-9, "area of %d", i)
+		assert.GreaterOrEqual(t, r.x, 0.0)
+		assert.GreaterOrEqual(t, r.y, 0.0)
+		assert.LessOrEqual(t, r.x+r.w, 6+1e-9)
+		assert.LessOrEqual(t, r.y+r.h, 4+1e-9)
+	}
+	assert.Equal(t, treemapRect{w: 3, h: 2}, rects[0])
+	assert.Equal(t, treemapRect{y: 2, w: 3, h: 2}, rects[1])
+	// 4 and 3 share a row on top of 2, 2 and 1
+	assert.InDelta(t, 7.0/3, rects[2].h, 1e-9)
+	assert.InDelta(t, 7.0/3, rects[3].h, 1e-9)
+	assert.InDelta(t, 5.0/3, rects[6].h, 1e-9)
+
+	assert.Equal(t, []treemapRect{{}, {}}, squarify([]float64{0, 0}, treemapRect{w: 10, h: 10}))
+	assert.Empty(t, squarify(nil, treemapRect{w: 10, h: 10}))
+}
+
+func TestNewTreemapTree(t *testing.T) {
+	root := newTreemapTree([]FileRecord{
+		{Path: "/src/project/cmd/main.go", LinesFound: 10, LinesHit: 5},
+		{Path: "/src/project/internal/parse/lexer.go", LinesFound: 40, LinesHit: 40},
+		{Path: "/src/project/internal/parse/parser.go", LinesFound: 30, LinesHit: 10},
+		{Path: "/src/project/empty.go"},
+	})
+	assert.Equal(t, "/src/project/", root.name)
+	assert.Equal(t, int64(80), root.found)
+	assert.Equal(t, int64(55), root.hit)
+	require.Len(t, root.children, 2)
+
+	internal := root.children[0]
+	assert.Equal(t, "internal/parse", internal.name)
+	assert.Equal(t, int64(70), internal.found)
+	require.Len(t, internal.children, 2)
+	assert.Equal(t, "lexer.go", internal.children[0].name)
+	assert.Equal(t, "parser.go", internal.children[1].name)
+	assert.Equal(t, "cmd", root.children[1].name)
+}
+
+func TestRenderTreemap(t *testing.T) {
+	summary := &Summary{Files: []FileRecord{
+		{Path: "pkg/a.go", LinesFound: 100, LinesHit: 95},
+		{Path: "pkg/b.go", LinesFound: 50, LinesHit: 40},
+		{Path: "main<1>.go", LinesFound: 50, LinesHit: 10},
+	}}
+
+	var out bytes.Buffer
+	require.NoError(t, RenderFormat("treemap", &out, summary))
+
+	var svg struct {
+		Width  int `xml:"width,attr"`
+		Groups []struct {
+			Title string `xml:"title"`
+			Rect  struct {
+				Width  float64 `xml:"width,attr"`
+				Height float64 `xml:"height,attr"`
+				Fill   string  `xml:"fill,attr"`
+			} `xml:"rect"`
+		} `xml:"g>g"`
+	}
+	require.NoError(t, xml.Unmarshal(out.Bytes(), &svg))
+	assert.Equal(t, treemapWidth, svg.Width)
+
+	fills := make(map[string]string)
+	areas := make(map[string]float64)
+	for _, g := range svg.Groups {
+		fills[g.Title] = g.Rect.Fill
+		areas[g.Title] = g.Rect.Width * g.Rect.Height
+	}
+	assert.Equal(t, badgeGreen, fills["pkg/a.go: 95.0% (95 of 100 lines)"])
+	assert.Equal(t, badgeYellow, fills["pkg/b.go: 80.0% (40 of 50 lines)"])
+	assert.Equal(t, badgeRed, fills["main<1>.go: 20.0% (10 of 50 lines)"])
+	assert.Contains(t, fills, "pkg: 90.0% (135 of 150 lines)")
+	// Rounding to tenths of pixels
+	total := float64(treemapWidth * treemapHeight)
+	assert.InDelta(t, total/4, areas["main<1>.go: 20.0% (10 of 50 lines)"], total/1000)
+	assert.InDelta(t, total*3/4, areas["pkg: 90.0% (135 of 150 lines)"], total/1000)
+	assert.False(t, math.IsNaN(areas["pkg/a.go: 95.0% (95 of 100 lines)"]))
+}
+
+func TestRenderTreemapEmpty(t *testing.T) {
+	var out bytes.Buffer
+	require.NoError(t, RenderTreemap(&out, &Summary{TotalLines: 10}))
+	assert.Contains(t, out.String(), "No line coverage data")
+}
@@ -0,0 +1,84 @@
+package lcov
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// UnknownRecordPolicy defines how records of a type the parser doesn't know are
+// handled, such as those added by newer lcov versions
+type UnknownRecordPolicy int
+
+const (
+	// UnknownRecordsIgnore skips unknown records (default)
+	UnknownRecordsIgnore UnknownRecordPolicy = iota
+	// UnknownRecordsWarn skips unknown records and reports them in Summary.Warnings
+	UnknownRecordsWarn
+	// UnknownRecordsError makes parsing fail with ErrUnknownRecord
+	UnknownRecordsError
+)
+
+// String returns the name of the policy
+func (u UnknownRecordPolicy) String() string {
+	switch u {
+	case UnknownRecordsIgnore:
+		return "ignore"
+	case UnknownRecordsWarn:
+		return "warn"
+	case UnknownRecordsError:
+		return "error"
+	}
+	return fmt.Sprintf("UnknownRecordPolicy(%d)", int(u))
+}
+
+// ParseUnknownRecordPolicy returns the policy matching a name as returned by String
+func ParseUnknownRecordPolicy(name string) (UnknownRecordPolicy, error) {
+	for _, u := range []UnknownRecordPolicy{UnknownRecordsIgnore, UnknownRecordsWarn, UnknownRecordsError} {
+		if u.String() == name {
+			return u, nil
+		}
+	}
+	return UnknownRecordsIgnore, fmt.Errorf("unknown record policy: %s", name)
+}
+
+// ErrUnknownRecord is returned by parsers configured with UnknownRecordsError
+// when a record of unknown type is found
+var ErrUnknownRecord = errors.New("unknown record type")
+
+// WithUnknownRecords configures how records of unknown type are handled.
+// Whatever the policy, the unknown types are listed in Summary.UnknownRecords.
+func WithUnknownRecords(policy UnknownRecordPolicy) Option {
+	return func(p *Parser) {
+		p.unknownPolicy = policy
+	}
+}
+
+// unknownRecord accounts for a record of unknown type, failing if configured
+func (p *Parser) unknownRecord(recordType RecordType) error {
+	if p.unknownPolicy == UnknownRecordsError {
+		return fmt.Errorf("%w: %s", ErrUnknownRecord, recordType)
+	}
+	if p.unknown == nil {
+		p.unknown = make(map[RecordType]int)
+	}
+	p.unknown[recordType]++
+	p.logDebug("record of unknown type skipped", "type", string(recordType))
+	return nil
+}
+
+// unknownRecords returns the unknown record types found, sorted, and warns about
+// them if configured
+func (p *Parser) unknownRecords() []string {
+	var types []string
+	for recordType := range p.unknown {
+		types = append(types, string(recordType))
+	}
+	sort.Strings(types)
+	if p.unknownPolicy == UnknownRecordsWarn {
+		for _, recordType := range types {
+			p.warn("", "%d records of unknown type %s ignored", p.unknown[RecordType(recordType)], recordType)
+		}
+	}
+	return types
+}
@@ -0,0 +1,59 @@
+package lcov
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const unknownRecordsTracefile = `VER:2
+SF:/src/main.go
+FNL:0,1,3
+FNA:0,1,main
+DA:1,1
+LF:1
+LH:1
+end_of_record
+SF:/src/util.go
+FNL:0,1,2
+DA:1,0
+LF:1
+LH:0
+end_of_record
+`
+
+func TestUnknownRecords(t *testing.T) {
+	summary, err := Summarize(strings.NewReader(unknownRecordsTracefile))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"FNA", "FNL", "VER"}, summary.UnknownRecords)
+	assert.Empty(t, summary.Warnings)
+	assert.Equal(t, 2, summary.TotalFiles)
+
+	summary, err = Summarize(strings.NewReader(unknownRecordsTracefile), WithUnknownRecords(UnknownRecordsWarn))
+	require.NoError(t, err)
+	assert.Equal(t, []Warning{
+		{Message: "1 records of unknown type FNA ignored"},
+		{Message: "2 records of unknown type FNL ignored"},
+		{Message: "1 records of unknown type VER ignored"},
+	}, summary.Warnings)
+
+	_, err = Summarize(strings.NewReader(unknownRecordsTracefile), WithUnknownRecords(UnknownRecordsError))
+	assert.ErrorIs(t, err, ErrUnknownRecord)
+	assert.EqualError(t, err, "failed to parse line 'VER:2': unknown record type: VER")
+
+	summary, err = Summarize(strings.NewReader("SF:/src/main.go\nDA:1,1\nend_of_record\n"), WithUnknownRecords(UnknownRecordsError))
+	require.NoError(t, err)
+	assert.Nil(t, summary.UnknownRecords)
+}
+
+func TestParseUnknownRecordPolicy(t *testing.T) {
+	for _, policy := range []UnknownRecordPolicy{UnknownRecordsIgnore, UnknownRecordsWarn, UnknownRecordsError} {
+		parsed, err := ParseUnknownRecordPolicy(policy.String())
+		require.NoError(t, err)
+		assert.Equal(t, policy, parsed)
+	}
+	_, err := ParseUnknownRecordPolicy("fail")
+	assert.EqualError(t, err, "unknown record policy: fail")
+}
@@ -0,0 +1,112 @@
+// Package lcov is the stable, versioned API of go-lcov-summary.
+//
+// Everything exported by this package is covered by a compatibility guarantee:
+// identifiers will not be removed or renamed, function signatures will not change
+// and Summary fields will keep their meaning. New options and fields may be added.
+//
+// Experimental APIs, such as detailed per-file records, diff based reports, merging
+// and renderers, live in the root package github.com/shastick/go-lcov-summary and
+// may change between releases.
+package lcov
+
+import (
+	"io"
+
+	core "github.com/shastick/go-lcov-summary"
+)
+
+// Summary represents the overall coverage summary. Line, function and branch
+// counters are 64-bit so that merged reports of very large code bases don't overflow.
+type Summary struct {
+	TotalFiles           int
+	TotalLines           int64
+	CoveredLines         int64
+	LineCoverageRate     float64
+	TotalFunctions       int64
+	CoveredFunctions     int64
+	FunctionCoverageRate float64
+	TotalBranches        int64
+	CoveredBranches      int64
+	BranchCoverageRate   float64
+
+	// Warnings lists the non-fatal issues encountered while parsing
+	Warnings []string
+}
+
+// DuplicateStrategy defines how SF blocks appearing several times for the same
+// source file are handled.
+type DuplicateStrategy int
+
+const (
+	// DuplicatesKeep counts every block as a distinct file (default)
+	DuplicatesKeep DuplicateStrategy = iota
+	// DuplicatesMerge merges the blocks of a file, summing execution counts
+	DuplicatesMerge
+	// DuplicatesFirst keeps the first block of each file and drops the others
+	DuplicatesFirst
+)
+
+// Option configures optional Parser behavior
+type Option struct {
+	apply core.Option
+}
+
+// WithDuplicateStrategy configures how repeated SF blocks for the same file are handled
+func WithDuplicateStrategy(strategy DuplicateStrategy) Option {
+	var s core.DuplicateStrategy
+	switch strategy {
+	case DuplicatesMerge:
+		s = core.DuplicatesMerge
+	case DuplicatesFirst:
+		s = core.DuplicatesFirst
+	default:
+		s = core.DuplicatesKeep
+	}
+	return Option{apply: core.WithDuplicateStrategy(s)}
+}
+
+// Summarize processes LCOV data from an io.Reader and returns summary information
+func Summarize(reader io.Reader, opts ...Option) (*Summary, error) {
+	return NewParser(reader, opts...).Parse()
+}
+
+// Parser represents an LCOV file parser
+type Parser struct {
+	parser *core.Parser
+}
+
+// NewParser creates a new LCOV parser
+func NewParser(reader io.Reader, opts ...Option) *Parser {
+	coreOpts := make([]core.Option, 0, len(opts))
+	for _, opt := range opts {
+		if opt.apply != nil {
+			coreOpts = append(coreOpts, opt.apply)
+		}
+	}
+	return &Parser{parser: core.NewParser(reader, coreOpts...)}
+}
+
+// Parse reads and parses the entire LCOV file
+func (p *Parser) Parse() (*Summary, error) {
+	s, err := p.parser.Parse()
+	if err != nil {
+		return nil, err
+	}
+
+	summary := &Summary{
+		TotalFiles:           s.TotalFiles,
+		TotalLines:           s.TotalLines,
+		CoveredLines:         s.CoveredLines,
+		LineCoverageRate:     s.LineCoverageRate,
+		TotalFunctions:       s.TotalFunctions,
+		CoveredFunctions:     s.CoveredFunctions,
+		FunctionCoverageRate: s.FunctionCoverageRate,
+		TotalBranches:        s.TotalBranches,
+		CoveredBranches:      s.CoveredBranches,
+		BranchCoverageRate:   s.BranchCoverageRate,
+	}
+	for _, w := range s.Warnings {
+		summary.Warnings = append(summary.Warnings, w.String())
+	}
+	return summary, nil
+}
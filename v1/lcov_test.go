@@ -0,0 +1,56 @@
+package lcov
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSummarize(t *testing.T) {
+	file, err := os.Open("../testdata/with_functions_and_branches.lcov")
+	require.NoError(t, err)
+	defer file.Close()
+
+	summary, err := Summarize(file)
+	require.NoError(t, err)
+	assert.Equal(t, &Summary{
+		TotalFiles:           2,
+		TotalLines:           10,
+		CoveredLines:         7,
+		LineCoverageRate:     70.0,
+		TotalFunctions:       4,
+		CoveredFunctions:     3,
+		FunctionCoverageRate: 75.0,
+		TotalBranches:        2,
+		CoveredBranches:      2,
+		BranchCoverageRate:   100.0,
+	}, summary)
+}
+
+func TestSummarizeWithDuplicateStrategy(t *testing.T) {
+	file, err := os.Open("../testdata/concatenated.lcov")
+	require.NoError(t, err)
+	defer file.Close()
+
+	summary, err := Summarize(file, WithDuplicateStrategy(DuplicatesMerge))
+	require.NoError(t, err)
+	assert.Equal(t, 2, summary.TotalFiles)
+	assert.Equal(t, int64(6), summary.TotalLines)
+	assert.Equal(t, []string{"1 duplicate source file blocks handled with the 'merge' strategy"}, summary.Warnings)
+}
+
+func TestSummarizeError(t *testing.T) {
+	summary, err := Summarize(strings.NewReader("DA:1,5\nend_of_record"))
+	assert.EqualError(t, err, "line data without source file")
+	assert.Nil(t, summary)
+}
+
+func TestSummarizeLargeCounters(t *testing.T) {
+	summary, err := Summarize(strings.NewReader("SF:a.go\nLF:5000000000\nLH:4000000000\nend_of_record\n"))
+	require.NoError(t, err)
+	assert.Equal(t, int64(5000000000), summary.TotalLines)
+	assert.Equal(t, int64(4000000000), summary.CoveredLines)
+}
@@ -0,0 +1,82 @@
+package lcov
+
+import (
+	"runtime/debug"
+	"strings"
+)
+
+// modulePath is the path of this module, used to find its version in the build info
+const modulePath = "github.com/shastick/go-lcov-summary"
+
+// buildDate is the date the binary was built, when set at link time with
+// -ldflags "-X github.com/shastick/go-lcov-summary.buildDate=..."
+var buildDate string
+
+// Version describes the build of the library: its module version, followed when
+// known by the VCS revision and the build date, e.g.
+// 'v1.4.0 (revision 3f2a9c1d0b7e, built 2024-05-02T10:11:12Z)'. The revision is only
+// known when the library is the main module of the binary, e.g. the CLI built from
+// a clone, and the date falls back to the time of that revision.
+func Version() string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return "(unknown)"
+	}
+	return formatVersion(info, buildDate)
+}
+
+// formatVersion formats the version of this module found in the build info
+func formatVersion(info *debug.BuildInfo, date string) string {
+	version := "(devel)"
+	var details []string
+	if info.Main.Path == modulePath {
+		if info.Main.Version != "" {
+			version = info.Main.Version
+		}
+
+		var revision, commitTime string
+		var modified bool
+		for _, setting := range info.Settings {
+			switch setting.Key {
+			case "vcs.revision":
+				revision = setting.Value
+			case "vcs.time":
+				commitTime = setting.Value
+			case "vcs.modified":
+				modified = setting.Value == "true"
+			}
+		}
+		if revision != "" {
+			if len(revision) > 12 {
+				revision = revision[:12]
+			}
+			if modified {
+				revision += "-dirty"
+			}
+			details = append(details, "revision "+revision)
+		}
+		if date == "" {
+			date = commitTime
+		}
+	} else {
+		for _, dep := range info.Deps {
+			if dep.Path == modulePath {
+				version = dep.Version
+				if dep.Replace != nil {
+					version += " => " + dep.Replace.Path
+					if dep.Replace.Version != "" {
+						version += " " + dep.Replace.Version
+					}
+				}
+			}
+		}
+	}
+
+	if date != "" {
+		details = append(details, "built "+date)
+	}
+	if len(details) == 0 {
+		return version
+	}
+	return version + " (" + strings.Join(details, ", ") + ")"
+}
@@ -0,0 +1,34 @@
+package lcov
+
+import (
+	"runtime/debug"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormatVersion(t *testing.T) {
+	main := &debug.BuildInfo{
+		Main: debug.Module{Path: modulePath, Version: "v1.4.0"},
+		Settings: []debug.BuildSetting{
+			{Key: "vcs.revision", Value: "3f2a9c1d0b7e5a4c3b2a1f0e9d8c7b6a5f4e3d2c"},
+			{Key: "vcs.time", Value: "2024-05-02T10:11:12Z"},
+			{Key: "vcs.modified", Value: "true"},
+		},
+	}
+	assert.Equal(t, "v1.4.0 (revision 3f2a9c1d0b7e-dirty, built 2024-05-02T10:11:12Z)", formatVersion(main, ""))
+	assert.Equal(t, "v1.4.0 (revision 3f2a9c1d0b7e-dirty, built 2024-06-01)", formatVersion(main, "2024-06-01"))
+	assert.Equal(t, "(devel)", formatVersion(&debug.BuildInfo{Main: debug.Module{Path: modulePath}}, ""))
+
+	dependency := &debug.BuildInfo{
+		Main: debug.Module{Path: "example.com/app", Version: "v0.1.0"},
+		Deps: []*debug.Module{{Path: modulePath, Version: "v1.3.2"}},
+	}
+	assert.Equal(t, "v1.3.2", formatVersion(dependency, ""))
+	dependency.Deps[0].Replace = &debug.Module{Path: "../go-lcov-summary"}
+	assert.Equal(t, "v1.3.2 => ../go-lcov-summary", formatVersion(dependency, ""))
+}
+
+func TestVersion(t *testing.T) {
+	assert.NotEmpty(t, Version())
+}
@@ -0,0 +1,37 @@
+package lcov
+
+import "fmt"
+
+// Warning describes a non-fatal issue encountered while parsing
+type Warning struct {
+	// File is the source file the warning relates to, empty when it concerns the whole input
+	File    string
+	Message string
+}
+
+// String formats the warning for display
+func (w Warning) String() string {
+	if w.File == "" {
+		return w.Message
+	}
+	return fmt.Sprintf("%s: %s", w.File, w.Message)
+}
+
+// WithWarningHandler passes every warning to handler as soon as it is
+// encountered, e.g. to log it while a large input is still being parsed. The
+// warnings are still returned in Summary.Warnings.
+func WithWarningHandler(handler func(Warning)) Option {
+	return func(p *Parser) {
+		p.warningHandler = handler
+	}
+}
+
+// warn records a warning for the summary being parsed
+func (p *Parser) warn(file string, format string, args ...any) {
+	warning := Warning{File: file, Message: fmt.Sprintf(format, args...)}
+	p.warnings = append(p.warnings, warning)
+	p.logWarning(warning)
+	if p.warningHandler != nil {
+		p.warningHandler(warning)
+	}
+}
@@ -0,0 +1,58 @@
+package lcov
+
+import (
+	"bufio"
+	"io"
+	"strconv"
+)
+
+// WriteLCOV writes file records as an LCOV tracefile, in the record order used by geninfo.
+// Detail records are written when available, followed by the file's counters.
+func WriteLCOV(w io.Writer, files []FileRecord) error {
+	bw := bufio.NewWriter(w)
+
+	for i := range files {
+		f := &files[i]
+		bw.WriteString("TN:" + f.TestName + "\n")
+		bw.WriteString("SF:" + f.Path + "\n")
+
+		for _, fn := range f.Functions {
+			if fn.Line > 0 {
+				bw.WriteString("FN:" + strconv.Itoa(fn.Line) + ",")
+				if fn.EndLine > 0 {
+					bw.WriteString(strconv.Itoa(fn.EndLine) + ",")
+				}
+				bw.WriteString(fn.Name + "\n")
+			}
+		}
+		for _, fn := range f.Functions {
+			bw.WriteString("FNDA:" + strconv.FormatInt(fn.Count, 10) + "," + fn.Name + "\n")
+		}
+		if f.FunctionsFound > 0 {
+			bw.WriteString("FNF:" + strconv.FormatInt(f.FunctionsFound, 10) + "\n")
+			bw.WriteString("FNH:" + strconv.FormatInt(f.FunctionsHit, 10) + "\n")
+		}
+
+		for _, b := range f.Branches {
+			taken := "-"
+			if b.Taken >= 0 {
+				taken = strconv.FormatInt(b.Taken, 10)
+			}
+			bw.WriteString("BRDA:" + strconv.Itoa(b.Line) + "," + strconv.Itoa(b.Block) + "," +
+				strconv.Itoa(b.Branch) + "," + taken + "\n")
+		}
+		if f.BranchesFound > 0 {
+			bw.WriteString("BRF:" + strconv.FormatInt(f.BranchesFound, 10) + "\n")
+			bw.WriteString("BRH:" + strconv.FormatInt(f.BranchesHit, 10) + "\n")
+		}
+
+		for _, l := range f.Lines {
+			bw.WriteString("DA:" + strconv.Itoa(l.Line) + "," + strconv.FormatInt(l.Count, 10) + "\n")
+		}
+		bw.WriteString("LF:" + strconv.FormatInt(f.LinesFound, 10) + "\n")
+		bw.WriteString("LH:" + strconv.FormatInt(f.LinesHit, 10) + "\n")
+		bw.WriteString("end_of_record\n")
+	}
+
+	return bw.Flush()
+}
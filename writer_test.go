@@ -0,0 +1,64 @@
+package lcov
+
+import (
+	"bytes"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteLCOV(t *testing.T) {
+	files := []FileRecord{{
+		Path:           "/src/main.go",
+		TestName:       "unit",
+		LinesFound:     2,
+		LinesHit:       1,
+		FunctionsFound: 1,
+		FunctionsHit:   1,
+		BranchesFound:  2,
+		BranchesHit:    1,
+		Lines:          []LineData{{Line: 1, Count: 3}, {Line: 2, Count: 0}},
+		Functions:      []FunctionData{{Name: "main", Line: 1, Count: 3}},
+		Branches:       []BranchData{{Line: 1, Block: 0, Branch: 0, Taken: 1}, {Line: 1, Block: 0, Branch: 1, Taken: -1}},
+	}}
+
+	var out bytes.Buffer
+	require.NoError(t, WriteLCOV(&out, files))
+	assert.Equal(t, `TN:unit
+SF:/src/main.go
+FN:1,main
+FNDA:3,main
+FNF:1
+FNH:1
+BRDA:1,0,0,1
+BRDA:1,0,1,-
+BRF:2
+BRH:1
+DA:1,3
+DA:2,0
+LF:2
+LH:1
+end_of_record
+`, out.String())
+}
+
+func TestWriteLCOVRoundTrip(t *testing.T) {
+	for _, path := range []string{"testdata/with_functions_and_branches.lcov", "testdata/concatenated.lcov", "testdata/functions_v2.lcov"} {
+		t.Run(path, func(t *testing.T) {
+			file, err := os.Open(path)
+			require.NoError(t, err)
+			defer file.Close()
+
+			original, err := Summarize(file, WithDetails())
+			require.NoError(t, err)
+
+			var out bytes.Buffer
+			require.NoError(t, WriteLCOV(&out, original.Files))
+			written, err := Summarize(&out, WithDetails())
+			require.NoError(t, err)
+			assert.Equal(t, original, written)
+		})
+	}
+}
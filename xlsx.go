@@ -0,0 +1,228 @@
+package lcov
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+func init() {
+	RegisterRenderer("xlsx", optionsRenderer(RenderXLSX))
+}
+
+// RenderXLSX writes the summary as an Excel workbook with two sheets: Summary,
+// with the covered and total counts and the rate of every metric, and Files,
+// with the same for every source file. Rates are colored by conditional
+// formatting according to the color thresholds, DefaultColorThresholds unless
+// configured WithColor. The Files sheet needs a summary parsed WithDetails.
+func RenderXLSX(w io.Writer, s *Summary, opts ...RenderOption) error {
+	cfg := newRenderConfig(opts)
+	thresholds := DefaultColorThresholds
+	if cfg.colors != nil {
+		thresholds = *cfg.colors
+	}
+
+	summary := &xlsxSheet{}
+	summary.row(xlsxText("Metric"), xlsxText("Covered"), xlsxText("Total"), xlsxText("Rate"))
+	for _, m := range []struct {
+		name       string
+		hit, found int64
+	}{
+		{"Lines", s.CoveredLines, s.TotalLines},
+		{"Functions", s.CoveredFunctions, s.TotalFunctions},
+		{"Branches", s.CoveredBranches, s.TotalBranches},
+	} {
+		summary.row(xlsxText(m.name), xlsxInt(m.hit), xlsxInt(m.found), xlsxRate(m.hit, m.found))
+	}
+	summary.row()
+	summary.row(xlsxText("Source files"), xlsxInt(int64(s.TotalFiles)))
+	summary.rateColumns = []string{"D2:D4"}
+
+	files := &xlsxSheet{}
+	files.row(xlsxText("File"),
+		xlsxText("Lines covered"), xlsxText("Lines total"), xlsxText("Line rate"),
+		xlsxText("Functions covered"), xlsxText("Functions total"), xlsxText("Function rate"),
+		xlsxText("Branches covered"), xlsxText("Branches total"), xlsxText("Branch rate"))
+	for _, f := range MergeFiles(s.Files) {
+		files.row(xlsxText(f.Path),
+			xlsxInt(f.LinesHit), xlsxInt(f.LinesFound), xlsxRate(f.LinesHit, f.LinesFound),
+			xlsxInt(f.FunctionsHit), xlsxInt(f.FunctionsFound), xlsxRate(f.FunctionsHit, f.FunctionsFound),
+			xlsxInt(f.BranchesHit), xlsxInt(f.BranchesFound), xlsxRate(f.BranchesHit, f.BranchesFound))
+	}
+	last := strconv.Itoa(max(len(files.rows), 2))
+	files.rateColumns = []string{"D2:D" + last, "G2:G" + last, "J2:J" + last}
+	files.freezeHeader = true
+
+	parts := []struct{ name, content string }{
+		{"[Content_Types].xml", xlsxContentTypes},
+		{"_rels/.rels", xlsxRootRels},
+		{"xl/workbook.xml", xlsxWorkbook},
+		{"xl/_rels/workbook.xml.rels", xlsxWorkbookRels},
+		{"xl/styles.xml", xlsxStyles},
+		{"xl/worksheets/sheet1.xml", summary.xml(thresholds)},
+		{"xl/worksheets/sheet2.xml", files.xml(thresholds)},
+	}
+	zw := zip.NewWriter(w)
+	for _, part := range parts {
+		// A fixed modification time keeps the output reproducible
+		pw, err := zw.CreateHeader(&zip.FileHeader{
+			Name:     part.name,
+			Method:   zip.Deflate,
+			Modified: time.Date(1980, 1, 1, 0, 0, 0, 0, time.UTC),
+		})
+		if err != nil {
+			return err
+		}
+		if _, err := io.WriteString(pw, part.content); err != nil {
+			return err
+		}
+	}
+	return zw.Close()
+}
+
+// xlsxCell is the XML of a cell, without its reference
+type xlsxCell string
+
+// Style indexes of xlsxStyles
+const (
+	xlsxStyleHeader  = 1
+	xlsxStylePercent = 2
+)
+
+func xlsxText(s string) xlsxCell {
+	var b strings.Builder
+	xml.EscapeText(&b, []byte(s))
+	return xlsxCell(`t="inlineStr"><is><t>` + b.String() + `</t></is>`)
+}
+
+func xlsxInt(n int64) xlsxCell {
+	return xlsxCell(`><v>` + strconv.FormatInt(n, 10) + `</v>`)
+}
+
+// xlsxRate is a ratio cell formatted as a percentage, empty without data
+func xlsxRate(hit, found int64) xlsxCell {
+	if found == 0 {
+		return xlsxCell(`s="` + strconv.Itoa(xlsxStylePercent) + `">`)
+	}
+	ratio := float64(hit) / float64(found)
+	return xlsxCell(`s="` + strconv.Itoa(xlsxStylePercent) + `"><v>` + strconv.FormatFloat(ratio, 'g', -1, 64) + `</v>`)
+}
+
+// xlsxSheet accumulates the rows of a worksheet, the first being its header
+type xlsxSheet struct {
+	rows [][]xlsxCell
+	// rateColumns are the ranges colored by conditional formatting, e.g. 'D2:D10'
+	rateColumns  []string
+	freezeHeader bool
+}
+
+func (s *xlsxSheet) row(cells ...xlsxCell) {
+	s.rows = append(s.rows, cells)
+}
+
+// xml returns the worksheet part
+func (s *xlsxSheet) xml(thresholds ColorThresholds) string {
+	var b strings.Builder
+	b.WriteString(xml.Header)
+	b.WriteString(`<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">`)
+	if s.freezeHeader {
+		b.WriteString(`<sheetViews><sheetView workbookViewId="0"><pane ySplit="1" topLeftCell="A2" activePane="bottomLeft" state="frozen"/></sheetView></sheetViews>`)
+	}
+	// The first column holds paths and metric names
+	b.WriteString(`<cols><col min="1" max="1" width="50" customWidth="1"/><col min="2" max="10" width="16" customWidth="1"/></cols>`)
+	b.WriteString(`<sheetData>`)
+	for i, cells := range s.rows {
+		row := strconv.Itoa(i + 1)
+		b.WriteString(`<row r="` + row + `">`)
+		for j, cell := range cells {
+			b.WriteString(`<c r="` + string(rune('A'+j)) + row + `"`)
+			if i == 0 {
+				b.WriteString(` s="` + strconv.Itoa(xlsxStyleHeader) + `"`)
+			}
+			b.WriteString(" " + string(cell) + `</c>`)
+		}
+		b.WriteString(`</row>`)
+	}
+	b.WriteString(`</sheetData>`)
+
+	medium := strconv.FormatFloat(thresholds.Medium/100, 'g', -1, 64)
+	high := strconv.FormatFloat(thresholds.High/100, 'g', -1, 64)
+	priority := 1
+	for _, ref := range s.rateColumns {
+		first, _, _ := strings.Cut(ref, ":")
+		// Empty cells of metrics without data are left uncolored
+		rules := []struct {
+			dxf      int
+			operator string
+			formulas []string
+		}{
+			{0, "lessThan", []string{medium}},
+			{1, "between", []string{medium, high}},
+			{2, "greaterThanOrEqual", []string{high}},
+		}
+		b.WriteString(`<conditionalFormatting sqref="` + ref + `">`)
+		b.WriteString(`<cfRule type="containsBlanks" dxfId="3" priority="` + strconv.Itoa(priority) + `" stopIfTrue="1"><formula>LEN(TRIM(` + first + `))=0</formula></cfRule>`)
+		priority++
+		for _, rule := range rules {
+			b.WriteString(`<cfRule type="cellIs" dxfId="` + strconv.Itoa(rule.dxf) + `" priority="` + strconv.Itoa(priority) + `" operator="` + rule.operator + `">`)
+			for _, formula := range rule.formulas {
+				b.WriteString(`<formula>` + formula + `</formula>`)
+			}
+			b.WriteString(`</cfRule>`)
+			priority++
+		}
+		b.WriteString(`</conditionalFormatting>`)
+	}
+	b.WriteString(`</worksheet>`)
+	return b.String()
+}
+
+const xlsxContentTypes = xml.Header + `<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+	`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+	`<Default Extension="xml" ContentType="application/xml"/>` +
+	`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>` +
+	`<Override PartName="/xl/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.styles+xml"/>` +
+	`<Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>` +
+	`<Override PartName="/xl/worksheets/sheet2.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>` +
+	`</Types>`
+
+const xlsxRootRels = xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+	`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>` +
+	`</Relationships>`
+
+const xlsxWorkbook = xml.Header + `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" ` +
+	`xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"><sheets>` +
+	`<sheet name="Summary" sheetId="1" r:id="rId1"/>` +
+	`<sheet name="Files" sheetId="2" r:id="rId2"/>` +
+	`</sheets></workbook>`
+
+const xlsxWorkbookRels = xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+	`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/>` +
+	`<Relationship Id="rId2" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet2.xml"/>` +
+	`<Relationship Id="rId3" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/>` +
+	`</Relationships>`
+
+// xlsxStyles defines the cell formats (default, bold header, percentage) and the
+// fills of the conditional formatting (red, yellow, green, none)
+const xlsxStyles = xml.Header + `<styleSheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">` +
+	`<numFmts count="1"><numFmt numFmtId="164" formatCode="0.0%"/></numFmts>` +
+	`<fonts count="2"><font><sz val="11"/><name val="Calibri"/></font><font><b/><sz val="11"/><name val="Calibri"/></font></fonts>` +
+	`<fills count="2"><fill><patternFill patternType="none"/></fill><fill><patternFill patternType="gray125"/></fill></fills>` +
+	`<borders count="1"><border><left/><right/><top/><bottom/><diagonal/></border></borders>` +
+	`<cellStyleXfs count="1"><xf numFmtId="0" fontId="0" fillId="0" borderId="0"/></cellStyleXfs>` +
+	`<cellXfs count="3">` +
+	`<xf numFmtId="0" fontId="0" fillId="0" borderId="0" xfId="0"/>` +
+	`<xf numFmtId="0" fontId="1" fillId="0" borderId="0" xfId="0" applyFont="1"/>` +
+	`<xf numFmtId="164" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/>` +
+	`</cellXfs>` +
+	`<cellStyles count="1"><cellStyle name="Normal" xfId="0" builtinId="0"/></cellStyles>` +
+	`<dxfs count="4">` +
+	`<dxf><font><color rgb="FF9C0006"/></font><fill><patternFill><bgColor rgb="FFFFC7CE"/></patternFill></fill></dxf>` +
+	`<dxf><font><color rgb="FF9C5700"/></font><fill><patternFill><bgColor rgb="FFFFEB9C"/></patternFill></fill></dxf>` +
+	`<dxf><font><color rgb="FF006100"/></font><fill><patternFill><bgColor rgb="FFC6EFCE"/></patternFill></fill></dxf>` +
+	`<dxf/>` +
+	`</dxfs>` +
+	`</styleSheet>`
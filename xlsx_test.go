@@ -0,0 +1,118 @@
+package lcov
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type xlsxTestSheet struct {
+	Rows []struct {
+		Cells []struct {
+			Ref    string `xml:"r,attr"`
+			Inline string `xml:"is>t"`
+			Value  string `xml:"v"`
+		} `xml:"c"`
+	} `xml:"sheetData>row"`
+	ConditionalFormatting []struct {
+		Ref   string `xml:"sqref,attr"`
+		Rules []struct {
+			Type     string   `xml:"type,attr"`
+			Operator string   `xml:"operator,attr"`
+			Formulas []string `xml:"formula"`
+		} `xml:"cfRule"`
+	} `xml:"conditionalFormatting"`
+}
+
+// readXLSX unzips a workbook, checking that every part is well-formed XML
+func readXLSX(t *testing.T, data []byte) map[string][]byte {
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	require.NoError(t, err)
+	parts := make(map[string][]byte)
+	for _, f := range zr.File {
+		r, err := f.Open()
+		require.NoError(t, err)
+		content, err := io.ReadAll(r)
+		require.NoError(t, err)
+		r.Close()
+		var anything struct{}
+		require.NoError(t, xml.Unmarshal(content, &anything), f.Name)
+		parts[f.Name] = content
+	}
+	return parts
+}
+
+// cellValues returns the values of the cells of a sheet by reference
+func cellValues(t *testing.T, part []byte) (map[string]string, xlsxTestSheet) {
+	var sheet xlsxTestSheet
+	require.NoError(t, xml.Unmarshal(part, &sheet))
+	values := make(map[string]string)
+	for _, row := range sheet.Rows {
+		for _, cell := range row.Cells {
+			values[cell.Ref] = cell.Inline + cell.Value
+		}
+	}
+	return values, sheet
+}
+
+func TestRenderXLSX(t *testing.T) {
+	summary := &Summary{
+		TotalFiles: 2, TotalLines: 10, CoveredLines: 7, TotalFunctions: 4, CoveredFunctions: 1,
+		Files: []FileRecord{
+			{Path: "a<b>.go", LinesFound: 8, LinesHit: 7, FunctionsFound: 4, FunctionsHit: 1},
+			{Path: "c.go", LinesFound: 2, LinesHit: 0},
+		},
+	}
+
+	var out bytes.Buffer
+	require.NoError(t, RenderXLSX(&out, summary, WithColor(ColorThresholds{Medium: 50, High: 80})))
+	parts := readXLSX(t, out.Bytes())
+	assert.Len(t, parts, 7)
+	assert.Contains(t, string(parts["xl/workbook.xml"]), `<sheet name="Summary"`)
+	assert.Contains(t, string(parts["xl/workbook.xml"]), `<sheet name="Files"`)
+
+	values, sheet := cellValues(t, parts["xl/worksheets/sheet1.xml"])
+	assert.Equal(t, "Lines", values["A2"])
+	assert.Equal(t, "7", values["B2"])
+	assert.Equal(t, "10", values["C2"])
+	assert.Equal(t, "0.7", values["D2"])
+	assert.Equal(t, "0.25", values["D3"])
+	assert.Equal(t, "", values["D4"])
+	assert.Equal(t, "2", values["B6"])
+	require.Len(t, sheet.ConditionalFormatting, 1)
+	assert.Equal(t, "D2:D4", sheet.ConditionalFormatting[0].Ref)
+	rules := sheet.ConditionalFormatting[0].Rules
+	require.Len(t, rules, 4)
+	assert.Equal(t, "containsBlanks", rules[0].Type)
+	assert.Equal(t, []string{"0.5"}, rules[1].Formulas)
+	assert.Equal(t, "between", rules[2].Operator)
+	assert.Equal(t, []string{"0.5", "0.8"}, rules[2].Formulas)
+	assert.Equal(t, []string{"0.8"}, rules[3].Formulas)
+
+	values, sheet = cellValues(t, parts["xl/worksheets/sheet2.xml"])
+	assert.Equal(t, "File", values["A1"])
+	assert.Equal(t, "a<b>.go", values["A2"])
+	assert.Equal(t, "0.875", values["D2"])
+	assert.Equal(t, "0.25", values["G2"])
+	assert.Equal(t, "", values["J2"])
+	assert.Equal(t, "c.go", values["A3"])
+	assert.Equal(t, "0", values["D3"])
+	require.Len(t, sheet.ConditionalFormatting, 3)
+	assert.Equal(t, "J2:J3", sheet.ConditionalFormatting[2].Ref)
+}
+
+func TestRenderXLSXReproducible(t *testing.T) {
+	summary := &Summary{TotalLines: 4, CoveredLines: 3}
+	var first, second bytes.Buffer
+	require.NoError(t, RenderFormat("xlsx", &first, summary))
+	require.NoError(t, RenderFormat("xlsx", &second, summary))
+	assert.Equal(t, first.Bytes(), second.Bytes())
+
+	_, sheet := cellValues(t, readXLSX(t, first.Bytes())["xl/worksheets/sheet1.xml"])
+	assert.Equal(t, []string{"0.75"}, sheet.ConditionalFormatting[0].Rules[1].Formulas)
+}